@@ -18,15 +18,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 	cloudprovider "k8s.io/cloud-provider"
 	"k8s.io/klog/v2"
 
@@ -36,24 +36,59 @@ import (
 
 var (
 	// Test configuration
-	kubeconfig     = flag.String("kubeconfig", "", "Path to kubeconfig file")
+	kubeconfig     = flag.String("kubeconfig", "", "Path to kubeconfig file (defaults to the in-cluster service account when running in a Pod)")
+	inCluster      = flag.Bool("in-cluster", false, "Use the in-cluster service account config even if --kubeconfig is set")
+	connectTimeout = flag.Duration("connect-timeout", 30*time.Second, "Timeout for the initial cluster connectivity check")
 	provider       = flag.String("provider", "", "Cloud provider (aws, gcp, azure, mock, existing)")
 	region         = flag.String("region", "", "Cloud provider region")
 	zone           = flag.String("zone", "", "Cloud provider zone")
 	clusterName    = flag.String("cluster", "", "Cluster name")
 	resourcePrefix = flag.String("prefix", "e2e-test", "Prefix for test resources")
+	namePrefix     = flag.String("name-prefix", "", "Prefix prepended to the names of created nodes, services and routes")
 
 	// Test execution
-	suite   = flag.String("suite", "all", "Test suite to run")
-	timeout = flag.Duration("timeout", 30*time.Minute, "Test timeout")
-	verbose = flag.Bool("verbose", false, "Enable verbose output")
-	cleanup = flag.Bool("cleanup", true, "Clean up resources after tests")
+	suite                = flag.String("suite", "all", "Test suite to run")
+	timeout              = flag.Duration("timeout", 30*time.Minute, "Test timeout")
+	verbose              = flag.Bool("verbose", false, "Enable verbose output")
+	cleanup              = flag.Bool("cleanup", true, "Clean up resources after tests")
+	verifyCleanup        = flag.Bool("verify-cleanup", false, "Fail teardown if any created node, service or route was not deleted")
+	keepOnFailure        = flag.Bool("keep-on-failure", false, "Keep the resources created by failed tests for inspection instead of cleaning them up")
+	strictWarnings       = flag.Bool("strict-warnings", false, "Fail the run if the harness reported any warnings, even if all tests passed")
+	useExistingNodes     = flag.Bool("use-existing-nodes", false, "Run node tests against the cluster's existing nodes instead of creating test nodes (existing provider only)")
+	failFast             = flag.Bool("fail-fast", false, "Stop the run at the first failing test")
+	maxLogs              = flag.Int("max-logs", 0, "Maximum number of test log entries to retain (0 = unlimited)")
+	repeat               = flag.Int("repeat", 1, "Run the selected suites N times and report per-test flake rates")
+	concurrentSuites     = flag.Int("concurrent-suites", 0, "Run up to N suites concurrently, each against its own copy of the test environment (0 = one after another; mock and cloud providers only)")
+	randomize            = flag.Bool("randomize", false, "Shuffle the order of tests within each suite, respecting test dependencies")
+	seed                 = flag.Int64("seed", 0, "Seed for --randomize (0 = pick one from the current time)")
+	nodeAddressTypes     = flag.String("node-address-types", "", "Comma-separated node address types the provider must report exactly, e.g. InternalIP,ExternalIP (default: require an InternalIP)")
+	lbSettleTime         = flag.Duration("lb-settle-time", 0, "Wait for a load balancer's status to stay unchanged this long, for providers that fill in ingress incrementally (0 = accept the first status)")
+	teardownTimeout      = flag.Duration("teardown-timeout", 0, "Fail teardown if the test namespace is not deleted within this long (existing provider; 0 = 30s)")
+	expectRegion         = flag.Bool("expect-region", true, "Require the provider to report a region for its zones (disable for single-region clouds)")
+	expectZone           = flag.Bool("expect-zone", false, "Require the provider to report a failure domain for its zones")
+	allowedRegions       = flag.String("allowed-regions", "", "Comma-separated regions the provider may report (default: any)")
+	allowedZones         = flag.String("allowed-zones", "", "Comma-separated zones the provider may report (default: any)")
+	providerIDScheme     = flag.String("provider-id-scheme", "", "Scheme node provider IDs must use, as in <scheme>://<id> (default: the provider's known scheme, or any for unknown providers)")
+	strictValidation     = flag.Bool("strict-validation", false, "Reject test nodes and services the API server would refuse (mock provider)")
+	providerRoutes       = flag.Bool("provider-backed-routes", false, "Create and delete test routes through the cloud provider instead of only tracking them (mock and cloud providers)")
+	deepConformance      = flag.Bool("deep-conformance", false, "Also run the slow and strict tests labeled for deep conformance")
+	reconcileCycles      = flag.Int("reconcile-cycles", 10, "Number of identical ensures the deep-conformance reconcile drift test performs")
+	lbIP                 = flag.String("load-balancer-ip", "", "Address the requested loadBalancerIP test asks for, e.g. a reserved static IP (default: 192.0.2.10)")
+	lbIPSupport          = flag.String("load-balancer-ip-support", "", "Expected outcome of requesting a loadBalancerIP: honored or unsupported (default: accept either)")
+	capabilitiesManifest = flag.String("capabilities-manifest", "", "Path to a YAML file listing the capabilities the provider supports; suites requiring others are skipped")
+	profile              = flag.String("profile", "", "Conformance profile to run instead of --suite: a built-in profile ("+strings.Join(testing.BuiltinConformanceProfiles(), ", ")+") or the path to a YAML profile")
 
 	// Output
-	outputFormat = flag.String("output", "text", "Output format (text, json)")
+	outputFormat      = flag.String("output", "text", "Output format ("+strings.Join(ccmtesting.ReportFormats(), ", ")+")")
+	resultsStore      = flag.String("results-store", "", "Path to a JSONL file each run's summary is appended to for trend tracking")
+	dumpDir           = flag.String("dump-dir", "", "Directory the test nodes, services and routes are dumped to as YAML when a test fails")
+	githubAnnotations = flag.Bool("github-annotations", false, "Group each suite's output and annotate failed tests with GitHub Actions workflow commands (default: on when GITHUB_ACTIONS=true)")
+	dashboard         = flag.String("serve-dashboard", "", "Address to serve a live HTML dashboard of the run on while it lasts, e.g. :8080")
 
 	// Credentials (for real cloud providers)
 	credentialsFile = flag.String("credentials", "", "Path to credentials file")
+	cassette        = flag.String("cassette", "", "Path to an HTTP cassette the real cloud provider's API calls are recorded to or replayed from, for runs without live credentials")
+	cassetteMode    = flag.String("cassette-mode", string(testing.CassetteReplay), "Whether --cassette is recorded from the live cloud (record) or served without it (replay)")
 
 	// Logging
 	logLevel = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
@@ -70,43 +105,106 @@ func main() {
 		klog.Fatal("--provider flag is required")
 	}
 
-	if *provider != "mock" && *provider != "existing" && *kubeconfig == "" {
-		klog.Fatal("--kubeconfig flag is required for real cloud providers (aws, gcp, azure)")
+	var err error
+
+	if _, found := ccmtesting.GetReportFormatter(*outputFormat); !found {
+		klog.Fatalf("Unknown --output format %q (available: %s)", *outputFormat, strings.Join(ccmtesting.ReportFormats(), ", "))
+	}
+
+	if *concurrentSuites > 0 && *repeat > 1 {
+		klog.Fatal("--concurrent-suites cannot be combined with --repeat")
+	}
+
+	var conformanceProfile *testing.ConformanceProfile
+	if *profile != "" {
+		conformanceProfile, err = loadConformanceProfile(*profile)
+		if err != nil {
+			klog.Fatalf("Failed to load conformance profile: %v", err)
+		}
+	}
+
+	if *cassette != "" && !testing.SupportsCassette(*provider) {
+		klog.Fatalf("--cassette is not supported for provider %s, whose API clients do not use the cassette transport", *provider)
+	}
+
+	// Other providers run against a fake clientset, which has no existing nodes
+	if *useExistingNodes && *provider != "existing" {
+		klog.Fatal("--use-existing-nodes requires --provider existing")
+	}
+
+	if *provider != "mock" && *provider != "existing" && *kubeconfig == "" && !*inCluster && !testing.RunningInCluster() {
+		klog.Fatal("--kubeconfig flag is required for real cloud providers (aws, gcp, azure) when not running in a cluster")
 	}
 
 	// Create Kubernetes client
 	var kubeClient kubernetes.Interface
-	var err error
 
 	if *provider == "mock" {
 		klog.Info("Using mock cloud provider")
 	} else {
-		klog.Infof("Connecting to cluster using kubeconfig: %s", *kubeconfig)
-		kubeClient, err = createKubeClient(*kubeconfig)
+		if *kubeconfig != "" && !*inCluster {
+			klog.Infof("Connecting to cluster using kubeconfig: %s", *kubeconfig)
+		} else {
+			klog.Info("Connecting to cluster using in-cluster service account")
+		}
+		kubeClient, err = createKubeClient(*kubeconfig, *inCluster)
 		if err != nil {
 			klog.Fatalf("Failed to create Kubernetes client: %v", err)
 		}
 
 		// Verify cluster connectivity
-		if err := verifyClusterConnection(kubeClient); err != nil {
+		if err := testing.VerifyClusterConnection(kubeClient, *connectTimeout); err != nil {
 			klog.Fatalf("Failed to connect to cluster: %v", err)
 		}
 	}
 
 	// Create test configuration
 	config := &ccmtesting.TestConfig{
-		ProviderName:         *provider,
-		ClusterName:          *clusterName,
-		Region:               *region,
-		Zone:                 *zone,
-		TestTimeout:          *timeout,
-		CleanupResources:     *cleanup,
-		MockExternalServices: *provider == "mock",
+		ProviderName:           *provider,
+		ClusterName:            *clusterName,
+		Region:                 *region,
+		Zone:                   *zone,
+		TestTimeout:            *timeout,
+		CleanupResources:       *cleanup,
+		MockExternalServices:   *provider == "mock",
+		NamePrefix:             *namePrefix,
+		UseExistingNodes:       *useExistingNodes,
+		ExpectRegion:           *expectRegion,
+		LoadBalancerSettleTime: *lbSettleTime,
+		TeardownTimeout:        *teardownTimeout,
+		ExpectZone:             *expectZone,
+		MaxLogs:                *maxLogs,
+		StrictValidation:       *strictValidation,
+		VerifyCleanup:          *verifyCleanup,
+		KeepOnFailure:          *keepOnFailure,
+		StrictWarnings:         *strictWarnings,
+		ProviderBackedRoutes:   *providerRoutes,
 		TestData: map[string]interface{}{
-			"resource-prefix": *resourcePrefix,
-			"test-mode":       "e2e",
+			"resource-prefix":          *resourcePrefix,
+			"test-mode":                "e2e",
+			"reconcile-cycles":         *reconcileCycles,
+			"load-balancer-ip":         *lbIP,
+			"load-balancer-ip-support": *lbIPSupport,
 		},
 	}
+	if *nodeAddressTypes != "" {
+		for _, addressType := range strings.Split(*nodeAddressTypes, ",") {
+			config.ExpectedNodeAddressTypes = append(config.ExpectedNodeAddressTypes, v1.NodeAddressType(strings.TrimSpace(addressType)))
+		}
+	}
+	if *providerIDScheme != "" {
+		config.ProviderIDScheme = *providerIDScheme
+	} else {
+		config.ProviderIDScheme = testing.DefaultProviderIDSchemes[*provider]
+	}
+	if *allowedRegions != "" {
+		config.AllowedRegions = splitList(*allowedRegions)
+	}
+	if *allowedZones != "" {
+		config.AllowedZones = splitList(*allowedZones)
+	}
+	klog.V(2).Infof("Test configuration: provider=%s cluster=%s region=%s zone=%s test data=%v",
+		config.ProviderName, config.ClusterName, config.Region, config.Zone, ccmtesting.RedactTestData(config.TestData))
 
 	// Create cloud provider adapter
 	cloudProvider, err := createCloudProvider(*provider, kubeClient)
@@ -117,7 +215,11 @@ func main() {
 	// Create test interface based on provider type
 	var testImpl ccmtesting.TestInterface
 	if *provider == "existing" {
-		testImpl = testing.NewExistingCCMTestInterface(kubeClient, config)
+		existingImpl := testing.NewExistingCCMTestInterface(kubeClient, config)
+		if err := existingImpl.CheckPermissions(context.Background()); err != nil {
+			klog.Fatalf("Insufficient permissions for the existing CCM tests: %v", err)
+		}
+		testImpl = existingImpl
 	} else {
 		testImpl = testing.NewCCMTestInterface(cloudProvider)
 	}
@@ -128,18 +230,60 @@ func main() {
 	if err != nil {
 		klog.Fatalf("Failed to setup test environment: %v", err)
 	}
-	defer func() {
-		klog.Info("Tearing down test environment...")
-		if err := testImpl.TeardownTestEnvironment(); err != nil {
-			klog.Warningf("Failed to teardown test environment: %v", err)
-		}
-	}()
 
 	// Create test runner
 	runner := ccmtesting.NewTestRunner(testImpl)
+	runner.FailFast = *failFast
+	runner.DumpDir = *dumpDir
+	if *capabilitiesManifest != "" {
+		capabilities, err := testing.LoadCapabilityManifest(*capabilitiesManifest)
+		if err != nil {
+			klog.Fatalf("Failed to load capability manifest: %v", err)
+		}
+		klog.Infof("Provider declares capabilities: %s", strings.Join(capabilities, ", "))
+		runner.SupportedCapabilities = capabilities
+	}
+	if *deepConformance {
+		runner.EnabledLabels = append(runner.EnabledLabels, testing.DeepConformanceLabel)
+	}
+	if *randomize {
+		runner.Randomize = true
+		runner.Seed = *seed
+		if runner.Seed == 0 {
+			runner.Seed = time.Now().UnixNano()
+		}
+		klog.Infof("Randomizing test order with seed %d (rerun with --seed=%d to reproduce)", runner.Seed, runner.Seed)
+	}
 
 	// Add test suites based on provider capabilities
-	addTestSuites(runner, *suite, *provider)
+	if conformanceProfile != nil {
+		suites, err := conformanceProfile.SelectTests(allTestSuites(*provider))
+		if err != nil {
+			klog.Fatalf("Failed to select conformance profile tests: %v", err)
+		}
+		klog.Infof("Running conformance profile %s", conformanceProfile)
+		for _, suite := range suites {
+			addTestSuite(runner, suite)
+		}
+	} else {
+		addTestSuites(runner, *suite, *provider)
+	}
+
+	var runDashboard *testing.Dashboard
+	if *dashboard != "" {
+		runDashboard = testing.NewDashboard(*provider)
+		runDashboard.Attach(runner)
+		if err := runDashboard.Start(*dashboard); err != nil {
+			klog.Fatalf("Failed to serve dashboard: %v", err)
+		}
+		klog.Infof("Serving dashboard on http://%s", runDashboard.Addr())
+	}
+
+	var annotator *testing.GitHubAnnotator
+	if *githubAnnotations || testing.RunningInGitHubActions() {
+		annotator = testing.NewGitHubAnnotator(os.Stdout)
+		annotator.Attach(runner)
+	}
 
 	// Run tests
 	klog.Info("Starting e2e tests...")
@@ -148,27 +292,118 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
-	err = runner.RunTests(ctx)
-	if err != nil {
-		klog.Fatalf("Test execution failed: %v", err)
+	var runErr error
+	if *concurrentSuites > 0 {
+		runErr = runner.RunTestsConcurrent(ctx, *concurrentSuites)
+	} else {
+		runErr = runner.RunTestsRepeated(ctx, *repeat)
+	}
+	if annotator != nil {
+		annotator.Close()
+	}
+
+	if runDashboard != nil {
+		runDashboard.Complete()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := runDashboard.Shutdown(shutdownCtx); err != nil {
+			klog.Warningf("Failed to shut down dashboard: %v", err)
+		}
+		shutdownCancel()
+	}
+	switch {
+	case errors.Is(runErr, ccmtesting.ErrWarningsReported):
+		klog.Errorf("Test run reported warnings with --strict-warnings set: %v", runErr)
+	case errors.Is(runErr, ccmtesting.ErrTestsFailed):
+		// Lists every failure reason by suite; the results below hold the details
+		klog.Errorf("Test run failed: %v", runErr)
+	case errors.Is(runErr, ccmtesting.ErrRunCancelled):
+		klog.Warningf("Test run did not complete, reporting partial results: %v", runErr)
+	case runErr != nil:
+		klog.Errorf("Test execution failed, reporting partial results: %v", runErr)
 	}
 
 	endTime := time.Now()
 
 	// Print results
 	results := runner.GetResults()
+	if *concurrentSuites > 0 {
+		results = runner.GetResultsSorted()
+	}
 	summary := runner.GetSummary()
+	manifest := runner.GetRunManifest()
+	details := ccmtesting.RunDetails{
+		Duration: endTime.Sub(startTime),
+		Logs:     testImpl.GetTestResults().ReportLogs(),
+		Verbose:  *verbose,
+		Manifest: &manifest,
+	}
 
-	printResults(results, summary, startTime, endTime, *outputFormat, *verbose)
+	if err := ccmtesting.FormatReport(os.Stdout, *outputFormat, results, summary, details); err != nil {
+		klog.Errorf("Failed to write %s results: %v", *outputFormat, err)
+	}
+
+	if *repeat > 1 && *outputFormat == "text" {
+		printFlakeRates(runner.GetFlakeRates(), *repeat)
+	}
 
-	// Exit with appropriate code
-	if summary.FailedTests > 0 {
+	var verdict testing.ConformanceVerdict
+	if conformanceProfile != nil {
+		verdict = conformanceProfile.Evaluate(runner.GetResults())
+		if *outputFormat == "text" {
+			printConformanceVerdict(verdict)
+		}
+		// Other formats must stay parseable, so the verdict goes to the log
+		klog.Infof("Conformance verdict: %s", verdict)
+	}
+
+	if *resultsStore != "" {
+		store := testing.NewJSONLResultStore(*resultsStore)
+		if err := store.Append(testing.NewRunRecord(*provider, summary, startTime)); err != nil {
+			klog.Warningf("Failed to record run in results store: %v", err)
+		}
+	}
+
+	// Tear down before exiting, as os.Exit skips deferred calls. A failed
+	// teardown may leave resources behind, so it fails the run.
+	klog.Info("Tearing down test environment...")
+	if err := testImpl.TeardownTestEnvironment(); err != nil {
+		klog.Errorf("Failed to teardown test environment: %v", err)
 		os.Exit(1)
 	}
+
+	// Exit with appropriate code. The verdict of a profile decides on its
+	// own whether failed tests stay within its thresholds.
+	if conformanceProfile != nil {
+		if !verdict.Conformant || (runErr != nil && !errors.Is(runErr, ccmtesting.ErrTestsFailed)) {
+			os.Exit(1)
+		}
+		return
+	}
+	if runErr != nil || summary.FailedTests > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadConformanceProfile returns the built-in conformance profile with the
+// given name, or else loads the profile from the file at that path.
+func loadConformanceProfile(nameOrPath string) (*testing.ConformanceProfile, error) {
+	if profile, found := testing.BuiltinConformanceProfile(nameOrPath); found {
+		return profile, nil
+	}
+	return testing.LoadConformanceProfile(nameOrPath)
+}
+
+// splitList splits a comma-separated flag value, trimming spaces.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		items = append(items, strings.TrimSpace(item))
+	}
+	return items
 }
 
-func createKubeClient(kubeconfigPath string) (kubernetes.Interface, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+func createKubeClient(kubeconfigPath string, inCluster bool) (kubernetes.Interface, error) {
+	config, err := testing.BuildRESTConfig(kubeconfigPath, inCluster)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config: %w", err)
 	}
@@ -181,19 +416,6 @@ func createKubeClient(kubeconfigPath string) (kubernetes.Interface, error) {
 	return clientset, nil
 }
 
-func verifyClusterConnection(client kubernetes.Interface) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	_, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1})
-	if err != nil {
-		return fmt.Errorf("failed to list nodes: %w", err)
-	}
-
-	klog.Info("Successfully connected to cluster")
-	return nil
-}
-
 func createCloudProvider(providerName string, kubeClient kubernetes.Interface) (cloudprovider.Interface, error) {
 	switch strings.ToLower(providerName) {
 	case "mock":
@@ -231,6 +453,10 @@ func createAWSCloudProvider(kubeClient kubernetes.Interface) (cloudprovider.Inte
 		ResourcePrefix:   *resourcePrefix,
 	}
 
+	if err := useCassette(config); err != nil {
+		return nil, err
+	}
+
 	adapter, err := testing.NewAWSCloudProviderAdapter(kubeClient, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS cloud provider adapter: %w", err)
@@ -262,6 +488,10 @@ func createGCPCloudProvider(kubeClient kubernetes.Interface) (cloudprovider.Inte
 		ResourcePrefix:   *resourcePrefix,
 	}
 
+	if err := useCassette(config); err != nil {
+		return nil, err
+	}
+
 	adapter, err := testing.NewGCPCloudProviderAdapter(kubeClient, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GCP cloud provider adapter: %w", err)
@@ -293,6 +523,10 @@ func createAzureCloudProvider(kubeClient kubernetes.Interface) (cloudprovider.In
 		ResourcePrefix:   *resourcePrefix,
 	}
 
+	if err := useCassette(config); err != nil {
+		return nil, err
+	}
+
 	adapter, err := testing.NewAzureCloudProviderAdapter(kubeClient, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Azure cloud provider adapter: %w", err)
@@ -306,6 +540,18 @@ func createAzureCloudProvider(kubeClient kubernetes.Interface) (cloudprovider.In
 	return adapter.GetCloudProvider(), nil
 }
 
+// useCassette routes the provider's API calls through the --cassette, if set.
+func useCassette(config *testing.RealCloudProviderConfig) error {
+	if *cassette == "" {
+		return nil
+	}
+	if _, err := config.UseCassette(*cassette, testing.CassetteMode(*cassetteMode)); err != nil {
+		return fmt.Errorf("failed to use cassette: %w", err)
+	}
+	klog.Infof("Using cassette %s in %s mode", *cassette, *cassetteMode)
+	return nil
+}
+
 func loadCredentials(credentialsFile string) (map[string]string, error) {
 	if credentialsFile == "" {
 		return make(map[string]string), nil
@@ -316,76 +562,101 @@ func loadCredentials(credentialsFile string) (map[string]string, error) {
 	return make(map[string]string), nil
 }
 
+// allTestSuites returns the suites run by --suite=all, including those of
+// plugins for the provider.
+func allTestSuites(provider string) []ccmtesting.TestSuite {
+	suites := []ccmtesting.TestSuite{
+		testing.CreateLoadBalancerTestSuite(),
+		testing.CreateNodeTestSuite(),
+		testing.CreateRouteTestSuite(),
+		testing.CreateInstancesTestSuite(),
+		testing.CreateZonesTestSuite(),
+		testing.CreateClustersTestSuite(),
+		testing.CreateConsistencyTestSuite(),
+		testing.CreateNodeLifecycleTestSuite(),
+	}
+	return append(suites, ccmtesting.CollectSuites(provider)...)
+}
+
 func addTestSuites(runner *ccmtesting.TestRunner, suite, provider string) {
 	switch strings.ToLower(suite) {
 	case "all":
-		runner.AddTestSuite(testing.CreateLoadBalancerTestSuite())
-		runner.AddTestSuite(testing.CreateNodeTestSuite())
-		runner.AddTestSuite(testing.CreateRouteTestSuite())
-		runner.AddTestSuite(testing.CreateInstancesTestSuite())
-		runner.AddTestSuite(testing.CreateZonesTestSuite())
-		runner.AddTestSuite(testing.CreateClustersTestSuite())
+		for _, testSuite := range allTestSuites(provider) {
+			addTestSuite(runner, testSuite)
+		}
+	case "plugins":
+		addPluginSuites(runner, provider)
 	case "loadbalancer":
-		runner.AddTestSuite(testing.CreateLoadBalancerTestSuite())
+		addTestSuite(runner, testing.CreateLoadBalancerTestSuite())
 	case "nodes":
-		runner.AddTestSuite(testing.CreateNodeTestSuite())
+		addTestSuite(runner, testing.CreateNodeTestSuite())
+	case "node-lifecycle":
+		addTestSuite(runner, testing.CreateNodeLifecycleTestSuite())
 	case "routes":
-		runner.AddTestSuite(testing.CreateRouteTestSuite())
+		addTestSuite(runner, testing.CreateRouteTestSuite())
 	case "instances":
-		runner.AddTestSuite(testing.CreateInstancesTestSuite())
+		addTestSuite(runner, testing.CreateInstancesTestSuite())
 	case "zones":
-		runner.AddTestSuite(testing.CreateZonesTestSuite())
+		addTestSuite(runner, testing.CreateZonesTestSuite())
 	case "clusters":
-		runner.AddTestSuite(testing.CreateClustersTestSuite())
+		addTestSuite(runner, testing.CreateClustersTestSuite())
+	case "consistency":
+		addTestSuite(runner, testing.CreateConsistencyTestSuite())
+	case "smoke":
+		addTestSuite(runner, testing.CreateSmokeTestSuite())
 	default:
 		klog.Fatalf("Unknown test suite: %s", suite)
 	}
 }
 
-func printResults(results []ccmtesting.TestResult, summary ccmtesting.TestSummary, startTime, endTime time.Time, format string, verbose bool) {
-	totalDuration := endTime.Sub(startTime)
+// addTestSuite registers a suite with the runner, refusing to start the run if
+// the suite is malformed.
+func addTestSuite(runner *ccmtesting.TestRunner, suite ccmtesting.TestSuite) {
+	if err := runner.AddTestSuiteChecked(suite); err != nil {
+		klog.Fatalf("Failed to add test suite: %v", err)
+	}
+}
 
-	switch format {
-	case "json":
-		printJSONResults(results, summary, totalDuration)
-	default:
-		printTextResults(results, summary, totalDuration, verbose)
+// addPluginSuites registers the suites that plugins linked into the binary
+// contribute for the provider.
+func addPluginSuites(runner *ccmtesting.TestRunner, provider string) {
+	for _, suite := range ccmtesting.CollectSuites(provider) {
+		addTestSuite(runner, suite)
 	}
 }
 
-func printTextResults(results []ccmtesting.TestResult, summary ccmtesting.TestSummary, totalDuration time.Duration, verbose bool) {
-	fmt.Printf("\n=== CCM E2E Test Results ===\n")
-	fmt.Printf("Total Duration: %v\n", totalDuration)
-	fmt.Printf("Test Summary: %d total, %d passed, %d failed, %d skipped\n",
-		summary.TotalTests, summary.PassedTests, summary.FailedTests, summary.SkippedTests)
-
-	if verbose {
-		fmt.Printf("\nDetailed Results:\n")
-		for _, result := range results {
-			status := "PASSED"
-			if !result.Success {
-				status = "FAILED"
-			}
-			if result.Test.Skip {
-				status = "SKIPPED"
-			}
-			fmt.Printf("  %s: %s (%v)\n", status, result.Test.Name, result.Duration)
-
-			// Note: TestResult doesn't have a Logs field in the current interface
-			// Logs are handled through the test interface's GetTestResults() method
+func printFlakeRates(rates []ccmtesting.TestFlakeRate, repeat int) {
+	fmt.Printf("\nFlake Report (%d runs):\n", repeat)
+	var flaky []ccmtesting.TestFlakeRate
+	for _, rate := range rates {
+		fmt.Printf("  %s\n", rate)
+		if rate.Flaky() {
+			flaky = append(flaky, rate)
 		}
 	}
 
-	if summary.FailedTests > 0 {
-		fmt.Printf("\n❌ Some tests failed: %d failed out of %d total\n", summary.FailedTests, summary.TotalTests)
+	if len(flaky) > 0 {
+		fmt.Printf("\n⚠️  Inconsistent tests: %d\n", len(flaky))
+		for _, rate := range flaky {
+			fmt.Printf("  %s\n", rate)
+		}
 	} else {
-		fmt.Printf("\n✅ All tests passed: %d passed out of %d total\n", summary.PassedTests, summary.TotalTests)
+		fmt.Printf("\n✅ All tests behaved consistently across %d runs\n", repeat)
 	}
 }
 
-func printJSONResults(results []ccmtesting.TestResult, summary ccmtesting.TestSummary, totalDuration time.Duration) {
-	// Implementation for JSON output
-	fmt.Printf("JSON output not yet implemented\n")
+func printConformanceVerdict(verdict testing.ConformanceVerdict) {
+	fmt.Printf("\nConformance Profile: %s %s\n", verdict.Profile, verdict.Version)
+	fmt.Printf("  Passed: %d  Failed: %d  Skipped: %d  Not run: %d  (of %d)\n",
+		verdict.Passed, verdict.Failed, verdict.Skipped, verdict.NotRun, verdict.Total)
+	if verdict.Conformant {
+		fmt.Printf("\n✅ CONFORMANT to %s %s\n", verdict.Profile, verdict.Version)
+		return
+	}
+	fmt.Printf("\n❌ NOT CONFORMANT to %s %s\n", verdict.Profile, verdict.Version)
+	for _, reason := range verdict.Reasons {
+		fmt.Printf("  %s\n", reason)
+	}
 }
 
 func setLogLevel(level string) {
@@ -29,7 +29,6 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 
 	ccmtestpkg "github.com/kubernetes/ccm-cloudagnostic-tests/pkg/testing"
@@ -37,10 +36,13 @@ import (
 )
 
 var (
-	kubeconfig = flag.String("kubeconfig", "", "Path to kubeconfig file")
-	namespace  = flag.String("namespace", "ccm-test", "Namespace for testing")
-	timeout    = flag.Duration("timeout", 5*time.Minute, "Test timeout")
-	junitFile  = flag.String("junit-file", "", "Path to JUnit XML output file")
+	kubeconfig     = flag.String("kubeconfig", "", "Path to kubeconfig file (defaults to the in-cluster service account when running in a Pod)")
+	inCluster      = flag.Bool("in-cluster", false, "Use the in-cluster service account config even if --kubeconfig is set")
+	namespace      = flag.String("namespace", "ccm-test", "Namespace for testing")
+	timeout        = flag.Duration("timeout", 5*time.Minute, "Test timeout")
+	connectTimeout = flag.Duration("connect-timeout", 30*time.Second, "Timeout for the initial cluster connectivity check")
+	junitFile      = flag.String("junit-file", "", "Path to JUnit XML output file")
+	lbProvider     = flag.String("lb-provider", "", "Cloud provider expected to provision load balancers (aws, gcp, azure, ibm); empty skips the check")
 )
 
 var (
@@ -75,47 +77,41 @@ var _ = BeforeSuite(func() {
 		}
 	}
 
-	// Check if we're in CI environment (no kubeconfig provided)
-	if *kubeconfig == "" {
+	// Check if we're in CI environment (no kubeconfig provided and not in a cluster)
+	if *kubeconfig == "" && !*inCluster && !ccmtestpkg.RunningInCluster() {
 		Skip("Skipping tests for github actions environment - no kubeconfig provided")
 	}
 
-	// Validate required flags
-	Expect(*kubeconfig).NotTo(BeEmpty(), "kubeconfig flag is required")
-
 	// Create Kubernetes client
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	config, err := ccmtestpkg.BuildRESTConfig(*kubeconfig, *inCluster)
 	Expect(err).NotTo(HaveOccurred(), "Failed to build kubeconfig")
 
 	clientset, err = kubernetes.NewForConfig(config)
 	Expect(err).NotTo(HaveOccurred(), "Failed to create clientset")
 
 	// Verify cluster connectivity
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	_, err = clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1})
+	err = ccmtestpkg.VerifyClusterConnection(clientset, *connectTimeout)
 	Expect(err).NotTo(HaveOccurred(), "Failed to connect to cluster")
 
 	klog.Info("Successfully connected to cluster")
 
 	// Create test interface
-	testInterface = ccmtestpkg.NewExistingCCMTestInterface(clientset, &ccmtesting.TestConfig{
+	testConfig := &ccmtesting.TestConfig{
 		ProviderName: "existing",
 		TestData: map[string]interface{}{
 			"resource-prefix": "existing-ccm-test",
 			"namespace":       *namespace,
 		},
-	})
+	}
+	testInterface = ccmtestpkg.NewExistingCCMTestInterface(clientset, testConfig)
+
+	// Fail early if the RBAC rules do not allow the operations the tests need
+	err = testInterface.CheckPermissions(context.Background())
+	Expect(err).NotTo(HaveOccurred(), "Insufficient permissions for the tests")
 
 	// Setup test environment
 	klog.Info("Setting up test environment...")
-	err = testInterface.SetupTestEnvironment(&ccmtesting.TestConfig{
-		ProviderName: "existing",
-		TestData: map[string]interface{}{
-			"resource-prefix": "existing-ccm-test",
-		},
-	})
+	err = testInterface.SetupTestEnvironment(testConfig)
 	Expect(err).NotTo(HaveOccurred(), "Failed to setup test environment")
 })
 
@@ -131,8 +127,8 @@ var _ = AfterSuite(func() {
 
 var _ = Describe("CCM Load Balancer Tests", Label("loadbalancer"), func() {
 	Context("LoadBalancer Service Creation", func() {
-		It("should create a LoadBalancer service and wait for CCM to provision it", func() {
-			By("Creating a test LoadBalancer service")
+		It("should create a LoadBalancer service and wait for CCM to provision it", Label("smoke"), func() {
+			By("Creating a test LoadBalancer service and waiting for CCM to provision it")
 			serviceConfig := &ccmtesting.TestServiceConfig{
 				Name:      "test-lb-service",
 				Namespace: testInterface.GetNamespace(),
@@ -145,17 +141,11 @@ var _ = Describe("CCM Load Balancer Tests", Label("loadbalancer"), func() {
 				},
 			}
 
-			service, err := testInterface.CreateTestService(context.Background(), serviceConfig)
-			Expect(err).NotTo(HaveOccurred(), "Failed to create test service")
+			service, lbStatus, err := testInterface.CreateLoadBalancerServiceAndWait(context.Background(), serviceConfig, *timeout)
+			Expect(err).NotTo(HaveOccurred(), "Failed to provision load balancer service")
 			Expect(service).NotTo(BeNil(), "Service should not be nil")
 			Expect(service.Name).To(Equal("test-lb-service"), "Service name should match")
 			Expect(service.Namespace).To(Equal(testInterface.GetNamespace()), "Service namespace should match")
-
-			klog.Infof("Created service: %s/%s", service.Namespace, service.Name)
-
-			By("Waiting for CCM to provision the load balancer")
-			lbStatus, err := testInterface.WaitForLoadBalancer(service.Name, *timeout)
-			Expect(err).NotTo(HaveOccurred(), "Failed to wait for load balancer")
 			Expect(lbStatus).NotTo(BeNil(), "Load balancer status should not be nil")
 			Expect(lbStatus.Ingress).NotTo(BeEmpty(), "Load balancer should have ingress")
 
@@ -182,27 +172,60 @@ var _ = Describe("CCM Load Balancer Tests", Label("loadbalancer"), func() {
 				},
 			}
 
-			service, err := testInterface.CreateTestService(context.Background(), serviceConfig)
-			Expect(err).NotTo(HaveOccurred(), "Failed to create test service")
+			service, lbStatus, err := testInterface.CreateLoadBalancerServiceAndWait(context.Background(), serviceConfig, *timeout)
+			Expect(err).NotTo(HaveOccurred(), "Failed to provision load balancer service")
 
-			By("Waiting for load balancer and validating provider")
-			lbStatus, err := testInterface.WaitForLoadBalancer(service.Name, *timeout)
-			Expect(err).NotTo(HaveOccurred(), "Failed to wait for load balancer")
-
-			// Note: Load balancer provider validation would be implemented here
-			// For now, we just verify the load balancer was provisioned
+			By("Validating the load balancer provider")
 			Expect(lbStatus.Ingress).NotTo(BeEmpty(), "Load balancer should have ingress")
+			err = testInterface.ValidateLoadBalancerProvider(lbStatus, *lbProvider)
+			Expect(err).NotTo(HaveOccurred(), "Load balancer was not provisioned by the expected provider")
 
 			By("Cleaning up the service")
 			err = testInterface.DeleteTestService(context.Background(), service.Name)
 			Expect(err).NotTo(HaveOccurred(), "Failed to delete test service")
 		})
 	})
+
+	Context("LoadBalancer Service Deletion", func() {
+		It("should remove a deleted LoadBalancer service and its load balancer", func() {
+			By("Creating a test LoadBalancer service and waiting for CCM to provision it")
+			serviceConfig := &ccmtesting.TestServiceConfig{
+				Name:      "test-lb-deletion",
+				Namespace: testInterface.GetNamespace(),
+				Type:      v1.ServiceTypeLoadBalancer,
+				Ports: []v1.ServicePort{
+					{
+						Port:     80,
+						Protocol: v1.ProtocolTCP,
+					},
+				},
+			}
+
+			service, _, err := testInterface.CreateLoadBalancerServiceAndWait(context.Background(), serviceConfig, *timeout)
+			Expect(err).NotTo(HaveOccurred(), "Failed to provision load balancer service")
+
+			By("Verifying the CCM guards the load balancer with the cleanup finalizer")
+			provisioned, err := clientset.CoreV1().Services(service.Namespace).Get(context.Background(), service.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get provisioned service")
+			Expect(provisioned.Finalizers).To(ContainElement("service.kubernetes.io/load-balancer-cleanup"),
+				"CCM did not add the load balancer cleanup finalizer")
+
+			By("Deleting the service")
+			err = testInterface.DeleteTestService(context.Background(), service.Name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete test service")
+
+			By("Waiting for the service and its load balancer to be removed")
+			err = testInterface.WaitForServiceDeleted(service.Name, *timeout)
+			Expect(err).NotTo(HaveOccurred(), "Service was not removed cleanly")
+
+			klog.Info("✅ Load balancer service removed cleanly")
+		})
+	})
 })
 
 var _ = Describe("CCM Node Management Tests", Label("node-management"), func() {
 	Context("Node Processing Validation", func() {
-		It("should verify CCM has processed existing nodes", func() {
+		It("should verify CCM has processed existing nodes", Label("smoke"), func() {
 			By("Getting existing nodes in the cluster")
 			nodes, err := testInterface.GetExistingNodes()
 			Expect(err).NotTo(HaveOccurred(), "Failed to get existing nodes")
@@ -293,6 +316,29 @@ var _ = Describe("CCM Node Management Tests", Label("node-management"), func() {
 				Expect(hasCloudMetadata).To(BeTrue(), "Node %s should have cloud provider metadata", node.Name)
 			}
 		})
+
+		It("should label initialized nodes with their cloud topology", func() {
+			By("Getting existing nodes")
+			nodes, err := testInterface.GetExistingNodes()
+			Expect(err).NotTo(HaveOccurred(), "Failed to get existing nodes")
+			Expect(nodes).NotTo(BeEmpty(), "No nodes found in the cluster")
+
+			// The existing CCM's cloud provider is not reachable from here, so
+			// only require that the node controller applied non-empty values
+			topologyLabels := map[string]string{
+				v1.LabelTopologyZone:   "",
+				v1.LabelTopologyRegion: "",
+			}
+
+			By("Waiting for the CCM to apply zone and region labels")
+			for _, node := range nodes {
+				labeled, err := testInterface.AwaitNodeLabels(node.Name, topologyLabels, *timeout)
+				Expect(err).NotTo(HaveOccurred(), "Node %s should carry topology labels", node.Name)
+
+				klog.Infof("✅ Node %s is in region %s, zone %s", node.Name,
+					labeled.Labels[v1.LabelTopologyRegion], labeled.Labels[v1.LabelTopologyZone])
+			}
+		})
 	})
 })
 
@@ -312,15 +358,12 @@ var _ = Describe("CCM Integration Tests", Label("integration"), func() {
 				},
 			}
 
-			service, err := testInterface.CreateTestService(context.Background(), serviceConfig)
-			Expect(err).NotTo(HaveOccurred(), "Failed to create integration test service")
+			service, lbStatus, err := testInterface.CreateLoadBalancerServiceAndWait(context.Background(), serviceConfig, *timeout)
+			Expect(err).NotTo(HaveOccurred(), "Failed to provision load balancer service in integration test")
 
-			lbStatus, err := testInterface.WaitForLoadBalancer(service.Name, *timeout)
-			Expect(err).NotTo(HaveOccurred(), "Failed to wait for load balancer in integration test")
-
-			// Note: Load balancer provider validation would be implemented here
-			// For now, we just verify the load balancer was provisioned
 			Expect(lbStatus.Ingress).NotTo(BeEmpty(), "Load balancer should have ingress in integration test")
+			err = testInterface.ValidateLoadBalancerProvider(lbStatus, *lbProvider)
+			Expect(err).NotTo(HaveOccurred(), "Load balancer was not provisioned by the expected provider in integration test")
 
 			// Cleanup
 			err = testInterface.DeleteTestService(context.Background(), service.Name)
@@ -346,3 +389,20 @@ var _ = Describe("CCM Integration Tests", Label("integration"), func() {
 		})
 	})
 })
+
+var _ = Describe("CCM Plugin Suites", Label("plugins"), func() {
+	// Suites contributed by plugins linked into the test binary for the
+	// existing provider, one spec per suite
+	for _, suite := range ccmtesting.CollectSuites("existing") {
+		It("should pass the "+suite.Name+" plugin suite", func() {
+			runner := ccmtesting.NewTestRunner(testInterface)
+			Expect(runner.AddTestSuiteChecked(suite)).To(Succeed(), "Invalid plugin suite")
+
+			err := runner.RunTests(context.Background())
+			for _, result := range runner.GetResults() {
+				Expect(result.Error).NotTo(HaveOccurred(), "Plugin test %s failed", result.Test.Name)
+			}
+			Expect(err).NotTo(HaveOccurred(), "Failed to run plugin suite")
+		})
+	}
+})
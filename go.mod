@@ -11,6 +11,7 @@ require (
 	k8s.io/client-go v0.33.4
 	k8s.io/cloud-provider v0.33.3
 	k8s.io/klog/v2 v2.130.1
+	sigs.k8s.io/yaml v1.4.0
 )
 
 replace github.com/miyadav/cloud-provider-testing-interface => ./third_party/github.com/miyadav/cloud-provider-testing-interface
@@ -54,5 +55,4 @@ require (
 	sigs.k8s.io/json v0.0.0-20241010143419-9aa6b5e7a4b3 // indirect
 	sigs.k8s.io/randfill v1.0.0 // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.6.0 // indirect
-	sigs.k8s.io/yaml v1.4.0 // indirect
 )
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Capabilities a provider can declare in a capability manifest, matched against
+// the RequiredCapabilities of the built-in suites.
+const (
+	CapabilityLoadBalancer = "loadbalancer"
+	CapabilityRoutes       = "routes"
+	CapabilityInstancesV2  = "instancesv2"
+	CapabilityZones        = "zones"
+	CapabilityClusters     = "clusters"
+
+	// CapabilityInstances is support for either the legacy Instances or the
+	// InstancesV2 interface, which the instance suites resolve alike. A
+	// manifest listing instancesv2 implies it.
+	CapabilityInstances = "instances"
+)
+
+// knownCapabilities are the capabilities a capability manifest may list.
+var knownCapabilities = map[string]bool{
+	CapabilityLoadBalancer: true,
+	CapabilityRoutes:       true,
+	CapabilityInstances:    true,
+	CapabilityInstancesV2:  true,
+	CapabilityZones:        true,
+	CapabilityClusters:     true,
+}
+
+// CapabilityManifest lists the capabilities a provider supports. It covers
+// features the provider implements the interface for but has disabled, which
+// probing the cloud provider at run time cannot detect.
+//
+//	capabilities:
+//	- loadbalancer
+//	- instancesv2
+type CapabilityManifest struct {
+	Capabilities []string `json:"capabilities"`
+}
+
+// LoadCapabilityManifest reads a capability manifest from a YAML file and
+// returns the capabilities it lists, which are never nil so that every suite
+// requiring a capability is skipped if the list is empty. Listing instancesv2
+// adds instances, which it implies. Unknown capabilities are rejected to catch
+// typos that would silently skip suites.
+func LoadCapabilityManifest(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capability manifest: %w", err)
+	}
+
+	var manifest CapabilityManifest
+	if err := yaml.UnmarshalStrict(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse capability manifest %s: %w", path, err)
+	}
+
+	var unknown []string
+	capabilities := make([]string, 0, len(manifest.Capabilities))
+	for _, capability := range manifest.Capabilities {
+		capability = strings.ToLower(strings.TrimSpace(capability))
+		if !knownCapabilities[capability] {
+			unknown = append(unknown, capability)
+			continue
+		}
+		capabilities = append(capabilities, capability)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("capability manifest %s lists unknown capabilities %s (known: %s)",
+			path, strings.Join(unknown, ", "), strings.Join(KnownCapabilities(), ", "))
+	}
+
+	if slices.Contains(capabilities, CapabilityInstancesV2) && !slices.Contains(capabilities, CapabilityInstances) {
+		capabilities = append(capabilities, CapabilityInstances)
+	}
+	return capabilities, nil
+}
+
+// KnownCapabilities returns the capabilities a capability manifest may list,
+// sorted.
+func KnownCapabilities() []string {
+	names := make([]string, 0, len(knownCapabilities))
+	for name := range knownCapabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// writeCapabilityManifest writes a capability manifest to a temporary file
+func writeCapabilityManifest(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "capabilities.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write capability manifest: %v", err)
+	}
+	return path
+}
+
+// TestLoadCapabilityManifest tests loading valid and invalid capability manifests
+func TestLoadCapabilityManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		wantErr  string
+	}{
+		{
+			name:     "listed capabilities",
+			content:  "capabilities:\n- loadbalancer\n- InstancesV2\n",
+			expected: []string{"loadbalancer", "instancesv2", "instances"},
+		},
+		{
+			name:     "legacy instances",
+			content:  "capabilities:\n- instances\n",
+			expected: []string{"instances"},
+		},
+		{
+			name:     "no capabilities",
+			content:  "capabilities: []\n",
+			expected: []string{},
+		},
+		{
+			name:    "unknown capability",
+			content: "capabilities:\n- loadbalancers\n",
+			wantErr: "unknown capabilities loadbalancers",
+		},
+		{
+			name:    "unknown field",
+			content: "features:\n- routes\n",
+			wantErr: "failed to parse capability manifest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capabilities, err := LoadCapabilityManifest(writeCapabilityManifest(t, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(capabilities, tt.expected) {
+				t.Errorf("Expected capabilities %v, got %v", tt.expected, capabilities)
+			}
+		})
+	}
+}
+
+// TestCapabilityManifestSkipsRouteSuite tests that the route suite is skipped
+// when the capability manifest does not list routes
+func TestCapabilityManifestSkipsRouteSuite(t *testing.T) {
+	capabilities, err := LoadCapabilityManifest(writeCapabilityManifest(t, "capabilities:\n- loadbalancer\n- zones\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ti, _ := newMockTestInterface(t)
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.SupportedCapabilities = capabilities
+	runner.AddTestSuite(CreateRouteTestSuite())
+	runner.AddTestSuite(CreateZonesTestSuite())
+
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, result := range runner.GetResults() {
+		switch result.Suite {
+		case "RouteManagement":
+			if !result.Test.Skip || !strings.Contains(result.Test.SkipReason, CapabilityRoutes) {
+				t.Errorf("Expected %s to be skipped for the routes capability, got skip=%t reason '%s'", result.Test.Name, result.Test.Skip, result.Test.SkipReason)
+			}
+		case "Zones":
+			if result.Test.Skip {
+				t.Errorf("Expected %s to run, got skipped: %s", result.Test.Name, result.Test.SkipReason)
+			}
+		}
+	}
+}
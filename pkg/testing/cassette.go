@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// CassetteMode is whether a cassette captures live HTTP interactions or
+// serves previously captured ones.
+type CassetteMode string
+
+const (
+	// CassetteRecord forwards requests to the cloud and writes every
+	// interaction to the cassette, replacing its previous contents.
+	CassetteRecord CassetteMode = "record"
+
+	// CassetteReplay serves the responses recorded in the cassette without
+	// contacting the cloud.
+	CassetteReplay CassetteMode = "replay"
+)
+
+// Cassette is a recording of the HTTP interactions of a cloud provider's API
+// clients, so that real-provider suites can be replayed deterministically
+// without credentials.
+//
+//	interactions:
+//	- request:
+//	    method: GET
+//	    url: https://metadata.example.com/v1/zone
+//	  response:
+//	    status: 200
+//	    body: '{"region":"us-east-1"}'
+//
+// Recorded bodies are stored verbatim, so review a cassette for secrets
+// before committing it.
+type Cassette struct {
+	// Interactions are the recorded requests and their responses, in the
+	// order they were made.
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// CassetteInteraction is a single recorded request and its response.
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest identifies a recorded request. Replayed requests match it
+// by method, URL and body.
+type CassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// CassetteResponse is a recorded response.
+type CassetteResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette from a YAML file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var cassette Cassette
+	if err := yaml.UnmarshalStrict(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// CassetteRoundTripper is an http.RoundTripper backed by a cassette. In record
+// mode it forwards requests to the next round tripper and saves every
+// interaction to the cassette file as it completes, so that an interrupted
+// run keeps what it recorded. In replay mode it answers requests from the
+// cassette: each request gets the first recorded response to a matching
+// request it has not served yet, and once all are served the last one again,
+// as providers polling for a state repeat the same request.
+type CassetteRoundTripper struct {
+	path string
+	mode CassetteMode
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	served   []bool
+}
+
+// NewCassetteRoundTripper creates a round tripper recording to or replaying
+// from the cassette at path. next carries the requests in record mode; nil
+// uses http.DefaultTransport.
+func NewCassetteRoundTripper(path string, mode CassetteMode, next http.RoundTripper) (*CassetteRoundTripper, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c := &CassetteRoundTripper{path: path, mode: mode, next: next}
+
+	switch mode {
+	case CassetteRecord:
+		c.cassette.Interactions = []CassetteInteraction{}
+		if err := c.save(); err != nil {
+			return nil, err
+		}
+	case CassetteReplay:
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		c.cassette = *cassette
+		c.served = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q, expected %s or %s", mode, CassetteRecord, CassetteReplay)
+	}
+	return c, nil
+}
+
+// RoundTrip records or replays a single request.
+func (c *CassetteRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, request, err := cassetteRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.mode == CassetteReplay {
+		return c.replay(req, request)
+	}
+
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response to %s %s: %w", request.Method, request.URL, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cassette.Interactions = append(c.cassette.Interactions, CassetteInteraction{
+		Request:  request,
+		Response: CassetteResponse{Status: resp.StatusCode, Headers: resp.Header.Clone(), Body: string(body)},
+	})
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// replay returns the recorded response to the request.
+func (c *CassetteRoundTripper) replay(req *http.Request, request CassetteRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	match := -1
+	for i, interaction := range c.cassette.Interactions {
+		if interaction.Request != request {
+			continue
+		}
+		match = i
+		if !c.served[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("cassette %s has no recorded response to %s %s", c.path, request.Method, request.URL)
+	}
+	c.served[match] = true
+
+	recorded := c.cassette.Interactions[match].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// save writes the cassette to its file. Callers hold c.mu, except while the
+// round tripper is created.
+func (c *CassetteRoundTripper) save() error {
+	data, err := yaml.Marshal(c.cassette)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// cassetteRequest returns the recorded form of the request, and a copy of the
+// request whose body can still be read by the next round tripper.
+func cassetteRequest(req *http.Request) (*http.Request, CassetteRequest, error) {
+	request := CassetteRequest{Method: req.Method, URL: req.URL.String()}
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, request, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, request, fmt.Errorf("failed to read body of %s %s: %w", req.Method, request.URL, err)
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	request.Body = string(body)
+	return req, request, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// httpZones reads the zone from a metadata endpoint over HTTP, as real
+// providers read it from their cloud's API
+type httpZones struct {
+	client *http.Client
+	url    string
+}
+
+func (z *httpZones) GetZone(ctx context.Context) (cloudprovider.Zone, error) {
+	var zone cloudprovider.Zone
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, z.url, nil)
+	if err != nil {
+		return zone, err
+	}
+	resp, err := z.client.Do(req)
+	if err != nil {
+		return zone, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return zone, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return zone, json.NewDecoder(resp.Body).Decode(&zone)
+}
+
+func (z *httpZones) GetZoneByProviderID(ctx context.Context, providerID string) (cloudprovider.Zone, error) {
+	return z.GetZone(ctx)
+}
+
+func (z *httpZones) GetZoneByNodeName(ctx context.Context, nodeName types.NodeName) (cloudprovider.Zone, error) {
+	return z.GetZone(ctx)
+}
+
+// httpZonesProvider is a provider whose zones come from an HTTP API reached
+// through the transport of its config
+type httpZonesProvider struct {
+	*MockCloudProvider
+	zones *httpZones
+}
+
+func newHTTPZonesProvider(config *RealCloudProviderConfig, url string) *httpZonesProvider {
+	return &httpZonesProvider{
+		MockCloudProvider: NewMockCloudProvider(),
+		zones:             &httpZones{client: &http.Client{Transport: config.Transport}, url: url},
+	}
+}
+
+func (p *httpZonesProvider) Zones() (cloudprovider.Zones, bool) {
+	return p.zones, true
+}
+
+// TestCassetteReplay tests that a provider using a replayed cassette returns
+// the recorded responses in order without contacting the cloud
+func TestCassetteReplay(t *testing.T) {
+	ctx := context.Background()
+	config := &RealCloudProviderConfig{ProviderName: "aws"}
+	if _, err := config.UseCassette(filepath.Join("testdata", "zones-cassette.yaml"), CassetteReplay); err != nil {
+		t.Fatalf("Failed to use cassette: %v", err)
+	}
+	zones, _ := newHTTPZonesProvider(config, "https://metadata.example.com/v1/zone").Zones()
+
+	// The last recorded response is served again once all are served
+	for _, expected := range []string{"us-east-1a", "us-east-1b", "us-east-1b"} {
+		zone, err := zones.GetZone(ctx)
+		if err != nil {
+			t.Fatalf("Expected the recorded zone, got %v", err)
+		}
+		if zone.FailureDomain != expected || zone.Region != "us-east-1" {
+			t.Errorf("Expected zone %s in region us-east-1, got %+v", expected, zone)
+		}
+	}
+
+	unrecorded, _ := newHTTPZonesProvider(config, "https://metadata.example.com/v1/region").Zones()
+	if _, err := unrecorded.GetZone(ctx); err == nil || !strings.Contains(err.Error(), "has no recorded response to GET https://metadata.example.com/v1/region") {
+		t.Errorf("Expected an error for an unrecorded request, got %v", err)
+	}
+}
+
+// TestCassetteRecord tests that recording captures live interactions that
+// replay without the cloud
+func TestCassetteRecord(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"failureDomain":"zone-a","region":"region-1"}`)
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+	recordConfig := &RealCloudProviderConfig{}
+	if _, err := recordConfig.UseCassette(path, CassetteRecord); err != nil {
+		t.Fatalf("Failed to use cassette: %v", err)
+	}
+	zones, _ := newHTTPZonesProvider(recordConfig, server.URL).Zones()
+	if zone, err := zones.GetZone(ctx); err != nil || zone.FailureDomain != "zone-a" {
+		t.Fatalf("Expected the live zone zone-a, got %+v, %v", zone, err)
+	}
+	server.Close()
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("Failed to load recorded cassette: %v", err)
+	}
+	if len(cassette.Interactions) != 1 || cassette.Interactions[0].Request.URL != server.URL {
+		t.Fatalf("Expected one interaction with %s, got %+v", server.URL, cassette.Interactions)
+	}
+
+	replayConfig := &RealCloudProviderConfig{}
+	if _, err := replayConfig.UseCassette(path, CassetteReplay); err != nil {
+		t.Fatalf("Failed to use cassette: %v", err)
+	}
+	zones, _ = newHTTPZonesProvider(replayConfig, server.URL).Zones()
+	if zone, err := zones.GetZone(ctx); err != nil || zone.FailureDomain != "zone-a" || zone.Region != "region-1" {
+		t.Errorf("Expected the recorded zone zone-a in region-1, got %+v, %v", zone, err)
+	}
+
+	if _, err := NewCassetteRoundTripper(path, "rewind", nil); err == nil || !strings.Contains(err.Error(), `unknown cassette mode "rewind"`) {
+		t.Errorf("Expected an unknown mode error, got %v", err)
+	}
+	if _, err := NewCassetteRoundTripper(filepath.Join(t.TempDir(), "missing.yaml"), CassetteReplay, nil); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected error wrapping os.ErrNotExist, got %v", err)
+	}
+}
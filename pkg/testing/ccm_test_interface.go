@@ -18,15 +18,23 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	cloudprovider "k8s.io/cloud-provider"
 	"k8s.io/klog/v2"
 
@@ -45,6 +53,9 @@ type CCMTestInterface struct {
 	// Informer factory for watching resources
 	informerFactory informers.SharedInformerFactory
 
+	// Closed on teardown to stop the informers started by the factory
+	informerStop chan struct{}
+
 	// Test configuration
 	config *ccmtesting.TestConfig
 
@@ -55,6 +66,16 @@ type CCMTestInterface struct {
 	createdResources map[string][]string
 	mu               sync.RWMutex
 
+	// Resources of failed tests that teardown leaves in place, by type and name
+	keptResources map[string]map[string]bool
+
+	// Created routes by name, since routes are not stored in the clientset
+	routes map[string]*cloudprovider.Route
+
+	// Clusters the routes created through the cloud provider belong to, by
+	// route name
+	routeClusters map[string]string
+
 	// Mock services for testing
 	mockServices map[string]interface{}
 }
@@ -65,6 +86,9 @@ func NewCCMTestInterface(cloudProvider cloudprovider.Interface) *CCMTestInterfac
 		cloudProvider:    cloudProvider,
 		kubeClient:       fake.NewSimpleClientset(),
 		createdResources: make(map[string][]string),
+		keptResources:    make(map[string]map[string]bool),
+		routes:           make(map[string]*cloudprovider.Route),
+		routeClusters:    make(map[string]string),
 		mockServices:     make(map[string]interface{}),
 		results: &ccmtesting.TestResults{
 			ResourceCounts: make(map[string]int),
@@ -76,54 +100,177 @@ func NewCCMTestInterface(cloudProvider cloudprovider.Interface) *CCMTestInterfac
 // SetupTestEnvironment initializes the test environment with the given configuration.
 func (c *CCMTestInterface) SetupTestEnvironment(config *ccmtesting.TestConfig) error {
 	c.config = config
-	c.results = &ccmtesting.TestResults{
+	results := &ccmtesting.TestResults{
 		ResourceCounts: make(map[string]int),
 		Metrics:        make(map[string]interface{}),
+		MaxLogs:        config.MaxLogs,
 	}
+	c.mu.Lock()
+	c.results = results
+	c.mu.Unlock()
 
 	// Initialize informer factory
 	if config.InformerFactory != nil {
 		c.informerFactory = config.InformerFactory
 	} else {
-		c.informerFactory = informers.NewSharedInformerFactory(c.kubeClient, 0)
+		c.informerFactory = informers.NewSharedInformerFactory(c.kubeClient, config.ResyncPeriod)
+	}
+
+	// Start informers; they run until the environment is torn down
+	c.informerStop = make(chan struct{})
+	c.informerFactory.Start(c.informerStop)
+
+	if err := c.waitForInformerSync(config.InformerSyncTimeout); err != nil {
+		close(c.informerStop)
+		c.informerStop = nil
+		return err
 	}
 
-	// Start informers
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	results.AddLog(fmt.Sprintf("Test environment setup completed for provider: %s", config.ProviderName))
+	return nil
+}
+
+// defaultInformerSyncTimeout is how long setup waits for informers to sync
+// when the TestConfig does not set InformerSyncTimeout.
+const defaultInformerSyncTimeout = 30 * time.Second
+
+// waitForInformerSync waits for the started informers to sync, naming the
+// informer types that did not sync within the timeout.
+func (c *CCMTestInterface) waitForInformerSync(timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultInformerSyncTimeout
+	}
+
+	// Only the wait is bounded; the informers keep running on informerStop
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	c.informerFactory.Start(ctx.Done())
 
-	// Wait for informers to sync
 	syncResult := c.informerFactory.WaitForCacheSync(ctx.Done())
-	for _, synced := range syncResult {
+	if len(syncResult) == 0 {
+		klog.Warning("No informers are registered with the informer factory; skipping cache sync")
+		c.GetTestResults().AddLog("Warning: no informers registered, skipping cache sync")
+		return nil
+	}
+
+	var unsynced []string
+	for informerType, synced := range syncResult {
 		if !synced {
-			return fmt.Errorf("failed to sync informers")
+			unsynced = append(unsynced, informerType.String())
 		}
 	}
-
-	c.results.AddLog(fmt.Sprintf("Test environment setup completed for provider: %s", config.ProviderName))
+	if len(unsynced) > 0 {
+		sort.Strings(unsynced)
+		return fmt.Errorf("failed to sync informers within %v: %s", timeout, strings.Join(unsynced, ", "))
+	}
 	return nil
 }
 
 // TeardownTestEnvironment cleans up the test environment and removes any test resources.
 func (c *CCMTestInterface) TeardownTestEnvironment() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	// Clean up created resources if cleanup is enabled
 	if c.config != nil && c.config.CleanupResources {
-		for resourceType, resources := range c.createdResources {
-			for _, resourceName := range resources {
-				c.results.AddLog(fmt.Sprintf("Cleaning up %s: %s", resourceType, resourceName))
-				// Cleanup logic would be implemented here based on resource type
+		c.cleanupResources(context.Background())
+	}
+
+	c.mu.Lock()
+	if c.informerStop != nil {
+		close(c.informerStop)
+		c.informerStop = nil
+	}
+	c.mu.Unlock()
+
+	results := c.GetTestResults()
+	if c.config != nil && c.config.VerifyCleanup {
+		if err := results.CheckCleanup(); err != nil {
+			results.AddLog(fmt.Sprintf("Cleanup check failed: %v", err))
+			return fmt.Errorf("test environment teardown: %w", err)
+		}
+	}
+
+	results.AddLog("Test environment teardown completed")
+	return nil
+}
+
+// cleanupResources deletes the tracked resources, except those kept for
+// inspection after a test failure.
+func (c *CCMTestInterface) cleanupResources(ctx context.Context) {
+	for resourceType, resources := range c.TrackedResources() {
+		for _, resourceName := range resources {
+			c.mu.RLock()
+			kept := c.keptResources[resourceType][resourceName]
+			c.mu.RUnlock()
+			if kept {
+				c.GetTestResults().AddLog(fmt.Sprintf("Keeping %s: %s", resourceType, resourceName))
+				continue
+			}
+
+			c.GetTestResults().AddLog(fmt.Sprintf("Cleaning up %s: %s", resourceType, resourceName))
+			if err := c.deleteTrackedResource(ctx, resourceType, resourceName); err != nil {
+				warnf(c.GetTestResults(), "Failed to clean up %s %s: %v", resourceType, resourceName, err)
 			}
 		}
 	}
+}
 
-	c.results.AddLog("Test environment teardown completed")
+// deleteTrackedResource deletes a tracked resource and stops tracking it. A
+// resource that is already gone counts as deleted.
+func (c *CCMTestInterface) deleteTrackedResource(ctx context.Context, resourceType, name string) error {
+	var countType string
+	var err error
+	switch {
+	case resourceType == "nodes":
+		countType = ccmtesting.ResourceTypeNodes
+		err = c.kubeClient.CoreV1().Nodes().Delete(ctx, name, metav1.DeleteOptions{})
+	case strings.HasPrefix(resourceType, "services/"):
+		countType = ccmtesting.ResourceTypeServices
+		namespace := strings.TrimPrefix(resourceType, "services/")
+		err = c.kubeClient.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	case resourceType == "routes":
+		countType = ccmtesting.ResourceTypeRoutes
+		err = c.deleteRoute(ctx, name)
+	default:
+		return fmt.Errorf("unknown resource type %s", resourceType)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if c.untrackResource(resourceType, name) {
+		c.GetTestResults().DecrementResourceCount(countType)
+	}
 	return nil
 }
 
+// TrackedResources returns a copy of the names of the created resources that
+// are still tracked, keyed by resource type.
+func (c *CCMTestInterface) TrackedResources() map[string][]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	resources := make(map[string][]string, len(c.createdResources))
+	for resourceType, names := range c.createdResources {
+		if len(names) > 0 {
+			resources[resourceType] = append([]string(nil), names...)
+		}
+	}
+	return resources
+}
+
+// KeepResources excludes the given resources from the cleanup on teardown.
+func (c *CCMTestInterface) KeepResources(resources map[string][]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for resourceType, names := range resources {
+		if c.keptResources[resourceType] == nil {
+			c.keptResources[resourceType] = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.keptResources[resourceType][name] = true
+		}
+	}
+}
+
 // GetCloudProvider returns the cloud provider instance to be tested.
 func (c *CCMTestInterface) GetCloudProvider() cloudprovider.Interface {
 	return c.cloudProvider
@@ -131,14 +278,18 @@ func (c *CCMTestInterface) GetCloudProvider() cloudprovider.Interface {
 
 // CreateTestNode creates a test node with the specified configuration.
 func (c *CCMTestInterface) CreateTestNode(ctx context.Context, nodeConfig *ccmtesting.TestNodeConfig) (*v1.Node, error) {
+	nodeName := c.config.ResourceNameOrGenerate(nodeConfig.Name, "test-node")
 	node := &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        nodeConfig.Name,
+			Name:        nodeName,
 			Labels:      nodeConfig.Labels,
 			Annotations: nodeConfig.Annotations,
 		},
 		Spec: v1.NodeSpec{
 			ProviderID: nodeConfig.ProviderID,
+			Taints:     nodeConfig.Taints,
+			PodCIDR:    nodeConfig.PrimaryPodCIDR(),
+			PodCIDRs:   nodeConfig.PodCIDRs,
 		},
 		Status: v1.NodeStatus{
 			Addresses:  nodeConfig.Addresses,
@@ -146,50 +297,141 @@ func (c *CCMTestInterface) CreateTestNode(ctx context.Context, nodeConfig *ccmte
 		},
 	}
 
+	if c.strictValidation() {
+		if err := validateNode(node); err != nil {
+			return nil, fmt.Errorf("failed to create test node: %w", err)
+		}
+	}
+
 	createdNode, err := c.kubeClient.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create test node: %w", err)
 	}
 
+	if registrar, ok := c.cloudProvider.(nodeRegistrar); ok {
+		registrar.RegisterNode(nodeName, testNodeData(nodeConfig))
+	}
+
 	// Track created resource
 	c.mu.Lock()
-	c.createdResources["nodes"] = append(c.createdResources["nodes"], nodeConfig.Name)
-	c.results.IncrementResourceCount("nodes")
+	c.createdResources["nodes"] = append(c.createdResources["nodes"], nodeName)
+	c.results.IncrementResourceCount(ccmtesting.ResourceTypeNodes)
 	c.mu.Unlock()
 
-	c.results.AddLog(fmt.Sprintf("Created test node: %s", nodeConfig.Name))
+	c.GetTestResults().AddLog(fmt.Sprintf("Created test node: %s", nodeName))
 	return createdNode, nil
 }
 
+// nodeRegistrar is implemented by cloud providers that back nodes with
+// registered instance data, such as MockCloudProvider. CreateTestNode
+// registers an existing instance matching each test node with them.
+type nodeRegistrar interface {
+	RegisterNode(name string, data NodeData)
+}
+
+// testNodeData returns the data of a running instance matching nodeConfig.
+func testNodeData(nodeConfig *ccmtesting.TestNodeConfig) NodeData {
+	data := NodeData{
+		ProviderID:   nodeConfig.ProviderID,
+		InstanceType: nodeConfig.InstanceType,
+		Addresses:    nodeConfig.Addresses,
+		Zone:         nodeConfig.Zone,
+		Region:       nodeConfig.Region,
+		Exists:       true,
+	}
+	for _, address := range nodeConfig.Addresses {
+		if address.Type == v1.NodeHostName {
+			data.Hostname = address.Address
+			break
+		}
+	}
+	return data
+}
+
+// UpdateTestNode updates an existing test node with the specified configuration.
+func (c *CCMTestInterface) UpdateTestNode(ctx context.Context, nodeConfig *ccmtesting.TestNodeConfig) (*v1.Node, error) {
+	nodeName := c.config.ResourceName(nodeConfig.Name)
+	nodes := c.kubeClient.CoreV1().Nodes()
+
+	node, err := nodes.Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test node: %w", err)
+	}
+
+	ccmtesting.ApplyTestNodeConfig(node, nodeConfig)
+
+	if c.strictValidation() {
+		if err := validateNode(node); err != nil {
+			return nil, fmt.Errorf("failed to update test node: %w", err)
+		}
+	}
+
+	updatedNode, err := nodes.Update(ctx, node, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update test node: %w", err)
+	}
+
+	// Addresses and conditions live in the node status, which Update does not write
+	if nodeConfig.Addresses != nil || nodeConfig.Conditions != nil {
+		updatedNode.Status = node.Status
+		updatedNode, err = nodes.UpdateStatus(ctx, updatedNode, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update test node status: %w", err)
+		}
+	}
+
+	c.GetTestResults().AddLog(fmt.Sprintf("Updated test node: %s", nodeName))
+	return updatedNode, nil
+}
+
 // DeleteTestNode deletes a test node.
 func (c *CCMTestInterface) DeleteTestNode(ctx context.Context, nodeName string) error {
+	nodeName = c.config.ResourceName(nodeName)
 	err := c.kubeClient.CoreV1().Nodes().Delete(ctx, nodeName, metav1.DeleteOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete test node: %w", err)
 	}
 
-	c.results.AddLog(fmt.Sprintf("Deleted test node: %s", nodeName))
+	if c.untrackResource("nodes", nodeName) {
+		c.GetTestResults().DecrementResourceCount(ccmtesting.ResourceTypeNodes)
+	}
+
+	c.GetTestResults().AddLog(fmt.Sprintf("Deleted test node: %s", nodeName))
 	return nil
 }
 
 // CreateTestService creates a test service with the specified configuration.
 func (c *CCMTestInterface) CreateTestService(ctx context.Context, serviceConfig *ccmtesting.TestServiceConfig) (*v1.Service, error) {
+	if err := ccmtesting.ValidateTestServiceConfig(serviceConfig); err != nil {
+		return nil, err
+	}
+
+	serviceName := c.config.ResourceNameOrGenerate(serviceConfig.Name, "test-service")
 	service := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        serviceConfig.Name,
+			Name:        serviceName,
 			Namespace:   serviceConfig.Namespace,
 			Labels:      serviceConfig.Labels,
 			Annotations: serviceConfig.Annotations,
 		},
 		Spec: v1.ServiceSpec{
-			Type:                  serviceConfig.Type,
-			Ports:                 serviceConfig.Ports,
-			LoadBalancerIP:        serviceConfig.LoadBalancerIP,
-			ExternalTrafficPolicy: serviceConfig.ExternalTrafficPolicy,
-			InternalTrafficPolicy: serviceConfig.InternalTrafficPolicy,
+			Type:                          serviceConfig.Type,
+			Ports:                         serviceConfig.Ports,
+			LoadBalancerIP:                serviceConfig.LoadBalancerIP,
+			ExternalTrafficPolicy:         serviceConfig.ExternalTrafficPolicy,
+			HealthCheckNodePort:           serviceConfig.HealthCheckNodePort,
+			InternalTrafficPolicy:         serviceConfig.InternalTrafficPolicy,
+			LoadBalancerClass:             serviceConfig.LoadBalancerClass,
+			AllocateLoadBalancerNodePorts: serviceConfig.AllocateLoadBalancerNodePorts,
 		},
 	}
 
+	if c.strictValidation() {
+		if err := validateService(service); err != nil {
+			return nil, fmt.Errorf("failed to create test service: %w", err)
+		}
+	}
+
 	createdService, err := c.kubeClient.CoreV1().Services(serviceConfig.Namespace).Create(ctx, service, metav1.CreateOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create test service: %w", err)
@@ -198,54 +440,411 @@ func (c *CCMTestInterface) CreateTestService(ctx context.Context, serviceConfig
 	// Track created resource
 	c.mu.Lock()
 	key := fmt.Sprintf("services/%s", serviceConfig.Namespace)
-	c.createdResources[key] = append(c.createdResources[key], serviceConfig.Name)
-	c.results.IncrementResourceCount("services")
+	c.createdResources[key] = append(c.createdResources[key], serviceName)
+	c.results.IncrementResourceCount(ccmtesting.ResourceTypeServices)
 	c.mu.Unlock()
 
-	c.results.AddLog(fmt.Sprintf("Created test service: %s/%s", serviceConfig.Namespace, serviceConfig.Name))
+	c.GetTestResults().AddLog(fmt.Sprintf("Created test service: %s/%s", serviceConfig.Namespace, serviceName))
 	return createdService, nil
 }
 
-// DeleteTestService deletes a test service.
+// UpdateTestService updates an existing test service with the specified configuration.
+func (c *CCMTestInterface) UpdateTestService(ctx context.Context, serviceConfig *ccmtesting.TestServiceConfig) (*v1.Service, error) {
+	serviceName := c.config.ResourceName(serviceConfig.Name)
+	services := c.kubeClient.CoreV1().Services(serviceConfig.Namespace)
+
+	service, err := services.Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test service: %w", err)
+	}
+
+	ccmtesting.ApplyTestServiceConfig(service, serviceConfig)
+
+	if c.strictValidation() {
+		if err := validateService(service); err != nil {
+			return nil, fmt.Errorf("failed to update test service: %w", err)
+		}
+	}
+
+	updatedService, err := services.Update(ctx, service, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update test service: %w", err)
+	}
+
+	c.GetTestResults().AddLog(fmt.Sprintf("Updated test service: %s/%s", serviceConfig.Namespace, serviceName))
+	return updatedService, nil
+}
+
+// DeleteTestService deletes a test service from the namespace it was created
+// in.
 func (c *CCMTestInterface) DeleteTestService(ctx context.Context, serviceName string) error {
-	// For simplicity, we'll delete from default namespace
-	// In a real implementation, you'd need to track the namespace
-	err := c.kubeClient.CoreV1().Services("default").Delete(ctx, serviceName, metav1.DeleteOptions{})
+	serviceName = c.config.ResourceName(serviceName)
+	namespace := c.trackedServiceNamespace(serviceName)
+	err := c.kubeClient.CoreV1().Services(namespace).Delete(ctx, serviceName, metav1.DeleteOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete test service: %w", err)
 	}
 
-	c.results.AddLog(fmt.Sprintf("Deleted test service: %s", serviceName))
+	if c.untrackResource("services/"+namespace, serviceName) {
+		c.GetTestResults().DecrementResourceCount(ccmtesting.ResourceTypeServices)
+	}
+
+	c.GetTestResults().AddLog(fmt.Sprintf("Deleted test service: %s/%s", namespace, serviceName))
+	return nil
+}
+
+// CreateLoadBalancerServiceAndWait creates a LoadBalancer service and ensures its
+// load balancer through the cloud provider, recording the result in the service
+// status. If the load balancer is not ready within timeout the service is deleted.
+func (c *CCMTestInterface) CreateLoadBalancerServiceAndWait(ctx context.Context, serviceConfig *ccmtesting.TestServiceConfig, timeout time.Duration) (*v1.Service, *v1.LoadBalancerStatus, error) {
+	lbConfig := *serviceConfig
+	lbConfig.Type = v1.ServiceTypeLoadBalancer
+
+	service, err := c.CreateTestService(ctx, &lbConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lbStatus, err := c.ensureLoadBalancer(ctx, service, timeout)
+	if err != nil {
+		if deleteErr := c.kubeClient.CoreV1().Services(service.Namespace).Delete(context.Background(), service.Name, metav1.DeleteOptions{}); deleteErr != nil {
+			warnf(c.GetTestResults(), "Failed to clean up service %s/%s after load balancer wait failed: %v", service.Namespace, service.Name, deleteErr)
+		} else {
+			if c.untrackResource(fmt.Sprintf("services/%s", service.Namespace), service.Name) {
+				c.GetTestResults().DecrementResourceCount(ccmtesting.ResourceTypeServices)
+			}
+			c.GetTestResults().AddLog(fmt.Sprintf("Deleted test service: %s", service.Name))
+		}
+		return nil, nil, fmt.Errorf("failed to wait for load balancer: %w", err)
+	}
+
+	updatedService, err := c.publishLoadBalancerStatus(ctx, service, lbStatus)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updatedService, lbStatus, nil
+}
+
+// publishLoadBalancerStatus writes the status of the ensured load balancer to
+// the service, as the service controller of a running CCM does.
+func (c *CCMTestInterface) publishLoadBalancerStatus(ctx context.Context, service *v1.Service, lbStatus *v1.LoadBalancerStatus) (*v1.Service, error) {
+	service = service.DeepCopy()
+	service.Status.LoadBalancer = *lbStatus
+	updatedService, err := c.kubeClient.CoreV1().Services(service.Namespace).UpdateStatus(ctx, service, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update test service status: %w", err)
+	}
+	return updatedService, nil
+}
+
+// WaitForLoadBalancer returns the load balancer status of the named test
+// service. Since no CCM runs against the fake clientset, a service without
+// ingress has its load balancer ensured and the status written back, as the
+// service controller would, so that the service reports the same status as
+// with a running CCM.
+func (c *CCMTestInterface) WaitForLoadBalancer(serviceName string, timeout time.Duration) (*v1.LoadBalancerStatus, error) {
+	ctx := context.Background()
+	serviceName = c.config.ResourceName(serviceName)
+	namespace := c.trackedServiceNamespace(serviceName)
+
+	service, err := c.kubeClient.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service %s: %w", serviceName, err)
+	}
+	if len(service.Status.LoadBalancer.Ingress) > 0 {
+		return &service.Status.LoadBalancer, nil
+	}
+	if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+		return nil, fmt.Errorf("service %s/%s is of type %s, not LoadBalancer", namespace, serviceName, service.Spec.Type)
+	}
+
+	lbStatus, err := c.ensureLoadBalancer(ctx, service, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for load balancer: %w", err)
+	}
+	updatedService, err := c.publishLoadBalancerStatus(ctx, service, lbStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	c.GetTestResults().AddLog(fmt.Sprintf("Published load balancer status of service %s/%s", namespace, serviceName))
+	return &updatedService.Status.LoadBalancer, nil
+}
+
+// trackedServiceNamespace returns the namespace the named test service was
+// created in, or "default" if it is not tracked.
+func (c *CCMTestInterface) trackedServiceNamespace(serviceName string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for key, names := range c.createdResources {
+		namespace, ok := strings.CutPrefix(key, "services/")
+		if ok && slices.Contains(names, serviceName) {
+			return namespace
+		}
+	}
+	return metav1.NamespaceDefault
+}
+
+// ErrLoadBalancerClassNotOwned is returned when a service selects a
+// loadBalancerClass the cloud provider does not implement, so its load
+// balancer is left to another controller.
+var ErrLoadBalancerClassNotOwned = errors.New("load balancer class not owned by the cloud provider")
+
+// loadBalancerClassOwner is implemented by load balancers that provision
+// services of a specific spec.loadBalancerClass, such as MockLoadBalancer.
+type loadBalancerClassOwner interface {
+	OwnsLoadBalancerClass(class *string) bool
+}
+
+// wantsLoadBalancer reports whether the provider's load balancer should
+// provision the service. Like the upstream service controller, services with a
+// loadBalancerClass are ignored unless the load balancer claims the class.
+func wantsLoadBalancer(lb cloudprovider.LoadBalancer, service *v1.Service) bool {
+	if service.Spec.LoadBalancerClass == nil {
+		return true
+	}
+	owner, ok := lb.(loadBalancerClassOwner)
+	return ok && owner.OwnsLoadBalancerClass(service.Spec.LoadBalancerClass)
+}
+
+// ensureLoadBalancer calls the provider's EnsureLoadBalancer for the service
+// against the current nodes, giving up once timeout has elapsed.
+func (c *CCMTestInterface) ensureLoadBalancer(ctx context.Context, service *v1.Service, timeout time.Duration) (*v1.LoadBalancerStatus, error) {
+	if c.cloudProvider == nil {
+		return nil, fmt.Errorf("no cloud provider configured")
+	}
+
+	lb, supported := c.cloudProvider.LoadBalancer()
+	if !supported {
+		return nil, ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	if !wantsLoadBalancer(lb, service) {
+		return nil, fmt.Errorf("%w: service %s/%s has loadBalancerClass %q", ErrLoadBalancerClassNotOwned, service.Namespace, service.Name, *service.Spec.LoadBalancerClass)
+	}
+
+	nodes, err := c.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type ensureResult struct {
+		status *v1.LoadBalancerStatus
+		err    error
+	}
+	done := make(chan ensureResult, 1)
+	go func() {
+		status, err := lb.EnsureLoadBalancer(waitCtx, c.config.ClusterName, service, nodes)
+		done <- ensureResult{status: status, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.err != nil {
+			return nil, result.err
+		}
+		if result.status == nil {
+			return nil, fmt.Errorf("cloud provider returned no load balancer status")
+		}
+		if c.config.LoadBalancerSettleTime <= 0 {
+			return result.status, nil
+		}
+		return c.awaitSettledLoadBalancer(waitCtx, lb, service, result.status)
+	case <-waitCtx.Done():
+		return nil, fmt.Errorf("timeout waiting for load balancer: %w", waitCtx.Err())
+	}
+}
+
+// awaitSettledLoadBalancer polls the provider's GetLoadBalancer until the
+// status has ingress points and has not changed for the configured
+// LoadBalancerSettleTime, so that a status the provider is still filling in
+// is not mistaken for the final one.
+func (c *CCMTestInterface) awaitSettledLoadBalancer(ctx context.Context, lb cloudprovider.LoadBalancer, service *v1.Service, status *v1.LoadBalancerStatus) (*v1.LoadBalancerStatus, error) {
+	settleTime := c.config.LoadBalancerSettleTime
+	// A settle time of a few nanoseconds would give a non-positive interval,
+	// which NewTicker panics on
+	ticker := time.NewTicker(max(min(settleTime/5, time.Second), time.Millisecond))
+	defer ticker.Stop()
+
+	changedAt := time.Now()
+	for {
+		if len(status.Ingress) > 0 && time.Since(changedAt) >= settleTime {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for load balancer status to settle at %v: %w", status.Ingress, ctx.Err())
+		case <-ticker.C:
+		}
+
+		current, exists, err := lb.GetLoadBalancer(ctx, c.config.ClusterName, service)
+		if err != nil {
+			klog.Warningf("Error getting load balancer for service %s/%s: %v", service.Namespace, service.Name, err)
+			continue
+		}
+		if !exists {
+			current = &v1.LoadBalancerStatus{}
+		}
+		if AssertLoadBalancerStatusEqual(status, current) != nil {
+			status = current
+			changedAt = time.Now()
+		}
+	}
+}
+
+// UpdateLoadBalancerHosts plays the node sync of the service controller: it
+// calls the provider's UpdateLoadBalancer for the service with the nodes
+// currently in the cluster, as the CCM does after nodes are added or removed.
+func (c *CCMTestInterface) UpdateLoadBalancerHosts(ctx context.Context, service *v1.Service) error {
+	if c.cloudProvider == nil {
+		return fmt.Errorf("no cloud provider configured")
+	}
+
+	lb, supported := c.cloudProvider.LoadBalancer()
+	if !supported {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	nodes, err := c.listNodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := lb.UpdateLoadBalancer(ctx, c.config.ClusterName, service, nodes); err != nil {
+		return fmt.Errorf("failed to update load balancer hosts for service %s/%s: %w", service.Namespace, service.Name, err)
+	}
+
+	c.GetTestResults().AddLog(fmt.Sprintf("Updated load balancer hosts for service %s/%s to %d nodes", service.Namespace, service.Name, len(nodes)))
 	return nil
 }
 
-// CreateTestRoute creates a test route with the specified configuration.
+// listNodes returns the nodes currently in the cluster.
+func (c *CCMTestInterface) listNodes(ctx context.Context) ([]*v1.Node, error) {
+	nodeList, err := c.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodes := make([]*v1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+	return nodes, nil
+}
+
+// CreateTestRoute creates a test route with the specified configuration. The
+// route is only tracked, unless ProviderBackedRoutes is set in the test
+// configuration, in which case it is first created through the cloud provider
+// in the cluster of the route config, or else of the test config.
 func (c *CCMTestInterface) CreateTestRoute(ctx context.Context, routeConfig *ccmtesting.TestRouteConfig) (*cloudprovider.Route, error) {
+	routeName := c.config.ResourceNameOrGenerate(routeConfig.Name, "test-route")
 	route := &cloudprovider.Route{
-		Name:            routeConfig.Name,
+		Name:            routeName,
 		TargetNode:      routeConfig.TargetNode,
 		DestinationCIDR: routeConfig.DestinationCIDR,
 		Blackhole:       routeConfig.Blackhole,
 	}
 
-	// In a real implementation, you would create the route through the cloud provider
-	// For now, we'll just track it
+	if c.providerBackedRoutes() {
+		routes, ok := c.cloudProvider.Routes()
+		if !ok {
+			return nil, ccmtesting.NewUnsupportedError("routes")
+		}
+		clusterName := routeConfig.ClusterName
+		if clusterName == "" && c.config != nil {
+			clusterName = c.config.ClusterName
+		}
+		if err := routes.CreateRoute(ctx, clusterName, routeName, route); err != nil {
+			return nil, fmt.Errorf("failed to create route %s through the cloud provider: %w", routeName, err)
+		}
+		c.mu.Lock()
+		c.routeClusters[routeName] = clusterName
+		c.mu.Unlock()
+		c.GetTestResults().AddLog(fmt.Sprintf("Created route %s through the cloud provider", routeName))
+	}
+
 	c.mu.Lock()
-	c.createdResources["routes"] = append(c.createdResources["routes"], routeConfig.Name)
-	c.results.IncrementResourceCount("routes")
+	c.createdResources["routes"] = append(c.createdResources["routes"], routeName)
+	c.routes[routeName] = route
+	c.results.IncrementResourceCount(ccmtesting.ResourceTypeRoutes)
 	c.mu.Unlock()
 
-	c.results.AddLog(fmt.Sprintf("Created test route: %s", routeConfig.Name))
+	c.GetTestResults().AddLog(fmt.Sprintf("Created test route: %s", routeName))
 	return route, nil
 }
 
-// DeleteTestRoute deletes a test route.
+// CreatesRoutesThroughProvider reports whether CreateTestRoute creates routes
+// through the cloud provider, as set by ProviderBackedRoutes.
+func (c *CCMTestInterface) CreatesRoutesThroughProvider() bool {
+	return c.providerBackedRoutes()
+}
+
+// providerBackedRoutes reports whether test routes are created and deleted
+// through the cloud provider.
+func (c *CCMTestInterface) providerBackedRoutes() bool {
+	return c.config != nil && c.config.ProviderBackedRoutes
+}
+
+// DeleteTestRoute deletes a test route, through the cloud provider if it was
+// created through it.
 func (c *CCMTestInterface) DeleteTestRoute(ctx context.Context, routeName string) error {
-	// In a real implementation, you would delete the route through the cloud provider
-	c.results.AddLog(fmt.Sprintf("Deleted test route: %s", routeName))
+	routeName = c.config.ResourceName(routeName)
+	if err := c.deleteRoute(ctx, routeName); err != nil {
+		return err
+	}
+	if c.untrackResource("routes", routeName) {
+		c.GetTestResults().DecrementResourceCount(ccmtesting.ResourceTypeRoutes)
+	}
+	c.GetTestResults().AddLog(fmt.Sprintf("Deleted test route: %s", routeName))
+	return nil
+}
+
+// deleteRoute forgets a created route, deleting it through the cloud provider
+// first if it was created through it.
+func (c *CCMTestInterface) deleteRoute(ctx context.Context, routeName string) error {
+	c.mu.RLock()
+	route := c.routes[routeName]
+	clusterName, providerBacked := c.routeClusters[routeName]
+	c.mu.RUnlock()
+
+	if providerBacked && route != nil {
+		routes, ok := c.cloudProvider.Routes()
+		if !ok {
+			return ccmtesting.NewUnsupportedError("routes")
+		}
+		if err := routes.DeleteRoute(ctx, clusterName, route); err != nil {
+			return fmt.Errorf("failed to delete route %s through the cloud provider: %w", routeName, err)
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.routes, routeName)
+	delete(c.routeClusters, routeName)
+	c.mu.Unlock()
 	return nil
 }
 
+// untrackResource removes a resource from the created resources tracking,
+// reporting whether it was tracked.
+func (c *CCMTestInterface) untrackResource(key, name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, tracked := range c.createdResources[key] {
+		if tracked == name {
+			c.createdResources[key] = append(c.createdResources[key][:i], c.createdResources[key][i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // WaitForCondition waits for a condition to be met.
 func (c *CCMTestInterface) WaitForCondition(ctx context.Context, condition ccmtesting.TestCondition) error {
 	timeout := condition.Timeout
@@ -271,7 +870,7 @@ func (c *CCMTestInterface) WaitForCondition(ctx context.Context, condition ccmte
 					continue
 				}
 				if met {
-					c.results.AddLog(fmt.Sprintf("Condition met: %s", condition.Type))
+					c.GetTestResults().AddLog(fmt.Sprintf("Condition met: %s", condition.Type))
 					return nil
 				}
 			}
@@ -279,11 +878,127 @@ func (c *CCMTestInterface) WaitForCondition(ctx context.Context, condition ccmte
 	}
 }
 
+// AwaitNodeLabels waits for the named node to carry the given labels. A label
+// with an empty expected value only needs to be present with a non-empty value.
+func (c *CCMTestInterface) AwaitNodeLabels(nodeName string, labels map[string]string, timeout time.Duration) (*v1.Node, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		node, err := c.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err == nil && nodeHasLabels(node, labels) {
+			c.GetTestResults().AddLog(fmt.Sprintf("Node %s has labels: %v", nodeName, labels))
+			return node, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for labels %v on node %s", labels, nodeName)
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForTaintRemoved waits until the named node no longer carries the taint.
+func (c *CCMTestInterface) WaitForTaintRemoved(ctx context.Context, nodeName, taintKey string, timeout time.Duration) error {
+	nodeName = c.config.ResourceName(nodeName)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		node, err := c.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("node %s was deleted while waiting for taint %s to be removed", nodeName, taintKey)
+		}
+		if err == nil && !ccmtesting.NodeHasTaint(node, taintKey) {
+			c.GetTestResults().AddLog(fmt.Sprintf("Taint %s removed from node %s", taintKey, nodeName))
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for taint %s to be removed from node %s", taintKey, nodeName)
+		case <-ticker.C:
+		}
+	}
+}
+
+// nodeHasLabels reports whether node carries every label in labels. A label
+// with an empty expected value matches any non-empty value.
+func nodeHasLabels(node *v1.Node, labels map[string]string) bool {
+	for key, expected := range labels {
+		value, ok := node.Labels[key]
+		if !ok || value == "" || (expected != "" && value != expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// DumpResources writes the tracked test nodes, services and routes, as stored
+// in the fake clientset, to w as YAML.
+func (c *CCMTestInterface) DumpResources(ctx context.Context, w io.Writer) error {
+	c.mu.RLock()
+	tracked := make(map[string][]string, len(c.createdResources))
+	for key, names := range c.createdResources {
+		tracked[key] = append([]string(nil), names...)
+	}
+	var routes []*cloudprovider.Route
+	for _, route := range c.routes {
+		routes = append(routes, route)
+	}
+	c.mu.RUnlock()
+
+	var nodes []*v1.Node
+	for _, name := range tracked["nodes"] {
+		node, err := c.kubeClient.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			klog.Warningf("Failed to get node %s for dump: %v", name, err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+
+	var services []*v1.Service
+	for key, names := range tracked {
+		namespace, ok := strings.CutPrefix(key, "services/")
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			service, err := c.kubeClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				klog.Warningf("Failed to get service %s/%s for dump: %v", namespace, name, err)
+				continue
+			}
+			services = append(services, service)
+		}
+	}
+
+	return ccmtesting.WriteResourceDump(w, nodes, services, routes)
+}
+
 // GetTestResults returns the results of the test execution.
 func (c *CCMTestInterface) GetTestResults() *ccmtesting.TestResults {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.results
 }
 
+// warnf logs a warning and records it in results, so that a run with
+// StrictWarnings set fails on it.
+func warnf(results *ccmtesting.TestResults, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	klog.WarningDepth(1, message)
+	results.AddWarning(message)
+}
+
 // ResetTestState resets the test state to a clean state.
 func (c *CCMTestInterface) ResetTestState() error {
 	c.mu.Lock()
@@ -291,17 +1006,52 @@ func (c *CCMTestInterface) ResetTestState() error {
 
 	// Clear created resources tracking
 	c.createdResources = make(map[string][]string)
+	c.keptResources = make(map[string]map[string]bool)
+	c.routes = make(map[string]*cloudprovider.Route)
+	c.routeClusters = make(map[string]string)
 
 	// Reset test results
 	c.results = &ccmtesting.TestResults{
 		ResourceCounts: make(map[string]int),
 		Metrics:        make(map[string]interface{}),
+		MaxLogs:        c.results.MaxLogs,
 	}
 
 	c.results.AddLog("Test state reset completed")
 	return nil
 }
 
+// CloneTestInterface returns a new CCMTestInterface with its own fake
+// clientset and test state, set up with the same configuration, so that
+// suites can run concurrently. A mock cloud provider is replaced with a clone
+// carrying its configuration and hooks but none of its load balancers or
+// routes; other providers are shared, as they manage independent cloud
+// resources.
+func (c *CCMTestInterface) CloneTestInterface() (ccmtesting.TestInterface, error) {
+	cloudProvider := c.cloudProvider
+	if mock, ok := cloudProvider.(*MockCloudProvider); ok {
+		cloudProvider = mock.Clone()
+	}
+	clone := NewCCMTestInterface(cloudProvider)
+
+	var config ccmtesting.TestConfig
+	if c.config != nil {
+		config = *c.config
+	}
+	// An informer factory in the config watches the original clientset
+	config.InformerFactory = nil
+	if err := clone.SetupTestEnvironment(&config); err != nil {
+		return nil, fmt.Errorf("failed to set up cloned test environment: %w", err)
+	}
+	return clone, nil
+}
+
+// strictValidation reports whether nodes and services must pass API server
+// validation before they are written to the fake clientset.
+func (c *CCMTestInterface) strictValidation() bool {
+	return c.config != nil && c.config.StrictValidation
+}
+
 // GetKubeClient returns the Kubernetes client used for testing.
 func (c *CCMTestInterface) GetKubeClient() kubernetes.Interface {
 	return c.kubeClient
@@ -312,7 +1062,42 @@ func (c *CCMTestInterface) GetInformerFactory() informers.SharedInformerFactory
 	return c.informerFactory
 }
 
+// NodeLister returns a lister of the nodes in the node informer's cache,
+// starting the informer if it was not requested before setup. The informer
+// runs until the environment is torn down; only the wait for its cache to
+// sync is bounded, by the InformerSyncTimeout of the TestConfig.
+func (c *CCMTestInterface) NodeLister() (corelisters.NodeLister, error) {
+	if c.informerFactory == nil || c.informerStop == nil {
+		return nil, fmt.Errorf("test environment is not set up")
+	}
+
+	// Requesting the informer after the factory started needs another Start
+	nodes := c.informerFactory.Core().V1().Nodes()
+	informer := nodes.Informer()
+	c.informerFactory.Start(c.informerStop)
+
+	timeout := c.config.InformerSyncTimeout
+	if timeout <= 0 {
+		timeout = defaultInformerSyncTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return nil, fmt.Errorf("failed to sync node informer within %v", timeout)
+	}
+	return nodes.Lister(), nil
+}
+
 // GetConfig returns the test configuration.
 func (c *CCMTestInterface) GetConfig() *ccmtesting.TestConfig {
 	return c.config
 }
+
+// GetExistingNodes returns the nodes present in the test cluster.
+func (c *CCMTestInterface) GetExistingNodes() ([]v1.Node, error) {
+	nodes, err := c.kubeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return nodes.Items, nil
+}
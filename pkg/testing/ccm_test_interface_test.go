@@ -0,0 +1,1214 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	cloudprovider "k8s.io/cloud-provider"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// TestCCMTestInterfaceNamePrefix tests that created nodes carry the configured
+// name prefix and that deletion by the unprefixed name resolves correctly
+func TestCCMTestInterfaceNamePrefix(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", NamePrefix: "ci-"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	ctx := context.Background()
+	node, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "test-node"})
+	if err != nil {
+		t.Fatalf("Failed to create test node: %v", err)
+	}
+
+	if node.Name != "ci-test-node" {
+		t.Errorf("Expected node name 'ci-test-node', got '%s'", node.Name)
+	}
+
+	if _, err := ti.GetKubeClient().CoreV1().Nodes().Get(ctx, "ci-test-node", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected prefixed node to exist: %v", err)
+	}
+
+	if err := ti.DeleteTestNode(ctx, "test-node"); err != nil {
+		t.Fatalf("Failed to delete test node: %v", err)
+	}
+
+	if _, err := ti.GetKubeClient().CoreV1().Nodes().Get(ctx, "ci-test-node", metav1.GetOptions{}); err == nil {
+		t.Error("Expected prefixed node to be deleted")
+	}
+
+	if len(ti.createdResources["nodes"]) != 0 {
+		t.Errorf("Expected no tracked nodes, got %v", ti.createdResources["nodes"])
+	}
+}
+
+// TestCCMTestInterfaceUpdate tests that node and service updates are written to
+// the clientset and that the updated objects stay tracked
+func TestCCMTestInterfaceUpdate(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "test-node", Labels: map[string]string{"role": "worker"}}); err != nil {
+		t.Fatalf("Failed to create test node: %v", err)
+	}
+
+	_, err := ti.UpdateTestNode(ctx, &ccmtesting.TestNodeConfig{
+		Name:      "test-node",
+		Labels:    map[string]string{"pool": "blue"},
+		Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.2"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update test node: %v", err)
+	}
+
+	node, err := ti.GetKubeClient().CoreV1().Nodes().Get(ctx, "test-node", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get test node: %v", err)
+	}
+
+	if node.Labels["role"] != "worker" || node.Labels["pool"] != "blue" {
+		t.Errorf("Expected merged labels, got %v", node.Labels)
+	}
+
+	if len(node.Status.Addresses) != 1 || node.Status.Addresses[0].Address != "10.0.0.2" {
+		t.Errorf("Expected updated address 10.0.0.2, got %v", node.Status.Addresses)
+	}
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "test-service",
+		Namespace: "default",
+		Type:      v1.ServiceTypeNodePort,
+		Ports:     []v1.ServicePort{{Name: "http", Port: 80}},
+	}
+	if _, err := ti.CreateTestService(ctx, serviceConfig); err != nil {
+		t.Fatalf("Failed to create test service: %v", err)
+	}
+
+	serviceConfig.Type = v1.ServiceTypeLoadBalancer
+	if _, err := ti.UpdateTestService(ctx, serviceConfig); err != nil {
+		t.Fatalf("Failed to update test service: %v", err)
+	}
+
+	service, err := ti.GetKubeClient().CoreV1().Services("default").Get(ctx, "test-service", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get test service: %v", err)
+	}
+
+	if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+		t.Errorf("Expected service type %s, got %s", v1.ServiceTypeLoadBalancer, service.Spec.Type)
+	}
+
+	if len(ti.createdResources["nodes"]) != 1 || len(ti.createdResources["services/default"]) != 1 {
+		t.Errorf("Expected updated resources to be tracked once, got %v", ti.createdResources)
+	}
+
+	if _, err := ti.UpdateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "missing-node"}); err == nil {
+		t.Error("Expected error updating a node that does not exist")
+	}
+}
+
+// TestCCMTestInterfaceAwaitNodeLabels tests that AwaitNodeLabels matches exact
+// and presence-only labels and times out when a label is missing
+func TestCCMTestInterfaceAwaitNodeLabels(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name: "test-node",
+		Labels: map[string]string{
+			v1.LabelTopologyZone:   "zone-a",
+			v1.LabelTopologyRegion: "region-1",
+		},
+	}
+	if _, err := ti.CreateTestNode(context.Background(), nodeConfig); err != nil {
+		t.Fatalf("Failed to create test node: %v", err)
+	}
+
+	labels := map[string]string{
+		v1.LabelTopologyZone:   "zone-a",
+		v1.LabelTopologyRegion: "",
+	}
+	if _, err := ti.AwaitNodeLabels("test-node", labels, time.Second); err != nil {
+		t.Errorf("Expected labels to match, got %v", err)
+	}
+
+	if _, err := ti.AwaitNodeLabels("test-node", map[string]string{v1.LabelTopologyZone: "zone-b"}, 10*time.Millisecond); err == nil {
+		t.Error("Expected timeout for a mismatched label value")
+	}
+
+	if _, err := ti.AwaitNodeLabels("test-node", map[string]string{v1.LabelInstanceTypeStable: ""}, 10*time.Millisecond); err == nil {
+		t.Error("Expected timeout for a missing label")
+	}
+}
+
+// TestCCMTestInterfaceCreateTestNodeRegistersNode tests that test nodes are
+// backed by a matching instance in the mock cloud
+func TestCCMTestInterfaceCreateTestNodeRegistersNode(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+	_, err := ti.CreateTestNode(context.Background(), &ccmtesting.TestNodeConfig{
+		Name:         "registered-node",
+		ProviderID:   "test-provider://registered-node",
+		InstanceType: "test-instance-type",
+		Zone:         "test-zone",
+		Region:       "test-region",
+		Addresses:    []v1.NodeAddress{{Type: v1.NodeHostName, Address: "registered-host"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test node: %v", err)
+	}
+
+	data, ok := provider.GetNodeData("registered-node")
+	if !ok {
+		t.Fatal("Expected the test node to be registered")
+	}
+	if data.ProviderID != "test-provider://registered-node" || data.Zone != "test-zone" || !data.Exists || data.Hostname != "registered-host" {
+		t.Errorf("Expected the registration to match the test node, got %+v", data)
+	}
+}
+
+// TestCCMTestInterfaceWaitForTaintRemoved tests that WaitForTaintRemoved
+// returns once the taint is gone and times out while it is present
+func TestCCMTestInterfaceWaitForTaintRemoved(t *testing.T) {
+	tests := []struct {
+		name    string
+		remove  bool
+		wantErr string
+	}{
+		{name: "removed in time", remove: true},
+		{name: "times out", wantErr: "timeout waiting for taint " + ccmtesting.UninitializedTaintKey + " to be removed from node tainted-node"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ti := NewCCMTestInterface(NewMockCloudProvider())
+			if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+
+			nodeConfig := &ccmtesting.TestNodeConfig{
+				Name:   "tainted-node",
+				Taints: []v1.Taint{uninitializedTaint, {Key: "other", Effect: v1.TaintEffectNoSchedule}},
+			}
+			if _, err := ti.CreateTestNode(ctx, nodeConfig); err != nil {
+				t.Fatalf("Failed to create test node: %v", err)
+			}
+
+			if tt.remove {
+				go func() {
+					time.Sleep(150 * time.Millisecond)
+					_, _ = ti.UpdateTestNode(ctx, &ccmtesting.TestNodeConfig{
+						Name:   "tainted-node",
+						Taints: []v1.Taint{{Key: "other", Effect: v1.TaintEffectNoSchedule}},
+					})
+				}()
+			}
+
+			err := ti.WaitForTaintRemoved(ctx, "tainted-node", ccmtesting.UninitializedTaintKey, 500*time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Expected error '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestCCMTestInterfaceWaitForLoadBalancer tests that waiting for the load
+// balancer of a created LoadBalancer service ensures it and writes its status
+// back to the service, as a running CCM would
+func TestCCMTestInterfaceWaitForLoadBalancer(t *testing.T) {
+	provider := NewMockCloudProvider()
+	ti := NewCCMTestInterface(provider)
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", NamePrefix: "e2e-"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:      "wait-lb",
+		Namespace: "apps",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports:     []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+	}); err != nil {
+		t.Fatalf("Failed to create test service: %v", err)
+	}
+
+	lbStatus, err := ti.WaitForLoadBalancer("wait-lb", time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(lbStatus.Ingress) == 0 {
+		t.Fatal("Expected load balancer ingress")
+	}
+
+	stored, err := ti.GetKubeClient().CoreV1().Services("apps").Get(ctx, "e2e-wait-lb", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected service to exist: %v", err)
+	}
+	if err := AssertLoadBalancerStatusEqual(lbStatus, &stored.Status.LoadBalancer); err != nil {
+		t.Errorf("Expected the service status to record the load balancer: %v", err)
+	}
+
+	// A second wait returns the published status without ensuring again
+	ensured := false
+	provider.GetMockLoadBalancer().EnsureLoadBalancerFunc = func(context.Context, string, *v1.Service, []*v1.Node) (*v1.LoadBalancerStatus, error) {
+		ensured = true
+		return nil, errors.New("unexpected ensure")
+	}
+	if _, err := ti.WaitForLoadBalancer("wait-lb", time.Second); err != nil || ensured {
+		t.Errorf("Expected the published status without another ensure, got error %v, ensured %v", err, ensured)
+	}
+
+	if _, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:  "cluster-ip",
+		Type:  v1.ServiceTypeClusterIP,
+		Ports: []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+	}); err != nil {
+		t.Fatalf("Failed to create test service: %v", err)
+	}
+	if _, err := ti.WaitForLoadBalancer("cluster-ip", time.Second); err == nil {
+		t.Error("Expected waiting on a ClusterIP service to fail")
+	}
+}
+
+// TestCCMTestInterfaceCreateLoadBalancerServiceAndWait tests that the service is
+// provisioned in one call and deleted again when the load balancer never becomes ready
+func TestCCMTestInterfaceCreateLoadBalancerServiceAndWait(t *testing.T) {
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "lb-and-wait",
+		Namespace: "default",
+		Ports:     []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		provider := NewMockCloudProvider()
+		ti := NewCCMTestInterface(provider)
+		if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+			t.Fatalf("Failed to setup test environment: %v", err)
+		}
+
+		ctx := context.Background()
+		service, lbStatus, err := ti.CreateLoadBalancerServiceAndWait(ctx, serviceConfig, time.Second)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+			t.Errorf("Expected service type LoadBalancer, got %s", service.Spec.Type)
+		}
+		if len(lbStatus.Ingress) == 0 {
+			t.Fatal("Expected load balancer ingress")
+		}
+
+		stored, err := ti.GetKubeClient().CoreV1().Services("default").Get(ctx, "lb-and-wait", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected service to exist: %v", err)
+		}
+		if len(stored.Status.LoadBalancer.Ingress) == 0 || stored.Status.LoadBalancer.Ingress[0].IP != lbStatus.Ingress[0].IP {
+			t.Errorf("Expected service status to record ingress %v, got %v", lbStatus.Ingress, stored.Status.LoadBalancer.Ingress)
+		}
+
+		if !provider.GetMockLoadBalancer().HasLoadBalancer("default", "lb-and-wait") {
+			t.Error("Expected load balancer to be ensured through the provider")
+		}
+	})
+
+	t.Run("converges on incrementally provisioned ingress", func(t *testing.T) {
+		provider := NewMockCloudProvider()
+		provider.GetMockLoadBalancer().SetProvisionDelay(100 * time.Millisecond)
+		ti := NewCCMTestInterface(provider)
+		config := &ccmtesting.TestConfig{ProviderName: "mock", LoadBalancerSettleTime: 300 * time.Millisecond}
+		if err := ti.SetupTestEnvironment(config); err != nil {
+			t.Fatalf("Failed to setup test environment: %v", err)
+		}
+
+		ctx := context.Background()
+		_, lbStatus, err := ti.CreateLoadBalancerServiceAndWait(ctx, serviceConfig, 5*time.Second)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected, _, _ := provider.GetMockLoadBalancer().GetLoadBalancer(ctx, "", &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "lb-and-wait", Namespace: "default"},
+		})
+		if len(lbStatus.Ingress) != 2 {
+			t.Fatalf("Expected the wait to return both ingress points, got %v", lbStatus.Ingress)
+		}
+		if err := AssertLoadBalancerStatusEqual(expected, lbStatus); err != nil {
+			t.Errorf("Expected the final load balancer status, got %v", err)
+		}
+
+		stored, err := ti.GetKubeClient().CoreV1().Services("default").Get(ctx, "lb-and-wait", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected service to exist: %v", err)
+		}
+		if len(stored.Status.LoadBalancer.Ingress) != 2 {
+			t.Errorf("Expected service status to record both ingress points, got %v", stored.Status.LoadBalancer.Ingress)
+		}
+	})
+
+	t.Run("returns partial ingress without a settle time", func(t *testing.T) {
+		provider := NewMockCloudProvider()
+		provider.GetMockLoadBalancer().SetProvisionDelay(time.Minute)
+		ti := NewCCMTestInterface(provider)
+		if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+			t.Fatalf("Failed to setup test environment: %v", err)
+		}
+
+		_, lbStatus, err := ti.CreateLoadBalancerServiceAndWait(context.Background(), serviceConfig, time.Second)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(lbStatus.Ingress) != 1 || lbStatus.Ingress[0].Hostname == "" {
+			t.Errorf("Expected only the hostname ingress point, got %v", lbStatus.Ingress)
+		}
+	})
+
+	t.Run("tiny settle time", func(t *testing.T) {
+		ti := NewCCMTestInterface(NewMockCloudProvider())
+		config := &ccmtesting.TestConfig{ProviderName: "mock", LoadBalancerSettleTime: time.Nanosecond}
+		if err := ti.SetupTestEnvironment(config); err != nil {
+			t.Fatalf("Failed to setup test environment: %v", err)
+		}
+
+		_, lbStatus, err := ti.CreateLoadBalancerServiceAndWait(context.Background(), serviceConfig, time.Second)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(lbStatus.Ingress) == 0 {
+			t.Error("Expected load balancer ingress")
+		}
+	})
+
+	t.Run("settle wait times out while provisioning", func(t *testing.T) {
+		provider := NewMockCloudProvider()
+		provider.GetMockLoadBalancer().SetProvisionDelay(time.Minute)
+		ti := NewCCMTestInterface(provider)
+		config := &ccmtesting.TestConfig{ProviderName: "mock", LoadBalancerSettleTime: time.Second}
+		if err := ti.SetupTestEnvironment(config); err != nil {
+			t.Fatalf("Failed to setup test environment: %v", err)
+		}
+
+		// The hostname alone settles, so only a deadline shorter than the
+		// settle time fails
+		_, _, err := ti.CreateLoadBalancerServiceAndWait(context.Background(), serviceConfig, 200*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "timeout waiting for load balancer status to settle") {
+			t.Errorf("Expected a settle timeout, got %v", err)
+		}
+	})
+
+	t.Run("wait timeout cleans up service", func(t *testing.T) {
+		provider := NewMockCloudProvider()
+		provider.GetMockLoadBalancer().EnsureLoadBalancerFunc = func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		ti := NewCCMTestInterface(provider)
+		if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+			t.Fatalf("Failed to setup test environment: %v", err)
+		}
+
+		ctx := context.Background()
+		_, _, err := ti.CreateLoadBalancerServiceAndWait(ctx, serviceConfig, 50*time.Millisecond)
+		if err == nil {
+			t.Fatal("Expected timeout error, got nil")
+		}
+
+		if _, err := ti.GetKubeClient().CoreV1().Services("default").Get(ctx, "lb-and-wait", metav1.GetOptions{}); err == nil {
+			t.Error("Expected service to be deleted after the wait failed")
+		}
+		if len(ti.createdResources["services/default"]) != 0 {
+			t.Errorf("Expected no tracked services, got %v", ti.createdResources["services/default"])
+		}
+	})
+}
+
+// TestCCMTestInterfaceCreateTestServiceValidation tests that misconfigured services
+// are rejected instead of being created in the fake clientset
+func TestCCMTestInterfaceCreateTestServiceValidation(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	ctx := context.Background()
+	configs := []*ccmtesting.TestServiceConfig{
+		{
+			Name:           "clusterip-with-lb-ip",
+			Namespace:      "default",
+			Type:           v1.ServiceTypeClusterIP,
+			Ports:          []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+			LoadBalancerIP: "10.0.0.1",
+		},
+		{
+			Name:      "lb-without-ports",
+			Namespace: "default",
+			Type:      v1.ServiceTypeLoadBalancer,
+		},
+	}
+
+	for _, config := range configs {
+		if _, err := ti.CreateTestService(ctx, config); !errors.Is(err, ccmtesting.ErrInvalidServiceConfig) {
+			t.Errorf("Expected ErrInvalidServiceConfig for %s, got %v", config.Name, err)
+		}
+		if _, err := ti.GetKubeClient().CoreV1().Services("default").Get(ctx, config.Name, metav1.GetOptions{}); err == nil {
+			t.Errorf("Expected service %s not to be created", config.Name)
+		}
+	}
+}
+
+// TestCCMTestInterfaceConcurrentResultsAccess tests that resetting the test
+// state while other goroutines read and record results is race free when run
+// with -race
+func TestCCMTestInterfaceConcurrentResultsAccess(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", MaxLogs: 10}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := ti.ResetTestState(); err != nil {
+					t.Errorf("Failed to reset test state: %v", err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = ti.GetTestResults().ReportLogs()
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				nodeConfig := &ccmtesting.TestNodeConfig{Name: fmt.Sprintf("race-node-%d-%d", i, j)}
+				if _, err := ti.CreateTestNode(context.Background(), nodeConfig); err != nil {
+					t.Errorf("Failed to create test node: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if results := ti.GetTestResults(); results.MaxLogs != 10 {
+		t.Errorf("Expected MaxLogs 10 to survive resets, got %d", results.MaxLogs)
+	}
+}
+
+// TestCCMTestInterfaceDumpResources tests that the YAML dump contains the created objects
+func TestCCMTestInterfaceDumpResources(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+	ctx := context.Background()
+
+	if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "dump-node", ProviderID: "mock://dump-node"}); err != nil {
+		t.Fatalf("Failed to create test node: %v", err)
+	}
+	_, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:      "dump-service",
+		Namespace: "default",
+		Type:      v1.ServiceTypeNodePort,
+		Ports:     []v1.ServicePort{{Name: "http", Port: 8080, Protocol: v1.ProtocolTCP}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test service: %v", err)
+	}
+	if _, err := ti.CreateTestRoute(ctx, &ccmtesting.TestRouteConfig{Name: "dump-route", TargetNode: "dump-node", DestinationCIDR: "10.1.0.0/24"}); err != nil {
+		t.Fatalf("Failed to create test route: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ti.DumpResources(ctx, &buf); err != nil {
+		t.Fatalf("Failed to dump resources: %v", err)
+	}
+
+	dump := buf.String()
+	for _, want := range []string{
+		"kind: Node",
+		"providerID: mock://dump-node",
+		"kind: Service",
+		"name: dump-service",
+		"type: NodePort",
+		"port: 8080",
+		"Name: dump-route",
+		"DestinationCIDR: 10.1.0.0/24",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("Expected dump to contain %q, got:\n%s", want, dump)
+		}
+	}
+	if documents := strings.Count(dump, "---\n") + 1; documents != 3 {
+		t.Errorf("Expected 3 YAML documents, got %d", documents)
+	}
+}
+
+// TestCCMTestInterfaceLoadBalancerClass tests that only services without a
+// loadBalancerClass or with the class the provider owns are provisioned
+func TestCCMTestInterfaceLoadBalancerClass(t *testing.T) {
+	ownedClass := "example.com/mock"
+	foreignClass := "example.com/other"
+
+	tests := []struct {
+		name        string
+		class       *string
+		provisioned bool
+	}{
+		{name: "no class", class: nil, provisioned: true},
+		{name: "owned class", class: &ownedClass, provisioned: true},
+		{name: "foreign class", class: &foreignClass, provisioned: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			provider.GetMockLoadBalancer().SetLoadBalancerClass(ownedClass)
+
+			ctx := context.Background()
+			service, lbStatus, err := ti.CreateLoadBalancerServiceAndWait(ctx, &ccmtesting.TestServiceConfig{
+				Name:              "lb-class",
+				Namespace:         "default",
+				Ports:             []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+				LoadBalancerClass: tt.class,
+			}, time.Second)
+
+			_, ensured := provider.GetMockLoadBalancer().GetEnsuredService("default", "lb-class")
+			if ensured != tt.provisioned {
+				t.Errorf("Expected EnsureLoadBalancer called %v, got %v", tt.provisioned, ensured)
+			}
+
+			if !tt.provisioned {
+				if !errors.Is(err, ErrLoadBalancerClassNotOwned) {
+					t.Errorf("Expected ErrLoadBalancerClassNotOwned, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(lbStatus.Ingress) == 0 {
+				t.Error("Expected load balancer ingress")
+			}
+			if got := service.Spec.LoadBalancerClass; (got == nil) != (tt.class == nil) || (got != nil && *got != *tt.class) {
+				t.Errorf("Expected loadBalancerClass %v on the service, got %v", tt.class, got)
+			}
+		})
+	}
+}
+
+// TestCCMTestInterfaceVerifyCleanup tests that deleting a node returns its
+// resource count to zero and that teardown reports resources left behind
+func TestCCMTestInterfaceVerifyCleanup(t *testing.T) {
+	ctx := context.Background()
+
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", VerifyCleanup: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "counted-node"}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	if count := ti.GetTestResults().GetResourceCounts()[ccmtesting.ResourceTypeNodes]; count != 1 {
+		t.Errorf("Expected node count 1, got %d", count)
+	}
+
+	if err := ti.DeleteTestNode(ctx, "counted-node"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if count := ti.GetTestResults().GetResourceCounts()[ccmtesting.ResourceTypeNodes]; count != 0 {
+		t.Errorf("Expected node count 0, got %d", count)
+	}
+
+	if _, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{Name: "leaked-service", Namespace: "default"}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	err := ti.TeardownTestEnvironment()
+	if !errors.Is(err, ccmtesting.ErrResourcesNotCleanedUp) {
+		t.Fatalf("Expected ErrResourcesNotCleanedUp, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 services") || strings.Contains(err.Error(), "nodes") {
+		t.Errorf("Expected only the service to be outstanding, got '%v'", err)
+	}
+}
+
+// TestCCMTestInterfaceDeleteResourceCounts tests that creating and then
+// deleting a resource leaves its count at zero, even when deleted twice
+func TestCCMTestInterfaceDeleteResourceCounts(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+	ctx := context.Background()
+
+	if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "node-1"}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	if _, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{Name: "service-1", Namespace: "default"}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if _, err := ti.CreateTestRoute(ctx, &ccmtesting.TestRouteConfig{Name: "route-1", TargetNode: "node-1", DestinationCIDR: "10.0.0.0/24"}); err != nil {
+		t.Fatalf("Failed to create route: %v", err)
+	}
+
+	if err := ti.DeleteTestNode(ctx, "node-1"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if err := ti.DeleteTestService(ctx, "service-1"); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+
+	// The clientset rejects deleting a node or service twice, but routes are
+	// only tracked locally, so a second delete succeeds
+	if err := ti.DeleteTestNode(ctx, "node-1"); err == nil {
+		t.Error("Expected an error deleting the node twice")
+	}
+	if err := ti.DeleteTestService(ctx, "service-1"); err == nil {
+		t.Error("Expected an error deleting the service twice")
+	}
+	for i := 0; i < 2; i++ {
+		if err := ti.DeleteTestRoute(ctx, "route-1"); err != nil {
+			t.Fatalf("Failed to delete route: %v", err)
+		}
+	}
+
+	for _, resourceType := range []string{ccmtesting.ResourceTypeNodes, ccmtesting.ResourceTypeServices, ccmtesting.ResourceTypeRoutes} {
+		if count := ti.GetTestResults().GetResourceCounts()[resourceType]; count != 0 {
+			t.Errorf("Expected %s count 0, got %d", resourceType, count)
+		}
+	}
+}
+
+// TestCCMTestInterfaceDeleteServiceInNamespace tests that a service created
+// outside the default namespace is deleted from its own namespace and its
+// count returns to zero
+func TestCCMTestInterfaceDeleteServiceInNamespace(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+	ctx := context.Background()
+
+	if _, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{Name: "service-1", Namespace: "other"}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if err := ti.DeleteTestService(ctx, "service-1"); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+
+	if _, err := ti.GetKubeClient().CoreV1().Services("other").Get(ctx, "service-1", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the service to be deleted from namespace other, got %v", err)
+	}
+	if count := ti.GetTestResults().GetResourceCounts()[ccmtesting.ResourceTypeServices]; count != 0 {
+		t.Errorf("Expected services count 0, got %d", count)
+	}
+	if tracked := ti.TrackedResources()["services/other"]; len(tracked) != 0 {
+		t.Errorf("Expected no tracked services in namespace other, got %v", tracked)
+	}
+}
+
+// TestCCMTestInterfaceExpectedResourceCounts tests that the resources the
+// test interface creates are counted under the shared resource types that
+// Test.ExpectedResourceCounts is keyed by
+func TestCCMTestInterfaceExpectedResourceCounts(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.AddTestSuite(ccmtesting.TestSuite{
+		Name: "Counts",
+		Tests: []ccmtesting.Test{{
+			Name: "Creates",
+			Run: func(ti ccmtesting.TestInterface) error {
+				ctx := context.Background()
+				if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "node-1"}); err != nil {
+					return err
+				}
+				_, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{Name: "service-1", Namespace: "other"})
+				return err
+			},
+			Timeout:                time.Minute,
+			ExpectedResourceCounts: map[string]int{ccmtesting.ResourceTypeNodes: 1, ccmtesting.ResourceTypeServices: 1, ccmtesting.ResourceTypeRoutes: 0},
+		}},
+	})
+
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Errorf("Expected the declared counts to match, got %v", err)
+	}
+}
+
+// TestCCMTestInterfaceLoadBalancerUnsupported tests that waiting for or
+// updating a load balancer of a provider without one reports the load
+// balancer as unsupported
+func TestCCMTestInterfaceLoadBalancerUnsupported(t *testing.T) {
+	ti := NewCCMTestInterface(&noLoadBalancerProvider{MockCloudProvider: NewMockCloudProvider()})
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	ctx := context.Background()
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "lb-service",
+		Namespace: "default",
+		Ports:     []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+	}
+	_, _, err := ti.CreateLoadBalancerServiceAndWait(ctx, serviceConfig, time.Second)
+	if !ccmtesting.IsUnsupportedError(err) {
+		t.Errorf("Expected an unsupported error, got %v", err)
+	}
+
+	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "lb-service", Namespace: "default"}}
+	if err := ti.UpdateLoadBalancerHosts(ctx, service); !ccmtesting.IsUnsupportedError(err) {
+		t.Errorf("Expected an unsupported error updating hosts, got %v", err)
+	}
+}
+
+// TestCCMTestInterfaceStrictWarningsReportedOnce tests that a warning fails a
+// run with StrictWarnings set once, from the runner, and not again from the
+// teardown of the test interface
+func TestCCMTestInterfaceStrictWarningsReportedOnce(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", StrictWarnings: true}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.AddTestSuite(ccmtesting.TestSuite{
+		Name: "Warnings",
+		Tests: []ccmtesting.Test{{
+			Name: "Warns",
+			Run: func(ti ccmtesting.TestInterface) error {
+				ti.GetTestResults().AddWarning("node has no cloud metadata")
+				return nil
+			},
+			Timeout: time.Minute,
+		}},
+	})
+
+	if err := runner.RunTests(context.Background()); !errors.Is(err, ccmtesting.ErrWarningsReported) {
+		t.Errorf("Expected error wrapping ErrWarningsReported, got %v", err)
+	}
+	if err := ti.TeardownTestEnvironment(); err != nil {
+		t.Errorf("Expected teardown to leave the warnings to the runner, got %v", err)
+	}
+}
+
+// TestCCMTestInterfaceGeneratedNames tests that nodes and services created
+// without a name get distinct generated names in the shared clientset
+func TestCCMTestInterfaceGeneratedNames(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+	ctx := context.Background()
+
+	first, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	second, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{})
+	if err != nil {
+		t.Fatalf("Failed to create second node: %v", err)
+	}
+	if first.Name == second.Name || !strings.HasPrefix(first.Name, "test-node-") {
+		t.Errorf("Expected distinct generated node names, got '%s' and '%s'", first.Name, second.Name)
+	}
+
+	service, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{Namespace: "default"})
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if !strings.HasPrefix(service.Name, "test-service-") {
+		t.Errorf("Expected a generated service name, got '%s'", service.Name)
+	}
+
+	if err := ti.DeleteTestNode(ctx, first.Name); err != nil {
+		t.Errorf("Expected the generated node to be deletable by name, got %v", err)
+	}
+}
+
+// TestCCMTestInterfaceKeepOnFailure tests that with KeepOnFailure the
+// resources of a failed test survive teardown while a passing test's don't
+func TestCCMTestInterfaceKeepOnFailure(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	config := &ccmtesting.TestConfig{ProviderName: "mock", CleanupResources: true, KeepOnFailure: true}
+	if err := ti.SetupTestEnvironment(config); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	createNode := func(name string, err error) func(ccmtesting.TestInterface) error {
+		return func(ti ccmtesting.TestInterface) error {
+			if _, createErr := ti.CreateTestNode(context.Background(), &ccmtesting.TestNodeConfig{Name: name}); createErr != nil {
+				return createErr
+			}
+			return err
+		}
+	}
+	failedCleanupRan := false
+
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.AddTestSuite(ccmtesting.TestSuite{
+		Name: "Keep On Failure Suite",
+		Tests: []ccmtesting.Test{
+			{Name: "Passing Test", Run: createNode("passing-node", nil)},
+			{
+				Name: "Failing Test",
+				Run:  createNode("failing-node", errors.New("boom")),
+				Cleanup: func(ccmtesting.TestInterface) error {
+					failedCleanupRan = true
+					return nil
+				},
+			},
+		},
+	})
+
+	if err := runner.RunTests(context.Background()); !errors.Is(err, ccmtesting.ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+	if failedCleanupRan {
+		t.Error("Expected the cleanup of the failed test to be skipped")
+	}
+
+	if err := ti.TeardownTestEnvironment(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	nodes := ti.GetKubeClient().CoreV1().Nodes()
+	if _, err := nodes.Get(context.Background(), "failing-node", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the failed test's node to survive teardown, got %v", err)
+	}
+	if _, err := nodes.Get(context.Background(), "passing-node", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the passing test's node to be cleaned up, got %v", err)
+	}
+}
+
+// TestCCMTestInterfaceInformerSync tests that setup names the informers that
+// failed to sync and warns rather than silently succeeding when none are registered
+func TestCCMTestInterfaceInformerSync(t *testing.T) {
+	t.Run("unsynced informer", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("list", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("nodes unavailable")
+		})
+		factory := informers.NewSharedInformerFactory(client, 0)
+		factory.Core().V1().Nodes().Informer()
+
+		ti := NewCCMTestInterface(NewMockCloudProvider())
+		err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{
+			ProviderName:        "mock",
+			InformerFactory:     factory,
+			InformerSyncTimeout: 200 * time.Millisecond,
+		})
+		if err == nil {
+			t.Fatal("Expected setup to fail when an informer cannot sync")
+		}
+		if !strings.Contains(err.Error(), "*v1.Node") || !strings.Contains(err.Error(), "200ms") {
+			t.Errorf("Expected error naming the node informer and timeout, got %v", err)
+		}
+	})
+
+	t.Run("no informers", func(t *testing.T) {
+		ti := NewCCMTestInterface(NewMockCloudProvider())
+		if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer ti.TeardownTestEnvironment()
+
+		warned := false
+		for _, log := range ti.GetTestResults().ReportLogs() {
+			if strings.Contains(log, "no informers registered") {
+				warned = true
+			}
+		}
+		if !warned {
+			t.Error("Expected a warning that no informers are registered")
+		}
+	})
+
+	t.Run("synced informer", func(t *testing.T) {
+		factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+		factory.Core().V1().Nodes().Informer()
+
+		ti := NewCCMTestInterface(NewMockCloudProvider())
+		if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", InformerFactory: factory}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer ti.TeardownTestEnvironment()
+
+		for _, log := range ti.GetTestResults().ReportLogs() {
+			if strings.Contains(log, "no informers registered") {
+				t.Errorf("Expected no warning with a registered informer, got '%s'", log)
+			}
+		}
+	})
+}
+
+// TestCCMTestInterfaceNodeLister tests that informers keep running after the
+// sync timeout of setup has passed, so that the node lister follows changes
+// made later in the test
+func TestCCMTestInterfaceNodeLister(t *testing.T) {
+	for _, registered := range []bool{true, false} {
+		name := "informer started lazily"
+		if registered {
+			name = "informer registered before setup"
+		}
+		t.Run(name, func(t *testing.T) {
+			ti := NewCCMTestInterface(NewMockCloudProvider())
+			client := ti.GetKubeClient()
+			factory := informers.NewSharedInformerFactory(client, 0)
+			if registered {
+				factory.Core().V1().Nodes().Informer()
+			}
+
+			if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{
+				ProviderName:        "mock",
+				InformerFactory:     factory,
+				InformerSyncTimeout: 500 * time.Millisecond,
+			}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer ti.TeardownTestEnvironment()
+
+			lister, err := ti.NodeLister()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// Outlive the sync timeout before changing anything
+			time.Sleep(600 * time.Millisecond)
+
+			ctx := context.Background()
+			node, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "listed-node"})
+			if err != nil {
+				t.Fatalf("Failed to create node: %v", err)
+			}
+			node.Labels = map[string]string{"updated": "true"}
+			if _, err := client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("Failed to update node: %v", err)
+			}
+
+			if !eventually(t, 5*time.Second, func() bool {
+				listed, err := lister.Get("listed-node")
+				return err == nil && listed.Labels["updated"] == "true"
+			}) {
+				t.Error("Expected the node lister to reflect the updated node")
+			}
+		})
+	}
+}
+
+// TestCCMTestInterfaceInformerResync tests that the default informer factory
+// resyncs with the configured period, re-delivering unchanged nodes as updates
+func TestCCMTestInterfaceInformerResync(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", ResyncPeriod: time.Second}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer ti.TeardownTestEnvironment()
+
+	ctx := context.Background()
+	if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "resynced-node"}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+
+	// Informers resync at most once a second, so record for long enough to
+	// see at least one resync without touching the node
+	events, err := ti.RecordNodeEvents(ctx, 2500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	resynced := false
+	for _, event := range events {
+		if event.Type == NodeEventUpdate && event.Node.Name == "resynced-node" &&
+			event.OldNode.ResourceVersion == event.Node.ResourceVersion {
+			resynced = true
+		}
+	}
+	if !resynced {
+		t.Errorf("Expected an unchanged update for resynced-node from a resync, got %v", events)
+	}
+}
+
+// TestCCMTestInterfaceRunTestsConcurrent tests that independent suites run
+// concurrently on clones with their own mock providers configured like the
+// original, leaving the original test interface untouched. Run it with -race
+// to check the isolation
+func TestCCMTestInterfaceRunTestsConcurrent(t *testing.T) {
+	provider := NewMockCloudProvider()
+	provider.RegisterNode("registered-node", NodeData{ProviderID: "mock://registered-node", Exists: true})
+	ti := NewCCMTestInterface(provider)
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", CleanupResources: true}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	providers := make(chan cloudprovider.Interface, 2)
+	runner := ccmtesting.NewTestRunner(ti)
+	for _, suite := range []ccmtesting.TestSuite{CreateLoadBalancerTestSuite(), CreateRouteTestSuite()} {
+		setup := suite.Setup
+		suite.Setup = func(ti ccmtesting.TestInterface) error {
+			providers <- ti.GetCloudProvider()
+			return setup(ti)
+		}
+		runner.AddTestSuite(suite)
+	}
+
+	if err := runner.RunTestsConcurrent(context.Background(), 2); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	close(providers)
+
+	seen := map[cloudprovider.Interface]bool{ti.GetCloudProvider(): true}
+	for provider := range providers {
+		if seen[provider] {
+			t.Error("Expected every suite to run against a mock provider of its own")
+		}
+		seen[provider] = true
+		if _, found := provider.(*MockCloudProvider).GetNodeData("registered-node"); !found {
+			t.Error("Expected the cloned mock provider to keep the registered nodes")
+		}
+	}
+
+	summaries := ccmtesting.SummarizeSuites(runner.GetResultsSorted())
+	if len(summaries) != 2 {
+		t.Fatalf("Expected results of 2 suites, got %+v", summaries)
+	}
+	for i, suite := range runner.TestSuites {
+		if summaries[i].Name != suite.Name || summaries[i].TotalTests != len(suite.Tests) || summaries[i].FailedTests != 0 {
+			t.Errorf("Expected all %d tests of %s to pass, got %+v", len(suite.Tests), suite.Name, summaries[i])
+		}
+	}
+
+	services, err := ti.GetKubeClient().CoreV1().Services("").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list services: %v", err)
+	}
+	if len(services.Items) != 0 {
+		t.Errorf("Expected no services on the original test interface, got %d", len(services.Items))
+	}
+}
+
+// Both test interfaces implement the TestInterface, including GetConfig
+var (
+	_ ccmtesting.TestInterface = (*CCMTestInterface)(nil)
+	_ ccmtesting.TestInterface = (*ExistingCCMTestInterface)(nil)
+)
+
+// TestTestInterfacesGetConfig tests that every test interface returns the
+// config passed to SetupTestEnvironment through the TestInterface
+func TestTestInterfacesGetConfig(t *testing.T) {
+	newConfig := func() *ccmtesting.TestConfig {
+		return &ccmtesting.TestConfig{
+			ProviderName: "mock",
+			ClusterName:  "prod-east",
+			TestData:     map[string]interface{}{"namespace": "ccm-test", "resource-prefix": "config-test"},
+		}
+	}
+
+	interfaces := map[string]ccmtesting.TestInterface{
+		"CCMTestInterface":         NewCCMTestInterface(NewMockCloudProvider()),
+		"ExistingCCMTestInterface": NewExistingCCMTestInterface(fake.NewSimpleClientset(), newConfig()),
+	}
+	for name, ti := range interfaces {
+		t.Run(name, func(t *testing.T) {
+			config := newConfig()
+			if err := ti.SetupTestEnvironment(config); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+			if got := ti.GetConfig(); got != config {
+				t.Errorf("Expected the config passed to SetupTestEnvironment, got %+v", got)
+			}
+		})
+	}
+}
+
+// TestCCMTestInterfaceProviderBackedRoutes tests that routes are only created
+// through the provider with ProviderBackedRoutes set, and deleted through it
+// both explicitly and on teardown
+func TestCCMTestInterfaceProviderBackedRoutes(t *testing.T) {
+	ctx := context.Background()
+	hasRoute := func(provider *MockCloudProvider, name string) bool {
+		routes, err := provider.GetMockRoutes().ListRoutes(ctx, "test-cluster")
+		if err != nil {
+			t.Fatalf("Failed to list routes: %v", err)
+		}
+		return slices.ContainsFunc(routes, func(route *cloudprovider.Route) bool {
+			return route.Name == name && route.TargetNode == "node-1" && route.DestinationCIDR == "10.0.0.0/24"
+		})
+	}
+	routeConfig := &ccmtesting.TestRouteConfig{Name: "route-1", TargetNode: "node-1", DestinationCIDR: "10.0.0.0/24"}
+
+	tracked, trackedProvider := newMockTestInterface(t)
+	if _, err := tracked.CreateTestRoute(ctx, routeConfig); err != nil {
+		t.Fatalf("Failed to create route: %v", err)
+	}
+	if hasRoute(trackedProvider, "route-1") {
+		t.Error("Expected a tracked route not to be created through the provider")
+	}
+
+	ti, provider := newMockTestInterface(t)
+	ti.GetConfig().ProviderBackedRoutes = true
+	if _, err := ti.CreateTestRoute(ctx, routeConfig); err != nil {
+		t.Fatalf("Failed to create route: %v", err)
+	}
+	if !hasRoute(provider, "route-1") {
+		t.Fatal("Expected ListRoutes to include the route created through CreateTestRoute")
+	}
+	if err := ti.DeleteTestRoute(ctx, "route-1"); err != nil {
+		t.Fatalf("Failed to delete route: %v", err)
+	}
+	if hasRoute(provider, "route-1") {
+		t.Error("Expected DeleteTestRoute to delete the route through the provider")
+	}
+
+	if _, err := ti.CreateTestRoute(ctx, routeConfig); err != nil {
+		t.Fatalf("Failed to create route: %v", err)
+	}
+	ti.GetConfig().CleanupResources = true
+	if err := ti.TeardownTestEnvironment(); err != nil {
+		t.Fatalf("Failed to tear down: %v", err)
+	}
+	if hasRoute(provider, "route-1") {
+		t.Error("Expected teardown to delete the route through the provider")
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// ConformanceProfile is a named, versioned set of tests a provider must pass to
+// claim conformance, so that a claim such as "basic v1" names the exact tests
+// it was checked against. Tests are listed by name, never by suite alone, so
+// that tests added to a suite later do not change what a version requires.
+//
+//	name: basic
+//	version: v1
+//	suites:
+//	- name: LoadBalancer
+//	  tests: [CreateLoadBalancer, DeleteLoadBalancer]
+//	thresholds:
+//	  maxSkipped: 1
+type ConformanceProfile struct {
+	// Name is the name of the profile.
+	Name string `json:"name"`
+
+	// Version is the version of the profile. A profile's tests and
+	// thresholds must not change without a new version.
+	Version string `json:"version"`
+
+	// Description describes what conformance to the profile means.
+	Description string `json:"description,omitempty"`
+
+	// Suites are the suites and tests the profile requires.
+	Suites []ConformanceProfileSuite `json:"suites"`
+
+	// Thresholds decide the verdict from the results of the tests.
+	Thresholds ConformanceThresholds `json:"thresholds,omitempty"`
+}
+
+// ConformanceProfileSuite lists the tests of one suite a profile requires.
+type ConformanceProfileSuite struct {
+	// Name is the name of the suite.
+	Name string `json:"name"`
+
+	// Tests are the names of the required tests of the suite.
+	Tests []string `json:"tests"`
+}
+
+// ConformanceThresholds are the limits a run must stay within to conform.
+type ConformanceThresholds struct {
+	// MinPassRate is the fraction of the profile's tests that ran which must
+	// pass, between 0 and 1. Zero requires all of them to pass.
+	MinPassRate float64 `json:"minPassRate,omitempty"`
+
+	// MaxSkipped is how many of the profile's tests may be skipped, for
+	// instance because the provider does not support a capability.
+	MaxSkipped int `json:"maxSkipped,omitempty"`
+}
+
+// String returns the name and version of the profile.
+func (p *ConformanceProfile) String() string {
+	return p.Name + " " + p.Version
+}
+
+// Validate checks that the profile names itself and requires at least one
+// test, and that its thresholds are in range. All problems found are
+// reported in the returned error.
+func (p *ConformanceProfile) Validate() error {
+	var problems []error
+	if p.Name == "" {
+		problems = append(problems, fmt.Errorf("profile has no name"))
+	}
+	if p.Version == "" {
+		problems = append(problems, fmt.Errorf("profile has no version"))
+	}
+	if len(p.Suites) == 0 {
+		problems = append(problems, fmt.Errorf("profile lists no suites"))
+	}
+
+	seen := make(map[string]bool)
+	for i, suite := range p.Suites {
+		switch {
+		case suite.Name == "":
+			problems = append(problems, fmt.Errorf("suite %d has no name", i))
+		case seen[suite.Name]:
+			problems = append(problems, fmt.Errorf("suite %s is listed twice", suite.Name))
+		case len(suite.Tests) == 0:
+			problems = append(problems, fmt.Errorf("suite %s lists no tests", suite.Name))
+		}
+		seen[suite.Name] = true
+	}
+
+	if p.Thresholds.MinPassRate < 0 || p.Thresholds.MinPassRate > 1 {
+		problems = append(problems, fmt.Errorf("minPassRate %v is not between 0 and 1", p.Thresholds.MinPassRate))
+	}
+	if p.Thresholds.MaxSkipped < 0 {
+		problems = append(problems, fmt.Errorf("maxSkipped %d is negative", p.Thresholds.MaxSkipped))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid conformance profile %s: %w", p, errors.Join(problems...))
+	}
+	return nil
+}
+
+// SelectTests returns the suites holding exactly the profile's tests, taken
+// from the given suites. Tests keep the order of their suite, and their
+// labels are cleared since the profile selects them explicitly. It returns an
+// error naming every suite or test of the profile that is not available.
+func (p *ConformanceProfile) SelectTests(suites []ccmtesting.TestSuite) ([]ccmtesting.TestSuite, error) {
+	available := make(map[string]ccmtesting.TestSuite, len(suites))
+	for _, suite := range suites {
+		available[suite.Name] = suite
+	}
+
+	var missing []string
+	selected := make([]ccmtesting.TestSuite, 0, len(p.Suites))
+	for _, profileSuite := range p.Suites {
+		suite, found := available[profileSuite.Name]
+		if !found {
+			missing = append(missing, "suite "+profileSuite.Name)
+			continue
+		}
+
+		tests := make([]ccmtesting.Test, 0, len(profileSuite.Tests))
+		for _, test := range suite.Tests {
+			if slices.Contains(profileSuite.Tests, test.Name) {
+				test.Labels = nil
+				tests = append(tests, test)
+			}
+		}
+		for _, name := range profileSuite.Tests {
+			if !slices.ContainsFunc(tests, func(test ccmtesting.Test) bool { return test.Name == name }) {
+				missing = append(missing, "test "+profileSuite.Name+"/"+name)
+			}
+		}
+
+		suite.Tests = tests
+		selected = append(selected, suite)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("conformance profile %s requires unavailable %s", p, strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// ConformanceVerdict is the outcome of checking a run against a profile.
+type ConformanceVerdict struct {
+	// Profile and Version identify the profile the run was checked against.
+	Profile string
+	Version string
+
+	// Conformant reports whether the run met every threshold of the profile.
+	Conformant bool
+
+	// Total is the number of tests the profile requires; Passed, Failed,
+	// Skipped and NotRun count them by outcome.
+	Total   int
+	Passed  int
+	Failed  int
+	Skipped int
+	NotRun  int
+
+	// Reasons explain why the run does not conform. It is empty for a
+	// conformant run.
+	Reasons []string
+}
+
+// String returns a one-line summary of the verdict.
+func (v ConformanceVerdict) String() string {
+	verdict := "CONFORMANT"
+	if !v.Conformant {
+		verdict = "NOT CONFORMANT"
+	}
+	summary := fmt.Sprintf("%s to %s %s: %d of %d tests passed, %d failed, %d skipped",
+		verdict, v.Profile, v.Version, v.Passed, v.Total, v.Failed, v.Skipped)
+	if v.NotRun > 0 {
+		summary += fmt.Sprintf(", %d did not run", v.NotRun)
+	}
+	if len(v.Reasons) > 0 {
+		summary += " (" + strings.Join(v.Reasons, "; ") + ")"
+	}
+	return summary
+}
+
+// Evaluate checks the results of a run against the profile's thresholds.
+// Results of tests outside the profile are ignored. A test that ran more
+// than once, as with repeated runs, passes only if every run passed. Every
+// test of the profile must have run for the run to conform.
+func (p *ConformanceProfile) Evaluate(results []ccmtesting.TestResult) ConformanceVerdict {
+	type outcome struct{ ran, skipped, failed bool }
+	outcomes := make(map[string]*outcome)
+	for _, suite := range p.Suites {
+		for _, test := range suite.Tests {
+			outcomes[suite.Name+"/"+test] = &outcome{}
+		}
+	}
+	for _, result := range results {
+		o, required := outcomes[result.Suite+"/"+result.Test.Name]
+		if !required {
+			continue
+		}
+		o.ran = true
+		if result.Test.Skip {
+			o.skipped = true
+		} else if !result.Success {
+			o.failed = true
+		}
+	}
+
+	verdict := ConformanceVerdict{Profile: p.Name, Version: p.Version, Total: len(outcomes)}
+	var notRun []string
+	for key, o := range outcomes {
+		switch {
+		case !o.ran:
+			verdict.NotRun++
+			notRun = append(notRun, key)
+		case o.failed:
+			verdict.Failed++
+		case o.skipped:
+			verdict.Skipped++
+		default:
+			verdict.Passed++
+		}
+	}
+
+	if len(notRun) > 0 {
+		sort.Strings(notRun)
+		verdict.Reasons = append(verdict.Reasons, "tests did not run: "+strings.Join(notRun, ", "))
+	}
+	if verdict.Skipped > p.Thresholds.MaxSkipped {
+		verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("%d tests skipped, at most %d allowed", verdict.Skipped, p.Thresholds.MaxSkipped))
+	}
+	minPassRate := p.Thresholds.MinPassRate
+	if minPassRate == 0 {
+		minPassRate = 1
+	}
+	if ran := verdict.Passed + verdict.Failed; ran == 0 {
+		verdict.Reasons = append(verdict.Reasons, "no tests ran")
+	} else if passRate := float64(verdict.Passed) / float64(ran); passRate < minPassRate {
+		verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("pass rate %.1f%% is below the required %.1f%%", passRate*100, minPassRate*100))
+	}
+
+	verdict.Conformant = len(verdict.Reasons) == 0
+	return verdict
+}
+
+// LoadConformanceProfile reads a conformance profile from a YAML file and
+// validates it.
+func LoadConformanceProfile(path string) (*ConformanceProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conformance profile: %w", err)
+	}
+
+	var profile ConformanceProfile
+	if err := yaml.UnmarshalStrict(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse conformance profile %s: %w", path, err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// builtinConformanceProfiles are the profiles shipped with the harness, by
+// name and version.
+var builtinConformanceProfiles = map[string]ConformanceProfile{
+	"basic-v1": {
+		Name:        "basic",
+		Version:     "v1",
+		Description: "Load balancer lifecycle, node metadata, instances and zones every cloud provider is expected to support",
+		Suites: []ConformanceProfileSuite{
+			{
+				Name:  "LoadBalancer",
+				Tests: []string{"CreateLoadBalancer", "UpdateLoadBalancer", "DeleteLoadBalancer", "LoadBalancerStatus", "LoadBalancerDeleteIdempotency"},
+			},
+			{
+				Name:  "NodeManagement",
+				Tests: []string{"NodeAddresses", "NodeProviderID", "NodeInstanceType", "NodeZones"},
+			},
+			{
+				Name:  "Instances",
+				Tests: []string{"InstanceExists", "InstanceShutdown", "InstanceMetadata"},
+			},
+			{
+				Name:  "Zones",
+				Tests: []string{"GetZone", "GetZoneByProviderID"},
+			},
+		},
+	},
+}
+
+// BuiltinConformanceProfile returns a copy of the built-in profile with the
+// given name and version, such as "basic-v1".
+func BuiltinConformanceProfile(name string) (*ConformanceProfile, bool) {
+	profile, found := builtinConformanceProfiles[name]
+	if !found {
+		return nil, false
+	}
+	profile.Suites = slices.Clone(profile.Suites)
+	for i := range profile.Suites {
+		profile.Suites[i].Tests = slices.Clone(profile.Suites[i].Tests)
+	}
+	return &profile, true
+}
+
+// BuiltinConformanceProfiles returns the names of the built-in profiles,
+// sorted.
+func BuiltinConformanceProfiles() []string {
+	names := make([]string, 0, len(builtinConformanceProfiles))
+	for name := range builtinConformanceProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// builtinTestSuites returns the suites of a full run, which profiles select from
+func builtinTestSuites() []ccmtesting.TestSuite {
+	return []ccmtesting.TestSuite{
+		CreateLoadBalancerTestSuite(),
+		CreateNodeTestSuite(),
+		CreateRouteTestSuite(),
+		CreateInstancesTestSuite(),
+		CreateZonesTestSuite(),
+		CreateClustersTestSuite(),
+		CreateConsistencyTestSuite(),
+		CreateNodeLifecycleTestSuite(),
+	}
+}
+
+// TestBasicConformanceProfile tests that the basic-v1 profile selects exactly
+// its tests and that the mock provider conforms to it
+func TestBasicConformanceProfile(t *testing.T) {
+	profile, found := BuiltinConformanceProfile("basic-v1")
+	if !found {
+		t.Fatal("Expected the basic-v1 profile to be built in")
+	}
+	if err := profile.Validate(); err != nil {
+		t.Fatalf("Expected the built-in profile to be valid, got %v", err)
+	}
+
+	suites, err := profile.SelectTests(builtinTestSuites())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	selected := make(map[string][]string)
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			selected[suite.Name] = append(selected[suite.Name], test.Name)
+		}
+	}
+	expected := map[string][]string{
+		"LoadBalancer":   {"CreateLoadBalancer", "UpdateLoadBalancer", "DeleteLoadBalancer", "LoadBalancerStatus", "LoadBalancerDeleteIdempotency"},
+		"NodeManagement": {"NodeAddresses", "NodeProviderID", "NodeInstanceType", "NodeZones"},
+		"Instances":      {"InstanceExists", "InstanceShutdown", "InstanceMetadata"},
+		"Zones":          {"GetZone", "GetZoneByProviderID"},
+	}
+	if !reflect.DeepEqual(selected, expected) {
+		t.Errorf("Expected selected tests %v, got %v", expected, selected)
+	}
+
+	ti, _ := newMockTestInterface(t)
+	runner := ccmtesting.NewTestRunner(ti)
+	for _, suite := range suites {
+		if err := runner.AddTestSuiteChecked(suite); err != nil {
+			t.Fatalf("Failed to add suite: %v", err)
+		}
+	}
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	verdict := profile.Evaluate(runner.GetResults())
+	if !verdict.Conformant {
+		t.Errorf("Expected the mock provider to conform, got %s", verdict)
+	}
+	if verdict.Profile != "basic" || verdict.Version != "v1" || verdict.Total != 14 || verdict.Passed != 14 {
+		t.Errorf("Expected 14 of 14 basic v1 tests to pass, got %s", verdict)
+	}
+}
+
+// TestConformanceProfileSelectTestsMissing tests that every unavailable suite
+// and test of a profile is reported
+func TestConformanceProfileSelectTestsMissing(t *testing.T) {
+	profile := &ConformanceProfile{
+		Name:    "custom",
+		Version: "v2",
+		Suites: []ConformanceProfileSuite{
+			{Name: "Zones", Tests: []string{"GetZone", "GetZoneByNodeName"}},
+			{Name: "Storage", Tests: []string{"AttachVolume"}},
+		},
+	}
+
+	_, err := profile.SelectTests(builtinTestSuites())
+	if err == nil {
+		t.Fatal("Expected an error for unavailable tests")
+	}
+	for _, want := range []string{"test Zones/GetZoneByNodeName", "suite Storage"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention '%s', got %v", want, err)
+		}
+	}
+}
+
+// TestConformanceProfileEvaluate tests that the verdict applies the profile
+// thresholds to the results of its tests only
+func TestConformanceProfileEvaluate(t *testing.T) {
+	profileSuites := []ConformanceProfileSuite{{Name: "Suite", Tests: []string{"A", "B", "C", "D"}}}
+	result := func(name string, success, skip bool) ccmtesting.TestResult {
+		return ccmtesting.TestResult{Suite: "Suite", Test: ccmtesting.Test{Name: name, Skip: skip}, Success: success}
+	}
+	allPassed := []ccmtesting.TestResult{
+		result("A", true, false), result("B", true, false), result("C", true, false), result("D", true, false),
+		result("Other", false, false),
+	}
+	oneFailed := []ccmtesting.TestResult{
+		result("A", true, false), result("B", true, false), result("C", true, false), result("D", false, false),
+	}
+	oneSkipped := []ccmtesting.TestResult{
+		result("A", true, false), result("B", true, false), result("C", true, false), result("D", false, true),
+	}
+
+	tests := []struct {
+		name       string
+		thresholds ConformanceThresholds
+		results    []ccmtesting.TestResult
+		conformant bool
+		reason     string
+	}{
+		{
+			name:       "all passed",
+			results:    allPassed,
+			conformant: true,
+		},
+		{
+			name:    "failure with default thresholds",
+			results: oneFailed,
+			reason:  "pass rate 75.0% is below the required 100.0%",
+		},
+		{
+			name:       "failure within pass rate",
+			thresholds: ConformanceThresholds{MinPassRate: 0.75},
+			results:    oneFailed,
+			conformant: true,
+		},
+		{
+			name:    "skip not allowed",
+			results: oneSkipped,
+			reason:  "1 tests skipped, at most 0 allowed",
+		},
+		{
+			name:       "skip allowed",
+			thresholds: ConformanceThresholds{MaxSkipped: 1},
+			results:    oneSkipped,
+			conformant: true,
+		},
+		{
+			name:       "test did not run",
+			thresholds: ConformanceThresholds{MinPassRate: 0.5},
+			results:    allPassed[:3],
+			reason:     "tests did not run: Suite/D",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &ConformanceProfile{Name: "test", Version: "v1", Suites: profileSuites, Thresholds: tt.thresholds}
+			verdict := profile.Evaluate(tt.results)
+			if verdict.Conformant != tt.conformant {
+				t.Errorf("Expected conformant %v, got %s", tt.conformant, verdict)
+			}
+			if tt.reason != "" && !strings.Contains(verdict.String(), tt.reason) {
+				t.Errorf("Expected verdict to mention '%s', got %s", tt.reason, verdict)
+			}
+		})
+	}
+}
+
+// TestLoadConformanceProfile tests loading valid and invalid profiles
+func TestLoadConformanceProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid profile",
+			content: "name: custom\nversion: v1\nsuites:\n- name: Zones\n  tests: [GetZone]\nthresholds:\n  maxSkipped: 1\n",
+		},
+		{
+			name:    "unknown field",
+			content: "name: custom\nversion: v1\nsuites:\n- name: Zones\n  tests: [GetZone]\nthreshold:\n  maxSkipped: 1\n",
+			wantErr: "failed to parse conformance profile",
+		},
+		{
+			name:    "no version",
+			content: "name: custom\nsuites:\n- name: Zones\n  tests: [GetZone]\n",
+			wantErr: "profile has no version",
+		},
+		{
+			name:    "suite without tests",
+			content: "name: custom\nversion: v1\nsuites:\n- name: Zones\n",
+			wantErr: "suite Zones lists no tests",
+		},
+		{
+			name:    "pass rate out of range",
+			content: "name: custom\nversion: v1\nsuites:\n- name: Zones\n  tests: [GetZone]\nthresholds:\n  minPassRate: 90\n",
+			wantErr: "minPassRate 90 is not between 0 and 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profile.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to write profile: %v", err)
+			}
+
+			profile, err := LoadConformanceProfile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if profile.String() != "custom v1" || profile.Thresholds.MaxSkipped != 1 {
+				t.Errorf("Expected profile custom v1 allowing 1 skipped test, got %s with %+v", profile, profile.Thresholds)
+			}
+		})
+	}
+
+	if _, err := LoadConformanceProfile(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected error wrapping os.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// DashboardRefreshInterval is how often the dashboard page reloads itself.
+const DashboardRefreshInterval = 5 * time.Second
+
+// RunPhase is the phase of a test run as reported by the health endpoints of
+// the dashboard.
+type RunPhase string
+
+const (
+	// RunPhaseNotStarted is the phase before the first test starts.
+	RunPhaseNotStarted RunPhase = "not-started"
+
+	// RunPhaseRunning is the phase once the first test has started.
+	RunPhaseRunning RunPhase = "running"
+
+	// RunPhaseComplete is the phase once the run is marked complete.
+	RunPhaseComplete RunPhase = "complete"
+)
+
+// DashboardStatus is the JSON body of the /healthz and /readyz endpoints of
+// the dashboard.
+type DashboardStatus struct {
+	Phase   RunPhase `json:"phase"`
+	Total   int      `json:"total"`
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Skipped int      `json:"skipped"`
+	Running int      `json:"running"`
+}
+
+// Dashboard serves a live, self-refreshing HTML view of a test run, rendered
+// with the html report format. It follows the run through the progress
+// callbacks of the TestRunner, since the runner is locked while it runs.
+// Alongside the page it serves /healthz, which answers as long as the runner
+// is alive, and /readyz, which fails until the first test starts, so that Pod
+// probes and sidecars can follow the run. Both report the phase of the run
+// and its counts as a DashboardStatus.
+type Dashboard struct {
+	providerName string
+	startTime    time.Time
+
+	mu      sync.Mutex
+	phase   RunPhase
+	results []ccmtesting.TestResult
+	running []ccmtesting.TestResult
+
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewDashboard creates a dashboard for a run against the named provider.
+func NewDashboard(providerName string) *Dashboard {
+	return &Dashboard{
+		providerName: providerName,
+		startTime:    time.Now(),
+		phase:        RunPhaseNotStarted,
+	}
+}
+
+// Attach registers the dashboard with the runner's progress callbacks,
+// keeping any callbacks already set.
+func (d *Dashboard) Attach(runner *ccmtesting.TestRunner) {
+	attachProgress(runner, d.testStarted, d.testFinished)
+}
+
+// attachProgress adds onStart and onFinish to the runner's progress
+// callbacks, calling them before any callbacks already set.
+func attachProgress(runner *ccmtesting.TestRunner, onStart func(string, ccmtesting.Test), onFinish func(ccmtesting.TestResult)) {
+	prevStart, prevFinish := runner.OnTestStart, runner.OnTestFinish
+	runner.OnTestStart = func(suiteName string, test ccmtesting.Test) {
+		onStart(suiteName, test)
+		if prevStart != nil {
+			prevStart(suiteName, test)
+		}
+	}
+	runner.OnTestFinish = func(result ccmtesting.TestResult) {
+		onFinish(result)
+		if prevFinish != nil {
+			prevFinish(result)
+		}
+	}
+}
+
+func (d *Dashboard) testStarted(suiteName string, test ccmtesting.Test) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.phase == RunPhaseNotStarted {
+		d.phase = RunPhaseRunning
+	}
+	d.running = append(d.running, ccmtesting.TestResult{Test: test, Suite: suiteName, StartTime: time.Now()})
+}
+
+func (d *Dashboard) testFinished(result ccmtesting.TestResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.running = slices.DeleteFunc(d.running, func(running ccmtesting.TestResult) bool {
+		return running.Suite == result.Suite && running.Test.Name == result.Test.Name
+	})
+	d.results = append(d.results, result)
+}
+
+// Complete marks the run as complete, once the runner has returned.
+func (d *Dashboard) Complete() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.phase = RunPhaseComplete
+}
+
+// Status returns the phase of the run and its current counts.
+func (d *Dashboard) Status() DashboardStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	summary := sumSuites(ccmtesting.SummarizeSuites(d.results))
+	return DashboardStatus{
+		Phase:   d.phase,
+		Total:   summary.TotalTests,
+		Passed:  summary.PassedTests,
+		Failed:  summary.FailedTests,
+		Skipped: summary.SkippedTests,
+		Running: len(d.running),
+	}
+}
+
+// Handler returns the handler serving the dashboard page and its health
+// endpoints.
+func (d *Dashboard) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", d)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeDashboardStatus(w, http.StatusOK, d.Status())
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status := d.Status()
+		code := http.StatusOK
+		if status.Phase == RunPhaseNotStarted {
+			code = http.StatusServiceUnavailable
+		}
+		writeDashboardStatus(w, code, status)
+	})
+	return mux
+}
+
+// writeDashboardStatus writes the status as JSON with the given status code.
+func writeDashboardStatus(w http.ResponseWriter, code int, status DashboardStatus) {
+	body, err := json.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// ServeHTTP renders the current state of the run.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	results := slices.Clone(d.results)
+	running := slices.Clone(d.running)
+	d.mu.Unlock()
+
+	summary := sumSuites(ccmtesting.SummarizeSuites(results))
+	summary.ProviderName = d.providerName
+	formatter := &HTMLReportFormatter{
+		details: ccmtesting.RunDetails{Duration: time.Since(d.startTime).Round(time.Second)},
+		refresh: DashboardRefreshInterval,
+		running: running,
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, results, summary); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// sumSuites adds up the summaries of every suite.
+func sumSuites(suites []ccmtesting.SuiteSummary) ccmtesting.TestSummary {
+	var summary ccmtesting.TestSummary
+	for _, suite := range suites {
+		summary.TotalTests += suite.TotalTests
+		summary.PassedTests += suite.PassedTests
+		summary.FailedTests += suite.FailedTests
+		summary.SkippedTests += suite.SkippedTests
+		summary.TotalDuration += suite.TotalDuration
+	}
+	return summary
+}
+
+// Start serves the dashboard and its health endpoints on addr in the
+// background until Shutdown is called. It returns an error if addr cannot be
+// listened on.
+func (d *Dashboard) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	d.listener = listener
+	d.server = &http.Server{Handler: d.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := d.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Errorf("Dashboard server failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the dashboard is served on, or an empty string if
+// it was not started.
+func (d *Dashboard) Addr() string {
+	if d.listener == nil {
+		return ""
+	}
+	return d.listener.Addr().String()
+}
+
+// Shutdown stops serving the dashboard, waiting for open requests to finish
+// until ctx is done.
+func (d *Dashboard) Shutdown(ctx context.Context) error {
+	if d.server == nil {
+		return nil
+	}
+	return d.server.Shutdown(ctx)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// getDashboard renders the dashboard page through its handler
+func getDashboard(t *testing.T, dashboard *Dashboard) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	dashboard.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	return recorder.Body.String()
+}
+
+// TestDashboardInProgress tests that the dashboard shows running tests and
+// live counts in the middle of a run
+func TestDashboardInProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	runner := ccmtesting.NewTestRunner(ccmtesting.NewFakeTestImplementation())
+	runner.AddTestSuite(ccmtesting.TestSuite{
+		Name: "LoadBalancer",
+		Tests: []ccmtesting.Test{
+			{Name: "Passes", Run: func(ccmtesting.TestInterface) error { return nil }},
+			{Name: "Fails", Run: func(ccmtesting.TestInterface) error { return errors.New("boom") }},
+			{Name: "Blocks", Run: func(ccmtesting.TestInterface) error {
+				close(started)
+				<-release
+				return nil
+			}},
+		},
+	})
+
+	dashboard := NewDashboard("mock")
+	dashboard.Attach(runner)
+
+	done := make(chan error)
+	go func() {
+		done <- runner.RunTests(context.Background())
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Timed out waiting for the blocking test to start")
+	}
+
+	page := getDashboard(t, dashboard)
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="5">`,
+		"<p>Test Summary: 2 total, 1 passed, 1 failed, 0 skipped, 1 running</p>",
+		"<h2>In Progress</h2>",
+		`<tr><td>LoadBalancer</td><td>Blocks</td><td class="running">running</td>`,
+		`<td>LoadBalancer</td><td>Fails</td><td class="failed">failed</td>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the page to contain '%s' mid-run, got:\n%s", want, page)
+		}
+	}
+
+	close(release)
+	if err := <-done; !errors.Is(err, ccmtesting.ErrTestsFailed) {
+		t.Errorf("Expected error wrapping ErrTestsFailed, got %v", err)
+	}
+
+	page = getDashboard(t, dashboard)
+	if !strings.Contains(page, "<p>Test Summary: 3 total, 2 passed, 1 failed, 0 skipped</p>") {
+		t.Errorf("Expected the final counts, got:\n%s", page)
+	}
+	if strings.Contains(page, "In Progress") {
+		t.Errorf("Expected no tests in progress after the run, got:\n%s", page)
+	}
+}
+
+// getDashboardStatus requests a health endpoint of the dashboard and decodes
+// its status
+func getDashboardStatus(t *testing.T, dashboard *Dashboard, path string) (int, DashboardStatus) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	dashboard.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	var status DashboardStatus
+	if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Expected a JSON status from %s, got %q: %v", path, recorder.Body.String(), err)
+	}
+	return recorder.Code, status
+}
+
+// TestDashboardHealthEndpoints tests that /readyz only succeeds once the run
+// has started and that both endpoints follow the phase of the run
+func TestDashboardHealthEndpoints(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	runner := ccmtesting.NewTestRunner(ccmtesting.NewFakeTestImplementation())
+	runner.AddTestSuite(ccmtesting.TestSuite{
+		Name: "LoadBalancer",
+		Tests: []ccmtesting.Test{
+			{Name: "Passes", Run: func(ccmtesting.TestInterface) error { return nil }},
+			{Name: "Blocks", Run: func(ccmtesting.TestInterface) error {
+				close(started)
+				<-release
+				return nil
+			}},
+		},
+	})
+
+	dashboard := NewDashboard("mock")
+	dashboard.Attach(runner)
+
+	if code, status := getDashboardStatus(t, dashboard, "/healthz"); code != http.StatusOK || status.Phase != RunPhaseNotStarted {
+		t.Errorf("Expected a healthy runner that has not started, got %d %+v", code, status)
+	}
+	if code, status := getDashboardStatus(t, dashboard, "/readyz"); code != http.StatusServiceUnavailable || status.Phase != RunPhaseNotStarted {
+		t.Errorf("Expected not ready before the run starts, got %d %+v", code, status)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- runner.RunTests(context.Background())
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Timed out waiting for the blocking test to start")
+	}
+
+	expected := DashboardStatus{Phase: RunPhaseRunning, Total: 1, Passed: 1, Running: 1}
+	if code, status := getDashboardStatus(t, dashboard, "/readyz"); code != http.StatusOK || status != expected {
+		t.Errorf("Expected ready with %+v mid-run, got %d %+v", expected, code, status)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	dashboard.Complete()
+
+	expected = DashboardStatus{Phase: RunPhaseComplete, Total: 2, Passed: 2}
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if code, status := getDashboardStatus(t, dashboard, path); code != http.StatusOK || status != expected {
+			t.Errorf("Expected %s to report %+v after the run, got %d %+v", path, expected, code, status)
+		}
+	}
+}
+
+// TestDashboardStartShutdown tests serving the dashboard over HTTP and
+// shutting it down
+func TestDashboardStartShutdown(t *testing.T) {
+	dashboard := NewDashboard("mock")
+	if err := dashboard.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	resp, err := http.Get("http://" + dashboard.Addr())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(string(body), "<p>Provider: mock") {
+		t.Errorf("Expected the dashboard page, got:\n%s", body)
+	}
+
+	if err := dashboard.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := http.Get("http://" + dashboard.Addr()); err == nil {
+		t.Errorf("Expected the dashboard to stop serving after shutdown")
+	}
+}
@@ -18,11 +18,21 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	cloudprovider "k8s.io/cloud-provider"
 	"k8s.io/klog/v2"
@@ -35,6 +45,23 @@ type ExistingCCMTestInterface struct {
 	kubeClient kubernetes.Interface
 	config     *ccmtesting.TestConfig
 	namespace  string
+	results    *ccmtesting.TestResults
+
+	// Names of the created test nodes in creation order. Nodes are cluster
+	// scoped, so deleting the test namespace does not remove them
+	createdNodes []string
+	mu           sync.Mutex
+
+	// LoadBalancerDeletedFunc optionally confirms with the cloud provider that the
+	// load balancer backing a deleted service is gone. It receives the last observed
+	// state of the service, or only its name and namespace if it was already gone,
+	// and is only consulted by WaitForServiceDeleted
+	LoadBalancerDeletedFunc func(ctx context.Context, service *v1.Service) (bool, error)
+
+	// LoadBalancerProviderPatterns optionally overrides the ingress patterns
+	// ValidateLoadBalancerProvider matches for each provider. Providers missing
+	// from it fall back to DefaultLoadBalancerProviderPatterns
+	LoadBalancerProviderPatterns map[string][]string
 }
 
 // NewExistingCCMTestInterface creates a new test interface for existing CCM
@@ -47,12 +74,16 @@ func NewExistingCCMTestInterface(kubeClient kubernetes.Interface, config *ccmtes
 		kubeClient: kubeClient,
 		config:     config,
 		namespace:  namespace,
+		results:    &ccmtesting.TestResults{},
 	}
 }
 
-// SetupTestEnvironment sets up the test environment
+// SetupTestEnvironment sets up the test environment. The namespace is the one
+// chosen from the config given to NewExistingCCMTestInterface, while config
+// replaces that config for everything else.
 func (e *ExistingCCMTestInterface) SetupTestEnvironment(config *ccmtesting.TestConfig) error {
 	klog.Infof("Setting up test environment in namespace: %s", e.namespace)
+	e.config = config
 
 	// Create test namespace
 	namespace := &v1.Namespace{
@@ -72,108 +103,494 @@ func (e *ExistingCCMTestInterface) SetupTestEnvironment(config *ccmtesting.TestC
 	return nil
 }
 
-// TeardownTestEnvironment cleans up the test environment
+// ErrMissingPermissions is returned by CheckPermissions when the client is not
+// allowed to perform operations the existing CCM tests need.
+var ErrMissingPermissions = errors.New("missing permissions for existing CCM tests")
+
+// requiredPermission is an operation on a resource the existing CCM tests need.
+type requiredPermission struct {
+	verb       string
+	resource   string
+	namespaced bool
+}
+
+// existingCCMPermissions are the operations the existing CCM tests perform.
+var existingCCMPermissions = []requiredPermission{
+	{verb: "create", resource: "namespaces"},
+	{verb: "delete", resource: "namespaces"},
+	{verb: "create", resource: "services", namespaced: true},
+	{verb: "delete", resource: "services", namespaced: true},
+	{verb: "create", resource: "nodes"},
+	{verb: "delete", resource: "nodes"},
+}
+
+// CheckPermissions verifies with SelfSubjectAccessReviews that the client may
+// perform every operation the tests need, so that missing RBAC rules are
+// reported before the run instead of failing tests midway. The returned error
+// wraps ErrMissingPermissions and lists each missing permission.
+func (e *ExistingCCMTestInterface) CheckPermissions(ctx context.Context) error {
+	var missing []string
+	for _, permission := range existingCCMPermissions {
+		attributes := &authorizationv1.ResourceAttributes{
+			Verb:     permission.verb,
+			Resource: permission.resource,
+		}
+		description := fmt.Sprintf("%s %s", permission.verb, permission.resource)
+		if permission.namespaced {
+			attributes.Namespace = e.namespace
+			description += fmt.Sprintf(" in namespace %s", e.namespace)
+		}
+
+		review, err := e.kubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to check permission to %s: %w", description, err)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, description)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingPermissions, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// defaultTeardownTimeout is how long teardown waits for the test namespace to
+// be deleted when the TestConfig does not set TeardownTimeout.
+const defaultTeardownTimeout = 30 * time.Second
+
+// TeardownTestEnvironment cleans up the test environment. The namespace,
+// and with it the services whose load balancers target the nodes, is deleted
+// first, followed by the cluster-scoped test nodes in reverse creation order.
+// A namespace still present after the TeardownTimeout of the TestConfig is
+// reported as an error naming what blocks its deletion.
 func (e *ExistingCCMTestInterface) TeardownTestEnvironment() error {
 	klog.Infof("Tearing down test environment in namespace: %s", e.namespace)
 
-	// Delete test namespace (this will cascade delete all resources)
+	var errs []error
+
+	// Delete test namespace (this will cascade delete all namespaced resources)
 	err := e.kubeClient.CoreV1().Namespaces().Delete(context.Background(), e.namespace, metav1.DeleteOptions{
 		GracePeriodSeconds: func() *int64 { v := int64(0); return &v }(),
 	})
 	if err != nil {
-		klog.Warningf("Failed to delete test namespace %s: %v", e.namespace, err)
+		warnf(e.results, "Failed to delete test namespace %s: %v", e.namespace, err)
 		// Continue with cleanup even if namespace deletion fails
+	} else {
+		timeout := defaultTeardownTimeout
+		if e.config != nil && e.config.TeardownTimeout > 0 {
+			timeout = e.config.TeardownTimeout
+		}
+		errs = append(errs, e.waitForNamespaceDeleted(timeout))
 	}
 
-	// Wait for namespace to be fully deleted
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	errs = append(errs, e.deleteCreatedNodes(context.Background()))
+	return errors.Join(errs...)
+}
+
+// waitForNamespaceDeleted waits up to timeout for the test namespace to be
+// gone, returning an error describing what blocks its deletion otherwise.
+func (e *ExistingCCMTestInterface) waitForNamespaceDeleted(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
 	for {
+		_, err := e.kubeClient.CoreV1().Namespaces().Get(ctx, e.namespace, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			klog.Infof("Namespace %s successfully deleted", e.namespace)
+			return nil
+		}
+
 		select {
 		case <-ctx.Done():
-			klog.Warningf("Timeout waiting for namespace %s to be deleted", e.namespace)
-			return nil
+			return fmt.Errorf("timeout after %v waiting for namespace %s to be deleted: %s", timeout, e.namespace, e.describeNamespaceBlockers())
 		case <-ticker.C:
-			_, err := e.kubeClient.CoreV1().Namespaces().Get(ctx, e.namespace, metav1.GetOptions{})
-			if err != nil {
-				klog.Infof("Namespace %s successfully deleted", e.namespace)
-				return nil
+		}
+	}
+}
+
+// describeNamespaceBlockers describes what keeps the test namespace from
+// being deleted: its finalizers, the conditions the namespace controller
+// reports and the services left in it with their finalizers.
+func (e *ExistingCCMTestInterface) describeNamespaceBlockers() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	namespace, err := e.kubeClient.CoreV1().Namespaces().Get(ctx, e.namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Sprintf("failed to get namespace: %v", err)
+	}
+
+	var blockers []string
+	if len(namespace.Spec.Finalizers) > 0 {
+		blockers = append(blockers, fmt.Sprintf("namespace finalizers %v", namespace.Spec.Finalizers))
+	}
+	if len(namespace.Finalizers) > 0 {
+		blockers = append(blockers, fmt.Sprintf("metadata finalizers %v", namespace.Finalizers))
+	}
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == v1.ConditionTrue {
+			blockers = append(blockers, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+
+	services, err := e.kubeClient.CoreV1().Services(e.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		blockers = append(blockers, fmt.Sprintf("failed to list services: %v", err))
+	} else {
+		for _, service := range services.Items {
+			blocker := "service " + service.Name
+			if len(service.Finalizers) > 0 {
+				blocker += fmt.Sprintf(" with finalizers %v", service.Finalizers)
 			}
+			blockers = append(blockers, blocker)
+		}
+	}
+
+	if len(blockers) == 0 {
+		return fmt.Sprintf("namespace is %s with nothing reported blocking it", namespace.Status.Phase)
+	}
+	return fmt.Sprintf("namespace is %s; %s", namespace.Status.Phase, strings.Join(blockers, "; "))
+}
+
+// deleteCreatedNodes deletes the test nodes that are still tracked, newest
+// first. Nodes that are already gone are ignored.
+func (e *ExistingCCMTestInterface) deleteCreatedNodes(ctx context.Context) error {
+	e.mu.Lock()
+	nodeNames := e.createdNodes
+	e.createdNodes = nil
+	e.mu.Unlock()
+
+	var errs []error
+	for i := len(nodeNames) - 1; i >= 0; i-- {
+		err := e.kubeClient.CoreV1().Nodes().Delete(ctx, nodeNames[i], metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			klog.Warningf("Failed to delete test node %s: %v", nodeNames[i], err)
+			errs = append(errs, fmt.Errorf("failed to delete test node %s: %w", nodeNames[i], err))
 		}
 	}
+	return errors.Join(errs...)
 }
 
 // CreateTestNode creates a test node
 func (e *ExistingCCMTestInterface) CreateTestNode(ctx context.Context, config *ccmtesting.TestNodeConfig) (*v1.Node, error) {
 	node := &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: config.Name,
+			Name: e.config.ResourceNameOrGenerate(config.Name, "test-node"),
 			Labels: map[string]string{
 				"test-prefix": e.config.TestData["resource-prefix"].(string),
 			},
 		},
 		Spec: v1.NodeSpec{
 			ProviderID: config.ProviderID,
+			Taints:     config.Taints,
+			PodCIDR:    config.PrimaryPodCIDR(),
+			PodCIDRs:   config.PodCIDRs,
 		},
 		Status: v1.NodeStatus{
-			Addresses: config.Addresses,
+			Addresses:  config.Addresses,
+			Conditions: config.Conditions,
 		},
 	}
 
-	return e.kubeClient.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+	createdNode, err := e.kubeClient.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.createdNodes = append(e.createdNodes, createdNode.Name)
+	e.mu.Unlock()
+	return createdNode, nil
+}
+
+// UpdateTestNode updates an existing test node
+func (e *ExistingCCMTestInterface) UpdateTestNode(ctx context.Context, config *ccmtesting.TestNodeConfig) (*v1.Node, error) {
+	nodes := e.kubeClient.CoreV1().Nodes()
+
+	node, err := nodes.Get(ctx, e.config.ResourceName(config.Name), metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test node: %w", err)
+	}
+
+	ccmtesting.ApplyTestNodeConfig(node, config)
+
+	updatedNode, err := nodes.Update(ctx, node, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update test node: %w", err)
+	}
+
+	// Addresses and conditions live in the node status, which Update does not write
+	if config.Addresses == nil && config.Conditions == nil {
+		return updatedNode, nil
+	}
+	updatedNode.Status = node.Status
+	return nodes.UpdateStatus(ctx, updatedNode, metav1.UpdateOptions{})
 }
 
 // CreateTestService creates a test service
 func (e *ExistingCCMTestInterface) CreateTestService(ctx context.Context, config *ccmtesting.TestServiceConfig) (*v1.Service, error) {
+	if err := ccmtesting.ValidateTestServiceConfig(config); err != nil {
+		return nil, err
+	}
+
 	service := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      config.Name,
+			Name:      e.config.ResourceNameOrGenerate(config.Name, "test-service"),
 			Namespace: e.namespace,
 			Labels: map[string]string{
 				"test-prefix": e.config.TestData["resource-prefix"].(string),
 			},
 		},
 		Spec: v1.ServiceSpec{
-			Type:  config.Type,
-			Ports: config.Ports,
+			Type:                          config.Type,
+			Ports:                         config.Ports,
+			LoadBalancerClass:             config.LoadBalancerClass,
+			AllocateLoadBalancerNodePorts: config.AllocateLoadBalancerNodePorts,
 		},
 	}
 
 	return e.kubeClient.CoreV1().Services(e.namespace).Create(ctx, service, metav1.CreateOptions{})
 }
 
+// UpdateTestService updates an existing test service
+func (e *ExistingCCMTestInterface) UpdateTestService(ctx context.Context, config *ccmtesting.TestServiceConfig) (*v1.Service, error) {
+	services := e.kubeClient.CoreV1().Services(e.namespace)
+
+	service, err := services.Get(ctx, e.config.ResourceName(config.Name), metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test service: %w", err)
+	}
+
+	ccmtesting.ApplyTestServiceConfig(service, config)
+	if service.Spec.Type == v1.ServiceTypeClusterIP {
+		// The API server rejects an external traffic policy on ClusterIP services
+		service.Spec.ExternalTrafficPolicy = ""
+	}
+
+	return services.Update(ctx, service, metav1.UpdateOptions{})
+}
+
 // WaitForLoadBalancer waits for a load balancer to be provisioned
 func (e *ExistingCCMTestInterface) WaitForLoadBalancer(serviceName string, timeout time.Duration) (*v1.LoadBalancerStatus, error) {
+	condition := ccmtesting.NewServiceIngressCondition(e.kubeClient, e.namespace, serviceName, timeout)
+	if err := e.WaitForCondition(context.Background(), condition); err != nil {
+		return nil, fmt.Errorf("timeout waiting for load balancer: %w", err)
+	}
+
+	service, err := e.kubeClient.CoreV1().Services(e.namespace).Get(context.Background(), serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service %s: %w", serviceName, err)
+	}
+	return &service.Status.LoadBalancer, nil
+}
+
+// CreateLoadBalancerServiceAndWait creates a LoadBalancer service and waits for the
+// CCM to provision it, deleting the service again if provisioning does not complete
+func (e *ExistingCCMTestInterface) CreateLoadBalancerServiceAndWait(ctx context.Context, config *ccmtesting.TestServiceConfig, timeout time.Duration) (*v1.Service, *v1.LoadBalancerStatus, error) {
+	lbConfig := *config
+	lbConfig.Type = v1.ServiceTypeLoadBalancer
+
+	service, err := e.CreateTestService(ctx, &lbConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create test service: %w", err)
+	}
+
+	lbStatus, err := e.WaitForLoadBalancer(service.Name, timeout)
+	if err != nil {
+		if deleteErr := e.kubeClient.CoreV1().Services(e.namespace).Delete(context.Background(), service.Name, metav1.DeleteOptions{}); deleteErr != nil {
+			warnf(e.results, "Failed to clean up service %s after load balancer wait failed: %v", service.Name, deleteErr)
+		}
+		return nil, nil, fmt.Errorf("failed to wait for load balancer: %w", err)
+	}
+
+	return service, lbStatus, nil
+}
+
+// WaitForServiceDeleted waits for a service to be removed from the cluster and, when
+// LoadBalancerDeletedFunc is set, for the provider to report its load balancer gone
+func (e *ExistingCCMTestInterface) WaitForServiceDeleted(serviceName string, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	serviceName = e.config.ResourceName(serviceName)
+	services := e.kubeClient.CoreV1().Services(e.namespace)
+
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	lastSeen := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: e.namespace}}
+	serviceGone := false
 	for {
-		select {
-		case <-ctx.Done():
-			return nil, fmt.Errorf("timeout waiting for load balancer")
+		if !serviceGone {
+			service, err := services.Get(ctx, serviceName, metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+				serviceGone = true
+			case err == nil:
+				lastSeen = service
+			}
+		}
 
-		case <-ticker.C:
-			service, err := e.kubeClient.CoreV1().Services(e.namespace).Get(ctx, serviceName, metav1.GetOptions{})
-			if err != nil {
-				continue
+		if serviceGone {
+			if e.LoadBalancerDeletedFunc == nil {
+				return nil
+			}
+			deleted, err := e.LoadBalancerDeletedFunc(ctx, lastSeen)
+			if err == nil && deleted {
+				return nil
 			}
+		}
 
-			if len(service.Status.LoadBalancer.Ingress) > 0 {
-				return &service.Status.LoadBalancer, nil
+		select {
+		case <-ctx.Done():
+			if !serviceGone {
+				if len(lastSeen.Finalizers) > 0 {
+					klog.Warningf("Service %s/%s is still present with finalizers %v; load balancer cleanup may be stuck", e.namespace, serviceName, lastSeen.Finalizers)
+				}
+				return fmt.Errorf("timeout waiting for service %s to be deleted", serviceName)
 			}
+			return fmt.Errorf("timeout waiting for load balancer of service %s to be deleted", serviceName)
+		case <-ticker.C:
 		}
 	}
 }
 
 // WaitForNodeReady waits for a node to become ready
 func (e *ExistingCCMTestInterface) WaitForNodeReady(nodeName string, timeout time.Duration) (*v1.Node, error) {
+	condition := ccmtesting.NewNodeReadyCondition(e.kubeClient, nodeName, timeout)
+	if err := e.WaitForCondition(context.Background(), condition); err != nil {
+		return nil, fmt.Errorf("timeout waiting for node to become ready: %w", err)
+	}
+
+	node, err := e.kubeClient.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+	return node, nil
+}
+
+// WaitForNodeDeleted waits for a node to be removed from the cluster, as the
+// CCM's node lifecycle controller does for nodes whose instance is gone
+func (e *ExistingCCMTestInterface) WaitForNodeDeleted(nodeName string, timeout time.Duration) error {
+	nodeName = e.config.ResourceName(nodeName)
+	condition := ccmtesting.TestCondition{
+		Type:    "NodeDeleted",
+		Timeout: timeout,
+		CheckFunction: func() (bool, error) {
+			_, err := e.kubeClient.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		},
+	}
+	if err := e.WaitForCondition(context.Background(), condition); err != nil {
+		return fmt.Errorf("timeout waiting for node %s to be deleted: %w", nodeName, err)
+	}
+	return nil
+}
+
+// updatedLoadBalancerReason is the reason of the event the service controller
+// records on a service after updating the hosts of its load balancer.
+const updatedLoadBalancerReason = "UpdatedLoadBalancer"
+
+// WaitForLoadBalancerHostsUpdated waits for the CCM's service controller to
+// update the hosts of the service's load balancer after since, as reported by
+// the UpdatedLoadBalancer event it records on the service.
+func (e *ExistingCCMTestInterface) WaitForLoadBalancerHostsUpdated(serviceName string, since time.Time, timeout time.Duration) error {
+	serviceName = e.config.ResourceName(serviceName)
+	// Event timestamps only have second precision
+	since = since.Truncate(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		events, err := e.kubeClient.CoreV1().Events(e.namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fmt.Sprintf("involvedObject.kind=Service,involvedObject.name=%s", serviceName),
+		})
+		if err == nil && hasEventSince(events.Items, serviceName, updatedLoadBalancerReason, since) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for load balancer hosts of service %s to be updated", serviceName)
+		case <-ticker.C:
+		}
+	}
+}
+
+// AwaitServiceAnnotation waits for the service to carry the annotation with
+// the given key and returns its value, for CCMs that record the ID of the
+// cloud load balancer on the service
+func (e *ExistingCCMTestInterface) AwaitServiceAnnotation(ctx context.Context, namespace, serviceName, key string, timeout time.Duration) (string, error) {
+	return awaitServiceAnnotation(ctx, e.kubeClient, namespace, serviceName, key, 5*time.Second, timeout)
+}
+
+// awaitServiceAnnotation polls the service every interval until it carries
+// the annotation with the given key, returning its value.
+func awaitServiceAnnotation(ctx context.Context, kubeClient kubernetes.Interface, namespace, serviceName, key string, interval, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		service, err := kubeClient.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+		if err == nil {
+			if value, ok := service.Annotations[key]; ok {
+				return value, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("timeout waiting for annotation %s on service %s/%s", key, namespace, serviceName)
+		case <-ticker.C:
+		}
+	}
+}
+
+// hasEventSince reports whether events include one for the named service with
+// the given reason that last occurred at or after since.
+func hasEventSince(events []v1.Event, serviceName, reason string, since time.Time) bool {
+	for _, event := range events {
+		if event.InvolvedObject.Kind != "Service" || event.InvolvedObject.Name != serviceName || event.Reason != reason {
+			continue
+		}
+		if !eventTime(event).Before(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTime returns when an event last occurred.
+func eventTime(event v1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.FirstTimestamp.Time
+}
+
+// AwaitNodeLabels waits for a node to carry the given labels. A label with an
+// empty expected value only needs to be present with a non-empty value.
+func (e *ExistingCCMTestInterface) AwaitNodeLabels(nodeName string, labels map[string]string, timeout time.Duration) (*v1.Node, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
@@ -181,21 +598,131 @@ func (e *ExistingCCMTestInterface) WaitForNodeReady(nodeName string, timeout tim
 	defer ticker.Stop()
 
 	for {
+		node, err := e.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err == nil && nodeHasLabels(node, labels) {
+			return node, nil
+		}
+
 		select {
 		case <-ctx.Done():
-			return nil, fmt.Errorf("timeout waiting for node to become ready")
+			return nil, fmt.Errorf("timeout waiting for labels %v on node %s", labels, nodeName)
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForTaintRemoved waits for the existing CCM to remove a taint from a
+// node, such as the uninitialized taint its cloud node controller removes
+func (e *ExistingCCMTestInterface) WaitForTaintRemoved(ctx context.Context, nodeName, taintKey string, timeout time.Duration) error {
+	nodeName = e.config.ResourceName(nodeName)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		node, err := e.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("node %s was deleted while waiting for taint %s to be removed", nodeName, taintKey)
+		}
+		if err == nil && !ccmtesting.NodeHasTaint(node, taintKey) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for taint %s to be removed from node %s", taintKey, nodeName)
 		case <-ticker.C:
-			node, err := e.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
-			if err != nil {
+		}
+	}
+}
+
+// nodeInitialized reports whether the cloud node controller has initialized
+// the node. It sets the provider ID and labels of the node and removes the
+// uninitialized taint in the same update
+func nodeInitialized(node *v1.Node) bool {
+	return node.Spec.ProviderID != "" && !ccmtesting.NodeHasTaint(node, ccmtesting.UninitializedTaintKey)
+}
+
+// WaitForNodeInitialized waits for the existing CCM to initialize a node and
+// returns the initialized node. It watches the node to return as soon as the
+// CCM updates it, and falls back to polling every 5 seconds. A watch that
+// fails or ends early is retried with a backoff capped at the poll interval
+func (e *ExistingCCMTestInterface) WaitForNodeInitialized(ctx context.Context, nodeName string, timeout time.Duration) (*v1.Node, error) {
+	nodeName = e.config.ResourceName(nodeName)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	const (
+		pollInterval   = 5 * time.Second
+		initialBackoff = 200 * time.Millisecond
+	)
+	nodes := e.kubeClient.CoreV1().Nodes()
+	backoff := initialBackoff
+	for {
+		node, err := nodes.Get(ctx, nodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("node %s was deleted while waiting for it to be initialized", nodeName)
+		}
+		if err == nil && nodeInitialized(node) {
+			return node, nil
+		}
+
+		options := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", nodeName).String()}
+		if err == nil {
+			options.ResourceVersion = node.ResourceVersion
+		}
+		watcher, err := nodes.Watch(ctx, options)
+		if err == nil {
+			initialized, ended, err := watchNodeInitialized(ctx, watcher, nodeName, pollInterval)
+			watcher.Stop()
+			if initialized != nil || err != nil {
+				return initialized, err
+			}
+			if !ended {
+				backoff = initialBackoff
 				continue
 			}
+		} else {
+			klog.V(2).Infof("Failed to watch node %s, retrying in %v: %v", nodeName, backoff, err)
+		}
 
-			// Check if node is ready
-			for _, condition := range node.Status.Conditions {
-				if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
-					return node, nil
-				}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for node %s to be initialized", nodeName)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, pollInterval)
+	}
+}
+
+// watchNodeInitialized reads the events of a node watch until the node is
+// initialized or the poll interval elapses. It returns the initialized node,
+// or whether the watch ended before the poll interval did
+func watchNodeInitialized(ctx context.Context, watcher watch.Interface, nodeName string, pollInterval time.Duration) (*v1.Node, bool, error) {
+	poll := time.NewTimer(pollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false, fmt.Errorf("timeout waiting for node %s to be initialized", nodeName)
+		case <-poll.C:
+			return nil, false, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil, true, nil
+			}
+			node, isNode := event.Object.(*v1.Node)
+			if !isNode || node.Name != nodeName {
+				continue
+			}
+			if event.Type == watch.Deleted {
+				return nil, false, fmt.Errorf("node %s was deleted while waiting for it to be initialized", nodeName)
+			}
+			if nodeInitialized(node) {
+				return node, false, nil
 			}
 		}
 	}
@@ -249,21 +776,87 @@ func (e *ExistingCCMTestInterface) VerifyCCMNodeProcessing(node *v1.Node) error
 
 	// If we can't find any cloud provider indicators, log a warning but don't fail
 	// This might be expected in some environments
-	klog.Warningf("No cloud provider annotations/labels found on node %s. This might be expected in some environments.", node.Name)
+	warnf(e.results, "No cloud provider annotations/labels found on node %s. This might be expected in some environments.", node.Name)
 
 	// For now, we'll consider this a success since the node is ready
 	// In a real implementation, you might want to make this configurable
 	return nil
 }
 
+// ErrLoadBalancerProviderMismatch is returned when a load balancer ingress does
+// not look like it was provisioned by the expected cloud provider
+var ErrLoadBalancerProviderMismatch = errors.New("load balancer provider mismatch")
+
+// DefaultLoadBalancerProviderPatterns holds the regular expressions matched
+// against the hostname, or the IP if there is none, of every load balancer
+// ingress. Providers that only hand out IPs can not be told apart by their
+// ingress, so they merely reject the hostnames of the others
+var DefaultLoadBalancerProviderPatterns = map[string][]string{
+	"aws": {
+		`\.elb\.amazonaws\.com$`,
+		`\.elb\.[a-z0-9-]+\.amazonaws\.com\.cn$`,
+	},
+	"gcp":   {`^[0-9.]+$`, `^[0-9a-f:]+$`},
+	"azure": {`^[0-9.]+$`, `^[0-9a-f:]+$`, `\.cloudapp\.azure\.com$`},
+	"ibm":   {`\.lb\.appdomain\.cloud$`},
+}
+
+// ValidateLoadBalancerProvider verifies that every ingress of a load balancer
+// matches one of the patterns of the expected provider. An empty provider
+// skips the validation
+func (e *ExistingCCMTestInterface) ValidateLoadBalancerProvider(status *v1.LoadBalancerStatus, expectedProvider string) error {
+	if expectedProvider == "" {
+		return nil
+	}
+	if status == nil || len(status.Ingress) == 0 {
+		return fmt.Errorf("load balancer has no ingress to validate against provider %s", expectedProvider)
+	}
+
+	patterns, ok := e.LoadBalancerProviderPatterns[expectedProvider]
+	if !ok {
+		patterns, ok = DefaultLoadBalancerProviderPatterns[expectedProvider]
+	}
+	if !ok {
+		return fmt.Errorf("no load balancer patterns configured for provider %s", expectedProvider)
+	}
+
+	expressions := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid load balancer pattern %q for provider %s: %w", pattern, expectedProvider, err)
+		}
+		expressions = append(expressions, re)
+	}
+
+	for _, ingress := range status.Ingress {
+		value := ingress.Hostname
+		if value == "" {
+			value = ingress.IP
+		}
+		if !slices.ContainsFunc(expressions, func(re *regexp.Regexp) bool { return re.MatchString(value) }) {
+			return fmt.Errorf("%w: ingress %q does not match provider %s", ErrLoadBalancerProviderMismatch, value, expectedProvider)
+		}
+	}
+	return nil
+}
+
 // DeleteTestService deletes a test service
 func (e *ExistingCCMTestInterface) DeleteTestService(ctx context.Context, serviceName string) error {
-	return e.kubeClient.CoreV1().Services(e.namespace).Delete(ctx, serviceName, metav1.DeleteOptions{})
+	return e.kubeClient.CoreV1().Services(e.namespace).Delete(ctx, e.config.ResourceName(serviceName), metav1.DeleteOptions{})
 }
 
 // DeleteTestNode deletes a test node
 func (e *ExistingCCMTestInterface) DeleteTestNode(ctx context.Context, nodeName string) error {
-	return e.kubeClient.CoreV1().Nodes().Delete(ctx, nodeName, metav1.DeleteOptions{})
+	nodeName = e.config.ResourceName(nodeName)
+	if err := e.kubeClient.CoreV1().Nodes().Delete(ctx, nodeName, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.createdNodes = slices.DeleteFunc(e.createdNodes, func(name string) bool { return name == nodeName })
+	e.mu.Unlock()
+	return nil
 }
 
 // CreateTestRoute creates a test route
@@ -271,7 +864,7 @@ func (e *ExistingCCMTestInterface) CreateTestRoute(ctx context.Context, routeCon
 	// For existing CCM testing, we don't create routes directly
 	// The CCM should handle route management
 	return &cloudprovider.Route{
-		Name:            routeConfig.Name,
+		Name:            e.config.ResourceNameOrGenerate(routeConfig.Name, "test-route"),
 		TargetNode:      routeConfig.TargetNode,
 		DestinationCIDR: routeConfig.DestinationCIDR,
 	}, nil
@@ -284,10 +877,68 @@ func (e *ExistingCCMTestInterface) DeleteTestRoute(ctx context.Context, routeNam
 	return nil
 }
 
-// WaitForCondition waits for a condition to be met
+// WaitForCondition waits for a condition to be met, polling its CheckFunction
+// until it reports success or the condition's timeout expires
 func (e *ExistingCCMTestInterface) WaitForCondition(ctx context.Context, condition ccmtesting.TestCondition) error {
-	// Implementation would wait for specific conditions
-	return nil
+	if condition.CheckFunction == nil {
+		return nil
+	}
+
+	timeout := condition.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("condition wait timed out: %s", condition.Type)
+		case <-ticker.C:
+			met, err := condition.CheckFunction()
+			if err != nil {
+				klog.Warningf("Error checking condition %s: %v", condition.Type, err)
+				continue
+			}
+			if met {
+				return nil
+			}
+		}
+	}
+}
+
+// DumpResources writes the live test nodes and the services in the test
+// namespace, as found by their test-prefix label, to w as YAML
+func (e *ExistingCCMTestInterface) DumpResources(ctx context.Context, w io.Writer) error {
+	selector := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("test-prefix=%s", e.config.TestData["resource-prefix"].(string)),
+	}
+
+	nodeList, err := e.kubeClient.CoreV1().Nodes().List(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("failed to list test nodes: %w", err)
+	}
+	serviceList, err := e.kubeClient.CoreV1().Services(e.namespace).List(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("failed to list test services: %w", err)
+	}
+
+	nodes := make([]*v1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+	services := make([]*v1.Service, 0, len(serviceList.Items))
+	for i := range serviceList.Items {
+		services = append(services, &serviceList.Items[i])
+	}
+
+	// Routes are managed by the CCM and not created by the tests
+	return ccmtesting.WriteResourceDump(w, nodes, services, nil)
 }
 
 // GetCloudProvider returns the cloud provider (nil for existing CCM testing)
@@ -306,6 +957,11 @@ func (e *ExistingCCMTestInterface) GetNamespace() string {
 	return e.namespace
 }
 
+// GetConfig returns the test configuration
+func (e *ExistingCCMTestInterface) GetConfig() *ccmtesting.TestConfig {
+	return e.config
+}
+
 // GetExistingNodes returns existing nodes in the cluster
 func (e *ExistingCCMTestInterface) GetExistingNodes() ([]v1.Node, error) {
 	nodes, err := e.kubeClient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
@@ -315,10 +971,10 @@ func (e *ExistingCCMTestInterface) GetExistingNodes() ([]v1.Node, error) {
 	return nodes.Items, nil
 }
 
-// GetTestResults returns test results
+// GetTestResults returns the test results, which collect the warnings
+// reported against the existing CCM
 func (e *ExistingCCMTestInterface) GetTestResults() *ccmtesting.TestResults {
-	// Implementation would track test results
-	return &ccmtesting.TestResults{}
+	return e.results
 }
 
 // Example test functions that use the existing CCM
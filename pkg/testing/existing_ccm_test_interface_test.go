@@ -0,0 +1,616 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// TestExistingCCMTestInterfaceWaitForServiceDeleted tests waiting for service and load balancer removal
+func TestExistingCCMTestInterfaceWaitForServiceDeleted(t *testing.T) {
+	config := &ccmtesting.TestConfig{
+		TestData: map[string]interface{}{"namespace": "ccm-test", "resource-prefix": ""},
+	}
+	lingering := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "lingering",
+			Namespace:  "ccm-test",
+			Finalizers: []string{"service.kubernetes.io/load-balancer-cleanup"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		service   string
+		lbDeleted func(ctx context.Context, service *v1.Service) (bool, error)
+		wantErr   string
+	}{
+		{
+			name:    "service already gone",
+			service: "deleted",
+		},
+		{
+			name:    "service stuck on finalizer",
+			service: "lingering",
+			wantErr: "timeout waiting for service lingering to be deleted",
+		},
+		{
+			name:    "load balancer confirmed gone",
+			service: "deleted",
+			lbDeleted: func(ctx context.Context, service *v1.Service) (bool, error) {
+				return service.Name == "deleted", nil
+			},
+		},
+		{
+			name:    "load balancer still present",
+			service: "deleted",
+			lbDeleted: func(ctx context.Context, service *v1.Service) (bool, error) {
+				return false, nil
+			},
+			wantErr: "timeout waiting for load balancer of service deleted to be deleted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExistingCCMTestInterface(fake.NewSimpleClientset(lingering.DeepCopy()), config)
+			e.LoadBalancerDeletedFunc = tt.lbDeleted
+
+			err := e.WaitForServiceDeleted(tt.service, 100*time.Millisecond)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestExistingCCMTestInterfaceWaitForLoadBalancerHostsUpdated tests waiting for
+// the service controller to report a load balancer host update
+func TestExistingCCMTestInterfaceWaitForLoadBalancerHostsUpdated(t *testing.T) {
+	config := &ccmtesting.TestConfig{
+		TestData: map[string]interface{}{"namespace": "ccm-test", "resource-prefix": ""},
+	}
+	since := time.Now()
+	event := func(name, service, reason string, at time.Time) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "ccm-test"},
+			InvolvedObject: v1.ObjectReference{Kind: "Service", Name: service, Namespace: "ccm-test"},
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(at),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		events  []*v1.Event
+		wantErr string
+	}{
+		{
+			name:   "hosts updated",
+			events: []*v1.Event{event("updated", "web", "UpdatedLoadBalancer", since.Add(time.Second))},
+		},
+		{
+			name:    "only an earlier update",
+			events:  []*v1.Event{event("updated", "web", "UpdatedLoadBalancer", since.Add(-time.Hour))},
+			wantErr: "timeout waiting for load balancer hosts of service web to be updated",
+		},
+		{
+			name: "update of another service",
+			events: []*v1.Event{
+				event("other", "api", "UpdatedLoadBalancer", since.Add(time.Second)),
+				event("ensured", "web", "EnsuredLoadBalancer", since.Add(time.Second)),
+			},
+			wantErr: "timeout waiting for load balancer hosts of service web to be updated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			for _, event := range tt.events {
+				if _, err := client.CoreV1().Events("ccm-test").Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("Failed to create event: %v", err)
+				}
+			}
+			e := NewExistingCCMTestInterface(client, config)
+
+			err := e.WaitForLoadBalancerHostsUpdated("web", since, 100*time.Millisecond)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestExistingCCMTestInterfaceCheckPermissions tests that every permission
+// denied by a SelfSubjectAccessReview is reported as missing
+func TestExistingCCMTestInterfaceCheckPermissions(t *testing.T) {
+	config := &ccmtesting.TestConfig{
+		TestData: map[string]interface{}{"namespace": "ccm-test", "resource-prefix": ""},
+	}
+
+	tests := []struct {
+		name    string
+		denied  map[string]bool
+		wantErr string
+	}{
+		{
+			name: "all allowed",
+		},
+		{
+			name:    "node deletion denied",
+			denied:  map[string]bool{"delete nodes": true},
+			wantErr: "missing permissions for existing CCM tests: delete nodes",
+		},
+		{
+			name:    "service creation and namespace deletion denied",
+			denied:  map[string]bool{"create services": true, "delete namespaces": true},
+			wantErr: "missing permissions for existing CCM tests: delete namespaces, create services in namespace ccm-test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			kubeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				review.Status.Allowed = !tt.denied[attributes.Verb+" "+attributes.Resource]
+				return true, review, nil
+			})
+
+			e := NewExistingCCMTestInterface(kubeClient, config)
+			err := e.CheckPermissions(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrMissingPermissions) {
+				t.Fatalf("Expected ErrMissingPermissions, got %v", err)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Expected error '%s', got '%v'", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestExistingCCMTestInterfaceTeardownDeletesNodes tests that teardown deletes
+// the cluster-scoped test nodes along with the test namespace
+func TestExistingCCMTestInterfaceTeardownDeletesNodes(t *testing.T) {
+	ctx := context.Background()
+	config := &ccmtesting.TestConfig{
+		TestData: map[string]interface{}{"namespace": "ccm-test", "resource-prefix": "existing-ccm-test"},
+	}
+	kubeClient := fake.NewSimpleClientset()
+	e := NewExistingCCMTestInterface(kubeClient, config)
+	if err := e.SetupTestEnvironment(config); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	for _, name := range []string{"teardown-node-a", "teardown-node-b"} {
+		if _, err := e.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: name}); err != nil {
+			t.Fatalf("Failed to create node %s: %v", name, err)
+		}
+	}
+	if _, err := e.CreateTestService(ctx, &ccmtesting.TestServiceConfig{Name: "teardown-service", Namespace: "ccm-test"}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	// A node deleted by the test itself is no longer cleaned up
+	if err := e.DeleteTestNode(ctx, "teardown-node-a"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+
+	if err := e.TeardownTestEnvironment(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := kubeClient.CoreV1().Namespaces().Get(ctx, "ccm-test", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the test namespace to be deleted, got %v", err)
+	}
+	nodes, err := kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list nodes: %v", err)
+	}
+	if len(nodes.Items) != 0 {
+		t.Errorf("Expected all test nodes to be deleted, got %d", len(nodes.Items))
+	}
+}
+
+// TestExistingCCMTestInterfaceTeardownNodeDeletionBestEffort tests that a node
+// that fails to delete is reported without stopping the other deletions
+func TestExistingCCMTestInterfaceTeardownNodeDeletionBestEffort(t *testing.T) {
+	ctx := context.Background()
+	config := &ccmtesting.TestConfig{
+		TestData: map[string]interface{}{"namespace": "ccm-test", "resource-prefix": "existing-ccm-test"},
+	}
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependReactor("delete", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.(clienttesting.DeleteAction).GetName() == "stuck-node" {
+			return true, nil, errors.New("node is protected")
+		}
+		return false, nil, nil
+	})
+
+	e := NewExistingCCMTestInterface(kubeClient, config)
+	for _, name := range []string{"leaked-node", "stuck-node"} {
+		if _, err := e.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: name}); err != nil {
+			t.Fatalf("Failed to create node %s: %v", name, err)
+		}
+	}
+
+	err := e.TeardownTestEnvironment()
+	if err == nil || !strings.Contains(err.Error(), "failed to delete test node stuck-node: node is protected") {
+		t.Errorf("Expected the stuck node to be reported, got %v", err)
+	}
+
+	if _, err := kubeClient.CoreV1().Nodes().Get(ctx, "leaked-node", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected leaked-node to be deleted despite the failure, got %v", err)
+	}
+}
+
+// TestExistingCCMTestInterfaceValidateLoadBalancerProvider tests matching load
+// balancer ingresses against the patterns of each provider
+func TestExistingCCMTestInterfaceValidateLoadBalancerProvider(t *testing.T) {
+	config := &ccmtesting.TestConfig{
+		TestData: map[string]interface{}{"namespace": "ccm-test", "resource-prefix": ""},
+	}
+
+	tests := []struct {
+		name         string
+		provider     string
+		ingress      []v1.LoadBalancerIngress
+		patterns     map[string][]string
+		wantErr      bool
+		wantMismatch bool
+	}{
+		{
+			name:     "aws hostname",
+			provider: "aws",
+			ingress:  []v1.LoadBalancerIngress{{Hostname: "a1b2c3-123456789.us-east-1.elb.amazonaws.com"}},
+		},
+		{
+			name:     "aws china hostname",
+			provider: "aws",
+			ingress:  []v1.LoadBalancerIngress{{Hostname: "a1b2c3-123456789.elb.cn-north-1.amazonaws.com.cn"}},
+		},
+		{
+			name:         "aws with ip",
+			provider:     "aws",
+			ingress:      []v1.LoadBalancerIngress{{IP: "203.0.113.10"}},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name:     "gcp ip",
+			provider: "gcp",
+			ingress:  []v1.LoadBalancerIngress{{IP: "203.0.113.10"}, {IP: "2001:db8::10"}},
+		},
+		{
+			name:         "gcp with aws hostname",
+			provider:     "gcp",
+			ingress:      []v1.LoadBalancerIngress{{Hostname: "a1b2c3-123456789.us-east-1.elb.amazonaws.com"}},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name:     "azure ip",
+			provider: "azure",
+			ingress:  []v1.LoadBalancerIngress{{IP: "20.62.1.10"}},
+		},
+		{
+			name:     "azure hostname",
+			provider: "azure",
+			ingress:  []v1.LoadBalancerIngress{{Hostname: "myservice.eastus.cloudapp.azure.com"}},
+		},
+		{
+			name:         "azure with ibm hostname",
+			provider:     "azure",
+			ingress:      []v1.LoadBalancerIngress{{Hostname: "abcd1234-us-south.lb.appdomain.cloud"}},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name:     "ibm hostname",
+			provider: "ibm",
+			ingress:  []v1.LoadBalancerIngress{{Hostname: "abcd1234-us-south.lb.appdomain.cloud"}},
+		},
+		{
+			name:         "one of several ingresses mismatched",
+			provider:     "aws",
+			ingress:      []v1.LoadBalancerIngress{{Hostname: "a1b2c3-123456789.us-east-1.elb.amazonaws.com"}, {IP: "203.0.113.10"}},
+			wantErr:      true,
+			wantMismatch: true,
+		},
+		{
+			name:     "configured patterns override defaults",
+			provider: "aws",
+			ingress:  []v1.LoadBalancerIngress{{Hostname: "lb.internal.example.com"}},
+			patterns: map[string][]string{"aws": {`\.internal\.example\.com$`}},
+		},
+		{
+			name:     "configured provider",
+			provider: "example",
+			ingress:  []v1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			patterns: map[string][]string{"example": {`\.example\.com$`}},
+		},
+		{
+			name:     "no expected provider",
+			provider: "",
+			ingress:  []v1.LoadBalancerIngress{{IP: "203.0.113.10"}},
+		},
+		{
+			name:     "unknown provider",
+			provider: "unknown",
+			ingress:  []v1.LoadBalancerIngress{{IP: "203.0.113.10"}},
+			wantErr:  true,
+		},
+		{
+			name:     "no ingress",
+			provider: "aws",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExistingCCMTestInterface(fake.NewSimpleClientset(), config)
+			e.LoadBalancerProviderPatterns = tt.patterns
+
+			err := e.ValidateLoadBalancerProvider(&v1.LoadBalancerStatus{Ingress: tt.ingress}, tt.provider)
+			if tt.wantErr && err == nil {
+				t.Fatalf("Expected an error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if errors.Is(err, ErrLoadBalancerProviderMismatch) != tt.wantMismatch {
+				t.Errorf("Expected mismatch %v, got %v", tt.wantMismatch, err)
+			}
+		})
+	}
+}
+
+// TestExistingCCMTestInterfaceAwaitServiceAnnotation tests reading an
+// annotation the CCM recorded on a service and timing out on a missing one
+func TestExistingCCMTestInterfaceAwaitServiceAnnotation(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "ccm-test",
+			Annotations: map[string]string{"example.com/load-balancer-id": "lb-0123"},
+		},
+	}
+	e := NewExistingCCMTestInterface(fake.NewSimpleClientset(service), &ccmtesting.TestConfig{})
+
+	id, err := e.AwaitServiceAnnotation(context.Background(), "ccm-test", "web", "example.com/load-balancer-id", time.Second)
+	if err != nil || id != "lb-0123" {
+		t.Errorf("Expected load balancer ID lb-0123, got %q, %v", id, err)
+	}
+
+	_, err = e.AwaitServiceAnnotation(context.Background(), "ccm-test", "web", "example.com/missing", 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timeout waiting for annotation example.com/missing") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+// TestExistingCCMTestInterfaceTeardownNamespaceTimeout tests that a namespace
+// that is never deleted fails teardown after the configured timeout, naming
+// what blocks its deletion
+func TestExistingCCMTestInterfaceTeardownNamespaceTimeout(t *testing.T) {
+	config := &ccmtesting.TestConfig{
+		TestData:        map[string]interface{}{"namespace": "ccm-test", "resource-prefix": ""},
+		TeardownTimeout: 300 * time.Millisecond,
+	}
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ccm-test"},
+		Spec:       v1.NamespaceSpec{Finalizers: []v1.FinalizerName{v1.FinalizerKubernetes}},
+		Status: v1.NamespaceStatus{
+			Phase: v1.NamespaceTerminating,
+			Conditions: []v1.NamespaceCondition{{
+				Type:    v1.NamespaceFinalizersRemaining,
+				Status:  v1.ConditionTrue,
+				Message: "Some content in the namespace has finalizers remaining",
+			}},
+		},
+	}
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "stuck-lb",
+			Namespace:  "ccm-test",
+			Finalizers: []string{"service.kubernetes.io/load-balancer-cleanup"},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(namespace, service)
+	// The namespace stays terminating, as with a load balancer that is never cleaned up
+	kubeClient.PrependReactor("delete", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+
+	e := NewExistingCCMTestInterface(kubeClient, config)
+	start := time.Now()
+	err := e.TeardownTestEnvironment()
+	if err == nil {
+		t.Fatal("Expected teardown to fail while the namespace is present")
+	}
+	if elapsed := time.Since(start); elapsed < config.TeardownTimeout {
+		t.Errorf("Expected teardown to wait at least %v, returned after %v", config.TeardownTimeout, elapsed)
+	}
+	for _, want := range []string{
+		"timeout after 300ms waiting for namespace ccm-test to be deleted",
+		"namespace is Terminating",
+		"namespace finalizers [kubernetes]",
+		"NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining",
+		"service stuck-lb with finalizers [service.kubernetes.io/load-balancer-cleanup]",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain '%s', got %v", want, err)
+		}
+	}
+}
+
+// TestExistingCCMTestInterfaceWaitForNodeInitialized tests that a watch event
+// removing the uninitialized taint returns the node well before the next poll
+func TestExistingCCMTestInterfaceWaitForNodeInitialized(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Spec: v1.NodeSpec{
+			ProviderID: "test-provider://worker-1",
+			Taints:     []v1.Taint{uninitializedTaint},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(node.DeepCopy())
+	watcher := watch.NewFake()
+	watching := make(chan string, 1)
+	kubeClient.PrependWatchReactor("nodes", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		watching <- action.(clienttesting.WatchAction).GetWatchRestrictions().Fields.String()
+		return true, watcher, nil
+	})
+	e := NewExistingCCMTestInterface(kubeClient, &ccmtesting.TestConfig{})
+
+	go func() {
+		if selector := <-watching; selector != "metadata.name=worker-1" {
+			t.Errorf("Expected a watch on node worker-1, got field selector %q", selector)
+		}
+		initialized := node.DeepCopy()
+		initialized.Spec.Taints = nil
+		initialized.Labels = map[string]string{v1.LabelTopologyZone: "zone-a"}
+		watcher.Modify(initialized)
+	}()
+
+	start := time.Now()
+	initialized, err := e.WaitForNodeInitialized(context.Background(), "worker-1", 10*time.Second)
+	if err != nil {
+		t.Fatalf("Expected the node to be initialized, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected a prompt return from the watch, took %v", elapsed)
+	}
+	if ccmtesting.NodeHasTaint(initialized, ccmtesting.UninitializedTaintKey) || initialized.Labels[v1.LabelTopologyZone] != "zone-a" {
+		t.Errorf("Expected the initialized node from the watch event, got %+v", initialized)
+	}
+}
+
+// TestExistingCCMTestInterfaceUseExistingNodes tests that the node, instances
+// and zones suites verify existing nodes through the Kubernetes API when there
+// is no cloud provider, failing on a node the CCM has not initialized
+func TestExistingCCMTestInterfaceUseExistingNodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		taints     []v1.Taint
+		wantFailed int
+	}{
+		{name: "initialized nodes"},
+		{name: "uninitialized node", taints: []v1.Taint{uninitializedTaint}, wantFailed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			for i, name := range []string{"existing-node-1", "existing-node-2"} {
+				node := &v1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+						Labels: map[string]string{
+							v1.LabelTopologyZone:       "us-east-1a",
+							v1.LabelTopologyRegion:     "us-east-1",
+							v1.LabelInstanceTypeStable: "m5.large",
+						},
+					},
+					Spec: v1.NodeSpec{ProviderID: "aws:///us-east-1a/i-" + name},
+					Status: v1.NodeStatus{
+						Addresses:  []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}},
+						Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+					},
+				}
+				if i == 1 {
+					node.Spec.Taints = tt.taints
+				}
+				if _, err := kubeClient.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("Failed to create existing node: %v", err)
+				}
+			}
+
+			config := &ccmtesting.TestConfig{
+				ProviderName:     "existing",
+				UseExistingNodes: true,
+				TestData:         map[string]interface{}{"namespace": "ccm-test", "resource-prefix": "existing-ccm-test"},
+			}
+			e := NewExistingCCMTestInterface(kubeClient, config)
+			if err := e.SetupTestEnvironment(config); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+
+			runner := ccmtesting.NewTestRunner(e)
+			runner.AddTestSuite(CreateNodeTestSuite())
+			runner.AddTestSuite(CreateInstancesTestSuite())
+			runner.AddTestSuite(CreateZonesTestSuite())
+
+			err := runner.RunTests(context.Background())
+			if tt.wantFailed == 0 && err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			summary := runner.GetSummary()
+			if summary.FailedTests != tt.wantFailed {
+				for _, result := range runner.GetResults() {
+					t.Logf("%s: success %t, error %v", result.Test.Name, result.Success, result.Error)
+				}
+				t.Errorf("Expected %d failed tests, got %d", tt.wantFailed, summary.FailedTests)
+			}
+
+			nodes, err := e.GetExistingNodes()
+			if err != nil {
+				t.Fatalf("Failed to list nodes: %v", err)
+			}
+			if len(nodes) != 2 {
+				t.Errorf("Expected only the 2 existing nodes, got %d", len(nodes))
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// RunningInGitHubActions reports whether the process runs in a GitHub Actions
+// workflow, which sets GITHUB_ACTIONS to "true".
+func RunningInGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// GitHubAnnotator writes GitHub Actions workflow commands while a run
+// progresses: the output of each suite is wrapped in a collapsible group and
+// every failed test is reported as an error annotation.
+type GitHubAnnotator struct {
+	mu    sync.Mutex
+	w     io.Writer
+	suite string
+}
+
+// NewGitHubAnnotator creates an annotator writing workflow commands to w,
+// which must be the standard output GitHub Actions reads them from.
+func NewGitHubAnnotator(w io.Writer) *GitHubAnnotator {
+	return &GitHubAnnotator{w: w}
+}
+
+// Attach registers the annotator with the runner's progress callbacks,
+// keeping any callbacks already set.
+func (a *GitHubAnnotator) Attach(runner *ccmtesting.TestRunner) {
+	attachProgress(runner, a.testStarted, a.testFinished)
+}
+
+func (a *GitHubAnnotator) testStarted(suiteName string, test ccmtesting.Test) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.enterSuite(suiteName)
+}
+
+func (a *GitHubAnnotator) testFinished(result ccmtesting.TestResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Tests skipped without starting are the first to report a new suite
+	a.enterSuite(result.Suite)
+	if result.Success {
+		return
+	}
+
+	message := "test failed"
+	if result.Error != nil {
+		message = result.Error.Error()
+	}
+	fmt.Fprintf(a.w, "::error title=%s::%s\n",
+		escapeGitHubProperty(result.Suite+"/"+result.Test.Name), escapeGitHubData(message))
+}
+
+// enterSuite closes the group of the previous suite and opens one for the
+// named suite, unless it is already open. The caller must hold a.mu.
+func (a *GitHubAnnotator) enterSuite(suiteName string) {
+	if suiteName == a.suite {
+		return
+	}
+	if a.suite != "" {
+		fmt.Fprintf(a.w, "::endgroup::\n")
+	}
+	fmt.Fprintf(a.w, "::group::%s\n", escapeGitHubData(suiteName))
+	a.suite = suiteName
+}
+
+// Close closes the group of the last suite, so that the report written after
+// the run is not collapsed into it.
+func (a *GitHubAnnotator) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.suite != "" {
+		fmt.Fprintf(a.w, "::endgroup::\n")
+		a.suite = ""
+	}
+}
+
+// escapeGitHubData escapes the message of a workflow command.
+func escapeGitHubData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command, which
+// additionally must not contain the separators ':' and ','.
+func escapeGitHubProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// TestGitHubAnnotator tests that failed tests produce escaped error
+// annotations and that each suite's output is grouped
+func TestGitHubAnnotator(t *testing.T) {
+	runner := ccmtesting.NewTestRunner(ccmtesting.NewFakeTestImplementation())
+	runner.AddTestSuite(ccmtesting.TestSuite{
+		Name: "LoadBalancer",
+		Tests: []ccmtesting.Test{
+			{Name: "Passes", Run: func(ccmtesting.TestInterface) error { return nil }},
+			{Name: "Fails", Run: func(ccmtesting.TestInterface) error {
+				return errors.New("100% of ports failed:\nport 80\r\nport 443")
+			}},
+		},
+	})
+	runner.AddTestSuite(ccmtesting.TestSuite{
+		Name:  "Nodes, Zones",
+		Tests: []ccmtesting.Test{{Name: "Skipped", Skip: true}},
+	})
+
+	var buf bytes.Buffer
+	annotator := NewGitHubAnnotator(&buf)
+	annotator.Attach(runner)
+	if err := runner.RunTests(context.Background()); !errors.Is(err, ccmtesting.ErrTestsFailed) {
+		t.Fatalf("Expected error wrapping ErrTestsFailed, got %v", err)
+	}
+	annotator.Close()
+
+	expected := "::group::LoadBalancer\n" +
+		"::error title=LoadBalancer/Fails::100%25 of ports failed:%0Aport 80%0D%0Aport 443\n" +
+		"::endgroup::\n" +
+		"::group::Nodes, Zones\n" +
+		"::endgroup::\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+// TestEscapeGitHubProperty tests that property separators are escaped
+func TestEscapeGitHubProperty(t *testing.T) {
+	if got := escapeGitHubProperty("Nodes, Zones/Get: 50%"); got != "Nodes%2C Zones/Get%3A 50%25" {
+		t.Errorf("Expected 'Nodes%%2C Zones/Get%%3A 50%%25', got '%s'", got)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// ResolveInstances returns the instances implementation of a cloud provider.
+// InstancesV2 is preferred when the provider offers it and is adapted to the
+// Instances method shape, so that tests written against Instances also run
+// against providers that only implement InstancesV2.
+func ResolveInstances(cp cloudprovider.Interface) (cloudprovider.Instances, bool) {
+	if cp == nil {
+		return nil, false
+	}
+
+	if instancesV2, ok := cp.InstancesV2(); ok && instancesV2 != nil {
+		return &instancesV2Adapter{instances: instancesV2}, true
+	}
+
+	return cp.Instances()
+}
+
+// instancesV2Adapter implements cloudprovider.Instances on top of
+// cloudprovider.InstancesV2 by looking nodes up through a stub node carrying
+// only the name or provider ID being queried.
+type instancesV2Adapter struct {
+	instances cloudprovider.InstancesV2
+}
+
+func nodeWithName(name types.NodeName) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: string(name)}}
+}
+
+func nodeWithProviderID(providerID string) *v1.Node {
+	return &v1.Node{Spec: v1.NodeSpec{ProviderID: providerID}}
+}
+
+// NodeAddresses returns the addresses of the named instance.
+func (a *instancesV2Adapter) NodeAddresses(ctx context.Context, name types.NodeName) ([]v1.NodeAddress, error) {
+	metadata, err := a.instances.InstanceMetadata(ctx, nodeWithName(name))
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NodeAddresses, nil
+}
+
+// NodeAddressesByProviderID returns the addresses of the instance with the given provider ID.
+func (a *instancesV2Adapter) NodeAddressesByProviderID(ctx context.Context, providerID string) ([]v1.NodeAddress, error) {
+	metadata, err := a.instances.InstanceMetadata(ctx, nodeWithProviderID(providerID))
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NodeAddresses, nil
+}
+
+// InstanceID returns the provider ID of the named instance, the closest
+// InstancesV2 equivalent of an instance ID.
+func (a *instancesV2Adapter) InstanceID(ctx context.Context, nodeName types.NodeName) (string, error) {
+	metadata, err := a.instances.InstanceMetadata(ctx, nodeWithName(nodeName))
+	if err != nil {
+		return "", err
+	}
+	return metadata.ProviderID, nil
+}
+
+// InstanceType returns the type of the named instance.
+func (a *instancesV2Adapter) InstanceType(ctx context.Context, name types.NodeName) (string, error) {
+	metadata, err := a.instances.InstanceMetadata(ctx, nodeWithName(name))
+	if err != nil {
+		return "", err
+	}
+	return metadata.InstanceType, nil
+}
+
+// InstanceTypeByProviderID returns the type of the instance with the given provider ID.
+func (a *instancesV2Adapter) InstanceTypeByProviderID(ctx context.Context, providerID string) (string, error) {
+	metadata, err := a.instances.InstanceMetadata(ctx, nodeWithProviderID(providerID))
+	if err != nil {
+		return "", err
+	}
+	return metadata.InstanceType, nil
+}
+
+// AddSSHKeyToAllInstances is not part of InstancesV2.
+func (a *instancesV2Adapter) AddSSHKeyToAllInstances(ctx context.Context, user string, keyData []byte) error {
+	return cloudprovider.NotImplemented
+}
+
+// CurrentNodeName returns the hostname as the node name.
+func (a *instancesV2Adapter) CurrentNodeName(ctx context.Context, hostname string) (types.NodeName, error) {
+	return types.NodeName(hostname), nil
+}
+
+// InstanceExistsByProviderID reports whether the instance with the given provider ID exists.
+func (a *instancesV2Adapter) InstanceExistsByProviderID(ctx context.Context, providerID string) (bool, error) {
+	return a.instances.InstanceExists(ctx, nodeWithProviderID(providerID))
+}
+
+// InstanceShutdownByProviderID reports whether the instance with the given provider ID is shut down.
+func (a *instancesV2Adapter) InstanceShutdownByProviderID(ctx context.Context, providerID string) (bool, error) {
+	return a.instances.InstanceShutdown(ctx, nodeWithProviderID(providerID))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ErrNoKubeconfig is returned by BuildRESTConfig when no kubeconfig path is
+// given and the process is not running inside a cluster.
+var ErrNoKubeconfig = errors.New("no kubeconfig provided and not running in a cluster: set --kubeconfig, or run as a Pod with a service account and --in-cluster")
+
+// RunningInCluster reports whether the process appears to run inside a Pod,
+// based on the service environment variables the kubelet injects.
+func RunningInCluster() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != ""
+}
+
+// BuildRESTConfig builds a client config for the cluster under test. With
+// inCluster set the Pod's service account is always used. Otherwise the given
+// kubeconfig is loaded, falling back to the service account when the path is
+// empty and the process runs inside a cluster.
+func BuildRESTConfig(kubeconfigPath string, inCluster bool) (*rest.Config, error) {
+	if inCluster {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+		}
+		return config, nil
+	}
+
+	if kubeconfigPath != "" {
+		config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig %s: %w", kubeconfigPath, err)
+		}
+		return config, nil
+	}
+
+	if !RunningInCluster() {
+		return nil, ErrNoKubeconfig
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+	return config, nil
+}
+
+// VerifyClusterConnection checks that the cluster under test is reachable by
+// listing a node within the given timeout. Failures are classified so that a
+// missing RBAC permission or rejected credentials are not mistaken for a
+// connectivity problem.
+func VerifyClusterConnection(client kubernetes.Interface, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1})
+	if err == nil {
+		return nil
+	}
+
+	var netErr net.Error
+	switch {
+	case apierrors.IsForbidden(err):
+		return fmt.Errorf("RBAC denied listing nodes; grant the test identity get/list on nodes (this is a setup problem, not connectivity): %w", err)
+	case apierrors.IsUnauthorized(err):
+		return fmt.Errorf("the API server rejected the credentials; check the kubeconfig or service account token: %w", err)
+	case errors.Is(err, context.DeadlineExceeded) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return fmt.Errorf("timed out after %s waiting for the API server; raise --connect-timeout for slow clusters: %w", timeout, err)
+	case errors.As(err, &netErr):
+		return fmt.Errorf("network error reaching the API server; check the server address and connectivity: %w", err)
+	default:
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://192.0.2.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test-token
+`
+
+// TestBuildRESTConfig tests resolving the cluster config from a kubeconfig path or the in-cluster environment
+func TestBuildRESTConfig(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	t.Run("empty path outside a cluster", func(t *testing.T) {
+		_, err := BuildRESTConfig("", false)
+		if !errors.Is(err, ErrNoKubeconfig) {
+			t.Errorf("Expected ErrNoKubeconfig, got %v", err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "--kubeconfig") {
+			t.Errorf("Expected error to mention --kubeconfig, got %v", err)
+		}
+	})
+
+	t.Run("forced in-cluster outside a cluster", func(t *testing.T) {
+		_, err := BuildRESTConfig("", true)
+		if err == nil || !strings.Contains(err.Error(), "in-cluster") {
+			t.Errorf("Expected in-cluster config error, got %v", err)
+		}
+	})
+
+	t.Run("missing kubeconfig file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing")
+		_, err := BuildRESTConfig(path, false)
+		if err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("Expected error naming %s, got %v", path, err)
+		}
+	})
+
+	t.Run("kubeconfig file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "kubeconfig")
+		if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+			t.Fatalf("Failed to write kubeconfig: %v", err)
+		}
+
+		config, err := BuildRESTConfig(path, false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if config.Host != "https://192.0.2.1:6443" {
+			t.Errorf("Expected host https://192.0.2.1:6443, got %s", config.Host)
+		}
+	})
+}
+
+// TestVerifyClusterConnection tests classification of cluster connectivity failures
+func TestVerifyClusterConnection(t *testing.T) {
+	nodes := schema.GroupResource{Resource: "nodes"}
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr string
+	}{
+		{
+			name: "connected",
+		},
+		{
+			name:    "forbidden",
+			err:     apierrors.NewForbidden(nodes, "", errors.New("cannot list resource")),
+			wantErr: "RBAC denied listing nodes",
+		},
+		{
+			name:    "unauthorized",
+			err:     apierrors.NewUnauthorized("invalid token"),
+			wantErr: "rejected the credentials",
+		},
+		{
+			name:    "timeout",
+			err:     context.DeadlineExceeded,
+			wantErr: "raise --connect-timeout",
+		},
+		{
+			name:    "other",
+			err:     errors.New("boom"),
+			wantErr: "failed to list nodes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if tt.err != nil {
+				client.PrependReactor("list", "nodes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.err
+				})
+			}
+
+			err := VerifyClusterConnection(client, time.Second)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error to wrap %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// AssertLoadBalancerStatusEqual returns an error describing the difference
+// between two load balancer statuses, or nil if they have the same ingress
+// points. Ingress points, and the ports of each, are compared as sets, since
+// providers may return them in a different order on every reconcile. A nil
+// status is equal to a status without ingress points.
+func AssertLoadBalancerStatusEqual(a, b *v1.LoadBalancerStatus) error {
+	aKeys := ingressKeys(a)
+	bKeys := ingressKeys(b)
+
+	var missing, unexpected []string
+	for key, count := range aKeys {
+		for i := bKeys[key]; i < count; i++ {
+			missing = append(missing, key)
+		}
+	}
+	for key, count := range bKeys {
+		for i := aKeys[key]; i < count; i++ {
+			unexpected = append(unexpected, key)
+		}
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return fmt.Errorf("load balancer ingress differs: missing [%s], unexpected [%s]", strings.Join(missing, ", "), strings.Join(unexpected, ", "))
+}
+
+// ingressKeys counts the ingress points of a status by their canonical form.
+func ingressKeys(status *v1.LoadBalancerStatus) map[string]int {
+	keys := make(map[string]int)
+	if status == nil {
+		return keys
+	}
+	for _, ingress := range status.Ingress {
+		keys[ingressKey(ingress)]++
+	}
+	return keys
+}
+
+// ingressKey returns a canonical form of an ingress point that does not depend
+// on the order of its ports.
+func ingressKey(ingress v1.LoadBalancerIngress) string {
+	ports := make([]string, 0, len(ingress.Ports))
+	for _, port := range ingress.Ports {
+		portKey := fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+		if port.Error != nil {
+			portKey += fmt.Sprintf("(error: %s)", *port.Error)
+		}
+		ports = append(ports, portKey)
+	}
+	sort.Strings(ports)
+
+	key := fmt.Sprintf("ip=%s hostname=%s", ingress.IP, ingress.Hostname)
+	if ingress.IPMode != nil {
+		key += fmt.Sprintf(" ipMode=%s", *ingress.IPMode)
+	}
+	if len(ports) > 0 {
+		key += fmt.Sprintf(" ports=%s", strings.Join(ports, ","))
+	}
+	return "{" + key + "}"
+}
+
+// loadBalancerClusterNameRecorder is implemented by load balancers that record
+// the cluster name they were ensured under, such as MockLoadBalancer.
+type loadBalancerClusterNameRecorder interface {
+	GetEnsuredClusterName(namespace, name string) (string, bool)
+}
+
+// AssertLoadBalancerClusterName returns an error unless the load balancer of
+// service was last ensured under the expected cluster name. Providers derive
+// load balancer names and tags from the cluster name, so ensuring under the
+// wrong one makes clusters sharing an account take over each other's load
+// balancers. The load balancer must record the cluster names it is given.
+func AssertLoadBalancerClusterName(lb cloudprovider.LoadBalancer, service *v1.Service, expected string) error {
+	recorder, ok := lb.(loadBalancerClusterNameRecorder)
+	if !ok {
+		return fmt.Errorf("load balancer %T does not record cluster names", lb)
+	}
+	clusterName, ok := recorder.GetEnsuredClusterName(service.Namespace, service.Name)
+	if !ok {
+		return fmt.Errorf("load balancer of service %s/%s was never ensured", service.Namespace, service.Name)
+	}
+	if clusterName != expected {
+		return fmt.Errorf("load balancer of service %s/%s was ensured under cluster %q, want %q", service.Namespace, service.Name, clusterName, expected)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// TestAssertLoadBalancerStatusEqual tests that load balancer statuses are
+// compared without regard to the order of ingress points and ports
+func TestAssertLoadBalancerStatusEqual(t *testing.T) {
+	protocolError := "Pending"
+	tests := []struct {
+		name    string
+		a       *v1.LoadBalancerStatus
+		b       *v1.LoadBalancerStatus
+		wantErr []string
+	}{
+		{
+			name: "both nil",
+		},
+		{
+			name: "nil and empty",
+			a:    nil,
+			b:    &v1.LoadBalancerStatus{},
+		},
+		{
+			name: "reordered ingress",
+			a: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{
+				{IP: "192.0.2.1"},
+				{Hostname: "lb.example.com"},
+			}},
+			b: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{
+				{Hostname: "lb.example.com"},
+				{IP: "192.0.2.1"},
+			}},
+		},
+		{
+			name: "reordered ports",
+			a: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{
+				IP: "192.0.2.1",
+				Ports: []v1.PortStatus{
+					{Port: 80, Protocol: v1.ProtocolTCP},
+					{Port: 53, Protocol: v1.ProtocolUDP, Error: &protocolError},
+				},
+			}}},
+			b: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{
+				IP: "192.0.2.1",
+				Ports: []v1.PortStatus{
+					{Port: 53, Protocol: v1.ProtocolUDP, Error: &protocolError},
+					{Port: 80, Protocol: v1.ProtocolTCP},
+				},
+			}}},
+		},
+		{
+			name:    "nil and non-empty",
+			a:       nil,
+			b:       &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.0.2.1"}}},
+			wantErr: []string{"missing []", "unexpected [{ip=192.0.2.1 hostname=}]"},
+		},
+		{
+			name:    "different ingress",
+			a:       &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.0.2.1"}}},
+			b:       &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.0.2.2"}}},
+			wantErr: []string{"missing [{ip=192.0.2.1 hostname=}]", "unexpected [{ip=192.0.2.2 hostname=}]"},
+		},
+		{
+			name: "duplicated ingress",
+			a: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{
+				{IP: "192.0.2.1"},
+				{IP: "192.0.2.1"},
+			}},
+			b:       &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.0.2.1"}}},
+			wantErr: []string{"missing [{ip=192.0.2.1 hostname=}]", "unexpected []"},
+		},
+		{
+			name: "different port error",
+			a: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{
+				IP:    "192.0.2.1",
+				Ports: []v1.PortStatus{{Port: 53, Protocol: v1.ProtocolUDP, Error: &protocolError}},
+			}}},
+			b: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{
+				IP:    "192.0.2.1",
+				Ports: []v1.PortStatus{{Port: 53, Protocol: v1.ProtocolUDP}},
+			}}},
+			wantErr: []string{"ports=53/UDP(error: Pending)", "unexpected [{ip=192.0.2.1 hostname= ports=53/UDP}]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AssertLoadBalancerStatusEqual(tt.a, tt.b)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error, got nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error containing '%s', got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+// TestAssertLoadBalancerClusterName tests that the cluster name a load
+// balancer was ensured under is compared against the expected one
+func TestAssertLoadBalancerClusterName(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 80}}},
+	}
+	mockLB := NewMockLoadBalancer()
+
+	if err := AssertLoadBalancerClusterName(mockLB, service, "prod-east"); err == nil || !strings.Contains(err.Error(), "never ensured") {
+		t.Errorf("Expected an error for a load balancer that was never ensured, got %v", err)
+	}
+
+	if _, err := mockLB.EnsureLoadBalancer(context.Background(), "prod-east", service, nil); err != nil {
+		t.Fatalf("Failed to ensure load balancer: %v", err)
+	}
+	if err := AssertLoadBalancerClusterName(mockLB, service, "prod-east"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := AssertLoadBalancerClusterName(mockLB, service, "prod-west"); err == nil || !strings.Contains(err.Error(), `"prod-east", want "prod-west"`) {
+		t.Errorf("Expected a cluster name mismatch, got %v", err)
+	}
+
+	var notRecording struct{ cloudprovider.LoadBalancer }
+	if err := AssertLoadBalancerClusterName(notRecording, service, "prod-east"); err == nil || !strings.Contains(err.Error(), "does not record cluster names") {
+		t.Errorf("Expected an error for a load balancer that does not record cluster names, got %v", err)
+	}
+}
@@ -19,7 +19,11 @@ package testing
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -36,21 +40,25 @@ type MockCloudProvider struct {
 	routes       *MockRoutes
 	clusters     *MockClusters
 
+	// Registered nodes, the single source of node data for the instances,
+	// zones and routes
+	nodes *nodeStore
+
 	// Test data
-	nodes    map[string]*v1.Node
 	services map[string]*v1.Service
 	routeMap map[string]*cloudprovider.Route
 }
 
 // NewMockCloudProvider creates a new mock cloud provider.
 func NewMockCloudProvider() *MockCloudProvider {
+	nodes := newNodeStore()
 	return &MockCloudProvider{
-		instances:    NewMockInstances(),
-		zones:        NewMockZones(),
+		instances:    newMockInstances(nodes),
+		zones:        newMockZones(nodes),
 		loadBalancer: NewMockLoadBalancer(),
-		routes:       NewMockRoutes(),
+		routes:       newMockRoutes(nodes),
 		clusters:     NewMockClusters(),
-		nodes:        make(map[string]*v1.Node),
+		nodes:        nodes,
 		services:     make(map[string]*v1.Service),
 		routeMap:     make(map[string]*cloudprovider.Route),
 	}
@@ -101,18 +109,459 @@ func (m *MockCloudProvider) HasClusterID() bool {
 	return true
 }
 
-// MockInstances implements the cloudprovider.Instances interface.
+// GetMockInstances returns the mock instances so tests can customize them.
+func (m *MockCloudProvider) GetMockInstances() *MockInstances {
+	return m.instances
+}
+
+// GetMockZones returns the mock zones so tests can customize them.
+func (m *MockCloudProvider) GetMockZones() *MockZones {
+	return m.zones
+}
+
+// GetMockLoadBalancer returns the mock load balancer so tests can inspect
+// what the provider was asked to do.
+func (m *MockCloudProvider) GetMockLoadBalancer() *MockLoadBalancer {
+	return m.loadBalancer
+}
+
+// GetMockRoutes returns the mock routes so tests can customize them.
+func (m *MockCloudProvider) GetMockRoutes() *MockRoutes {
+	return m.routes
+}
+
+// GetMockClusters returns the mock clusters so tests can customize them.
+func (m *MockCloudProvider) GetMockClusters() *MockClusters {
+	return m.clusters
+}
+
+// NodeData describes the cloud instance backing a node. The mock instances
+// and zones answer every query about a registered node from its NodeData, so
+// that they report consistent data.
+type NodeData struct {
+	// ProviderID is the provider ID of the instance, by which it can be
+	// looked up as well as by node name.
+	ProviderID string
+
+	// InstanceType is the instance type; the mock instance type is reported
+	// if it is empty.
+	InstanceType string
+
+	// Addresses are the addresses of the instance.
+	Addresses []v1.NodeAddress
+
+	// Zone and Region locate the instance; the mock zone is reported if Zone
+	// is empty.
+	Zone   string
+	Region string
+
+	// Exists and Shutdown are the state of the instance, as reported by the
+	// existence and shutdown checks.
+	Exists   bool
+	Shutdown bool
+
+	// Hostname is the hostname of the instance, reported as a Hostname
+	// address and resolved to the node by CurrentNodeName.
+	Hostname string
+}
+
+// RegisterNode registers the instance backing the named node, replacing any
+// earlier registration.
+func (m *MockCloudProvider) RegisterNode(name string, data NodeData) {
+	m.nodes.register(types.NodeName(name), data)
+}
+
+// GetNodeData returns the data registered for the named node.
+func (m *MockCloudProvider) GetNodeData(name string) (NodeData, bool) {
+	return m.nodes.get(types.NodeName(name))
+}
+
+// MockCloudProviderSnapshot is the state of a MockCloudProvider at one point
+// in time, as captured by Snapshot.
+type MockCloudProviderSnapshot struct {
+	nodes map[types.NodeName]NodeData
+
+	removedInstances              map[string]bool
+	existenceChecksNotImplemented bool
+
+	providerIDZones map[string]cloudprovider.Zone
+	nodeNameZones   map[types.NodeName]cloudprovider.Zone
+
+	ensuredServices     map[string]*v1.Service
+	loadBalancers       map[string]*v1.LoadBalancerStatus
+	loadBalancerNames   map[string]string
+	ensuredNodes        map[string][]string
+	updatedNodes        map[string][]string
+	ensuredClusterNames map[string]string
+	loadBalancerClass   string
+	provisionDelay      time.Duration
+	provisionedAt       map[string]time.Time
+
+	routes map[string]*cloudprovider.Route
+}
+
+// Snapshot captures the registered nodes, instance and zone overrides, load
+// balancers and routes of the provider, so that a test can set up a baseline
+// once and Restore it after each destructive case. Hooks such as
+// EnsureLoadBalancerFunc are not part of the snapshot.
+func (m *MockCloudProvider) Snapshot() *MockCloudProviderSnapshot {
+	snapshot := &MockCloudProviderSnapshot{}
+
+	m.nodes.mu.RLock()
+	snapshot.nodes = copyNodeData(m.nodes.nodes)
+	m.nodes.mu.RUnlock()
+
+	m.instances.mu.RLock()
+	snapshot.removedInstances = maps.Clone(m.instances.removedInstances)
+	snapshot.existenceChecksNotImplemented = m.instances.existenceChecksNotImplemented
+	m.instances.mu.RUnlock()
+
+	m.zones.mu.RLock()
+	snapshot.providerIDZones = maps.Clone(m.zones.providerIDZones)
+	snapshot.nodeNameZones = maps.Clone(m.zones.nodeNameZones)
+	m.zones.mu.RUnlock()
+
+	lb := m.loadBalancer
+	lb.mu.RLock()
+	snapshot.ensuredServices = copyServices(lb.ensuredServices)
+	snapshot.loadBalancers = copyLoadBalancerStatuses(lb.loadBalancers)
+	snapshot.loadBalancerNames = maps.Clone(lb.loadBalancerNames)
+	snapshot.ensuredNodes = copyNodeNames(lb.ensuredNodes)
+	snapshot.updatedNodes = copyNodeNames(lb.updatedNodes)
+	snapshot.ensuredClusterNames = maps.Clone(lb.ensuredClusterNames)
+	snapshot.loadBalancerClass = lb.loadBalancerClass
+	snapshot.provisionDelay = lb.provisionDelay
+	snapshot.provisionedAt = maps.Clone(lb.provisionedAt)
+	lb.mu.RUnlock()
+
+	m.routes.mu.RLock()
+	snapshot.routes = copyRoutes(m.routes.routes)
+	m.routes.mu.RUnlock()
+
+	return snapshot
+}
+
+// Restore rolls the provider back to the state captured by Snapshot,
+// discarding every change made since. A snapshot can be restored any number
+// of times.
+func (m *MockCloudProvider) Restore(snapshot *MockCloudProviderSnapshot) {
+	m.nodes.mu.Lock()
+	m.nodes.nodes = copyNodeData(snapshot.nodes)
+	m.nodes.mu.Unlock()
+
+	m.instances.mu.Lock()
+	m.instances.removedInstances = maps.Clone(snapshot.removedInstances)
+	m.instances.existenceChecksNotImplemented = snapshot.existenceChecksNotImplemented
+	m.instances.mu.Unlock()
+
+	m.zones.mu.Lock()
+	m.zones.providerIDZones = maps.Clone(snapshot.providerIDZones)
+	m.zones.nodeNameZones = maps.Clone(snapshot.nodeNameZones)
+	m.zones.mu.Unlock()
+
+	lb := m.loadBalancer
+	lb.mu.Lock()
+	lb.ensuredServices = copyServices(snapshot.ensuredServices)
+	lb.loadBalancers = copyLoadBalancerStatuses(snapshot.loadBalancers)
+	lb.loadBalancerNames = maps.Clone(snapshot.loadBalancerNames)
+	lb.ensuredNodes = copyNodeNames(snapshot.ensuredNodes)
+	lb.updatedNodes = copyNodeNames(snapshot.updatedNodes)
+	lb.ensuredClusterNames = maps.Clone(snapshot.ensuredClusterNames)
+	lb.loadBalancerClass = snapshot.loadBalancerClass
+	lb.provisionDelay = snapshot.provisionDelay
+	lb.provisionedAt = maps.Clone(snapshot.provisionedAt)
+	lb.mu.Unlock()
+
+	m.routes.mu.Lock()
+	m.routes.routes = copyRoutes(snapshot.routes)
+	m.routes.mu.Unlock()
+}
+
+// Clone returns a new provider configured like this one: the same registered
+// nodes, instance and zone overrides, load balancer class and provisioning
+// delay, and hooks. The load balancers and routes this provider created are
+// not copied, so that the clone starts from a clean cloud.
+func (m *MockCloudProvider) Clone() *MockCloudProvider {
+	snapshot := m.Snapshot()
+	snapshot.ensuredServices = nil
+	snapshot.loadBalancers = nil
+	snapshot.loadBalancerNames = map[string]string{}
+	snapshot.ensuredNodes = nil
+	snapshot.updatedNodes = nil
+	snapshot.ensuredClusterNames = map[string]string{}
+	snapshot.provisionedAt = map[string]time.Time{}
+	snapshot.routes = nil
+
+	clone := NewMockCloudProvider()
+	clone.Restore(snapshot)
+
+	instances, cloneInstances := m.instances, clone.instances
+	cloneInstances.NodeAddressesFunc = instances.NodeAddressesFunc
+	cloneInstances.NodeAddressesByProviderIDFunc = instances.NodeAddressesByProviderIDFunc
+	cloneInstances.InstanceIDFunc = instances.InstanceIDFunc
+	cloneInstances.InstanceTypeFunc = instances.InstanceTypeFunc
+	cloneInstances.InstanceTypeByProviderIDFunc = instances.InstanceTypeByProviderIDFunc
+	cloneInstances.AddSSHKeyToAllInstancesFunc = instances.AddSSHKeyToAllInstancesFunc
+	cloneInstances.CurrentNodeNameFunc = instances.CurrentNodeNameFunc
+	cloneInstances.InstanceExistsByProviderIDFunc = instances.InstanceExistsByProviderIDFunc
+	cloneInstances.InstanceShutdownByProviderIDFunc = instances.InstanceShutdownByProviderIDFunc
+
+	clone.zones.GetZoneFunc = m.zones.GetZoneFunc
+	clone.zones.GetZoneByProviderIDFunc = m.zones.GetZoneByProviderIDFunc
+	clone.zones.GetZoneByNodeNameFunc = m.zones.GetZoneByNodeNameFunc
+
+	lb, cloneLB := m.loadBalancer, clone.loadBalancer
+	cloneLB.EnsureLoadBalancerFunc = lb.EnsureLoadBalancerFunc
+	cloneLB.UpdateLoadBalancerFunc = lb.UpdateLoadBalancerFunc
+	cloneLB.EnsureLoadBalancerDeletedFunc = lb.EnsureLoadBalancerDeletedFunc
+	cloneLB.GetLoadBalancerNameFunc = lb.GetLoadBalancerNameFunc
+	cloneLB.GetLoadBalancerFunc = lb.GetLoadBalancerFunc
+
+	clone.routes.ListRoutesFunc = m.routes.ListRoutesFunc
+	clone.routes.CreateRouteFunc = m.routes.CreateRouteFunc
+	clone.routes.DeleteRouteFunc = m.routes.DeleteRouteFunc
+
+	clone.clusters.ListClustersFunc = m.clusters.ListClustersFunc
+	clone.clusters.MasterFunc = m.clusters.MasterFunc
+
+	return clone
+}
+
+func copyNodeData(nodes map[types.NodeName]NodeData) map[types.NodeName]NodeData {
+	copied := make(map[types.NodeName]NodeData, len(nodes))
+	for name, data := range nodes {
+		data.Addresses = slices.Clone(data.Addresses)
+		copied[name] = data
+	}
+	return copied
+}
+
+func copyServices(services map[string]*v1.Service) map[string]*v1.Service {
+	copied := make(map[string]*v1.Service, len(services))
+	for key, service := range services {
+		copied[key] = service.DeepCopy()
+	}
+	return copied
+}
+
+func copyLoadBalancerStatuses(statuses map[string]*v1.LoadBalancerStatus) map[string]*v1.LoadBalancerStatus {
+	copied := make(map[string]*v1.LoadBalancerStatus, len(statuses))
+	for name, status := range statuses {
+		copied[name] = status.DeepCopy()
+	}
+	return copied
+}
+
+func copyNodeNames(nodes map[string][]string) map[string][]string {
+	copied := make(map[string][]string, len(nodes))
+	for key, names := range nodes {
+		copied[key] = slices.Clone(names)
+	}
+	return copied
+}
+
+func copyRoutes(routes map[string]*cloudprovider.Route) map[string]*cloudprovider.Route {
+	copied := make(map[string]*cloudprovider.Route, len(routes))
+	for cidr, route := range routes {
+		route := *route
+		route.TargetNodeAddresses = slices.Clone(route.TargetNodeAddresses)
+		copied[cidr] = &route
+	}
+	return copied
+}
+
+// nodeStore holds the NodeData of registered nodes by node name. A
+// MockCloudProvider shares one store between its sub-interfaces.
+type nodeStore struct {
+	mu    sync.RWMutex
+	nodes map[types.NodeName]NodeData
+}
+
+func newNodeStore() *nodeStore {
+	return &nodeStore{nodes: make(map[types.NodeName]NodeData)}
+}
+
+func (s *nodeStore) register(name types.NodeName, data NodeData) {
+	data.Addresses = slices.Clone(data.Addresses)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes[name] = data
+}
+
+func (s *nodeStore) get(name types.NodeName) (NodeData, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.nodes[name]
+	return data, ok
+}
+
+// getByProviderID returns the name and data of the node registered with the
+// given provider ID.
+func (s *nodeStore) getByProviderID(providerID string) (types.NodeName, NodeData, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for name, data := range s.nodes {
+		if data.ProviderID == providerID {
+			return name, data, true
+		}
+	}
+	return "", NodeData{}, false
+}
+
+// getByHostname returns the name of the node registered with the given
+// hostname.
+func (s *nodeStore) getByHostname(hostname string) (types.NodeName, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for name, data := range s.nodes {
+		if data.Hostname == hostname {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// setExists sets whether the instance registered with the given provider ID
+// exists, reporting whether such an instance is registered.
+func (s *nodeStore) setExists(providerID string, exists bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, data := range s.nodes {
+		if data.ProviderID == providerID {
+			data.Exists = exists
+			s.nodes[name] = data
+			return true
+		}
+	}
+	return false
+}
+
+// addresses returns the addresses of a registered node, including its
+// hostname.
+func (d NodeData) addresses() []v1.NodeAddress {
+	addresses := slices.Clone(d.Addresses)
+	if d.Hostname != "" && !slices.ContainsFunc(addresses, func(address v1.NodeAddress) bool { return address.Type == v1.NodeHostName }) {
+		addresses = append(addresses, v1.NodeAddress{Type: v1.NodeHostName, Address: d.Hostname})
+	}
+	return addresses
+}
+
+// zone returns the zone of a registered node, and whether it has one.
+func (d NodeData) zone() (cloudprovider.Zone, bool) {
+	if d.Zone == "" {
+		return cloudprovider.Zone{}, false
+	}
+	return cloudprovider.Zone{FailureDomain: d.Zone, Region: d.Region}, true
+}
+
+// MockInstances implements the cloudprovider.Instances interface. Nodes
+// registered through MockCloudProvider.RegisterNode are reported from their
+// NodeData; every other node gets fixed mock data.
 type MockInstances struct {
 	mu sync.RWMutex
+
+	nodes *nodeStore
+
+	// removedInstances holds the provider IDs of instances that no longer
+	// exist; every other instance exists.
+	removedInstances map[string]bool
+
+	// existenceChecksNotImplemented makes the existence and shutdown checks
+	// return cloudprovider.NotImplemented.
+	existenceChecksNotImplemented bool
+
+	// The hooks below, if set, replace the method of the same name, which
+	// then no longer consults the registered nodes.
+
+	// NodeAddressesFunc overrides NodeAddresses. It also answers
+	// NodeAddressesByProviderID for registered nodes, unless
+	// NodeAddressesByProviderIDFunc is set.
+	NodeAddressesFunc func(ctx context.Context, name types.NodeName) ([]v1.NodeAddress, error)
+
+	// NodeAddressesByProviderIDFunc overrides NodeAddressesByProviderID.
+	NodeAddressesByProviderIDFunc func(ctx context.Context, providerID string) ([]v1.NodeAddress, error)
+
+	// InstanceIDFunc overrides InstanceID, including the InstanceNotFound
+	// error for registered nodes whose instance no longer exists.
+	InstanceIDFunc func(ctx context.Context, nodeName types.NodeName) (string, error)
+
+	// InstanceTypeFunc overrides InstanceType.
+	InstanceTypeFunc func(ctx context.Context, name types.NodeName) (string, error)
+
+	// InstanceTypeByProviderIDFunc overrides InstanceTypeByProviderID.
+	InstanceTypeByProviderIDFunc func(ctx context.Context, providerID string) (string, error)
+
+	// AddSSHKeyToAllInstancesFunc overrides AddSSHKeyToAllInstances, which
+	// otherwise does nothing.
+	AddSSHKeyToAllInstancesFunc func(ctx context.Context, user string, keyData []byte) error
+
+	// CurrentNodeNameFunc overrides CurrentNodeName.
+	CurrentNodeNameFunc func(ctx context.Context, hostname string) (types.NodeName, error)
+
+	// InstanceExistsByProviderIDFunc overrides InstanceExistsByProviderID,
+	// so SetInstanceExists, SetExistenceChecksNotImplemented and the Exists
+	// of registered nodes no longer affect the existence check.
+	InstanceExistsByProviderIDFunc func(ctx context.Context, providerID string) (bool, error)
+
+	// InstanceShutdownByProviderIDFunc overrides
+	// InstanceShutdownByProviderID, so SetExistenceChecksNotImplemented and
+	// the Shutdown of registered nodes no longer affect the shutdown check.
+	InstanceShutdownByProviderIDFunc func(ctx context.Context, providerID string) (bool, error)
 }
 
-// NewMockInstances creates a new mock instances interface.
+// NewMockInstances creates a new mock instances interface with a node store
+// of its own.
 func NewMockInstances() *MockInstances {
-	return &MockInstances{}
+	return newMockInstances(newNodeStore())
+}
+
+func newMockInstances(nodes *nodeStore) *MockInstances {
+	return &MockInstances{
+		nodes:            nodes,
+		removedInstances: make(map[string]bool),
+	}
+}
+
+// SetInstanceExists sets whether the instance with the given provider ID
+// exists, to simulate instances being removed from the cloud.
+func (m *MockInstances) SetInstanceExists(providerID string, exists bool) {
+	if m.nodes.setExists(providerID, exists) {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if exists {
+		delete(m.removedInstances, providerID)
+	} else {
+		m.removedInstances[providerID] = true
+	}
+}
+
+// SetExistenceChecksNotImplemented makes InstanceExistsByProviderID and
+// InstanceShutdownByProviderID return cloudprovider.NotImplemented, as
+// providers without those checks do.
+func (m *MockInstances) SetExistenceChecksNotImplemented(notImplemented bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.existenceChecksNotImplemented = notImplemented
 }
 
 // NodeAddresses returns the addresses of the specified instance.
 func (m *MockInstances) NodeAddresses(ctx context.Context, name types.NodeName) ([]v1.NodeAddress, error) {
+	if m.NodeAddressesFunc != nil {
+		return m.NodeAddressesFunc(ctx, name)
+	}
+
+	if data, ok := m.nodes.get(name); ok {
+		if !data.Exists {
+			return nil, cloudprovider.InstanceNotFound
+		}
+		return data.addresses(), nil
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -126,170 +575,685 @@ func (m *MockInstances) NodeAddresses(ctx context.Context, name types.NodeName)
 
 // NodeAddressesByProviderID returns the addresses of the specified instance.
 func (m *MockInstances) NodeAddressesByProviderID(ctx context.Context, providerID string) ([]v1.NodeAddress, error) {
+	if m.NodeAddressesByProviderIDFunc != nil {
+		return m.NodeAddressesByProviderIDFunc(ctx, providerID)
+	}
+	if name, _, ok := m.nodes.getByProviderID(providerID); ok {
+		return m.NodeAddresses(ctx, name)
+	}
 	return m.NodeAddresses(ctx, types.NodeName("mock-node"))
 }
 
 // InstanceID returns the cloud provider ID of the specified instance.
 func (m *MockInstances) InstanceID(ctx context.Context, nodeName types.NodeName) (string, error) {
+	if m.InstanceIDFunc != nil {
+		return m.InstanceIDFunc(ctx, nodeName)
+	}
+	if data, ok := m.nodes.get(nodeName); ok && data.ProviderID != "" {
+		if !data.Exists {
+			return "", cloudprovider.InstanceNotFound
+		}
+		return data.ProviderID, nil
+	}
 	return fmt.Sprintf("mock-provider://%s", nodeName), nil
 }
 
 // InstanceType returns the type of the specified instance.
 func (m *MockInstances) InstanceType(ctx context.Context, name types.NodeName) (string, error) {
+	if m.InstanceTypeFunc != nil {
+		return m.InstanceTypeFunc(ctx, name)
+	}
+	if data, ok := m.nodes.get(name); ok && data.InstanceType != "" {
+		return data.InstanceType, nil
+	}
 	return "mock-instance-type", nil
 }
 
 // InstanceTypeByProviderID returns the type of the specified instance.
 func (m *MockInstances) InstanceTypeByProviderID(ctx context.Context, providerID string) (string, error) {
+	if m.InstanceTypeByProviderIDFunc != nil {
+		return m.InstanceTypeByProviderIDFunc(ctx, providerID)
+	}
+	if _, data, ok := m.nodes.getByProviderID(providerID); ok && data.InstanceType != "" {
+		return data.InstanceType, nil
+	}
 	return "mock-instance-type", nil
 }
 
 // AddSSHKeyToAllInstances adds an SSH public key as a legal identity for all instances.
 func (m *MockInstances) AddSSHKeyToAllInstances(ctx context.Context, user string, keyData []byte) error {
+	if m.AddSSHKeyToAllInstancesFunc != nil {
+		return m.AddSSHKeyToAllInstancesFunc(ctx, user, keyData)
+	}
 	return nil
 }
 
 // CurrentNodeName returns the name of the node we are currently running on.
 func (m *MockInstances) CurrentNodeName(ctx context.Context, hostname string) (types.NodeName, error) {
+	if m.CurrentNodeNameFunc != nil {
+		return m.CurrentNodeNameFunc(ctx, hostname)
+	}
+	if name, ok := m.nodes.getByHostname(hostname); ok {
+		return name, nil
+	}
 	return types.NodeName(hostname), nil
 }
 
 // InstanceExistsByProviderID returns true if the instance for the given provider ID still exists.
 func (m *MockInstances) InstanceExistsByProviderID(ctx context.Context, providerID string) (bool, error) {
-	return true, nil
+	if m.InstanceExistsByProviderIDFunc != nil {
+		return m.InstanceExistsByProviderIDFunc(ctx, providerID)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.existenceChecksNotImplemented {
+		return false, cloudprovider.NotImplemented
+	}
+	if _, data, ok := m.nodes.getByProviderID(providerID); ok {
+		return data.Exists, nil
+	}
+	return !m.removedInstances[providerID], nil
 }
 
 // InstanceShutdownByProviderID returns true if the instance is shutdown in cloudprovider.
 func (m *MockInstances) InstanceShutdownByProviderID(ctx context.Context, providerID string) (bool, error) {
+	if m.InstanceShutdownByProviderIDFunc != nil {
+		return m.InstanceShutdownByProviderIDFunc(ctx, providerID)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.existenceChecksNotImplemented {
+		return false, cloudprovider.NotImplemented
+	}
+	if _, data, ok := m.nodes.getByProviderID(providerID); ok {
+		return data.Shutdown, nil
+	}
 	return false, nil
 }
 
 // MockZones implements the cloudprovider.Zones interface.
 type MockZones struct {
+	mu sync.RWMutex
+
+	nodes *nodeStore
+
+	// providerIDZones and nodeNameZones hold zones set for individual nodes,
+	// which take precedence over the zone of a registered node; other nodes
+	// are in the default mock zone.
+	providerIDZones map[string]cloudprovider.Zone
+	nodeNameZones   map[types.NodeName]cloudprovider.Zone
+
+	// GetZoneFunc overrides GetZone, which otherwise returns the default
+	// mock zone.
+	GetZoneFunc func(ctx context.Context) (cloudprovider.Zone, error)
+
+	// GetZoneByProviderIDFunc overrides GetZoneByProviderID, so zones set
+	// with SetZoneForProviderID and those of registered nodes are ignored.
+	GetZoneByProviderIDFunc func(ctx context.Context, providerID string) (cloudprovider.Zone, error)
+
+	// GetZoneByNodeNameFunc overrides GetZoneByNodeName, so zones set with
+	// SetZoneForNodeName and those of registered nodes are ignored.
+	GetZoneByNodeNameFunc func(ctx context.Context, nodeName types.NodeName) (cloudprovider.Zone, error)
 }
 
-// NewMockZones creates a new mock zones interface.
+// NewMockZones creates a new mock zones interface with a node store of its
+// own.
 func NewMockZones() *MockZones {
-	return &MockZones{}
+	return newMockZones(newNodeStore())
+}
+
+func newMockZones(nodes *nodeStore) *MockZones {
+	return &MockZones{
+		nodes:           nodes,
+		providerIDZones: make(map[string]cloudprovider.Zone),
+		nodeNameZones:   make(map[types.NodeName]cloudprovider.Zone),
+	}
+}
+
+// defaultMockZone is the zone reported for nodes without a registered zone.
+var defaultMockZone = cloudprovider.Zone{
+	FailureDomain: "mock-zone",
+	Region:        "mock-region",
+}
+
+// SetZoneForProviderID registers the zone GetZoneByProviderID reports for a node.
+func (m *MockZones) SetZoneForProviderID(providerID string, zone cloudprovider.Zone) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.providerIDZones[providerID] = zone
+}
+
+// SetZoneForNodeName registers the zone GetZoneByNodeName reports for a node.
+func (m *MockZones) SetZoneForNodeName(nodeName types.NodeName, zone cloudprovider.Zone) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodeNameZones[nodeName] = zone
 }
 
 // GetZone returns the Zone containing the current failure zone and locality region.
 func (m *MockZones) GetZone(ctx context.Context) (cloudprovider.Zone, error) {
-	return cloudprovider.Zone{
-		FailureDomain: "mock-zone",
-		Region:        "mock-region",
-	}, nil
+	if m.GetZoneFunc != nil {
+		return m.GetZoneFunc(ctx)
+	}
+	return defaultMockZone, nil
 }
 
 // GetZoneByProviderID returns the Zone containing the current failure zone and locality region.
 func (m *MockZones) GetZoneByProviderID(ctx context.Context, providerID string) (cloudprovider.Zone, error) {
-	return cloudprovider.Zone{
-		FailureDomain: "mock-zone",
-		Region:        "mock-region",
-	}, nil
+	if m.GetZoneByProviderIDFunc != nil {
+		return m.GetZoneByProviderIDFunc(ctx, providerID)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if zone, ok := m.providerIDZones[providerID]; ok {
+		return zone, nil
+	}
+	if _, data, ok := m.nodes.getByProviderID(providerID); ok {
+		if zone, ok := data.zone(); ok {
+			return zone, nil
+		}
+	}
+	return defaultMockZone, nil
 }
 
 // GetZoneByNodeName returns the Zone containing the current failure zone and locality region.
 func (m *MockZones) GetZoneByNodeName(ctx context.Context, nodeName types.NodeName) (cloudprovider.Zone, error) {
-	return cloudprovider.Zone{
-		FailureDomain: "mock-zone",
-		Region:        "mock-region",
-	}, nil
+	if m.GetZoneByNodeNameFunc != nil {
+		return m.GetZoneByNodeNameFunc(ctx, nodeName)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if zone, ok := m.nodeNameZones[nodeName]; ok {
+		return zone, nil
+	}
+	if data, ok := m.nodes.get(nodeName); ok {
+		if zone, ok := data.zone(); ok {
+			return zone, nil
+		}
+	}
+	return defaultMockZone, nil
 }
 
 // MockLoadBalancer implements the cloudprovider.LoadBalancer interface.
 type MockLoadBalancer struct {
 	mu sync.RWMutex
+
+	// ensuredServices records a copy of the last service passed to
+	// EnsureLoadBalancer, keyed by namespace/name.
+	ensuredServices map[string]*v1.Service
+
+	// loadBalancers holds the status of each load balancer that currently
+	// exists, keyed by its GetLoadBalancerName, as a cloud would store it.
+	// Services whose names collide therefore share a load balancer.
+	loadBalancers map[string]*v1.LoadBalancerStatus
+
+	// loadBalancerNames records the name of the load balancer last ensured
+	// for each service, keyed by namespace/name.
+	loadBalancerNames map[string]string
+
+	// ensuredNodes and updatedNodes record the names of the nodes passed to
+	// the last EnsureLoadBalancer and UpdateLoadBalancer call, keyed by
+	// namespace/name.
+	ensuredNodes map[string][]string
+	updatedNodes map[string][]string
+
+	// ensuredClusterNames records the cluster name passed to the last
+	// EnsureLoadBalancer call, keyed by namespace/name.
+	ensuredClusterNames map[string]string
+
+	// loadBalancerClass is the spec.loadBalancerClass this load balancer
+	// owns in addition to services without a class, if set.
+	loadBalancerClass string
+
+	// provisionDelay, if set, makes the ingress points of a new load
+	// balancer appear one at a time, hostnames first, provisionDelay apart.
+	provisionDelay time.Duration
+
+	// provisionedAt records when each load balancer was created, keyed by
+	// its GetLoadBalancerName.
+	provisionedAt map[string]time.Time
+
+	// EnsureLoadBalancerFunc overrides EnsureLoadBalancer, including its
+	// load balancer class and port checks. Only the cluster name is still
+	// recorded: the ensured service, its nodes and the load balancer itself
+	// are not, so HasLoadBalancer, GetLoadBalancer and GetEnsuredService do
+	// not see load balancers the hook creates.
+	EnsureLoadBalancerFunc func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error)
+
+	// UpdateLoadBalancerFunc overrides UpdateLoadBalancer, whose nodes are
+	// then not recorded for GetUpdatedNodes.
+	UpdateLoadBalancerFunc func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error
+
+	// EnsureLoadBalancerDeletedFunc overrides EnsureLoadBalancerDeleted, so
+	// load balancers created by EnsureLoadBalancer are left in place.
+	EnsureLoadBalancerDeletedFunc func(ctx context.Context, clusterName string, service *v1.Service) error
+
+	// GetLoadBalancerNameFunc overrides GetLoadBalancerName. The name is
+	// also the key under which load balancers are stored, so services the
+	// hook gives the same name share a load balancer.
+	GetLoadBalancerNameFunc func(ctx context.Context, clusterName string, service *v1.Service) string
+
+	// GetLoadBalancerFunc overrides GetLoadBalancer, which then no longer
+	// reports the stored load balancers or their provisioning delay.
+	GetLoadBalancerFunc func(ctx context.Context, clusterName string, service *v1.Service) (*v1.LoadBalancerStatus, bool, error)
 }
 
 // NewMockLoadBalancer creates a new mock load balancer interface.
 func NewMockLoadBalancer() *MockLoadBalancer {
-	return &MockLoadBalancer{}
+	return &MockLoadBalancer{
+		ensuredServices:     make(map[string]*v1.Service),
+		loadBalancers:       make(map[string]*v1.LoadBalancerStatus),
+		loadBalancerNames:   make(map[string]string),
+		provisionedAt:       make(map[string]time.Time),
+		ensuredNodes:        make(map[string][]string),
+		updatedNodes:        make(map[string][]string),
+		ensuredClusterNames: make(map[string]string),
+	}
+}
+
+// SetLoadBalancerClass sets the spec.loadBalancerClass the mock load balancer
+// owns. Services without a class are always owned.
+func (m *MockLoadBalancer) SetLoadBalancerClass(class string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loadBalancerClass = class
+}
+
+// SetProvisionDelay makes the mock provision new load balancers gradually, as
+// providers that allocate a hostname before an IP do: EnsureLoadBalancer and
+// GetLoadBalancer only report the hostname ingress point at first, and each
+// further ingress point another delay later. Zero reports all at once.
+func (m *MockLoadBalancer) SetProvisionDelay(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.provisionDelay = delay
+}
+
+// provisionedStatus returns the part of the status of the named load balancer
+// provisioned so far.
+func (m *MockLoadBalancer) provisionedStatus(name string, status *v1.LoadBalancerStatus) *v1.LoadBalancerStatus {
+	status = status.DeepCopy()
+	if m.provisionDelay <= 0 {
+		return status
+	}
+
+	sort.SliceStable(status.Ingress, func(i, j int) bool {
+		return status.Ingress[i].Hostname != "" && status.Ingress[j].Hostname == ""
+	})
+	if visible := 1 + int(time.Since(m.provisionedAt[name])/m.provisionDelay); visible < len(status.Ingress) {
+		status.Ingress = status.Ingress[:visible]
+	}
+	return status
+}
+
+// OwnsLoadBalancerClass reports whether the mock load balancer provisions
+// services with the given spec.loadBalancerClass.
+func (m *MockLoadBalancer) OwnsLoadBalancerClass(class *string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ownsLoadBalancerClass(class)
+}
+
+func (m *MockLoadBalancer) ownsLoadBalancerClass(class *string) bool {
+	return class == nil || (m.loadBalancerClass != "" && *class == m.loadBalancerClass)
+}
+
+// serviceKey returns the namespace/name key used to track a service.
+func serviceKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
 }
 
 // EnsureLoadBalancer creates a new load balancer 'name', or updates the existing one.
 func (m *MockLoadBalancer) EnsureLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+	key := serviceKey(service.Namespace, service.Name)
+
+	// The cluster name is recorded even when the call is overridden, since
+	// it is the caller that is checked
+	m.mu.Lock()
+	m.ensuredClusterNames[key] = clusterName
+	m.mu.Unlock()
+
+	if m.EnsureLoadBalancerFunc != nil {
+		return m.EnsureLoadBalancerFunc(ctx, clusterName, service, nodes)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Return mock load balancer status
+	if !m.ownsLoadBalancerClass(service.Spec.LoadBalancerClass) {
+		return nil, fmt.Errorf("service %s/%s has loadBalancerClass %q, which this load balancer does not own", service.Namespace, service.Name, *service.Spec.LoadBalancerClass)
+	}
+	// Like a real provider, refuse to create a load balancer with no listeners
+	if len(service.Spec.Ports) == 0 {
+		return nil, fmt.Errorf("service %s/%s has no ports to load balance", service.Namespace, service.Name)
+	}
+
+	m.ensuredServices[key] = service.DeepCopy()
+	m.ensuredNodes[key] = nodeNames(nodes)
+
+	// Return mock load balancer status, with every service port ready. A
+	// requested spec.loadBalancerIP is honored.
+	ip := "192.168.1.100"
+	if service.Spec.LoadBalancerIP != "" {
+		ip = service.Spec.LoadBalancerIP
+	}
 	status := &v1.LoadBalancerStatus{
 		Ingress: []v1.LoadBalancerIngress{
-			{IP: "192.168.1.100"},
-			{Hostname: "mock-lb.example.com"},
+			{IP: ip, Ports: portStatuses(service)},
+			{Hostname: "mock-lb.example.com", Ports: portStatuses(service)},
 		},
 	}
+	name := m.GetLoadBalancerName(ctx, clusterName, service)
+	if _, exists := m.loadBalancers[name]; !exists {
+		m.provisionedAt[name] = time.Now()
+	}
+	m.loadBalancers[name] = status.DeepCopy()
+	m.loadBalancerNames[key] = name
 
-	return status, nil
+	return m.provisionedStatus(name, status), nil
 }
 
 // UpdateLoadBalancer updates hosts under the specified load balancer.
 func (m *MockLoadBalancer) UpdateLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error {
+	if m.UpdateLoadBalancerFunc != nil {
+		return m.UpdateLoadBalancerFunc(ctx, clusterName, service, nodes)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.updatedNodes[serviceKey(service.Namespace, service.Name)] = nodeNames(nodes)
 	return nil
 }
 
+// portStatuses returns a PortStatus without an error for every port of
+// service. Ports without a protocol default to TCP, as in the API server.
+func portStatuses(service *v1.Service) []v1.PortStatus {
+	if len(service.Spec.Ports) == 0 {
+		return nil
+	}
+
+	statuses := make([]v1.PortStatus, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = v1.ProtocolTCP
+		}
+		statuses = append(statuses, v1.PortStatus{Port: port.Port, Protocol: protocol})
+	}
+	return statuses
+}
+
+// nodeNames returns the names of nodes, sorted.
+func nodeNames(nodes []*v1.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // EnsureLoadBalancerDeleted deletes the specified load balancer if it exists.
+// Deleting a load balancer that does not exist succeeds, as with real
+// providers.
 func (m *MockLoadBalancer) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *v1.Service) error {
+	if m.EnsureLoadBalancerDeletedFunc != nil {
+		return m.EnsureLoadBalancerDeletedFunc(ctx, clusterName, service)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := m.GetLoadBalancerName(ctx, clusterName, service)
+	delete(m.loadBalancers, name)
+	delete(m.provisionedAt, name)
+	delete(m.loadBalancerNames, serviceKey(service.Namespace, service.Name))
 	return nil
 }
 
 // GetLoadBalancerName returns the name of the load balancer.
 func (m *MockLoadBalancer) GetLoadBalancerName(ctx context.Context, clusterName string, service *v1.Service) string {
-	return fmt.Sprintf("mock-lb-%s", service.Name)
+	if m.GetLoadBalancerNameFunc != nil {
+		return m.GetLoadBalancerNameFunc(ctx, clusterName, service)
+	}
+	return fmt.Sprintf("mock-lb-%s-%s", service.Namespace, service.Name)
 }
 
 // GetLoadBalancer returns whether the specified load balancer exists, and if so, what its status is.
 func (m *MockLoadBalancer) GetLoadBalancer(ctx context.Context, clusterName string, service *v1.Service) (*v1.LoadBalancerStatus, bool, error) {
-	status := &v1.LoadBalancerStatus{
-		Ingress: []v1.LoadBalancerIngress{
-			{IP: "192.168.1.100"},
-		},
+	if m.GetLoadBalancerFunc != nil {
+		return m.GetLoadBalancerFunc(ctx, clusterName, service)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name := m.GetLoadBalancerName(ctx, clusterName, service)
+	status, ok := m.loadBalancers[name]
+	if !ok {
+		return nil, false, nil
+	}
+	return m.provisionedStatus(name, status), true, nil
+}
+
+// HasLoadBalancer returns whether a load balancer currently exists for the
+// given namespace and name.
+func (m *MockLoadBalancer) HasLoadBalancer(namespace, name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	loadBalancerName, ok := m.loadBalancerNames[serviceKey(namespace, name)]
+	if !ok {
+		return false
+	}
+	_, ok = m.loadBalancers[loadBalancerName]
+	return ok
+}
+
+// LoadBalancerCount returns the number of load balancers that currently exist.
+func (m *MockLoadBalancer) LoadBalancerCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.loadBalancers)
+}
+
+// GetEnsuredService returns the last service passed to EnsureLoadBalancer for
+// the given namespace and name.
+func (m *MockLoadBalancer) GetEnsuredService(namespace, name string) (*v1.Service, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	service, ok := m.ensuredServices[serviceKey(namespace, name)]
+	if !ok {
+		return nil, false
+	}
+	return service.DeepCopy(), true
+}
+
+// GetEnsuredNodes returns the sorted names of the nodes passed to the last
+// EnsureLoadBalancer call for the given namespace and name.
+func (m *MockLoadBalancer) GetEnsuredNodes(namespace, name string) ([]string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes, ok := m.ensuredNodes[serviceKey(namespace, name)]
+	return append([]string(nil), nodes...), ok
+}
+
+// GetEnsuredClusterName returns the cluster name passed to the last
+// EnsureLoadBalancer call for the given namespace and name.
+func (m *MockLoadBalancer) GetEnsuredClusterName(namespace, name string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clusterName, ok := m.ensuredClusterNames[serviceKey(namespace, name)]
+	return clusterName, ok
+}
+
+// GetUpdatedNodes returns the sorted names of the nodes passed to the last
+// UpdateLoadBalancer call for the given namespace and name.
+func (m *MockLoadBalancer) GetUpdatedNodes(namespace, name string) ([]string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes, ok := m.updatedNodes[serviceKey(namespace, name)]
+	return append([]string(nil), nodes...), ok
+}
+
+// GetEnsuredNodePorts returns the NodePorts of the last service passed to
+// EnsureLoadBalancer for the given namespace and name, in port order.
+func (m *MockLoadBalancer) GetEnsuredNodePorts(namespace, name string) ([]int32, bool) {
+	service, ok := m.GetEnsuredService(namespace, name)
+	if !ok {
+		return nil, false
+	}
+
+	nodePorts := make([]int32, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		nodePorts = append(nodePorts, port.NodePort)
+	}
+	return nodePorts, true
+}
+
+// GetEnsuredProtocols returns the port protocols of the last service passed to
+// EnsureLoadBalancer for the given namespace and name, in port order.
+func (m *MockLoadBalancer) GetEnsuredProtocols(namespace, name string) ([]v1.Protocol, bool) {
+	service, ok := m.GetEnsuredService(namespace, name)
+	if !ok {
+		return nil, false
+	}
+
+	protocols := make([]v1.Protocol, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		protocols = append(protocols, port.Protocol)
+	}
+	return protocols, true
+}
+
+// UsesNodePorts returns whether the load balancer ensured for the given
+// namespace and name forwards to NodePorts, which it does unless the service
+// sets allocateLoadBalancerNodePorts to false to target pods directly.
+func (m *MockLoadBalancer) UsesNodePorts(namespace, name string) (bool, bool) {
+	service, ok := m.GetEnsuredService(namespace, name)
+	if !ok {
+		return false, false
 	}
-	return status, true, nil
+
+	allocate := service.Spec.AllocateLoadBalancerNodePorts
+	return allocate == nil || *allocate, true
 }
 
-// MockRoutes implements the cloudprovider.Routes interface.
+// MockRoutes implements the cloudprovider.Routes interface. Like a route
+// table, it holds one created route per destination CIDR, listed alongside a
+// fixed mock route until deleted.
 type MockRoutes struct {
-	mu sync.RWMutex
+	mu     sync.RWMutex
+	nodes  *nodeStore
+	routes map[string]*cloudprovider.Route // by destination CIDR
+
+	// ListRoutesFunc overrides ListRoutes, so created routes are no longer
+	// listed unless the hook lists them.
+	ListRoutesFunc func(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error)
+
+	// CreateRouteFunc overrides CreateRoute. The route is not stored, so it
+	// is not listed, and routes to removed instances are not rejected.
+	CreateRouteFunc func(ctx context.Context, clusterName string, nameHint string, route *cloudprovider.Route) error
+
+	// DeleteRouteFunc overrides DeleteRoute, so stored routes are left in
+	// place.
+	DeleteRouteFunc func(ctx context.Context, clusterName string, route *cloudprovider.Route) error
 }
 
-// NewMockRoutes creates a new mock routes interface.
+// NewMockRoutes creates a new mock routes interface with a node store of its
+// own.
 func NewMockRoutes() *MockRoutes {
-	return &MockRoutes{}
+	return newMockRoutes(newNodeStore())
+}
+
+func newMockRoutes(nodes *nodeStore) *MockRoutes {
+	return &MockRoutes{
+		nodes:  nodes,
+		routes: make(map[string]*cloudprovider.Route),
+	}
 }
 
 // ListRoutes lists all managed routes that belong to the specified clusterName.
 func (m *MockRoutes) ListRoutes(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error) {
+	if m.ListRoutesFunc != nil {
+		return m.ListRoutesFunc(ctx, clusterName)
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return []*cloudprovider.Route{
+	routes := []*cloudprovider.Route{
 		{
 			Name:            "mock-route-1",
 			TargetNode:      "mock-node-1",
 			DestinationCIDR: "10.0.0.0/24",
 		},
-	}, nil
+	}
+	created := make([]*cloudprovider.Route, 0, len(m.routes))
+	for _, route := range m.routes {
+		route := *route
+		created = append(created, &route)
+	}
+	sort.Slice(created, func(i, j int) bool { return created[i].Name < created[j].Name })
+	return append(routes, created...), nil
 }
 
-// CreateRoute creates the described managed route.
+// CreateRoute creates the described managed route, named after nameHint
+// unless the route is already named. Routes to a registered node whose
+// instance no longer exists are rejected.
 func (m *MockRoutes) CreateRoute(ctx context.Context, clusterName string, nameHint string, route *cloudprovider.Route) error {
+	if m.CreateRouteFunc != nil {
+		return m.CreateRouteFunc(ctx, clusterName, nameHint, route)
+	}
+
+	if data, ok := m.nodes.get(route.TargetNode); ok && !data.Exists {
+		return fmt.Errorf("route target %s: %w", route.TargetNode, cloudprovider.InstanceNotFound)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	created := *route
+	if created.Name == "" {
+		created.Name = nameHint
+	}
+	m.routes[created.DestinationCIDR] = &created
 	return nil
 }
 
 // DeleteRoute deletes the specified managed route.
 func (m *MockRoutes) DeleteRoute(ctx context.Context, clusterName string, route *cloudprovider.Route) error {
+	if m.DeleteRouteFunc != nil {
+		return m.DeleteRouteFunc(ctx, clusterName, route)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	delete(m.routes, route.DestinationCIDR)
 	return nil
 }
 
 // MockClusters implements the cloudprovider.Clusters interface.
 type MockClusters struct {
+	// ListClustersFunc overrides ListClusters, which otherwise returns two
+	// fixed mock clusters.
+	ListClustersFunc func(ctx context.Context) ([]string, error)
+
+	// MasterFunc overrides Master, which otherwise returns a fixed mock
+	// address.
+	MasterFunc func(ctx context.Context, clusterName string) (string, error)
 }
 
 // NewMockClusters creates a new mock clusters interface.
@@ -299,11 +1263,17 @@ func NewMockClusters() *MockClusters {
 
 // ListClusters lists the names of the available clusters.
 func (m *MockClusters) ListClusters(ctx context.Context) ([]string, error) {
+	if m.ListClustersFunc != nil {
+		return m.ListClustersFunc(ctx)
+	}
 	return []string{"mock-cluster-1", "mock-cluster-2"}, nil
 }
 
 // Master gets back the address (either DNS name or IP address) of the master node for the cluster.
 func (m *MockClusters) Master(ctx context.Context, clusterName string) (string, error) {
+	if m.MasterFunc != nil {
+		return m.MasterFunc(ctx, clusterName)
+	}
 	return "mock-master.example.com", nil
 }
 
@@ -0,0 +1,500 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// TestMockLoadBalancerCapturesNodePorts tests that the mock load balancer records
+// the NodePorts of the ensured service for every port
+func TestMockLoadBalancerCapturesNodePorts(t *testing.T) {
+	provider := NewMockCloudProvider()
+	lb, _ := provider.LoadBalancer()
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "multi-port", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, NodePort: 30080},
+				{Name: "https", Port: 443, NodePort: 30443},
+				{Name: "metrics", Port: 9090, NodePort: 30090},
+			},
+		},
+	}
+
+	if _, err := lb.EnsureLoadBalancer(context.Background(), "test-cluster", service, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	nodePorts, found := provider.GetMockLoadBalancer().GetEnsuredNodePorts("default", "multi-port")
+	if !found {
+		t.Fatal("Expected ensured service to be recorded")
+	}
+
+	expected := []int32{30080, 30443, 30090}
+	if len(nodePorts) != len(expected) {
+		t.Fatalf("Expected %d node ports, got %d", len(expected), len(nodePorts))
+	}
+	for i := range expected {
+		if nodePorts[i] != expected[i] {
+			t.Errorf("Expected node port %d at index %d, got %d", expected[i], i, nodePorts[i])
+		}
+	}
+
+	// Mutating the caller's service must not change the recorded copy
+	service.Spec.Ports[0].NodePort = 31000
+	nodePorts, _ = provider.GetMockLoadBalancer().GetEnsuredNodePorts("default", "multi-port")
+	if nodePorts[0] != 30080 {
+		t.Errorf("Expected recorded node port to remain 30080, got %d", nodePorts[0])
+	}
+
+	if _, found := provider.GetMockLoadBalancer().GetEnsuredNodePorts("default", "unknown"); found {
+		t.Error("Expected no record for a service that was never ensured")
+	}
+}
+
+// TestMockLoadBalancerTracksExistence tests that GetLoadBalancer reflects
+// EnsureLoadBalancer and EnsureLoadBalancerDeleted calls
+func TestMockLoadBalancerTracksExistence(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockCloudProvider()
+	lb, _ := provider.LoadBalancer()
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "tracked", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer, Ports: []v1.ServicePort{{Port: 80}}},
+	}
+
+	if _, exists, _ := lb.GetLoadBalancer(ctx, "test-cluster", service); exists {
+		t.Error("Expected no load balancer before EnsureLoadBalancer")
+	}
+
+	if _, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	status, exists, err := lb.GetLoadBalancer(ctx, "test-cluster", service)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !exists || status == nil || len(status.Ingress) == 0 {
+		t.Error("Expected load balancer with ingress after EnsureLoadBalancer")
+	}
+
+	if err := lb.EnsureLoadBalancerDeleted(ctx, "test-cluster", service); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if provider.GetMockLoadBalancer().HasLoadBalancer("default", "tracked") {
+		t.Error("Expected load balancer to be removed after EnsureLoadBalancerDeleted")
+	}
+}
+
+// TestMockRoutesTracksCreatedRoutes tests that created routes are listed,
+// named after the name hint, until deleted
+func TestMockRoutesTracksCreatedRoutes(t *testing.T) {
+	ctx := context.Background()
+	routes, _ := NewMockCloudProvider().Routes()
+
+	route := &cloudprovider.Route{TargetNode: "node-1", DestinationCIDR: "10.244.1.0/24"}
+	if err := routes.CreateRoute(ctx, "test-cluster", "node-1", route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	routeList, err := routes.ListRoutes(ctx, "test-cluster")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(routeList) != 2 || routeList[1].Name != "node-1" || routeList[1].DestinationCIDR != "10.244.1.0/24" {
+		t.Fatalf("Expected the created route after the fixed one, got %+v", routeList)
+	}
+
+	if err := routes.DeleteRoute(ctx, "test-cluster", routeList[1]); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if routeList, _ := routes.ListRoutes(ctx, "test-cluster"); len(routeList) != 1 {
+		t.Errorf("Expected only the fixed route after deletion, got %+v", routeList)
+	}
+}
+
+// TestMockLoadBalancerProvisionDelay tests that a gradually provisioned load
+// balancer reports its hostname first and its IP a delay later
+func TestMockLoadBalancerProvisionDelay(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockCloudProvider()
+	provider.GetMockLoadBalancer().SetProvisionDelay(100 * time.Millisecond)
+	lb, _ := provider.LoadBalancer()
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "staged", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer, Ports: []v1.ServicePort{{Port: 80}}},
+	}
+
+	ensured, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ensured.Ingress) != 1 || ensured.Ingress[0].Hostname != "mock-lb.example.com" {
+		t.Errorf("Expected EnsureLoadBalancer to report only the hostname, got %v", ensured.Ingress)
+	}
+
+	partial, _, err := lb.GetLoadBalancer(ctx, "test-cluster", service)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(partial.Ingress) != 1 || partial.Ingress[0].Hostname != "mock-lb.example.com" {
+		t.Errorf("Expected the first GetLoadBalancer to report only the hostname, got %v", partial.Ingress)
+	}
+
+	// Ensuring the load balancer again does not restart its provisioning
+	time.Sleep(150 * time.Millisecond)
+	if _, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	complete, _, err := lb.GetLoadBalancer(ctx, "test-cluster", service)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(complete.Ingress) != 2 || complete.Ingress[1].IP != "192.168.1.100" {
+		t.Errorf("Expected a later GetLoadBalancer to add the IP, got %v", complete.Ingress)
+	}
+}
+
+// TestMockLoadBalancerRecordsNodes tests that the nodes passed to ensures and
+// updates are recorded separately per service
+func TestMockLoadBalancerRecordsNodes(t *testing.T) {
+	ctx := context.Background()
+	mockLB := NewMockLoadBalancer()
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "members", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 80}}},
+	}
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+	}
+
+	if _, ok := mockLB.GetUpdatedNodes("default", "members"); ok {
+		t.Error("Expected no updated nodes before UpdateLoadBalancer")
+	}
+
+	if _, err := mockLB.EnsureLoadBalancer(ctx, "test-cluster", service, nodes); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mockLB.UpdateLoadBalancer(ctx, "test-cluster", service, nodes[1:]); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ensured, ok := mockLB.GetEnsuredNodes("default", "members")
+	if !ok || strings.Join(ensured, ",") != "node-a,node-b" {
+		t.Errorf("Expected ensured nodes [node-a node-b], got %v", ensured)
+	}
+	updated, ok := mockLB.GetUpdatedNodes("default", "members")
+	if !ok || strings.Join(updated, ",") != "node-a" {
+		t.Errorf("Expected updated nodes [node-a], got %v", updated)
+	}
+}
+
+// TestMockInstancesSetInstanceExists tests that instances removed with
+// SetInstanceExists are reported as gone until they are restored
+func TestMockInstancesSetInstanceExists(t *testing.T) {
+	ctx := context.Background()
+	instances := NewMockInstances()
+
+	exists, err := instances.InstanceExistsByProviderID(ctx, "test-provider://node-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !exists {
+		t.Error("Expected instance to exist by default")
+	}
+
+	instances.SetInstanceExists("test-provider://node-1", false)
+	if exists, _ := instances.InstanceExistsByProviderID(ctx, "test-provider://node-1"); exists {
+		t.Error("Expected removed instance not to exist")
+	}
+	if exists, _ := instances.InstanceExistsByProviderID(ctx, "test-provider://node-2"); !exists {
+		t.Error("Expected other instances to still exist")
+	}
+
+	instances.SetInstanceExists("test-provider://node-1", true)
+	if exists, _ := instances.InstanceExistsByProviderID(ctx, "test-provider://node-1"); !exists {
+		t.Error("Expected restored instance to exist")
+	}
+}
+
+// TestMockInstancesExistenceChecksNotImplemented tests that the existence and
+// shutdown checks return NotImplemented when configured to
+func TestMockInstancesExistenceChecksNotImplemented(t *testing.T) {
+	ctx := context.Background()
+	instances := NewMockInstances()
+	instances.SetExistenceChecksNotImplemented(true)
+
+	if _, err := instances.InstanceExistsByProviderID(ctx, "test-provider://node-1"); !errors.Is(err, cloudprovider.NotImplemented) {
+		t.Errorf("Expected NotImplemented from InstanceExistsByProviderID, got %v", err)
+	}
+	if _, err := instances.InstanceShutdownByProviderID(ctx, "test-provider://node-1"); !errors.Is(err, cloudprovider.NotImplemented) {
+		t.Errorf("Expected NotImplemented from InstanceShutdownByProviderID, got %v", err)
+	}
+
+	instances.SetExistenceChecksNotImplemented(false)
+	if exists, err := instances.InstanceExistsByProviderID(ctx, "test-provider://node-1"); err != nil || !exists {
+		t.Errorf("Expected instance to exist once implemented, got %t, %v", exists, err)
+	}
+}
+
+// TestMockCloudProviderRegisterNode tests that the instances and zones report
+// consistent data for a registered node, by name and by provider ID
+func TestMockCloudProviderRegisterNode(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockCloudProvider()
+	provider.RegisterNode("node-1", NodeData{
+		ProviderID:   "mock-provider://i-0123",
+		InstanceType: "m5.large",
+		Addresses:    []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.7"}},
+		Zone:         "zone-b",
+		Region:       "region-1",
+		Exists:       true,
+		Hostname:     "ip-10-0-0-7",
+	})
+	instances, _ := provider.Instances()
+	zones, _ := provider.Zones()
+
+	expectedAddresses := []v1.NodeAddress{
+		{Type: v1.NodeInternalIP, Address: "10.0.0.7"},
+		{Type: v1.NodeHostName, Address: "ip-10-0-0-7"},
+	}
+	byName, err := instances.NodeAddresses(ctx, "node-1")
+	if err != nil || !slices.Equal(byName, expectedAddresses) {
+		t.Errorf("Expected addresses %v by name, got %v, %v", expectedAddresses, byName, err)
+	}
+	byProviderID, err := instances.NodeAddressesByProviderID(ctx, "mock-provider://i-0123")
+	if err != nil || !slices.Equal(byProviderID, expectedAddresses) {
+		t.Errorf("Expected addresses %v by provider ID, got %v, %v", expectedAddresses, byProviderID, err)
+	}
+
+	if id, err := instances.InstanceID(ctx, "node-1"); err != nil || id != "mock-provider://i-0123" {
+		t.Errorf("Expected instance ID 'mock-provider://i-0123', got '%s', %v", id, err)
+	}
+	if instanceType, _ := instances.InstanceType(ctx, "node-1"); instanceType != "m5.large" {
+		t.Errorf("Expected instance type 'm5.large' by name, got '%s'", instanceType)
+	}
+	if instanceType, _ := instances.InstanceTypeByProviderID(ctx, "mock-provider://i-0123"); instanceType != "m5.large" {
+		t.Errorf("Expected instance type 'm5.large' by provider ID, got '%s'", instanceType)
+	}
+	if name, _ := instances.CurrentNodeName(ctx, "ip-10-0-0-7"); name != "node-1" {
+		t.Errorf("Expected hostname to resolve to node-1, got '%s'", name)
+	}
+
+	expectedZone := cloudprovider.Zone{FailureDomain: "zone-b", Region: "region-1"}
+	if zone, _ := zones.GetZoneByNodeName(ctx, "node-1"); zone != expectedZone {
+		t.Errorf("Expected zone %+v by name, got %+v", expectedZone, zone)
+	}
+	if zone, _ := zones.GetZoneByProviderID(ctx, "mock-provider://i-0123"); zone != expectedZone {
+		t.Errorf("Expected zone %+v by provider ID, got %+v", expectedZone, zone)
+	}
+
+	provider.GetMockInstances().SetInstanceExists("mock-provider://i-0123", false)
+	if exists, _ := instances.InstanceExistsByProviderID(ctx, "mock-provider://i-0123"); exists {
+		t.Error("Expected the removed instance not to exist")
+	}
+	if _, err := instances.NodeAddresses(ctx, "node-1"); !errors.Is(err, cloudprovider.InstanceNotFound) {
+		t.Errorf("Expected InstanceNotFound for the addresses of a removed instance, got %v", err)
+	}
+	if data, _ := provider.GetNodeData("node-1"); data.Exists {
+		t.Error("Expected the registered data to record the removal")
+	}
+}
+
+// TestMockCloudProviderNodeStoreZoneUpdate tests that a zone change in the
+// node store is seen by the zones and by the zone label checks alike
+func TestMockCloudProviderNodeStoreZoneUpdate(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockCloudProvider()
+	provider.RegisterNode("node-1", NodeData{ProviderID: "mock-provider://node-1", Zone: "zone-a", Region: "region-1", Exists: true})
+	zones, _ := provider.Zones()
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{v1.LabelTopologyZone: "zone-a", v1.LabelTopologyRegion: "region-1"},
+		},
+		Spec: v1.NodeSpec{ProviderID: "mock-provider://node-1"},
+	}
+	if err := checkNodeZoneConsistency(ctx, zones, node); err != nil {
+		t.Fatalf("Expected the labels to match the registered zone, got %v", err)
+	}
+
+	data, _ := provider.GetNodeData("node-1")
+	data.Zone = "zone-b"
+	provider.RegisterNode("node-1", data)
+
+	if zone, _ := zones.GetZoneByProviderID(ctx, "mock-provider://node-1"); zone.FailureDomain != "zone-b" {
+		t.Errorf("Expected GetZoneByProviderID to report zone-b, got %s", zone.FailureDomain)
+	}
+	if zone, _ := zones.GetZoneByNodeName(ctx, "node-1"); zone.FailureDomain != "zone-b" {
+		t.Errorf("Expected GetZoneByNodeName to report zone-b, got %s", zone.FailureDomain)
+	}
+	if err := checkNodeZoneConsistency(ctx, zones, node); err == nil || !strings.Contains(err.Error(), `"zone-b"`) {
+		t.Errorf("Expected the stale zone label to be reported against zone-b, got %v", err)
+	}
+}
+
+// TestMockRoutesRejectRemovedInstance tests that routes to a registered node
+// are rejected once its instance no longer exists
+func TestMockRoutesRejectRemovedInstance(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockCloudProvider()
+	provider.RegisterNode("node-1", NodeData{ProviderID: "mock-provider://node-1", Exists: true})
+	routes, _ := provider.Routes()
+
+	route := &cloudprovider.Route{TargetNode: "node-1", DestinationCIDR: "10.244.1.0/24"}
+	if err := routes.CreateRoute(ctx, "test-cluster", "node-1", route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	provider.GetMockInstances().SetInstanceExists("mock-provider://node-1", false)
+	route = &cloudprovider.Route{TargetNode: "node-1", DestinationCIDR: "10.244.2.0/24"}
+	if err := routes.CreateRoute(ctx, "test-cluster", "node-1", route); !errors.Is(err, cloudprovider.InstanceNotFound) {
+		t.Errorf("Expected InstanceNotFound for a route to a removed instance, got %v", err)
+	}
+}
+
+// TestMockCloudProviderSnapshotRestore tests that restoring a snapshot brings
+// back a deleted load balancer and undoes node and route changes, and that a
+// snapshot can be restored more than once
+func TestMockCloudProviderSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockCloudProvider()
+	lb, _ := provider.LoadBalancer()
+	routes, _ := provider.Routes()
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "baseline", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer, Ports: []v1.ServicePort{{Port: 80, NodePort: 30080}}},
+	}
+	status, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	provider.RegisterNode("baseline-node", NodeData{ProviderID: "mock://baseline-node", Exists: true})
+	route := &cloudprovider.Route{TargetNode: "baseline-node", DestinationCIDR: "10.1.0.0/24"}
+	if err := routes.CreateRoute(ctx, "test-cluster", "baseline-route", route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	snapshot := provider.Snapshot()
+
+	for i := 0; i < 2; i++ {
+		if err := lb.EnsureLoadBalancerDeleted(ctx, "test-cluster", service); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := routes.DeleteRoute(ctx, "test-cluster", route); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		provider.GetMockInstances().SetInstanceExists("mock://baseline-node", false)
+		provider.RegisterNode("extra-node", NodeData{Exists: true})
+		if provider.GetMockLoadBalancer().HasLoadBalancer("default", "baseline") {
+			t.Fatal("Expected the load balancer to be deleted")
+		}
+
+		provider.Restore(snapshot)
+
+		restored, exists, err := lb.GetLoadBalancer(ctx, "test-cluster", service)
+		if err != nil || !exists {
+			t.Fatalf("Expected the load balancer to reappear after restore %d, got exists %v, error %v", i+1, exists, err)
+		}
+		if restored.Ingress[0].IP != status.Ingress[0].IP {
+			t.Errorf("Expected restored ingress IP %s, got %s", status.Ingress[0].IP, restored.Ingress[0].IP)
+		}
+		if nodePorts, _ := provider.GetMockLoadBalancer().GetEnsuredNodePorts("default", "baseline"); !slices.Equal(nodePorts, []int32{30080}) {
+			t.Errorf("Expected restored node ports [30080], got %v", nodePorts)
+		}
+		if data, _ := provider.GetNodeData("baseline-node"); !data.Exists {
+			t.Error("Expected the baseline node to exist again")
+		}
+		if _, found := provider.GetNodeData("extra-node"); found {
+			t.Error("Expected the node registered after the snapshot to be gone")
+		}
+		listed, _ := routes.ListRoutes(ctx, "test-cluster")
+		if !slices.ContainsFunc(listed, func(r *cloudprovider.Route) bool { return r.Name == "baseline-route" }) {
+			t.Errorf("Expected the baseline route to be listed again, got %d routes", len(listed))
+		}
+	}
+}
+
+// TestMockCloudProviderClone tests that a clone keeps the configuration and
+// hooks of the provider but none of its load balancers or routes
+func TestMockCloudProviderClone(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMockCloudProvider()
+	provider.RegisterNode("node-1", NodeData{ProviderID: "mock://node-1", Exists: true})
+	provider.GetMockLoadBalancer().SetLoadBalancerClass("example.com/lb")
+	provider.GetMockZones().GetZoneFunc = func(ctx context.Context) (cloudprovider.Zone, error) {
+		return cloudprovider.Zone{FailureDomain: "hooked-zone"}, nil
+	}
+
+	lb, _ := provider.LoadBalancer()
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "original", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer, Ports: []v1.ServicePort{{Port: 80}}},
+	}
+	if _, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	clone := provider.Clone()
+
+	if data, found := clone.GetNodeData("node-1"); !found || data.ProviderID != "mock://node-1" {
+		t.Errorf("Expected the registered node to be cloned, got %+v, found %v", data, found)
+	}
+	class := "example.com/lb"
+	if !clone.GetMockLoadBalancer().OwnsLoadBalancerClass(&class) {
+		t.Errorf("Expected the clone to own load balancer class %s", class)
+	}
+	zones, _ := clone.Zones()
+	if zone, err := zones.GetZone(ctx); err != nil || zone.FailureDomain != "hooked-zone" {
+		t.Errorf("Expected the zone hook to be cloned, got %+v, %v", zone, err)
+	}
+	if count := clone.GetMockLoadBalancer().LoadBalancerCount(); count != 0 {
+		t.Errorf("Expected no load balancers in the clone, got %d", count)
+	}
+
+	// The clone's load balancers are its own
+	cloneLB, _ := clone.LoadBalancer()
+	if _, err := cloneLB.EnsureLoadBalancer(ctx, "test-cluster", service, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := lb.EnsureLoadBalancerDeleted(ctx, "test-cluster", service); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !clone.GetMockLoadBalancer().HasLoadBalancer("default", "original") {
+		t.Error("Expected the clone's load balancer to survive deletion from the original")
+	}
+}
@@ -0,0 +1,390 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	cloudprovider "k8s.io/cloud-provider"
+	servicehelper "k8s.io/cloud-provider/service/helpers"
+	"k8s.io/klog/v2"
+)
+
+// maxReconcileRetries is how often MockReconciler retries a failing service
+// before dropping it until its next change.
+const maxReconcileRetries = 5
+
+// MockLoadBalancerIDAnnotation is the annotation MockReconciler records the
+// name of a service's load balancer under, as CCMs record the ID of the cloud
+// resource backing a service.
+const MockLoadBalancerIDAnnotation = "mock-cloud-provider.k8s.io/load-balancer-id"
+
+// reconcileItem is a queued unit of work: a full sync of the service with
+// the given key, or only an update of its load balancer hosts.
+type reconcileItem struct {
+	service   string
+	hostsOnly bool
+}
+
+// MockReconciler simulates the service controller of a running CCM against a
+// clientset without one, such as the fake clientset of CCMTestInterface. It
+// watches services and nodes and drives the cloud provider like the CCM
+// would: it ensures the load balancer of LoadBalancer services and writes its
+// status back to the service, updates the load balancer hosts when nodes
+// change and deletes the load balancer when the service is deleted or no
+// longer of type LoadBalancer. Like the CCM, it adds the load balancer cleanup
+// finalizer to a service before ensuring its load balancer and only removes it
+// once the load balancer is deleted, so that a deleted service outlives its
+// load balancer. Tests can then create services through the
+// clientset and wait for their status, exercising the full path from the
+// event to the provider call to the published status.
+type MockReconciler struct {
+	kubeClient    kubernetes.Interface
+	cloudProvider cloudprovider.Interface
+	clusterName   string
+
+	serviceLister corelisters.ServiceLister
+	nodeLister    corelisters.NodeLister
+	queue         workqueue.TypedRateLimitingInterface[reconcileItem]
+
+	// Services with an ensured load balancer by key, kept to delete the
+	// load balancer once the service is gone
+	mu       sync.Mutex
+	services map[string]*v1.Service
+}
+
+// NewMockReconciler creates a reconciler driving cloudProvider from the
+// services and nodes of kubeClient.
+func NewMockReconciler(kubeClient kubernetes.Interface, cloudProvider cloudprovider.Interface, clusterName string) *MockReconciler {
+	return &MockReconciler{
+		kubeClient:    kubeClient,
+		cloudProvider: cloudProvider,
+		clusterName:   clusterName,
+		services:      make(map[string]*v1.Service),
+	}
+}
+
+// Start begins watching services and nodes and reconciles them in the
+// background until ctx is done. It returns once the watches have synced, or
+// an error if they do not sync before ctx is done.
+func (r *MockReconciler) Start(ctx context.Context) error {
+	lb, supported := r.cloudProvider.LoadBalancer()
+	if !supported {
+		return fmt.Errorf("cloud provider does not support load balancers")
+	}
+
+	factory := informers.NewSharedInformerFactory(r.kubeClient, 0)
+	serviceInformer := factory.Core().V1().Services()
+	nodeInformer := factory.Core().V1().Nodes()
+	r.serviceLister = serviceInformer.Lister()
+	r.nodeLister = nodeInformer.Lister()
+	r.queue = workqueue.NewTypedRateLimitingQueueWithConfig(
+		workqueue.DefaultTypedControllerRateLimiter[reconcileItem](),
+		workqueue.TypedRateLimitingQueueConfig[reconcileItem]{Name: "mock-reconciler"},
+	)
+
+	if _, err := serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    r.enqueueService,
+		UpdateFunc: func(_, obj interface{}) { r.enqueueService(obj) },
+		DeleteFunc: r.enqueueService,
+	}); err != nil {
+		return fmt.Errorf("failed to watch services: %w", err)
+	}
+	if _, err := nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { r.enqueueHostUpdates() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if nodeChangedForLoadBalancers(oldObj.(*v1.Node), newObj.(*v1.Node)) {
+				r.enqueueHostUpdates()
+			}
+		},
+		DeleteFunc: func(interface{}) { r.enqueueHostUpdates() },
+	}); err != nil {
+		return fmt.Errorf("failed to watch nodes: %w", err)
+	}
+
+	factory.Start(ctx.Done())
+	for informerType, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			r.queue.ShutDown()
+			return fmt.Errorf("failed to sync %s informer", informerType)
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		r.queue.ShutDown()
+	}()
+	go func() {
+		for r.processNextItem(ctx, lb) {
+		}
+	}()
+
+	klog.Info("Mock reconciler started")
+	return nil
+}
+
+func (r *MockReconciler) enqueueService(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("Failed to get key of service: %v", err)
+		return
+	}
+	r.queue.Add(reconcileItem{service: key})
+}
+
+// enqueueHostUpdates queues a host update for every service with a load
+// balancer, as the node sync of the service controller does.
+func (r *MockReconciler) enqueueHostUpdates() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.services {
+		r.queue.Add(reconcileItem{service: key, hostsOnly: true})
+	}
+}
+
+// nodeChangedForLoadBalancers reports whether an update changed a node in a
+// way that affects the load balancers it is a host of, ignoring heartbeats.
+func nodeChangedForLoadBalancers(oldNode, newNode *v1.Node) bool {
+	if !labels.Equals(oldNode.Labels, newNode.Labels) || oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable {
+		return true
+	}
+	return nodeReadyStatus(oldNode) != nodeReadyStatus(newNode)
+}
+
+// nodeReadyStatus returns the status of the node's Ready condition.
+func nodeReadyStatus(node *v1.Node) v1.ConditionStatus {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status
+		}
+	}
+	return v1.ConditionUnknown
+}
+
+func (r *MockReconciler) processNextItem(ctx context.Context, lb cloudprovider.LoadBalancer) bool {
+	item, shutdown := r.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer r.queue.Done(item)
+
+	var err error
+	if item.hostsOnly {
+		err = r.updateHosts(ctx, lb, item.service)
+	} else {
+		err = r.syncService(ctx, lb, item.service)
+	}
+
+	switch {
+	case err == nil:
+		r.queue.Forget(item)
+	case r.queue.NumRequeues(item) < maxReconcileRetries:
+		klog.Warningf("Failed to reconcile service %s, retrying: %v", item.service, err)
+		r.queue.AddRateLimited(item)
+	default:
+		klog.Errorf("Failed to reconcile service %s, dropping it: %v", item.service, err)
+		r.queue.Forget(item)
+	}
+	return true
+}
+
+// syncService ensures the load balancer of a LoadBalancer service and
+// publishes its status, or deletes the load balancer of a service that is
+// being deleted or no longer wants one and releases its cleanup finalizer.
+func (r *MockReconciler) syncService(ctx context.Context, lb cloudprovider.LoadBalancer, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	service, err := r.serviceLister.Services(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return r.deleteLoadBalancer(ctx, lb, key, nil)
+	}
+	if err != nil {
+		return err
+	}
+	if service.DeletionTimestamp != nil || service.Spec.Type != v1.ServiceTypeLoadBalancer || !wantsLoadBalancer(lb, service) {
+		if err := r.deleteLoadBalancer(ctx, lb, key, service); err != nil {
+			return err
+		}
+		return r.removeFinalizer(ctx, service)
+	}
+
+	if service, err = r.addFinalizer(ctx, service); err != nil {
+		return err
+	}
+
+	nodes, err := r.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	status, err := lb.EnsureLoadBalancer(ctx, r.clusterName, service, nodes)
+	if err != nil {
+		return fmt.Errorf("failed to ensure load balancer: %w", err)
+	}
+
+	r.mu.Lock()
+	r.services[key] = service
+	r.mu.Unlock()
+
+	if id := lb.GetLoadBalancerName(ctx, r.clusterName, service); service.Annotations[MockLoadBalancerIDAnnotation] != id {
+		annotated := service.DeepCopy()
+		if annotated.Annotations == nil {
+			annotated.Annotations = make(map[string]string)
+		}
+		annotated.Annotations[MockLoadBalancerIDAnnotation] = id
+		if service, err = r.kubeClient.CoreV1().Services(namespace).Update(ctx, annotated, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to annotate service: %w", err)
+		}
+	}
+
+	if status == nil || AssertLoadBalancerStatusEqual(status, &service.Status.LoadBalancer) == nil {
+		return nil
+	}
+
+	updated := service.DeepCopy()
+	updated.Status.LoadBalancer = *status
+	if _, err := r.kubeClient.CoreV1().Services(namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update service status: %w", err)
+	}
+	klog.V(2).Infof("Mock reconciler published load balancer status of service %s", key)
+	return nil
+}
+
+// deleteLoadBalancer deletes the load balancer ensured for the service with
+// the given key, if any. The load balancer of a current service that still
+// carries the cleanup finalizer is deleted even if this reconciler did not
+// ensure it.
+func (r *MockReconciler) deleteLoadBalancer(ctx context.Context, lb cloudprovider.LoadBalancer, key string, current *v1.Service) error {
+	r.mu.Lock()
+	service, ok := r.services[key]
+	r.mu.Unlock()
+	if !ok {
+		if current == nil || !servicehelper.HasLBFinalizer(current) {
+			return nil
+		}
+		service = current
+	}
+
+	if err := lb.EnsureLoadBalancerDeleted(ctx, r.clusterName, service); err != nil {
+		return fmt.Errorf("failed to delete load balancer: %w", err)
+	}
+
+	r.mu.Lock()
+	delete(r.services, key)
+	r.mu.Unlock()
+	klog.V(2).Infof("Mock reconciler deleted load balancer of service %s", key)
+	return nil
+}
+
+// addFinalizer adds the load balancer cleanup finalizer to the service unless
+// it already carries it, and returns the updated service.
+func (r *MockReconciler) addFinalizer(ctx context.Context, service *v1.Service) (*v1.Service, error) {
+	if servicehelper.HasLBFinalizer(service) {
+		return service, nil
+	}
+
+	updated := service.DeepCopy()
+	updated.Finalizers = append(updated.Finalizers, servicehelper.LoadBalancerCleanupFinalizer)
+	updated, err := r.kubeClient.CoreV1().Services(service.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add load balancer cleanup finalizer: %w", err)
+	}
+	return updated, nil
+}
+
+// removeFinalizer removes the load balancer cleanup finalizer from the
+// service once its load balancer is deleted.
+func (r *MockReconciler) removeFinalizer(ctx context.Context, service *v1.Service) error {
+	if !servicehelper.HasLBFinalizer(service) {
+		return nil
+	}
+
+	updated := service.DeepCopy()
+	updated.Finalizers = slices.DeleteFunc(updated.Finalizers, func(finalizer string) bool {
+		return finalizer == servicehelper.LoadBalancerCleanupFinalizer
+	})
+	if _, err := r.kubeClient.CoreV1().Services(service.Namespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to remove load balancer cleanup finalizer: %w", err)
+	}
+	klog.V(2).Infof("Mock reconciler removed the load balancer cleanup finalizer of service %s/%s", service.Namespace, service.Name)
+	return nil
+}
+
+// updateHosts updates the hosts of the service's load balancer to the
+// current nodes.
+func (r *MockReconciler) updateHosts(ctx context.Context, lb cloudprovider.LoadBalancer, key string) error {
+	r.mu.Lock()
+	service, ok := r.services[key]
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	nodes, err := r.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	if err := lb.UpdateLoadBalancer(ctx, r.clusterName, service, nodes); err != nil {
+		return fmt.Errorf("failed to update load balancer hosts: %w", err)
+	}
+	return nil
+}
+
+// WaitForLoadBalancer waits for the status of the service to report the
+// ingress of its load balancer, as published by the reconciler.
+func (r *MockReconciler) WaitForLoadBalancer(ctx context.Context, namespace, serviceName string, timeout time.Duration) (*v1.LoadBalancerStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		service, err := r.kubeClient.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+		if err == nil && len(service.Status.LoadBalancer.Ingress) > 0 {
+			return &service.Status.LoadBalancer, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for load balancer of service %s/%s", namespace, serviceName)
+		case <-ticker.C:
+		}
+	}
+}
+
+// AwaitServiceAnnotation waits for the service to carry the annotation with
+// the given key, such as MockLoadBalancerIDAnnotation, and returns its value.
+func (r *MockReconciler) AwaitServiceAnnotation(ctx context.Context, namespace, serviceName, key string, timeout time.Duration) (string, error) {
+	return awaitServiceAnnotation(ctx, r.kubeClient, namespace, serviceName, key, 100*time.Millisecond, timeout)
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// startMockReconciler starts a reconciler for a fresh fake clientset and mock
+// provider that runs until the test ends
+func startMockReconciler(t *testing.T) (kubernetes.Interface, *MockCloudProvider, *MockReconciler) {
+	t.Helper()
+
+	kubeClient := fake.NewSimpleClientset()
+	provider := NewMockCloudProvider()
+	reconciler := NewMockReconciler(kubeClient, provider, "test-cluster")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := reconciler.Start(ctx); err != nil {
+		t.Fatalf("Failed to start reconciler: %v", err)
+	}
+	return kubeClient, provider, reconciler
+}
+
+// eventually polls check until it returns true or the timeout elapses
+func eventually(t *testing.T, timeout time.Duration, check func() bool) bool {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if check() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return check()
+}
+
+func newReconcilerTestService(name string, serviceType v1.ServiceType) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Type:  serviceType,
+			Ports: []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+		},
+	}
+}
+
+// TestMockReconcilerPublishesLoadBalancerStatus tests that a LoadBalancer
+// service created through the clientset gets the status of the load balancer
+// the reconciler ensures, and loses the load balancer once deleted
+func TestMockReconcilerPublishesLoadBalancerStatus(t *testing.T) {
+	ctx := context.Background()
+	kubeClient, provider, reconciler := startMockReconciler(t)
+
+	service := newReconcilerTestService("web", v1.ServiceTypeLoadBalancer)
+	if _, err := kubeClient.CoreV1().Services("default").Create(ctx, service, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	status, err := reconciler.WaitForLoadBalancer(ctx, "default", "web", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Expected the load balancer status to be published, got %v", err)
+	}
+	if len(status.Ingress) == 0 || status.Ingress[0].IP == "" {
+		t.Errorf("Expected an ingress IP, got %+v", status.Ingress)
+	}
+	if !provider.GetMockLoadBalancer().HasLoadBalancer("default", "web") {
+		t.Error("Expected the mock provider to have a load balancer for the service")
+	}
+
+	if err := kubeClient.CoreV1().Services("default").Delete(ctx, "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+	if !eventually(t, 5*time.Second, func() bool { return !provider.GetMockLoadBalancer().HasLoadBalancer("default", "web") }) {
+		t.Error("Expected the load balancer to be deleted with the service")
+	}
+}
+
+// TestMockReconcilerIgnoresOtherServiceTypes tests that services that are not
+// of type LoadBalancer get no load balancer, and that changing a service away
+// from LoadBalancer deletes it
+func TestMockReconcilerIgnoresOtherServiceTypes(t *testing.T) {
+	ctx := context.Background()
+	kubeClient, provider, reconciler := startMockReconciler(t)
+	services := kubeClient.CoreV1().Services("default")
+
+	if _, err := services.Create(ctx, newReconcilerTestService("internal", v1.ServiceTypeClusterIP), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if _, err := services.Create(ctx, newReconcilerTestService("web", v1.ServiceTypeLoadBalancer), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if _, err := reconciler.WaitForLoadBalancer(ctx, "default", "web", 5*time.Second); err != nil {
+		t.Fatalf("Expected the load balancer status to be published, got %v", err)
+	}
+	if provider.GetMockLoadBalancer().HasLoadBalancer("default", "internal") {
+		t.Error("Expected no load balancer for a ClusterIP service")
+	}
+
+	service, err := services.Get(ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get service: %v", err)
+	}
+	service.Spec.Type = v1.ServiceTypeClusterIP
+	if _, err := services.Update(ctx, service, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+	if !eventually(t, 5*time.Second, func() bool { return !provider.GetMockLoadBalancer().HasLoadBalancer("default", "web") }) {
+		t.Error("Expected the load balancer to be deleted once the service is no longer of type LoadBalancer")
+	}
+}
+
+// TestMockReconcilerUpdatesHostsOnNodeChanges tests that adding and removing
+// nodes updates the hosts of existing load balancers
+func TestMockReconcilerUpdatesHostsOnNodeChanges(t *testing.T) {
+	ctx := context.Background()
+	kubeClient, provider, reconciler := startMockReconciler(t)
+	mockLB := provider.GetMockLoadBalancer()
+
+	if _, err := kubeClient.CoreV1().Services("default").Create(ctx, newReconcilerTestService("web", v1.ServiceTypeLoadBalancer), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if _, err := reconciler.WaitForLoadBalancer(ctx, "default", "web", 5*time.Second); err != nil {
+		t.Fatalf("Expected the load balancer status to be published, got %v", err)
+	}
+
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	if _, err := kubeClient.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	if !eventually(t, 5*time.Second, func() bool {
+		nodes, ok := mockLB.GetUpdatedNodes("default", "web")
+		return ok && slices.Equal(nodes, []string{"node-1"})
+	}) {
+		nodes, _ := mockLB.GetUpdatedNodes("default", "web")
+		t.Errorf("Expected the hosts to be updated to [node-1], got %v", nodes)
+	}
+
+	if err := kubeClient.CoreV1().Nodes().Delete(ctx, "node-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+	if !eventually(t, 5*time.Second, func() bool {
+		nodes, ok := mockLB.GetUpdatedNodes("default", "web")
+		return ok && len(nodes) == 0
+	}) {
+		nodes, _ := mockLB.GetUpdatedNodes("default", "web")
+		t.Errorf("Expected the hosts to be updated to no nodes, got %v", nodes)
+	}
+}
+
+// TestMockReconcilerStopsWithContext tests that the reconciler stops
+// reconciling once its context is done
+func TestMockReconcilerStopsWithContext(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	provider := NewMockCloudProvider()
+	reconciler := NewMockReconciler(kubeClient, provider, "test-cluster")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := reconciler.Start(ctx); err != nil {
+		t.Fatalf("Failed to start reconciler: %v", err)
+	}
+	cancel()
+	if !eventually(t, 5*time.Second, reconciler.queue.ShuttingDown) {
+		t.Fatal("Expected the work queue to shut down")
+	}
+
+	service := newReconcilerTestService("web", v1.ServiceTypeLoadBalancer)
+	if _, err := kubeClient.CoreV1().Services("default").Create(context.Background(), service, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if provider.GetMockLoadBalancer().HasLoadBalancer("default", "web") {
+		t.Error("Expected no load balancer after the reconciler stopped")
+	}
+}
+
+// TestMockReconcilerAnnotatesLoadBalancerID tests that the reconciler records
+// the name of the provisioned load balancer on the service
+func TestMockReconcilerAnnotatesLoadBalancerID(t *testing.T) {
+	ctx := context.Background()
+	kubeClient, provider, reconciler := startMockReconciler(t)
+
+	service := newReconcilerTestService("web", v1.ServiceTypeLoadBalancer)
+	if _, err := kubeClient.CoreV1().Services("default").Create(ctx, service, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if _, err := reconciler.WaitForLoadBalancer(ctx, "default", "web", 5*time.Second); err != nil {
+		t.Fatalf("Expected the load balancer status to be published, got %v", err)
+	}
+
+	id, err := reconciler.AwaitServiceAnnotation(ctx, "default", "web", MockLoadBalancerIDAnnotation, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Expected the load balancer ID annotation, got %v", err)
+	}
+	expected := provider.GetMockLoadBalancer().GetLoadBalancerName(ctx, "test-cluster", service)
+	if id != expected {
+		t.Errorf("Expected load balancer ID %s, got %s", expected, id)
+	}
+
+	if _, err := reconciler.AwaitServiceAnnotation(ctx, "default", "web", "example.com/missing", 200*time.Millisecond); err == nil {
+		t.Error("Expected a timeout for an annotation that is never set")
+	}
+}
+
+// honorServiceFinalizers makes the fake clientset delete services like the
+// API server does: deleting a service with finalizers only sets its deletion
+// timestamp, and the service disappears once its last finalizer is removed.
+// removed is called with the service right before it disappears
+func honorServiceFinalizers(kubeClient *fake.Clientset, removed func(service *v1.Service)) {
+	gvr := v1.SchemeGroupVersion.WithResource("services")
+	kubeClient.PrependReactor("delete", "services", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(clienttesting.DeleteAction)
+		obj, err := kubeClient.Tracker().Get(gvr, deleteAction.GetNamespace(), deleteAction.GetName())
+		if err != nil || len(obj.(*v1.Service).Finalizers) == 0 {
+			return false, nil, nil
+		}
+		service := obj.(*v1.Service).DeepCopy()
+		now := metav1.Now()
+		service.DeletionTimestamp = &now
+		return true, service, kubeClient.Tracker().Update(gvr, service, service.Namespace)
+	})
+	kubeClient.PrependReactor("update", "services", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		service := action.(clienttesting.UpdateAction).GetObject().(*v1.Service)
+		if action.GetSubresource() != "" || service.DeletionTimestamp == nil || len(service.Finalizers) > 0 {
+			return false, nil, nil
+		}
+		removed(service)
+		return true, service, kubeClient.Tracker().Delete(gvr, service.Namespace, service.Name)
+	})
+}
+
+// TestMockReconcilerLoadBalancerCleanupFinalizer tests that the reconciler
+// guards the load balancer with the cleanup finalizer and removes it only
+// after deleting the load balancer, before the service disappears
+func TestMockReconcilerLoadBalancerCleanupFinalizer(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewSimpleClientset()
+	provider := NewMockCloudProvider()
+	mockLB := provider.GetMockLoadBalancer()
+
+	var mu sync.Mutex
+	var removed, lbPresentAtRemoval bool
+	honorServiceFinalizers(kubeClient, func(service *v1.Service) {
+		mu.Lock()
+		defer mu.Unlock()
+		removed = true
+		lbPresentAtRemoval = mockLB.HasLoadBalancer(service.Namespace, service.Name)
+	})
+
+	reconciler := NewMockReconciler(kubeClient, provider, "test-cluster")
+	reconcilerCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	if err := reconciler.Start(reconcilerCtx); err != nil {
+		t.Fatalf("Failed to start reconciler: %v", err)
+	}
+
+	services := kubeClient.CoreV1().Services("default")
+	if _, err := services.Create(ctx, newReconcilerTestService("web", v1.ServiceTypeLoadBalancer), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if _, err := reconciler.WaitForLoadBalancer(ctx, "default", "web", 5*time.Second); err != nil {
+		t.Fatalf("Expected the load balancer status to be published, got %v", err)
+	}
+	service, err := services.Get(ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get service: %v", err)
+	}
+	if !slices.Contains(service.Finalizers, "service.kubernetes.io/load-balancer-cleanup") {
+		t.Fatalf("Expected the load balancer cleanup finalizer, got %v", service.Finalizers)
+	}
+
+	if err := services.Delete(ctx, "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+	if !eventually(t, 5*time.Second, func() bool {
+		_, err := services.Get(ctx, "web", metav1.GetOptions{})
+		return apierrors.IsNotFound(err)
+	}) {
+		t.Fatal("Expected the service to disappear once its finalizer is removed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !removed {
+		t.Error("Expected the finalizer to be removed before the service disappeared")
+	}
+	if lbPresentAtRemoval {
+		t.Error("Expected the load balancer to be deleted before the finalizer was removed")
+	}
+	if mockLB.HasLoadBalancer("default", "web") {
+		t.Error("Expected no load balancer after the service was deleted")
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NodeEventType is the kind of change a NodeEvent records.
+type NodeEventType string
+
+const (
+	// NodeEventAdd records a node being created.
+	NodeEventAdd NodeEventType = "Add"
+
+	// NodeEventUpdate records a node being changed.
+	NodeEventUpdate NodeEventType = "Update"
+
+	// NodeEventDelete records a node being deleted.
+	NodeEventDelete NodeEventType = "Delete"
+)
+
+// NodeEvent is a node change observed by the node informer.
+type NodeEvent struct {
+	// Type is the kind of change.
+	Type NodeEventType
+
+	// Node is the node after the change, or the last known state of a
+	// deleted node.
+	Node *v1.Node
+
+	// OldNode is the node before the change. It is only set for updates.
+	OldNode *v1.Node
+
+	// Time is when the event was observed.
+	Time time.Time
+}
+
+// ChangedLabels returns the labels an update added or changed, with their new
+// values. It returns nil for events other than updates.
+func (e NodeEvent) ChangedLabels() map[string]string {
+	if e.Type != NodeEventUpdate || e.OldNode == nil || e.Node == nil {
+		return nil
+	}
+
+	changed := make(map[string]string)
+	for key, value := range e.Node.Labels {
+		if oldValue, found := e.OldNode.Labels[key]; !found || oldValue != value {
+			changed[key] = value
+		}
+	}
+	return changed
+}
+
+// nodeEventRecorder collects node events from informer callbacks.
+type nodeEventRecorder struct {
+	mu     sync.Mutex
+	events []NodeEvent
+}
+
+func (r *nodeEventRecorder) record(eventType NodeEventType, oldObj, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return
+	}
+
+	event := NodeEvent{Type: eventType, Node: node.DeepCopy(), Time: time.Now()}
+	if oldNode, ok := oldObj.(*v1.Node); ok {
+		event.OldNode = oldNode.DeepCopy()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *nodeEventRecorder) recorded() []NodeEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]NodeEvent(nil), r.events...)
+}
+
+// RecordNodeEvents records the node Add, Update and Delete events observed by
+// the node informer until the timeout elapses or ctx is cancelled, and returns
+// them in the order they were observed. Nodes that already exist when
+// recording starts are not reported as added. If ctx is cancelled the events
+// recorded so far are returned along with the context's error.
+func (c *CCMTestInterface) RecordNodeEvents(ctx context.Context, timeout time.Duration) ([]NodeEvent, error) {
+	if c.informerFactory == nil || c.informerStop == nil {
+		return nil, fmt.Errorf("test environment is not set up")
+	}
+
+	// Requesting the informer after the factory started needs another Start
+	informer := c.informerFactory.Core().V1().Nodes().Informer()
+	c.informerFactory.Start(c.informerStop)
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return nil, fmt.Errorf("failed to sync node informer: %w", ctx.Err())
+	}
+
+	recorder := &nodeEventRecorder{}
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				recorder.record(NodeEventAdd, nil, obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			recorder.record(NodeEventUpdate, oldObj, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			recorder.record(NodeEventDelete, nil, obj)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add node event handler: %w", err)
+	}
+	defer informer.RemoveEventHandler(registration)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return recorder.recorded(), nil
+	case <-ctx.Done():
+		return recorder.recorded(), ctx.Err()
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// TestCCMTestInterfaceRecordNodeEvents tests that node updates, creations and
+// deletions during the recording window are captured, while nodes that existed
+// beforehand are not reported as added
+func TestCCMTestInterfaceRecordNodeEvents(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+	defer ti.TeardownTestEnvironment()
+	ctx := context.Background()
+
+	if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "watched-node"}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+
+	type recording struct {
+		events []NodeEvent
+		err    error
+	}
+	done := make(chan recording, 1)
+	go func() {
+		events, err := ti.RecordNodeEvents(ctx, 1500*time.Millisecond)
+		done <- recording{events: events, err: err}
+	}()
+
+	// The handler is registered asynchronously, so keep relabeling the node
+	// until the recorder is certain to be listening
+	for i := 0; i < 10; i++ {
+		nodeConfig := &ccmtesting.TestNodeConfig{
+			Name:   "watched-node",
+			Labels: map[string]string{"round": fmt.Sprint(i)},
+		}
+		if _, err := ti.UpdateTestNode(ctx, nodeConfig); err != nil {
+			t.Fatalf("Failed to update node: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if _, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{Name: "added-node"}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	if err := ti.DeleteTestNode(ctx, "added-node"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+
+	result := <-done
+	if result.err != nil {
+		t.Fatalf("Expected no error, got %v", result.err)
+	}
+
+	var updated, added, deleted bool
+	for _, event := range result.events {
+		switch {
+		case event.Type == NodeEventUpdate && event.Node.Name == "watched-node":
+			if _, found := event.ChangedLabels()["round"]; found {
+				updated = true
+			}
+		case event.Type == NodeEventAdd && event.Node.Name == "watched-node":
+			t.Error("Expected no Add event for a node that existed before recording")
+		case event.Type == NodeEventAdd && event.Node.Name == "added-node":
+			added = true
+		case event.Type == NodeEventDelete && event.Node.Name == "added-node":
+			deleted = true
+		}
+	}
+
+	if !updated {
+		t.Errorf("Expected an Update event with a changed 'round' label, got %v", result.events)
+	}
+	if !added {
+		t.Error("Expected an Add event for added-node")
+	}
+	if !deleted {
+		t.Error("Expected a Delete event for added-node")
+	}
+}
+
+// TestNodeEventChangedLabels tests that only added and changed labels are reported for updates
+func TestNodeEventChangedLabels(t *testing.T) {
+	oldNode := &v1.Node{}
+	oldNode.Labels = map[string]string{"kept": "a", "changed": "b", "removed": "c"}
+	newNode := &v1.Node{}
+	newNode.Labels = map[string]string{"kept": "a", "changed": "x", "added": "y"}
+
+	changed := NodeEvent{Type: NodeEventUpdate, OldNode: oldNode, Node: newNode}.ChangedLabels()
+	if len(changed) != 2 || changed["changed"] != "x" || changed["added"] != "y" {
+		t.Errorf("Expected changed and added labels, got %v", changed)
+	}
+
+	if labels := (NodeEvent{Type: NodeEventAdd, Node: newNode}).ChangedLabels(); labels != nil {
+		t.Errorf("Expected no changed labels for an Add event, got %v", labels)
+	}
+}
+
+// TestCCMTestInterfaceRecordNodeEventsRequiresSetup tests that recording fails before the environment is set up
+func TestCCMTestInterfaceRecordNodeEventsRequiresSetup(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if _, err := ti.RecordNodeEvents(context.Background(), time.Millisecond); err == nil {
+		t.Error("Expected error recording node events before setup")
+	}
+}
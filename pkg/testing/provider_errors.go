@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/klog/v2"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// ProviderErrorClass is the broad category of an error returned by a cloud provider.
+type ProviderErrorClass string
+
+const (
+	// ProviderErrorUnknown is an error that matches none of the known patterns.
+	ProviderErrorUnknown ProviderErrorClass = "Unknown"
+	// ProviderErrorThrottled is a rate limiting response from the provider API.
+	ProviderErrorThrottled ProviderErrorClass = "Throttled"
+	// ProviderErrorQuotaExceeded means an account or project quota is exhausted.
+	ProviderErrorQuotaExceeded ProviderErrorClass = "QuotaExceeded"
+	// ProviderErrorUnauthorized means the credentials are missing, invalid or lack permission.
+	ProviderErrorUnauthorized ProviderErrorClass = "Unauthorized"
+	// ProviderErrorNotFound means the referenced cloud resource does not exist.
+	ProviderErrorNotFound ProviderErrorClass = "NotFound"
+	// ProviderErrorInvalid means the request itself was rejected as invalid,
+	// such as a load balancer for a service without ports. It is a failure of
+	// the test's input, not a fault of the provider.
+	ProviderErrorInvalid ProviderErrorClass = "Invalid"
+	// ProviderErrorUnsupported means the provider does not support the
+	// requested feature, such as a service's spec.loadBalancerIP.
+	ProviderErrorUnsupported ProviderErrorClass = "Unsupported"
+)
+
+// providerErrorPatterns maps lower-cased fragments of AWS, GCP and Azure SDK
+// error messages to their class. Classes are checked in order, so throttling
+// codes such as RequestLimitExceeded win over the broader quota patterns and
+// quota errors reported with a 403 are not mistaken for auth failures.
+var providerErrorPatterns = []struct {
+	class    ProviderErrorClass
+	patterns []string
+}{
+	{ProviderErrorThrottled, []string{"throttl", "ratelimitexceeded", "rate limit", "requestlimitexceeded", "too many requests", "toomanyrequests", "slowdown"}},
+	{ProviderErrorQuotaExceeded, []string{"quota", "limitexceeded", "resource_exhausted", "resourceexhausted", "insufficient capacity"}},
+	{ProviderErrorUnauthorized, []string{"unauthorized", "accessdenied", "access denied", "authorizationfailed", "authenticationfailed", "invalidclienttokenid", "expiredtoken", "permission", "forbidden"}},
+	{ProviderErrorNotFound, []string{"notfound", "not found", "does not exist"}},
+	{ProviderErrorUnsupported, []string{"unsupported", "not supported", "notsupported"}},
+}
+
+// ClassifyProviderError sorts an error returned by a cloud provider into a
+// ProviderErrorClass so callers can decide whether retrying is worthwhile.
+func ClassifyProviderError(err error) ProviderErrorClass {
+	if err == nil {
+		return ProviderErrorUnknown
+	}
+
+	if errors.Is(err, cloudprovider.InstanceNotFound) {
+		return ProviderErrorNotFound
+	}
+	if errors.Is(err, ccmtesting.ErrInvalidServiceConfig) {
+		return ProviderErrorInvalid
+	}
+	if errors.Is(err, cloudprovider.NotImplemented) || ccmtesting.IsUnsupportedError(err) {
+		return ProviderErrorUnsupported
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, group := range providerErrorPatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(message, pattern) {
+				return group.class
+			}
+		}
+	}
+
+	// AWS SDK errors expose the HTTP status of the failed response
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		if class := classifyStatusCode(statusErr.HTTPStatusCode()); class != ProviderErrorUnknown {
+			return class
+		}
+	}
+
+	var apiStatus apierrors.APIStatus
+	if errors.As(err, &apiStatus) {
+		return classifyStatusCode(int(apiStatus.Status().Code))
+	}
+
+	return ProviderErrorUnknown
+}
+
+func classifyStatusCode(code int) ProviderErrorClass {
+	switch code {
+	case http.StatusTooManyRequests:
+		return ProviderErrorThrottled
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ProviderErrorUnauthorized
+	case http.StatusNotFound:
+		return ProviderErrorNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ProviderErrorInvalid
+	default:
+		return ProviderErrorUnknown
+	}
+}
+
+// RetryPolicy controls how RetryProviderCall retries failed provider calls.
+type RetryPolicy struct {
+	// Attempts is the maximum number of calls, including the first.
+	Attempts int
+
+	// Backoff is the base delay before retrying an unclassified error. The
+	// delay grows linearly with each attempt.
+	Backoff time.Duration
+
+	// ThrottleBackoff is the base delay before retrying a throttled call,
+	// normally much longer than Backoff to let the provider's rate limit recover.
+	ThrottleBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used when a caller does not configure a RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:        3,
+	Backoff:         2 * time.Second,
+	ThrottleBackoff: 10 * time.Second,
+}
+
+// RetryProviderCall calls fn until it succeeds, the policy's attempts are
+// used up or ctx is done. Throttled calls back off for longer, while
+// unauthorized, quota, not-found, invalid request and unsupported feature
+// errors are returned immediately since retrying cannot fix them.
+func RetryProviderCall(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	if policy.Attempts <= 0 {
+		policy = DefaultRetryPolicy
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+
+		class := ClassifyProviderError(err)
+		var delay time.Duration
+		switch class {
+		case ProviderErrorThrottled:
+			delay = policy.ThrottleBackoff * time.Duration(attempt)
+		case ProviderErrorUnknown:
+			delay = policy.Backoff * time.Duration(attempt)
+		default:
+			return fmt.Errorf("%s provider error: %w", class, err)
+		}
+
+		if attempt >= policy.Attempts {
+			return fmt.Errorf("%s provider error after %d attempts: %w", class, attempt, err)
+		}
+
+		klog.V(2).Infof("Retrying %s provider error in %v (attempt %d of %d): %v", class, delay, attempt, policy.Attempts, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s provider error, retry aborted: %w", class, err)
+		case <-time.After(delay):
+		}
+	}
+}
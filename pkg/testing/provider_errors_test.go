@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cloudprovider "k8s.io/cloud-provider"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// awsResponseError mimics the AWS SDK's smithy API errors, which expose an
+// error code and the HTTP status of the failed response
+type awsResponseError struct {
+	code    string
+	message string
+	status  int
+}
+
+func (e *awsResponseError) Error() string {
+	return fmt.Sprintf("api error %s: %s", e.code, e.message)
+}
+
+func (e *awsResponseError) HTTPStatusCode() int {
+	return e.status
+}
+
+// TestClassifyProviderError tests classification of representative AWS, GCP and Azure SDK errors
+func TestClassifyProviderError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ProviderErrorClass
+	}{
+		{"nil", nil, ProviderErrorUnknown},
+		{"generic", errors.New("connection reset by peer"), ProviderErrorUnknown},
+
+		{"aws throttling", &awsResponseError{"Throttling", "Rate exceeded", 400}, ProviderErrorThrottled},
+		{"aws request limit", &awsResponseError{"RequestLimitExceeded", "Request limit exceeded.", 503}, ProviderErrorThrottled},
+		{"aws vcpu limit", &awsResponseError{"VcpuLimitExceeded", "You have requested more vCPU capacity than your current vCPU limit allows", 400}, ProviderErrorQuotaExceeded},
+		{"aws unauthorized", &awsResponseError{"UnauthorizedOperation", "You are not authorized to perform this operation.", 403}, ProviderErrorUnauthorized},
+		{"aws invalid token", &awsResponseError{"InvalidClientTokenId", "The security token included in the request is invalid.", 403}, ProviderErrorUnauthorized},
+		{"aws instance not found", &awsResponseError{"InvalidInstanceID.NotFound", "The instance ID 'i-0123' does not exist", 400}, ProviderErrorNotFound},
+		{"aws status only", &awsResponseError{"ServiceUnavailable", "please retry", 429}, ProviderErrorThrottled},
+
+		{"gcp rate limit", errors.New("googleapi: Error 429: Rate Limit Exceeded, rateLimitExceeded"), ProviderErrorThrottled},
+		{"gcp quota", errors.New("googleapi: Error 403: Quota 'CPUS' exceeded.  Limit: 24.0 in region us-central1., quotaExceeded"), ProviderErrorQuotaExceeded},
+		{"gcp permission", errors.New("googleapi: Error 403: Required 'compute.forwardingRules.get' permission for 'projects/p/regions/r/forwardingRules/a1', forbidden"), ProviderErrorUnauthorized},
+		{"gcp not found", errors.New("googleapi: Error 404: The resource 'projects/p/zones/z/instances/vm' was not found, notFound"), ProviderErrorNotFound},
+
+		{"azure throttled", errors.New("PUT https://management.azure.com/...\nRESPONSE 429: 429 Too Many Requests\nERROR CODE: SubscriptionRequestsThrottled"), ProviderErrorThrottled},
+		{"azure quota", errors.New("RESPONSE 409: 409 Conflict\nERROR CODE: QuotaExceeded"), ProviderErrorQuotaExceeded},
+		{"azure authorization", errors.New("RESPONSE 403: 403 Forbidden\nERROR CODE: AuthorizationFailed"), ProviderErrorUnauthorized},
+		{"azure not found", errors.New("RESPONSE 404: 404 Not Found\nERROR CODE: ResourceNotFound"), ProviderErrorNotFound},
+
+		{"wrapped", fmt.Errorf("failed to ensure load balancer: %w", &awsResponseError{"Throttling", "Rate exceeded", 400}), ProviderErrorThrottled},
+		{"cloudprovider instance not found", fmt.Errorf("lookup: %w", cloudprovider.InstanceNotFound), ProviderErrorNotFound},
+		{"kubernetes too many requests", apierrors.NewTooManyRequests("slow down", 1), ProviderErrorThrottled},
+		{"kubernetes conflict", apierrors.NewConflict(schema.GroupResource{Resource: "services"}, "svc", errors.New("modified")), ProviderErrorUnknown},
+		{"invalid test service config", fmt.Errorf("create: %w", ccmtesting.ErrInvalidServiceConfig), ProviderErrorInvalid},
+		{"aws validation error", &awsResponseError{"ValidationError", "At least one listener is required", 400}, ProviderErrorInvalid},
+		{"kubernetes invalid", apierrors.NewInvalid(schema.GroupKind{Kind: "Service"}, "svc", nil), ProviderErrorInvalid},
+		{"cloudprovider not implemented", fmt.Errorf("ensure: %w", cloudprovider.NotImplemented), ProviderErrorUnsupported},
+		{"unsupported capability", ccmtesting.NewUnsupportedError("load balancer IP"), ProviderErrorUnsupported},
+		{"unsupported message", errors.New("spec.loadBalancerIP is not supported by this provider"), ProviderErrorUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyProviderError(tt.err); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestRetryProviderCall tests that retries back off on throttling and fail fast on auth errors
+func TestRetryProviderCall(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond, ThrottleBackoff: 20 * time.Millisecond}
+	throttled := &awsResponseError{"Throttling", "Rate exceeded", 400}
+	unauthorized := &awsResponseError{"AccessDenied", "User is not authorized", 403}
+
+	t.Run("recovers after throttling", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		err := RetryProviderCall(context.Background(), policy, func() error {
+			calls++
+			if calls < 3 {
+				return throttled
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("Expected 3 calls, got %d", calls)
+		}
+		// Throttle backoff grows with each attempt: 20ms + 40ms
+		if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+			t.Errorf("Expected throttled retries to back off for at least 60ms, took %v", elapsed)
+		}
+	})
+
+	t.Run("fails fast on auth errors", func(t *testing.T) {
+		calls := 0
+		err := RetryProviderCall(context.Background(), policy, func() error {
+			calls++
+			return unauthorized
+		})
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+		if !errors.Is(err, unauthorized) {
+			t.Errorf("Expected error to wrap %v, got %v", unauthorized, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), string(ProviderErrorUnauthorized)) {
+			t.Errorf("Expected error to name its class, got %v", err)
+		}
+	})
+
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		calls := 0
+		err := RetryProviderCall(context.Background(), policy, func() error {
+			calls++
+			return errors.New("connection reset by peer")
+		})
+		if calls != policy.Attempts {
+			t.Errorf("Expected %d calls, got %d", policy.Attempts, calls)
+		}
+		if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+			t.Errorf("Expected error after 3 attempts, got %v", err)
+		}
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls := 0
+		err := RetryProviderCall(ctx, policy, func() error {
+			calls++
+			return throttled
+		})
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+		if !errors.Is(err, throttled) {
+			t.Errorf("Expected error to wrap %v, got %v", throttled, err)
+		}
+	})
+}
@@ -19,11 +19,14 @@ package testing
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	cloudprovider "k8s.io/cloud-provider"
 	"k8s.io/klog/v2"
@@ -58,6 +61,27 @@ type RealCloudProviderConfig struct {
 	TestTimeout      int
 	CleanupResources bool
 	ResourcePrefix   string // Prefix for test resources to avoid conflicts
+
+	// Retry controls retries of provider API calls (zero value = DefaultRetryPolicy)
+	Retry RetryPolicy
+
+	// Transport carries the HTTP requests of the provider's API clients
+	// (nil = http.DefaultTransport). UseCassette sets it to record or
+	// replay them.
+	Transport http.RoundTripper
+}
+
+// UseCassette makes the provider's API clients record their HTTP interactions
+// to, or replay them from, the cassette at path, wrapping the configured
+// transport. It must be called before the provider is initialized, and only
+// takes effect for providers for which SupportsCassette reports true.
+func (c *RealCloudProviderConfig) UseCassette(path string, mode CassetteMode) (*CassetteRoundTripper, error) {
+	cassette, err := NewCassetteRoundTripper(path, mode, c.Transport)
+	if err != nil {
+		return nil, err
+	}
+	c.Transport = cassette
+	return cassette, nil
 }
 
 // NewRealCloudProviderAdapter creates a new adapter for real cloud provider testing
@@ -75,6 +99,8 @@ func (r *RealCloudProviderAdapter) Initialize() error {
 	defer r.mu.Unlock()
 
 	klog.Infof("Initializing real cloud provider adapter for %s", r.config.ProviderName)
+	klog.V(2).Infof("Cloud provider config: region=%s zone=%s cluster=%s credentials=%v",
+		r.config.Region, r.config.Zone, r.config.ClusterName, ccmtesting.RedactSecrets(r.config.Credentials))
 
 	// Initialize the cloud provider if needed
 	// Note: Most cloud providers don't have an Initialize method, so we skip this
@@ -105,7 +131,10 @@ func (r *RealCloudProviderAdapter) Cleanup(ctx context.Context) error {
 		} else {
 			for _, service := range services.Items {
 				if service.Spec.Type == v1.ServiceTypeLoadBalancer {
-					if err := lb.EnsureLoadBalancerDeleted(ctx, r.config.ClusterName, &service); err != nil {
+					err := RetryProviderCall(ctx, r.config.Retry, func() error {
+						return lb.EnsureLoadBalancerDeleted(ctx, r.config.ClusterName, &service)
+					})
+					if err != nil {
 						klog.Warningf("Failed to delete load balancer for service %s: %v", service.Name, err)
 					}
 				}
@@ -115,13 +144,21 @@ func (r *RealCloudProviderAdapter) Cleanup(ctx context.Context) error {
 
 	// Clean up routes
 	if routes, ok := r.cloudProvider.Routes(); ok {
-		routeList, err := routes.ListRoutes(ctx, r.config.ClusterName)
+		var routeList []*cloudprovider.Route
+		err := RetryProviderCall(ctx, r.config.Retry, func() error {
+			var listErr error
+			routeList, listErr = routes.ListRoutes(ctx, r.config.ClusterName)
+			return listErr
+		})
 		if err != nil {
 			klog.Warningf("Failed to list routes for cleanup: %v", err)
 		} else {
 			for _, route := range routeList {
 				if strings.HasPrefix(route.Name, r.config.ResourcePrefix) {
-					if err := routes.DeleteRoute(ctx, r.config.ClusterName, route); err != nil {
+					err := RetryProviderCall(ctx, r.config.Retry, func() error {
+						return routes.DeleteRoute(ctx, r.config.ClusterName, route)
+					})
+					if err != nil {
 						klog.Warningf("Failed to delete route %s: %v", route.Name, err)
 					}
 				}
@@ -220,23 +257,35 @@ func NewAzureCloudProviderAdapter(kubeClient kubernetes.Interface, config *RealC
 	return adapter, nil
 }
 
+// cassetteProviders are the providers whose initializers build their API
+// clients on RealCloudProviderConfig.Transport. The initializers below are
+// placeholders that build no clients, so none do yet.
+var cassetteProviders = sets.New[string]()
+
+// SupportsCassette reports whether the API calls of the named provider go
+// through RealCloudProviderConfig.Transport, so that a cassette set with
+// UseCassette records or replays them.
+func SupportsCassette(providerName string) bool {
+	return cassetteProviders.Has(providerName)
+}
+
 // Placeholder functions for cloud provider initialization
 // These would be implemented based on your specific cloud provider setup
 
 func initializeAWSCloudProvider(config *RealCloudProviderConfig) (cloudprovider.Interface, error) {
 	// Implementation would depend on your AWS cloud provider setup
-	// Example: return aws.NewCloudProvider(config.Credentials)
+	// Example: return aws.NewCloudProvider(config.Credentials, config.Transport)
 	return nil, fmt.Errorf("AWS cloud provider initialization not implemented")
 }
 
 func initializeGCPCloudProvider(config *RealCloudProviderConfig) (cloudprovider.Interface, error) {
 	// Implementation would depend on your GCP cloud provider setup
-	// Example: return gcp.NewCloudProvider(config.Credentials)
+	// Example: return gcp.NewCloudProvider(config.Credentials, config.Transport)
 	return nil, fmt.Errorf("GCP cloud provider initialization not implemented")
 }
 
 func initializeAzureCloudProvider(config *RealCloudProviderConfig) (cloudprovider.Interface, error) {
 	// Implementation would depend on your Azure cloud provider setup
-	// Example: return azure.NewCloudProvider(config.Credentials)
+	// Example: return azure.NewCloudProvider(config.Credentials, config.Transport)
 	return nil, fmt.Errorf("Azure cloud provider initialization not implemented")
 }
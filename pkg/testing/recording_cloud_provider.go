@@ -0,0 +1,323 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// CloudCall is a call recorded by a RecordingCloudProvider.
+type CloudCall struct {
+	// Method is the interface and method called, such as
+	// "LoadBalancer.EnsureLoadBalancer", or the name passed to Record.
+	Method string
+
+	// Time is when the call was made.
+	Time time.Time
+}
+
+// RecordingCloudProvider wraps a cloud provider and records every call made
+// through its LoadBalancer, Instances, InstancesV2, Zones, Clusters and Routes
+// interfaces in the order they were made, so that tests can assert the
+// sequence a reconcile produces rather than only how often each method ran.
+// Steps outside the cloud provider, such as updating a service status, can
+// be recorded alongside with Record. The wrapped interfaces only expose the
+// cloud provider methods, hiding extras such as the recorders of the mocks.
+type RecordingCloudProvider struct {
+	cloudprovider.Interface
+
+	mu    sync.Mutex
+	calls []CloudCall
+}
+
+// NewRecordingCloudProvider creates a recording wrapper around cloudProvider.
+func NewRecordingCloudProvider(cloudProvider cloudprovider.Interface) *RecordingCloudProvider {
+	return &RecordingCloudProvider{Interface: cloudProvider}
+}
+
+// Record records a call to method, for steps the cloud provider does not see.
+func (p *RecordingCloudProvider) Record(method string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, CloudCall{Method: method, Time: time.Now()})
+}
+
+// Calls returns the recorded calls in the order they were made.
+func (p *RecordingCloudProvider) Calls() []CloudCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.calls)
+}
+
+// Reset forgets the recorded calls.
+func (p *RecordingCloudProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = nil
+}
+
+// AssertCallOrder returns an error unless the given methods were called in
+// that relative order, with any other calls allowed in between. A method is
+// named either in full, as "Routes.DeleteRoute", or by its method name alone.
+func (p *RecordingCloudProvider) AssertCallOrder(methods ...string) error {
+	calls := p.Calls()
+
+	next := 0
+	for i, method := range methods {
+		matches := func(call CloudCall) bool {
+			return call.Method == method || strings.HasSuffix(call.Method, "."+method)
+		}
+
+		found := slices.IndexFunc(calls[next:], matches)
+		if found >= 0 {
+			next += found + 1
+			continue
+		}
+		if i == 0 || !slices.ContainsFunc(calls, matches) {
+			return fmt.Errorf("%s was never called; calls: %s", method, formatCloudCalls(calls))
+		}
+		return fmt.Errorf("%s was not called after %s; calls: %s", method, methods[i-1], formatCloudCalls(calls))
+	}
+	return nil
+}
+
+// formatCloudCalls lists the methods of calls in order.
+func formatCloudCalls(calls []CloudCall) string {
+	methods := make([]string, 0, len(calls))
+	for _, call := range calls {
+		methods = append(methods, call.Method)
+	}
+	return "[" + strings.Join(methods, ", ") + "]"
+}
+
+// LoadBalancer returns the recording load balancer interface, if supported.
+func (p *RecordingCloudProvider) LoadBalancer() (cloudprovider.LoadBalancer, bool) {
+	lb, ok := p.Interface.LoadBalancer()
+	if !ok || lb == nil {
+		return lb, ok
+	}
+	return &recordingLoadBalancer{provider: p, lb: lb}, true
+}
+
+// Instances returns the recording instances interface, if supported.
+func (p *RecordingCloudProvider) Instances() (cloudprovider.Instances, bool) {
+	instances, ok := p.Interface.Instances()
+	if !ok || instances == nil {
+		return instances, ok
+	}
+	return &recordingInstances{provider: p, instances: instances}, true
+}
+
+// InstancesV2 returns the recording InstancesV2 interface, if supported.
+func (p *RecordingCloudProvider) InstancesV2() (cloudprovider.InstancesV2, bool) {
+	instances, ok := p.Interface.InstancesV2()
+	if !ok || instances == nil {
+		return instances, ok
+	}
+	return &recordingInstancesV2{provider: p, instances: instances}, true
+}
+
+// Zones returns the recording zones interface, if supported.
+func (p *RecordingCloudProvider) Zones() (cloudprovider.Zones, bool) {
+	zones, ok := p.Interface.Zones()
+	if !ok || zones == nil {
+		return zones, ok
+	}
+	return &recordingZones{provider: p, zones: zones}, true
+}
+
+// Clusters returns the recording clusters interface, if supported.
+func (p *RecordingCloudProvider) Clusters() (cloudprovider.Clusters, bool) {
+	clusters, ok := p.Interface.Clusters()
+	if !ok || clusters == nil {
+		return clusters, ok
+	}
+	return &recordingClusters{provider: p, clusters: clusters}, true
+}
+
+// Routes returns the recording routes interface, if supported.
+func (p *RecordingCloudProvider) Routes() (cloudprovider.Routes, bool) {
+	routes, ok := p.Interface.Routes()
+	if !ok || routes == nil {
+		return routes, ok
+	}
+	return &recordingRoutes{provider: p, routes: routes}, true
+}
+
+type recordingLoadBalancer struct {
+	provider *RecordingCloudProvider
+	lb       cloudprovider.LoadBalancer
+}
+
+func (r *recordingLoadBalancer) GetLoadBalancer(ctx context.Context, clusterName string, service *v1.Service) (*v1.LoadBalancerStatus, bool, error) {
+	r.provider.Record("LoadBalancer.GetLoadBalancer")
+	return r.lb.GetLoadBalancer(ctx, clusterName, service)
+}
+
+func (r *recordingLoadBalancer) GetLoadBalancerName(ctx context.Context, clusterName string, service *v1.Service) string {
+	r.provider.Record("LoadBalancer.GetLoadBalancerName")
+	return r.lb.GetLoadBalancerName(ctx, clusterName, service)
+}
+
+func (r *recordingLoadBalancer) EnsureLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+	r.provider.Record("LoadBalancer.EnsureLoadBalancer")
+	return r.lb.EnsureLoadBalancer(ctx, clusterName, service, nodes)
+}
+
+func (r *recordingLoadBalancer) UpdateLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error {
+	r.provider.Record("LoadBalancer.UpdateLoadBalancer")
+	return r.lb.UpdateLoadBalancer(ctx, clusterName, service, nodes)
+}
+
+func (r *recordingLoadBalancer) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *v1.Service) error {
+	r.provider.Record("LoadBalancer.EnsureLoadBalancerDeleted")
+	return r.lb.EnsureLoadBalancerDeleted(ctx, clusterName, service)
+}
+
+type recordingInstances struct {
+	provider  *RecordingCloudProvider
+	instances cloudprovider.Instances
+}
+
+func (r *recordingInstances) NodeAddresses(ctx context.Context, name types.NodeName) ([]v1.NodeAddress, error) {
+	r.provider.Record("Instances.NodeAddresses")
+	return r.instances.NodeAddresses(ctx, name)
+}
+
+func (r *recordingInstances) NodeAddressesByProviderID(ctx context.Context, providerID string) ([]v1.NodeAddress, error) {
+	r.provider.Record("Instances.NodeAddressesByProviderID")
+	return r.instances.NodeAddressesByProviderID(ctx, providerID)
+}
+
+func (r *recordingInstances) InstanceID(ctx context.Context, nodeName types.NodeName) (string, error) {
+	r.provider.Record("Instances.InstanceID")
+	return r.instances.InstanceID(ctx, nodeName)
+}
+
+func (r *recordingInstances) InstanceType(ctx context.Context, name types.NodeName) (string, error) {
+	r.provider.Record("Instances.InstanceType")
+	return r.instances.InstanceType(ctx, name)
+}
+
+func (r *recordingInstances) InstanceTypeByProviderID(ctx context.Context, providerID string) (string, error) {
+	r.provider.Record("Instances.InstanceTypeByProviderID")
+	return r.instances.InstanceTypeByProviderID(ctx, providerID)
+}
+
+func (r *recordingInstances) AddSSHKeyToAllInstances(ctx context.Context, user string, keyData []byte) error {
+	r.provider.Record("Instances.AddSSHKeyToAllInstances")
+	return r.instances.AddSSHKeyToAllInstances(ctx, user, keyData)
+}
+
+func (r *recordingInstances) CurrentNodeName(ctx context.Context, hostname string) (types.NodeName, error) {
+	r.provider.Record("Instances.CurrentNodeName")
+	return r.instances.CurrentNodeName(ctx, hostname)
+}
+
+func (r *recordingInstances) InstanceExistsByProviderID(ctx context.Context, providerID string) (bool, error) {
+	r.provider.Record("Instances.InstanceExistsByProviderID")
+	return r.instances.InstanceExistsByProviderID(ctx, providerID)
+}
+
+func (r *recordingInstances) InstanceShutdownByProviderID(ctx context.Context, providerID string) (bool, error) {
+	r.provider.Record("Instances.InstanceShutdownByProviderID")
+	return r.instances.InstanceShutdownByProviderID(ctx, providerID)
+}
+
+type recordingInstancesV2 struct {
+	provider  *RecordingCloudProvider
+	instances cloudprovider.InstancesV2
+}
+
+func (r *recordingInstancesV2) InstanceExists(ctx context.Context, node *v1.Node) (bool, error) {
+	r.provider.Record("InstancesV2.InstanceExists")
+	return r.instances.InstanceExists(ctx, node)
+}
+
+func (r *recordingInstancesV2) InstanceShutdown(ctx context.Context, node *v1.Node) (bool, error) {
+	r.provider.Record("InstancesV2.InstanceShutdown")
+	return r.instances.InstanceShutdown(ctx, node)
+}
+
+func (r *recordingInstancesV2) InstanceMetadata(ctx context.Context, node *v1.Node) (*cloudprovider.InstanceMetadata, error) {
+	r.provider.Record("InstancesV2.InstanceMetadata")
+	return r.instances.InstanceMetadata(ctx, node)
+}
+
+type recordingZones struct {
+	provider *RecordingCloudProvider
+	zones    cloudprovider.Zones
+}
+
+func (r *recordingZones) GetZone(ctx context.Context) (cloudprovider.Zone, error) {
+	r.provider.Record("Zones.GetZone")
+	return r.zones.GetZone(ctx)
+}
+
+func (r *recordingZones) GetZoneByProviderID(ctx context.Context, providerID string) (cloudprovider.Zone, error) {
+	r.provider.Record("Zones.GetZoneByProviderID")
+	return r.zones.GetZoneByProviderID(ctx, providerID)
+}
+
+func (r *recordingZones) GetZoneByNodeName(ctx context.Context, nodeName types.NodeName) (cloudprovider.Zone, error) {
+	r.provider.Record("Zones.GetZoneByNodeName")
+	return r.zones.GetZoneByNodeName(ctx, nodeName)
+}
+
+type recordingClusters struct {
+	provider *RecordingCloudProvider
+	clusters cloudprovider.Clusters
+}
+
+func (r *recordingClusters) ListClusters(ctx context.Context) ([]string, error) {
+	r.provider.Record("Clusters.ListClusters")
+	return r.clusters.ListClusters(ctx)
+}
+
+func (r *recordingClusters) Master(ctx context.Context, clusterName string) (string, error) {
+	r.provider.Record("Clusters.Master")
+	return r.clusters.Master(ctx, clusterName)
+}
+
+type recordingRoutes struct {
+	provider *RecordingCloudProvider
+	routes   cloudprovider.Routes
+}
+
+func (r *recordingRoutes) ListRoutes(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error) {
+	r.provider.Record("Routes.ListRoutes")
+	return r.routes.ListRoutes(ctx, clusterName)
+}
+
+func (r *recordingRoutes) CreateRoute(ctx context.Context, clusterName string, nameHint string, route *cloudprovider.Route) error {
+	r.provider.Record("Routes.CreateRoute")
+	return r.routes.CreateRoute(ctx, clusterName, nameHint, route)
+}
+
+func (r *recordingRoutes) DeleteRoute(ctx context.Context, clusterName string, route *cloudprovider.Route) error {
+	r.provider.Record("Routes.DeleteRoute")
+	return r.routes.DeleteRoute(ctx, clusterName, route)
+}
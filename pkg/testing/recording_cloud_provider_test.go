@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// TestRecordingCloudProviderAssertCallOrder tests that recorded calls are
+// checked for their relative order, allowing other calls in between
+func TestRecordingCloudProviderAssertCallOrder(t *testing.T) {
+	ctx := context.Background()
+	provider := NewRecordingCloudProvider(NewMockCloudProvider())
+
+	lb, _ := provider.LoadBalancer()
+	routes, _ := provider.Routes()
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 80}}},
+	}
+	if _, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, nil); err != nil {
+		t.Fatalf("Failed to ensure load balancer: %v", err)
+	}
+	provider.Record("Service.UpdateStatus")
+	if _, err := routes.ListRoutes(ctx, "test-cluster"); err != nil {
+		t.Fatalf("Failed to list routes: %v", err)
+	}
+	if err := routes.DeleteRoute(ctx, "test-cluster", &cloudprovider.Route{DestinationCIDR: "10.244.0.0/24"}); err != nil {
+		t.Fatalf("Failed to delete route: %v", err)
+	}
+	provider.Record("Node.Delete")
+
+	if calls := formatCloudCalls(provider.Calls()); calls != "[LoadBalancer.EnsureLoadBalancer, Service.UpdateStatus, Routes.ListRoutes, Routes.DeleteRoute, Node.Delete]" {
+		t.Errorf("Unexpected recorded calls %s", calls)
+	}
+
+	for _, order := range [][]string{
+		{"EnsureLoadBalancer", "Service.UpdateStatus"},
+		{"LoadBalancer.EnsureLoadBalancer", "DeleteRoute", "Node.Delete"},
+		{"Routes.DeleteRoute"},
+	} {
+		if err := provider.AssertCallOrder(order...); err != nil {
+			t.Errorf("Expected order %v to hold, got %v", order, err)
+		}
+	}
+
+	tests := []struct {
+		order   []string
+		wantErr string
+	}{
+		{order: []string{"Service.UpdateStatus", "EnsureLoadBalancer"}, wantErr: "EnsureLoadBalancer was not called after Service.UpdateStatus"},
+		{order: []string{"Node.Delete", "DeleteRoute"}, wantErr: "DeleteRoute was not called after Node.Delete"},
+		{order: []string{"EnsureLoadBalancer", "CreateRoute"}, wantErr: "CreateRoute was never called"},
+		{order: []string{"EnsureLoadBalancer", "EnsureLoadBalancer"}, wantErr: "EnsureLoadBalancer was not called after EnsureLoadBalancer"},
+	}
+	for _, tt := range tests {
+		err := provider.AssertCallOrder(tt.order...)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Expected order %v to fail with '%s', got %v", tt.order, tt.wantErr, err)
+		}
+	}
+
+	provider.Reset()
+	if calls := provider.Calls(); len(calls) != 0 {
+		t.Errorf("Expected no calls after reset, got %v", calls)
+	}
+}
+
+// TestRecordingCloudProviderUnsupported tests that interfaces the wrapped
+// provider does not support stay unsupported
+func TestRecordingCloudProviderUnsupported(t *testing.T) {
+	provider := NewRecordingCloudProvider(&noLoadBalancerProvider{MockCloudProvider: NewMockCloudProvider()})
+
+	if lb, ok := provider.LoadBalancer(); ok || lb != nil {
+		t.Errorf("Expected no load balancer, got %v, %v", lb, ok)
+	}
+	if _, ok := provider.Zones(); !ok {
+		t.Error("Expected zones to stay supported")
+	}
+	if name := provider.ProviderName(); name != "mock-cloud-provider" {
+		t.Errorf("Expected provider name mock-cloud-provider, got %s", name)
+	}
+}
@@ -0,0 +1,444 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+func init() {
+	mustRegisterReportFormatter("text", &TextReportFormatter{})
+	mustRegisterReportFormatter("json", &JSONReportFormatter{})
+	mustRegisterReportFormatter("csv", &CSVReportFormatter{})
+	mustRegisterReportFormatter("tap", &TAPReportFormatter{})
+	mustRegisterReportFormatter("html", &HTMLReportFormatter{})
+}
+
+// mustRegisterReportFormatter registers a built-in formatter, panicking if the
+// name is already taken since that is a programming error.
+func mustRegisterReportFormatter(name string, formatter ccmtesting.ReportFormatter) {
+	if err := ccmtesting.RegisterReportFormatter(name, formatter); err != nil {
+		panic(err)
+	}
+}
+
+// TextReportFormatter writes a human-readable report with a per-suite summary,
+// and per-test results and logs in verbose mode.
+type TextReportFormatter struct {
+	details ccmtesting.RunDetails
+}
+
+// WithRunDetails returns a text formatter reporting the given run details.
+func (f *TextReportFormatter) WithRunDetails(details ccmtesting.RunDetails) ccmtesting.ReportFormatter {
+	return &TextReportFormatter{details: details}
+}
+
+// Format writes the text report to w.
+func (f *TextReportFormatter) Format(w io.Writer, results []ccmtesting.TestResult, summary ccmtesting.TestSummary) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\n=== CCM E2E Test Results ===\n")
+	fmt.Fprintf(&b, "Provider: %s (cluster ID: %v)\n", summary.ProviderName, summary.HasClusterID)
+	fmt.Fprintf(&b, "Total Duration: %v\n", runDuration(f.details, summary))
+	fmt.Fprintf(&b, "Test Summary: %d total, %d passed, %d failed, %d skipped\n",
+		summary.TotalTests, summary.PassedTests, summary.FailedTests, summary.SkippedTests)
+
+	if suites := ccmtesting.SummarizeSuites(results); len(suites) > 0 {
+		fmt.Fprintf(&b, "\nSuite Summary:\n")
+		for _, suite := range suites {
+			icon := "✅"
+			if suite.FailedTests > 0 {
+				icon = "❌"
+			}
+			fmt.Fprintf(&b, "  %s %s: %d total, %d passed, %d failed, %d skipped (%v)\n", icon, suite.Name,
+				suite.TotalTests, suite.PassedTests, suite.FailedTests, suite.SkippedTests, suite.TotalDuration)
+		}
+	}
+
+	if f.details.Verbose {
+		fmt.Fprintf(&b, "\nDetailed Results:\n")
+		for _, result := range results {
+			status := strings.ToUpper(resultStatus(result))
+			if result.Test.Skip {
+				fmt.Fprintf(&b, "  %s: %s (%v)\n", status, result.Test.Name, result.Duration)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s: %s (%v: setup %v, run %v, cleanup %v)%s\n", status, result.Test.Name,
+				result.Duration, result.SetupDuration, result.RunDuration, result.CleanupDuration, timeoutNote(result))
+		}
+
+		if len(f.details.Logs) > 0 {
+			fmt.Fprintf(&b, "\nTest Logs:\n")
+			for _, log := range f.details.Logs {
+				fmt.Fprintf(&b, "  %s\n", log)
+			}
+		}
+	}
+
+	if summary.FailedTests > 0 {
+		fmt.Fprintf(&b, "\n❌ Some tests failed: %d failed out of %d total\n", summary.FailedTests, summary.TotalTests)
+	} else {
+		fmt.Fprintf(&b, "\n✅ All tests passed: %d passed out of %d total\n", summary.PassedTests, summary.TotalTests)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// JSONReportFormatter writes the report as a single indented JSON document.
+type JSONReportFormatter struct {
+	details ccmtesting.RunDetails
+}
+
+// WithRunDetails returns a JSON formatter reporting the given run details.
+func (f *JSONReportFormatter) WithRunDetails(details ccmtesting.RunDetails) ccmtesting.ReportFormatter {
+	return &JSONReportFormatter{details: details}
+}
+
+// jsonSummary is the JSON form of a TestSummary.
+type jsonSummary struct {
+	Total    int    `json:"total"`
+	Passed   int    `json:"passed"`
+	Failed   int    `json:"failed"`
+	Skipped  int    `json:"skipped"`
+	Duration string `json:"duration"`
+}
+
+// jsonSuiteSummary is the JSON form of a SuiteSummary.
+type jsonSuiteSummary struct {
+	Name string `json:"name"`
+	jsonSummary
+}
+
+// jsonResult is the JSON form of a TestResult.
+type jsonResult struct {
+	Suite           string `json:"suite"`
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	Duration        string `json:"duration"`
+	SetupDuration   string `json:"setupDuration"`
+	RunDuration     string `json:"runDuration"`
+	CleanupDuration string `json:"cleanupDuration"`
+	Error           string `json:"error,omitempty"`
+	SkipReason      string `json:"skipReason,omitempty"`
+	TimedOut        bool   `json:"timedOut,omitempty"`
+	TimeoutSource   string `json:"timeoutSource,omitempty"`
+}
+
+// jsonReport is the document written by the json output format.
+type jsonReport struct {
+	Manifest     *ccmtesting.RunManifest `json:"manifest,omitempty"`
+	Provider     string                  `json:"provider"`
+	HasClusterID bool                    `json:"hasClusterID"`
+	Duration     string                  `json:"duration"`
+	Summary      jsonSummary             `json:"summary"`
+	Suites       []jsonSuiteSummary      `json:"suites"`
+	Results      []jsonResult            `json:"results"`
+}
+
+func newJSONSummary(summary ccmtesting.TestSummary) jsonSummary {
+	return jsonSummary{
+		Total:    summary.TotalTests,
+		Passed:   summary.PassedTests,
+		Failed:   summary.FailedTests,
+		Skipped:  summary.SkippedTests,
+		Duration: summary.TotalDuration.String(),
+	}
+}
+
+// Format writes the JSON report to w.
+func (f *JSONReportFormatter) Format(w io.Writer, results []ccmtesting.TestResult, summary ccmtesting.TestSummary) error {
+	suites := ccmtesting.SummarizeSuites(results)
+	report := jsonReport{
+		Manifest:     f.details.Manifest,
+		Provider:     summary.ProviderName,
+		HasClusterID: summary.HasClusterID,
+		Duration:     runDuration(f.details, summary).String(),
+		Summary:      newJSONSummary(summary),
+		Suites:       make([]jsonSuiteSummary, 0, len(suites)),
+		Results:      make([]jsonResult, 0, len(results)),
+	}
+
+	for _, suite := range suites {
+		report.Suites = append(report.Suites, jsonSuiteSummary{Name: suite.Name, jsonSummary: newJSONSummary(suite.TestSummary)})
+	}
+
+	for _, result := range results {
+		entry := jsonResult{
+			Suite:           result.Suite,
+			Name:            result.Test.Name,
+			Status:          resultStatus(result),
+			Duration:        result.Duration.String(),
+			SetupDuration:   result.SetupDuration.String(),
+			RunDuration:     result.RunDuration.String(),
+			CleanupDuration: result.CleanupDuration.String(),
+			SkipReason:      result.Test.SkipReason,
+			TimedOut:        result.TimedOut,
+			TimeoutSource:   result.TimeoutSource,
+		}
+		if result.Error != nil {
+			entry.Error = result.Error.Error()
+		}
+		report.Results = append(report.Results, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// CSVReportFormatter writes one row per test with the columns suite, test,
+// status, duration_ms and error, for importing results into spreadsheets.
+type CSVReportFormatter struct{}
+
+// Format writes the CSV report to w.
+func (f *CSVReportFormatter) Format(w io.Writer, results []ccmtesting.TestResult, summary ccmtesting.TestSummary) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"suite", "test", "status", "duration_ms", "error"}); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		errMessage := ""
+		if result.Error != nil {
+			errMessage = result.Error.Error()
+		}
+		row := []string{
+			result.Suite,
+			result.Test.Name,
+			resultStatus(result),
+			strconv.FormatInt(result.Duration.Milliseconds(), 10),
+			errMessage,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// TAPReportFormatter writes the report in the Test Anything Protocol, version
+// 13. Skipped tests carry a SKIP directive and failures a YAML diagnostic
+// block with the error.
+type TAPReportFormatter struct{}
+
+// Format writes the TAP report to w.
+func (f *TAPReportFormatter) Format(w io.Writer, results []ccmtesting.TestResult, summary ccmtesting.TestSummary) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+	for i, result := range results {
+		name := fmt.Sprintf("%s/%s", result.Suite, result.Test.Name)
+		switch resultStatus(result) {
+		case "skipped":
+			fmt.Fprintf(&b, "ok %d - %s # SKIP %s\n", i+1, name, tapEscape(result.Test.SkipReason))
+		case "failed":
+			fmt.Fprintf(&b, "not ok %d - %s\n", i+1, name)
+			message := "test failed"
+			if result.Error != nil {
+				message = result.Error.Error()
+			}
+			fmt.Fprintf(&b, "  ---\n")
+			fmt.Fprintf(&b, "  message: %s\n", strconv.Quote(message))
+			fmt.Fprintf(&b, "  severity: fail\n")
+			fmt.Fprintf(&b, "  duration_ms: %d\n", result.Duration.Milliseconds())
+			fmt.Fprintf(&b, "  ...\n")
+		default:
+			fmt.Fprintf(&b, "ok %d - %s\n", i+1, name)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tapEscape keeps a directive reason on a single line, as TAP requires.
+func tapEscape(reason string) string {
+	return strings.Join(strings.Fields(reason), " ")
+}
+
+// HTMLReportFormatter writes the report as a standalone HTML page with a
+// per-suite summary and a table of every test result.
+type HTMLReportFormatter struct {
+	details ccmtesting.RunDetails
+
+	// refresh, if set, makes the page reload itself at that interval
+	refresh time.Duration
+
+	// running are the tests still in progress, with their start times
+	running []ccmtesting.TestResult
+}
+
+// WithRunDetails returns an HTML formatter reporting the given run details.
+func (f *HTMLReportFormatter) WithRunDetails(details ccmtesting.RunDetails) ccmtesting.ReportFormatter {
+	return &HTMLReportFormatter{details: details, refresh: f.refresh, running: f.running}
+}
+
+// htmlResult is a test result as rendered in the HTML report.
+type htmlResult struct {
+	Suite    string
+	Name     string
+	Status   string
+	Duration time.Duration
+	Message  string
+}
+
+// htmlReport is the data the HTML report template renders.
+type htmlReport struct {
+	Refresh  int
+	Provider string
+	Duration time.Duration
+	Summary  ccmtesting.TestSummary
+	Suites   []ccmtesting.SuiteSummary
+	Running  []htmlResult
+	Results  []htmlResult
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+{{- if .Refresh}}
+<meta http-equiv="refresh" content="{{.Refresh}}">
+{{- end}}
+<title>CCM E2E Test Results</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+.skipped, .running { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>CCM E2E Test Results</h1>
+<p>Provider: {{.Provider}}, duration: {{.Duration}}</p>
+<p>Test Summary: {{.Summary.TotalTests}} total, {{.Summary.PassedTests}} passed, {{.Summary.FailedTests}} failed, {{.Summary.SkippedTests}} skipped{{if .Running}}, {{len .Running}} running{{end}}</p>
+{{- if .Running}}
+<h2>In Progress</h2>
+<table>
+<tr><th>Suite</th><th>Test</th><th>Status</th><th>Elapsed</th></tr>
+{{- range .Running}}
+<tr><td>{{.Suite}}</td><td>{{.Name}}</td><td class="running">{{.Status}}</td><td>{{.Duration}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Suites}}
+<h2>Suite Summary</h2>
+<table>
+<tr><th>Suite</th><th>Total</th><th>Passed</th><th>Failed</th><th>Skipped</th><th>Duration</th></tr>
+{{- range .Suites}}
+<tr><td>{{.Name}}</td><td>{{.TotalTests}}</td><td>{{.PassedTests}}</td><td>{{.FailedTests}}</td><td>{{.SkippedTests}}</td><td>{{.TotalDuration}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Results}}
+<h2>Results</h2>
+<table>
+<tr><th>Suite</th><th>Test</th><th>Status</th><th>Duration</th><th>Details</th></tr>
+{{- range .Results}}
+<tr><td>{{.Suite}}</td><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Duration}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// Format writes the HTML report to w.
+func (f *HTMLReportFormatter) Format(w io.Writer, results []ccmtesting.TestResult, summary ccmtesting.TestSummary) error {
+	report := htmlReport{
+		Refresh:  int(f.refresh.Seconds()),
+		Provider: summary.ProviderName,
+		Duration: runDuration(f.details, summary),
+		Summary:  summary,
+		Suites:   ccmtesting.SummarizeSuites(results),
+	}
+
+	now := time.Now()
+	for _, test := range f.running {
+		report.Running = append(report.Running, htmlResult{
+			Suite:    test.Suite,
+			Name:     test.Test.Name,
+			Status:   "running",
+			Duration: now.Sub(test.StartTime).Round(time.Second),
+		})
+	}
+
+	for _, result := range results {
+		entry := htmlResult{
+			Suite:    result.Suite,
+			Name:     result.Test.Name,
+			Status:   resultStatus(result),
+			Duration: result.Duration,
+			Message:  result.Test.SkipReason,
+		}
+		if result.Error != nil {
+			entry.Message = result.Error.Error() + timeoutNote(result)
+		}
+		report.Results = append(report.Results, entry)
+	}
+
+	return htmlReportTemplate.Execute(w, report)
+}
+
+// resultStatus returns "passed", "failed" or "skipped" for a test result.
+func resultStatus(result ccmtesting.TestResult) string {
+	switch {
+	case result.Test.Skip:
+		return "skipped"
+	case !result.Success:
+		return "failed"
+	default:
+		return "passed"
+	}
+}
+
+// timeoutNote describes which deadline a timed out test hit, or returns an
+// empty string if it did not time out.
+func timeoutNote(result ccmtesting.TestResult) string {
+	switch {
+	case !result.TimedOut:
+		return ""
+	case result.TimeoutSource == ccmtesting.TimeoutSourceTest:
+		return fmt.Sprintf(" [timed out: test timeout of %v]", result.Test.Timeout)
+	default:
+		return " [timed out: run deadline]"
+	}
+}
+
+// runDuration returns the wall-clock duration of the run, falling back to the
+// summed test durations when the caller did not provide it.
+func runDuration(details ccmtesting.RunDetails, summary ccmtesting.TestSummary) time.Duration {
+	if details.Duration > 0 {
+		return details.Duration
+	}
+	return summary.TotalDuration
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// reportResults returns one passed, one failed and one skipped test result
+func reportResults() ([]ccmtesting.TestResult, ccmtesting.TestSummary) {
+	results := []ccmtesting.TestResult{
+		{Suite: "Nodes", Test: ccmtesting.Test{Name: "NodeAddresses"}, Success: true, Duration: time.Second,
+			SetupDuration: 100 * time.Millisecond, RunDuration: 800 * time.Millisecond, CleanupDuration: 100 * time.Millisecond},
+		{Suite: "Nodes", Test: ccmtesting.Test{Name: "NodeZones"}, Error: errors.New("zone mismatch"), Duration: time.Second,
+			RunDuration: time.Second},
+		{Suite: "Routes", Test: ccmtesting.Test{Name: "CreateRoute", Skip: true, SkipReason: "routes unsupported"}, Success: true},
+	}
+	summary := ccmtesting.TestSummary{
+		TotalTests:    3,
+		PassedTests:   1,
+		FailedTests:   1,
+		SkippedTests:  1,
+		TotalDuration: 2 * time.Second,
+		ProviderName:  "mock-cloud-provider",
+		HasClusterID:  true,
+	}
+	return results, summary
+}
+
+// TestBuiltinReportFormattersRegistered tests that the built-in formats are available
+func TestBuiltinReportFormattersRegistered(t *testing.T) {
+	for _, format := range []string{"text", "json", "csv", "tap", "html"} {
+		if _, found := ccmtesting.GetReportFormatter(format); !found {
+			t.Errorf("Expected %s formatter to be registered", format)
+		}
+	}
+}
+
+// TestTextReportFormatter tests the text report with and without verbose details
+func TestTextReportFormatter(t *testing.T) {
+	results, summary := reportResults()
+
+	var buf bytes.Buffer
+	if err := ccmtesting.FormatReport(&buf, "text", results, summary, ccmtesting.RunDetails{Duration: 5 * time.Second}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report := buf.String()
+	for _, want := range []string{
+		"Provider: mock-cloud-provider (cluster ID: true)",
+		"Total Duration: 5s",
+		"Test Summary: 3 total, 1 passed, 1 failed, 1 skipped",
+		"❌ Nodes: 2 total, 1 passed, 1 failed, 0 skipped",
+		"✅ Routes: 1 total, 0 passed, 0 failed, 1 skipped",
+		"Some tests failed: 1 failed out of 3 total",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain '%s', got:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "Detailed Results") {
+		t.Error("Expected no detailed results without verbose")
+	}
+
+	buf.Reset()
+	details := ccmtesting.RunDetails{Verbose: true, Logs: []string{"Created test node: node-1"}}
+	if err := ccmtesting.FormatReport(&buf, "text", results, summary, details); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report = buf.String()
+	for _, want := range []string{
+		"PASSED: NodeAddresses (1s: setup 100ms, run 800ms, cleanup 100ms)",
+		"FAILED: NodeZones (1s: setup 0s, run 1s, cleanup 0s)",
+		"SKIPPED: CreateRoute (0s)",
+		"Created test node: node-1",
+		"Total Duration: 2s",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected verbose report to contain '%s', got:\n%s", want, report)
+		}
+	}
+}
+
+// TestJSONReportFormatter tests that the json report decodes with the expected fields
+func TestJSONReportFormatter(t *testing.T) {
+	results, summary := reportResults()
+
+	var buf bytes.Buffer
+	if err := ccmtesting.FormatReport(&buf, "json", results, summary, ccmtesting.RunDetails{Duration: 5 * time.Second}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	if report.Provider != "mock-cloud-provider" || !report.HasClusterID || report.Duration != "5s" {
+		t.Errorf("Expected provider info and 5s duration, got %+v", report)
+	}
+	if report.Summary.Total != 3 || report.Summary.Failed != 1 {
+		t.Errorf("Expected 3 total and 1 failed, got %+v", report.Summary)
+	}
+	if len(report.Suites) != 2 {
+		t.Errorf("Expected 2 suites, got %d", len(report.Suites))
+	}
+
+	expected := []jsonResult{
+		{Suite: "Nodes", Name: "NodeAddresses", Status: "passed", Duration: "1s", SetupDuration: "100ms", RunDuration: "800ms", CleanupDuration: "100ms"},
+		{Suite: "Nodes", Name: "NodeZones", Status: "failed", Duration: "1s", SetupDuration: "0s", RunDuration: "1s", CleanupDuration: "0s", Error: "zone mismatch"},
+		{Suite: "Routes", Name: "CreateRoute", Status: "skipped", Duration: "0s", SetupDuration: "0s", RunDuration: "0s", CleanupDuration: "0s", SkipReason: "routes unsupported"},
+	}
+	if len(report.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(report.Results))
+	}
+	for i := range expected {
+		if report.Results[i] != expected[i] {
+			t.Errorf("Expected result %+v, got %+v", expected[i], report.Results[i])
+		}
+	}
+}
+
+// TestJSONReportFormatterManifest tests that the run manifest leads the json
+// report and carries the provider with credentials redacted
+func TestJSONReportFormatterManifest(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+	ti.GetConfig().TestData["secret"] = "hunter2"
+	runner := ccmtesting.NewTestRunner(ti)
+	manifest := runner.GetRunManifest()
+
+	results, summary := reportResults()
+	var buf bytes.Buffer
+	if err := ccmtesting.FormatReport(&buf, "json", results, summary, ccmtesting.RunDetails{Manifest: &manifest}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "{\n  \"manifest\": {") {
+		t.Errorf("Expected the report to start with the manifest, got %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("Expected the secret to be redacted, got %s", buf.String())
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if report.Manifest == nil {
+		t.Fatalf("Expected a manifest")
+	}
+	if report.Manifest.Provider != "mock-cloud-provider" {
+		t.Errorf("Expected provider mock-cloud-provider, got %s", report.Manifest.Provider)
+	}
+	if report.Manifest.Config == nil || report.Manifest.Config.TestData["secret"] != ccmtesting.RedactedValue {
+		t.Errorf("Expected secret redacted to %s, got %+v", ccmtesting.RedactedValue, report.Manifest.Config)
+	}
+}
+
+// TestCSVReportFormatter tests that the csv report parses back into one row per
+// test, with errors containing commas and newlines quoted correctly
+func TestCSVReportFormatter(t *testing.T) {
+	results, summary := reportResults()
+	results[1].Error = errors.New("zone mismatch: got \"zone-a, zone-b\"\nexpected zone-c")
+	results[0].Duration = 1500 * time.Millisecond
+
+	var buf bytes.Buffer
+	if err := ccmtesting.FormatReport(&buf, "csv", results, summary, ccmtesting.RunDetails{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got %v", err)
+	}
+
+	expected := [][]string{
+		{"suite", "test", "status", "duration_ms", "error"},
+		{"Nodes", "NodeAddresses", "passed", "1500", ""},
+		{"Nodes", "NodeZones", "failed", "1000", "zone mismatch: got \"zone-a, zone-b\"\nexpected zone-c"},
+		{"Routes", "CreateRoute", "skipped", "0", ""},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(expected), len(rows), rows)
+	}
+	for i := range expected {
+		if strings.Join(rows[i], "|") != strings.Join(expected[i], "|") {
+			t.Errorf("Expected row %d to be %q, got %q", i, expected[i], rows[i])
+		}
+	}
+}
+
+// TestTAPReportFormatter tests that the tap plan matches the number of results,
+// skips carry a SKIP directive and failures report their error
+func TestTAPReportFormatter(t *testing.T) {
+	results, summary := reportResults()
+
+	var buf bytes.Buffer
+	if err := ccmtesting.FormatReport(&buf, "tap", results, summary, ccmtesting.RunDetails{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "TAP version 13" {
+		t.Errorf("Expected TAP version line, got '%s'", lines[0])
+	}
+	if lines[1] != "1..3" {
+		t.Errorf("Expected plan '1..3', got '%s'", lines[1])
+	}
+
+	testLines := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "ok ") || strings.HasPrefix(line, "not ok ") {
+			testLines++
+		}
+	}
+	if testLines != len(results) {
+		t.Errorf("Expected %d test lines, got %d", len(results), testLines)
+	}
+
+	for _, want := range []string{
+		"ok 1 - Nodes/NodeAddresses",
+		"not ok 2 - Nodes/NodeZones",
+		"  message: \"zone mismatch\"",
+		"ok 3 - Routes/CreateRoute # SKIP routes unsupported",
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("Expected report to contain '%s', got:\n%s", want, buf.String())
+		}
+	}
+}
+
+// TestHTMLReportFormatter tests the HTML report tables and escaping
+func TestHTMLReportFormatter(t *testing.T) {
+	results, summary := reportResults()
+	results[1].Error = errors.New("zone <us-east-1a> mismatch")
+
+	var buf bytes.Buffer
+	if err := ccmtesting.FormatReport(&buf, "html", results, summary, ccmtesting.RunDetails{Duration: time.Minute}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report := buf.String()
+	for _, want := range []string{
+		"<p>Provider: mock-cloud-provider, duration: 1m0s</p>",
+		"<p>Test Summary: 3 total, 1 passed, 1 failed, 1 skipped</p>",
+		"<tr><td>Nodes</td><td>2</td><td>1</td><td>1</td><td>0</td><td>2s</td></tr>",
+		`<tr><td>Nodes</td><td>NodeAddresses</td><td class="passed">passed</td><td>1s</td><td></td></tr>`,
+		`<tr><td>Nodes</td><td>NodeZones</td><td class="failed">failed</td><td>1s</td><td>zone &lt;us-east-1a&gt; mismatch</td></tr>`,
+		`<tr><td>Routes</td><td>CreateRoute</td><td class="skipped">skipped</td><td>0s</td><td>routes unsupported</td></tr>`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain '%s', got:\n%s", want, report)
+		}
+	}
+	for _, unwanted := range []string{"http-equiv=\"refresh\"", "In Progress"} {
+		if strings.Contains(report, unwanted) {
+			t.Errorf("Expected the final report not to contain '%s', got:\n%s", unwanted, report)
+		}
+	}
+}
+
+// TestReportTimeoutSource tests that reports tell a test's own timeout from
+// the run deadline
+func TestReportTimeoutSource(t *testing.T) {
+	results := []ccmtesting.TestResult{
+		{Suite: "Nodes", Test: ccmtesting.Test{Name: "SlowNode", Timeout: 2 * time.Minute},
+			Error: context.DeadlineExceeded, TimedOut: true, TimeoutSource: ccmtesting.TimeoutSourceTest},
+		{Suite: "Nodes", Test: ccmtesting.Test{Name: "CutShort", Timeout: 5 * time.Minute},
+			Error: context.DeadlineExceeded, TimedOut: true, TimeoutSource: ccmtesting.TimeoutSourceRun},
+	}
+	summary := ccmtesting.TestSummary{TotalTests: 2, FailedTests: 2}
+
+	var text bytes.Buffer
+	if err := ccmtesting.FormatReport(&text, "text", results, summary, ccmtesting.RunDetails{Verbose: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"FAILED: SlowNode (0s: setup 0s, run 0s, cleanup 0s) [timed out: test timeout of 2m0s]",
+		"FAILED: CutShort (0s: setup 0s, run 0s, cleanup 0s) [timed out: run deadline]",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected text report to contain '%s', got:\n%s", want, text.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ccmtesting.FormatReport(&buf, "json", results, summary, ccmtesting.RunDetails{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	for i, want := range []string{"test", "run"} {
+		if !report.Results[i].TimedOut || report.Results[i].TimeoutSource != want {
+			t.Errorf("Expected result %d to time out from %q, got %+v", i, want, report.Results[i])
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// GitSHAEnvVar is the environment variable NewRunRecord reads the git sha of
+// the code under test from.
+const GitSHAEnvVar = "GIT_SHA"
+
+// RunRecord is the summary of a single test run as persisted in a ResultStore.
+type RunRecord struct {
+	// Timestamp is when the run started.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Provider is the cloud provider the run was executed against.
+	Provider string `json:"provider"`
+
+	// GitSHA is the git sha of the code under test, if known.
+	GitSHA string `json:"gitSha,omitempty"`
+
+	// TotalTests is the total number of tests run.
+	TotalTests int `json:"totalTests"`
+
+	// PassedTests is the number of tests that passed.
+	PassedTests int `json:"passedTests"`
+
+	// FailedTests is the number of tests that failed.
+	FailedTests int `json:"failedTests"`
+
+	// SkippedTests is the number of tests that were skipped.
+	SkippedTests int `json:"skippedTests"`
+}
+
+// NewRunRecord creates a RunRecord from a run summary, taking the git sha
+// from the GitSHAEnvVar environment variable.
+func NewRunRecord(provider string, summary ccmtesting.TestSummary, timestamp time.Time) RunRecord {
+	return RunRecord{
+		Timestamp:    timestamp,
+		Provider:     provider,
+		GitSHA:       os.Getenv(GitSHAEnvVar),
+		TotalTests:   summary.TotalTests,
+		PassedTests:  summary.PassedTests,
+		FailedTests:  summary.FailedTests,
+		SkippedTests: summary.SkippedTests,
+	}
+}
+
+// ResultStore is an append-only log of test runs used to track conformance
+// over time.
+type ResultStore interface {
+	// Append adds a run to the store.
+	Append(record RunRecord) error
+
+	// Load returns all runs in the store in the order they were appended.
+	Load() ([]RunRecord, error)
+}
+
+// JSONLResultStore is a ResultStore that writes one JSON object per line to a file.
+type JSONLResultStore struct {
+	path string
+}
+
+// NewJSONLResultStore creates a JSONLResultStore backed by the file at path.
+// The file is created on the first Append.
+func NewJSONLResultStore(path string) *JSONLResultStore {
+	return &JSONLResultStore{path: path}
+}
+
+// Append adds a run to the end of the store file.
+func (s *JSONLResultStore) Append(record RunRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode run record: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open results store %s: %w", s.path, err)
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write to results store %s: %w", s.path, err)
+	}
+
+	return f.Close()
+}
+
+// Load returns all runs in the store file. A missing file is an empty store.
+func (s *JSONLResultStore) Load() ([]RunRecord, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results store %s: %w", s.path, err)
+	}
+	defer f.Close()
+
+	records, err := ReadRunRecords(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results store %s: %w", s.path, err)
+	}
+
+	return records, nil
+}
+
+// ReadRunRecords decodes JSONL run records from r for trend queries. Blank
+// lines are ignored and malformed lines are skipped with a warning so that a
+// single bad write does not hide the rest of the history.
+func ReadRunRecords(r io.Reader) ([]RunRecord, error) {
+	var records []RunRecord
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record RunRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			klog.Warningf("Skipping malformed run record on line %d: %v", lineNumber, err)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// TestJSONLResultStore tests that runs appended to the store are read back in order
+func TestJSONLResultStore(t *testing.T) {
+	t.Setenv(GitSHAEnvVar, "abc123")
+
+	store := NewJSONLResultStore(filepath.Join(t.TempDir(), "results.jsonl"))
+
+	records, err := store.Load()
+	if err != nil {
+		t.Fatalf("Expected no error loading a missing store, got %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Expected empty store, got %d records", len(records))
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		summary := ccmtesting.TestSummary{TotalTests: 10, PassedTests: 10 - i, FailedTests: i}
+		if err := store.Append(NewRunRecord("mock", summary, start.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatalf("Expected no error appending run %d, got %v", i, err)
+		}
+	}
+
+	records, err = store.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	for i, record := range records {
+		if !record.Timestamp.Equal(start.Add(time.Duration(i) * time.Hour)) {
+			t.Errorf("Expected record %d to be in append order, got timestamp %v", i, record.Timestamp)
+		}
+		if record.FailedTests != i {
+			t.Errorf("Expected record %d to have %d failed tests, got %d", i, i, record.FailedTests)
+		}
+		if record.Provider != "mock" {
+			t.Errorf("Expected provider mock, got %s", record.Provider)
+		}
+		if record.GitSHA != "abc123" {
+			t.Errorf("Expected git sha abc123, got %s", record.GitSHA)
+		}
+	}
+}
+
+// TestJSONLResultStoreSkipsMalformedLines tests that malformed lines do not hide valid records
+func TestJSONLResultStoreSkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	store := NewJSONLResultStore(path)
+
+	if err := store.Append(RunRecord{Provider: "first", TotalTests: 1}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	if _, err := f.WriteString("{\"provider\": \"truncated\n\nnot json\n"); err != nil {
+		t.Fatalf("Failed to write malformed lines: %v", err)
+	}
+	f.Close()
+
+	if err := store.Append(RunRecord{Provider: "second", TotalTests: 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records, err := store.Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Provider != "first" || records[1].Provider != "second" {
+		t.Errorf("Expected records first and second, got %s and %s", records[0].Provider, records[1].Provider)
+	}
+}
+
+// TestReadRunRecords tests decoding run records from a reader
+func TestReadRunRecords(t *testing.T) {
+	input := `{"provider":"aws","totalTests":5,"passedTests":5}
+garbage
+{"provider":"gcp","totalTests":4,"failedTests":1}
+`
+	records, err := ReadRunRecords(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[1].Provider != "gcp" || records[1].FailedTests != 1 {
+		t.Errorf("Expected gcp record with 1 failure, got %+v", records[1])
+	}
+}
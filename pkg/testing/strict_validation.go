@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"net"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// The validation below is the subset of the API server's core/v1 validation
+// that test services and nodes can violate. The fake clientset performs no
+// validation, so CCMTestInterface applies it itself in strict validation mode.
+
+var (
+	supportedServiceTypes = []v1.ServiceType{
+		v1.ServiceTypeClusterIP,
+		v1.ServiceTypeNodePort,
+		v1.ServiceTypeLoadBalancer,
+		v1.ServiceTypeExternalName,
+	}
+	supportedPortProtocols = []v1.Protocol{
+		v1.ProtocolTCP,
+		v1.ProtocolUDP,
+		v1.ProtocolSCTP,
+	}
+	supportedExternalTrafficPolicies = []v1.ServiceExternalTrafficPolicy{
+		v1.ServiceExternalTrafficPolicyCluster,
+		v1.ServiceExternalTrafficPolicyLocal,
+	}
+	supportedInternalTrafficPolicies = []v1.ServiceInternalTrafficPolicy{
+		v1.ServiceInternalTrafficPolicyCluster,
+		v1.ServiceInternalTrafficPolicyLocal,
+	}
+	supportedNodeAddressTypes = []v1.NodeAddressType{
+		v1.NodeHostName,
+		v1.NodeInternalIP,
+		v1.NodeExternalIP,
+		v1.NodeInternalDNS,
+		v1.NodeExternalDNS,
+	}
+)
+
+// validateService returns the Invalid error the API server would return for
+// the service, or nil if the service is valid.
+func validateService(service *v1.Service) error {
+	allErrs := apivalidation.ValidateObjectMeta(&service.ObjectMeta, true, apivalidation.NameIsDNS1035Label, field.NewPath("metadata"))
+
+	specPath := field.NewPath("spec")
+	spec := &service.Spec
+
+	serviceType := spec.Type
+	if serviceType == "" {
+		serviceType = v1.ServiceTypeClusterIP
+	}
+	if !sets.New(supportedServiceTypes...).Has(serviceType) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("type"), serviceType, supportedServiceTypes))
+	}
+	external := serviceType == v1.ServiceTypeNodePort || serviceType == v1.ServiceTypeLoadBalancer
+
+	portsPath := specPath.Child("ports")
+	if serviceType != v1.ServiceTypeExternalName && len(spec.Ports) == 0 {
+		allErrs = append(allErrs, field.Required(portsPath, ""))
+	}
+
+	portNames := sets.New[string]()
+	portKeys := sets.New[string]()
+	for i, port := range spec.Ports {
+		idxPath := portsPath.Index(i)
+
+		if port.Name == "" {
+			if len(spec.Ports) > 1 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+			}
+		} else {
+			for _, msg := range validation.IsDNS1123Label(port.Name) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), port.Name, msg))
+			}
+			if portNames.Has(port.Name) {
+				allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), port.Name))
+			}
+			portNames.Insert(port.Name)
+		}
+
+		for _, msg := range validation.IsValidPortNum(int(port.Port)) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), port.Port, msg))
+		}
+
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = v1.ProtocolTCP
+		}
+		if !sets.New(supportedPortProtocols...).Has(protocol) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("protocol"), protocol, supportedPortProtocols))
+		}
+
+		key := fmt.Sprintf("%d/%s", port.Port, protocol)
+		if portKeys.Has(key) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, key))
+		}
+		portKeys.Insert(key)
+
+		if port.NodePort != 0 {
+			if !external {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("nodePort"), fmt.Sprintf("may not be used when `type` is '%s'", serviceType)))
+			}
+			for _, msg := range validation.IsValidPortNum(int(port.NodePort)) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("nodePort"), port.NodePort, msg))
+			}
+		}
+	}
+
+	if spec.LoadBalancerIP != "" {
+		if serviceType != v1.ServiceTypeLoadBalancer {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("loadBalancerIP"), "may only be used when `type` is 'LoadBalancer'"))
+		} else {
+			allErrs = append(allErrs, validation.IsValidIP(specPath.Child("loadBalancerIP"), spec.LoadBalancerIP)...)
+		}
+	}
+
+	if spec.AllocateLoadBalancerNodePorts != nil && serviceType != v1.ServiceTypeLoadBalancer {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("allocateLoadBalancerNodePorts"), "may only be used when `type` is 'LoadBalancer'"))
+	}
+
+	if spec.ExternalTrafficPolicy != "" {
+		if !external {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("externalTrafficPolicy"), "may only be set for externally-accessible services"))
+		} else if !sets.New(supportedExternalTrafficPolicies...).Has(spec.ExternalTrafficPolicy) {
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("externalTrafficPolicy"), spec.ExternalTrafficPolicy, supportedExternalTrafficPolicies))
+		}
+	}
+
+	if spec.InternalTrafficPolicy != nil && !sets.New(supportedInternalTrafficPolicies...).Has(*spec.InternalTrafficPolicy) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("internalTrafficPolicy"), *spec.InternalTrafficPolicy, supportedInternalTrafficPolicies))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(schema.GroupKind{Kind: "Service"}, service.Name, allErrs)
+	}
+	return nil
+}
+
+// validateNode returns the Invalid error the API server would return for the
+// node, or nil if the node is valid.
+func validateNode(node *v1.Node) error {
+	allErrs := apivalidation.ValidateObjectMeta(&node.ObjectMeta, false, apivalidation.NameIsDNSSubdomain, field.NewPath("metadata"))
+
+	addressesPath := field.NewPath("status", "addresses")
+	for i, address := range node.Status.Addresses {
+		idxPath := addressesPath.Index(i)
+		if !sets.New(supportedNodeAddressTypes...).Has(address.Type) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("type"), address.Type, supportedNodeAddressTypes))
+		}
+		if address.Address == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("address"), ""))
+		}
+	}
+
+	podCIDRsPath := field.NewPath("spec", "podCIDRs")
+	for i, podCIDR := range node.Spec.PodCIDRs {
+		if _, _, err := net.ParseCIDR(podCIDR); err != nil {
+			allErrs = append(allErrs, field.Invalid(podCIDRsPath.Index(i), podCIDR, "must be a valid CIDR"))
+		}
+	}
+	if len(node.Spec.PodCIDRs) > 0 && node.Spec.PodCIDR != node.Spec.PodCIDRs[0] {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "podCIDR"), node.Spec.PodCIDR, "must match the first entry of podCIDRs"))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(schema.GroupKind{Kind: "Node"}, node.Name, allErrs)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// TestValidateService tests the API server validation applied to services in strict mode
+func TestValidateService(t *testing.T) {
+	tcp80 := v1.ServicePort{Name: "http", Port: 80, Protocol: v1.ProtocolTCP}
+	invalidPolicy := v1.ServiceInternalTrafficPolicy("Nearby")
+	noNodePorts := false
+
+	tests := []struct {
+		name    string
+		mutate  func(*v1.Service)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mutate: func(s *v1.Service) {},
+		},
+		{
+			name:    "invalid name",
+			mutate:  func(s *v1.Service) { s.Name = "Not_A_Label" },
+			wantErr: "metadata.name",
+		},
+		{
+			name:    "missing namespace",
+			mutate:  func(s *v1.Service) { s.Namespace = "" },
+			wantErr: "metadata.namespace",
+		},
+		{
+			name:    "no ports",
+			mutate:  func(s *v1.Service) { s.Spec.Ports = nil },
+			wantErr: "spec.ports: Required value",
+		},
+		{
+			name: "unnamed ports",
+			mutate: func(s *v1.Service) {
+				s.Spec.Ports = []v1.ServicePort{{Port: 80}, {Port: 443}}
+			},
+			wantErr: "spec.ports[0].name: Required value",
+		},
+		{
+			name: "duplicate ports",
+			mutate: func(s *v1.Service) {
+				s.Spec.Ports = []v1.ServicePort{tcp80, {Name: "other", Port: 80, Protocol: v1.ProtocolTCP}}
+			},
+			wantErr: "spec.ports[1]: Duplicate value",
+		},
+		{
+			name:    "port out of range",
+			mutate:  func(s *v1.Service) { s.Spec.Ports[0].Port = 70000 },
+			wantErr: "spec.ports[0].port",
+		},
+		{
+			name:    "node port on ClusterIP",
+			mutate:  func(s *v1.Service) { s.Spec.Ports[0].NodePort = 30080 },
+			wantErr: "spec.ports[0].nodePort: Forbidden",
+		},
+		{
+			name: "invalid load balancer IP",
+			mutate: func(s *v1.Service) {
+				s.Spec.Type = v1.ServiceTypeLoadBalancer
+				s.Spec.LoadBalancerIP = "not-an-ip"
+			},
+			wantErr: "spec.loadBalancerIP",
+		},
+		{
+			name:    "allocateLoadBalancerNodePorts on ClusterIP",
+			mutate:  func(s *v1.Service) { s.Spec.AllocateLoadBalancerNodePorts = &noNodePorts },
+			wantErr: "spec.allocateLoadBalancerNodePorts: Forbidden",
+		},
+		{
+			name:    "unsupported internal traffic policy",
+			mutate:  func(s *v1.Service) { s.Spec.InternalTrafficPolicy = &invalidPolicy },
+			wantErr: "spec.internalTrafficPolicy: Unsupported value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "valid-service", Namespace: "default"},
+				Spec: v1.ServiceSpec{
+					Type:  v1.ServiceTypeClusterIP,
+					Ports: []v1.ServicePort{tcp80},
+				},
+			}
+			tt.mutate(service)
+
+			err := validateService(service)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if !apierrors.IsInvalid(err) {
+				t.Fatalf("Expected an Invalid error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error to contain '%s', got '%v'", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestCCMTestInterfaceStrictValidation tests that an invalid service is rejected in
+// strict mode and accepted by the fake clientset otherwise
+func TestCCMTestInterfaceStrictValidation(t *testing.T) {
+	invalidService := &ccmtesting.TestServiceConfig{
+		Name:      "unnamed-ports",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports: []v1.ServicePort{
+			{Port: 80, Protocol: v1.ProtocolTCP},
+			{Port: 443, Protocol: v1.ProtocolTCP},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		strict bool
+	}{
+		{name: "strict", strict: true},
+		{name: "lenient", strict: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti := NewCCMTestInterface(NewMockCloudProvider())
+			if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", StrictValidation: tt.strict}); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+
+			ctx := context.Background()
+			_, err := ti.CreateTestService(ctx, invalidService)
+			_, getErr := ti.GetKubeClient().CoreV1().Services("default").Get(ctx, "unnamed-ports", metav1.GetOptions{})
+
+			if tt.strict {
+				if !apierrors.IsInvalid(err) {
+					t.Fatalf("Expected an Invalid error in strict mode, got %v", err)
+				}
+				if getErr == nil {
+					t.Error("Expected the invalid service not to be created")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected the fake clientset to accept the service, got %v", err)
+			}
+			if getErr != nil {
+				t.Errorf("Expected the service to be created: %v", getErr)
+			}
+		})
+	}
+
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", StrictValidation: true}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+	_, err := ti.CreateTestNode(context.Background(), &ccmtesting.TestNodeConfig{
+		Name:      "strict-node",
+		Addresses: []v1.NodeAddress{{Type: "PrivateIP", Address: "10.0.0.1"}},
+	})
+	if !apierrors.IsInvalid(err) {
+		t.Errorf("Expected an Invalid error for an unknown node address type, got %v", err)
+	}
+}
+
+// TestStrictValidationNodePodCIDRs tests that strict validation rejects nodes
+// with malformed pod CIDRs
+func TestStrictValidationNodePodCIDRs(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", StrictValidation: true}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	_, err := ti.CreateTestNode(context.Background(), &ccmtesting.TestNodeConfig{
+		Name:     "strict-cidr-node",
+		PodCIDRs: []string{"10.244.1.0/24", "10.244.2.0"},
+	})
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.podCIDRs[1]") {
+		t.Errorf("Expected an Invalid error for spec.podCIDRs[1], got %v", err)
+	}
+
+	if _, err := ti.CreateTestNode(context.Background(), &ccmtesting.TestNodeConfig{
+		Name:     "valid-cidr-node",
+		PodCIDRs: []string{"10.244.1.0/24", "fd00:10:244:1::/64"},
+	}); err != nil {
+		t.Errorf("Expected dual-stack pod CIDRs to be accepted, got %v", err)
+	}
+}
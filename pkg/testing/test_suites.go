@@ -18,14 +18,25 @@ package testing
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	cloudprovider "k8s.io/cloud-provider"
+	cloudproviderapi "k8s.io/cloud-provider/api"
 
 	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
 )
@@ -33,10 +44,11 @@ import (
 // CreateLoadBalancerTestSuite creates a test suite for load balancer functionality.
 func CreateLoadBalancerTestSuite() ccmtesting.TestSuite {
 	return ccmtesting.TestSuite{
-		Name:        "LoadBalancer",
-		Description: "Tests for cloud provider load balancer functionality",
-		Setup:       setupLoadBalancerTestSuite,
-		Teardown:    teardownLoadBalancerTestSuite,
+		Name:                 "LoadBalancer",
+		Description:          "Tests for cloud provider load balancer functionality",
+		RequiredCapabilities: []string{CapabilityLoadBalancer},
+		Setup:                setupLoadBalancerTestSuite,
+		Teardown:             teardownLoadBalancerTestSuite,
 		Tests: []ccmtesting.Test{
 			{
 				Name:        "CreateLoadBalancer",
@@ -68,6 +80,91 @@ func CreateLoadBalancerTestSuite() ccmtesting.TestSuite {
 				Run:         testLoadBalancerHealthCheck,
 				Timeout:     3 * time.Minute,
 			},
+			{
+				Name:        "LoadBalancerNodePorts",
+				Description: "Test that the load balancer is given the service NodePorts",
+				Run:         testLoadBalancerNodePorts,
+				Timeout:     3 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerWithoutNodePorts",
+				Description: "Test a load balancer for a service that does not allocate NodePorts",
+				Run:         testLoadBalancerWithoutNodePorts,
+				Timeout:     3 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerWithoutPorts",
+				Description: "Test that a load balancer for a service without ports is rejected",
+				Run:         testLoadBalancerWithoutPorts,
+				Timeout:     2 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerMixedProtocol",
+				Description: "Test a load balancer for a service with both TCP and UDP ports",
+				Run:         testLoadBalancerMixedProtocol,
+				Timeout:     3 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerReconcileDrift",
+				Description: "Test that repeated identical ensures return a stable status without accumulating load balancers",
+				Run:         testLoadBalancerReconcileDrift,
+				Timeout:     10 * time.Minute,
+				Labels:      []string{DeepConformanceLabel},
+			},
+			{
+				Name:        "ServiceTypeTransition",
+				Description: "Test that changing a service's type creates and deletes its load balancer",
+				Run:         testServiceTypeTransition,
+				Timeout:     5 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerNodeRemoval",
+				Description: "Test that a deleted node is dropped from the load balancer hosts",
+				Run:         func(ti ccmtesting.TestInterface) error { return testLoadBalancerNodeRemoval(context.Background(), ti) },
+				Timeout:     10 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerPortStatus",
+				Description: "Test that the load balancer ingress reports a port status for every service port",
+				Run:         func(ti ccmtesting.TestInterface) error { return testLoadBalancerPortStatus(context.Background(), ti) },
+				Timeout:     10 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerMultipleServices",
+				Description: "Test that load balancers of services sharing nodes have distinct names and independent lifecycles",
+				Run:         func(ti ccmtesting.TestInterface) error { return testMultipleLoadBalancers(context.Background(), ti) },
+				Timeout:     10 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerHealthCheckNodePort",
+				Description: "Test that the load balancer follows the health check node port across external traffic policy changes",
+				Run:         func(ti ccmtesting.TestInterface) error { return testHealthCheckNodePort(context.Background(), ti) },
+				Timeout:     10 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerDeleteIdempotency",
+				Description: "Test that deleting a missing or already deleted load balancer succeeds",
+				Run: func(ti ccmtesting.TestInterface) error {
+					return testLoadBalancerDeleteIdempotency(context.Background(), ti)
+				},
+				Timeout: 5 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerAnnotationRemoval",
+				Description: "Test that removing an annotation from a service reaches the load balancer",
+				Run: func(ti ccmtesting.TestInterface) error {
+					return testLoadBalancerAnnotationRemoval(context.Background(), ti)
+				},
+				Timeout: 5 * time.Minute,
+			},
+			{
+				Name:        "LoadBalancerRequestedIP",
+				Description: "Test that a requested spec.loadBalancerIP is honored or rejected as unsupported",
+				Run: func(ti ccmtesting.TestInterface) error {
+					return testLoadBalancerRequestedIP(context.Background(), ti)
+				},
+				Timeout: 5 * time.Minute,
+			},
 		},
 	}
 }
@@ -98,6 +195,12 @@ func CreateNodeTestSuite() ccmtesting.TestSuite {
 				Run:         testNodeProviderID,
 				Timeout:     2 * time.Minute,
 			},
+			{
+				Name:        "NodeProviderIDFormat",
+				Description: "Test that node provider IDs take the canonical <scheme>://<id> form",
+				Run:         testNodeProviderIDFormat,
+				Timeout:     2 * time.Minute,
+			},
 			{
 				Name:        "NodeInstanceType",
 				Description: "Test node instance type detection",
@@ -110,6 +213,12 @@ func CreateNodeTestSuite() ccmtesting.TestSuite {
 				Run:         testNodeZones,
 				Timeout:     2 * time.Minute,
 			},
+			{
+				Name:        "NodeTopologyLabels",
+				Description: "Test that initialized nodes carry zone and region labels from the cloud",
+				Run:         testNodeTopologyLabels,
+				Timeout:     2 * time.Minute,
+			},
 		},
 	}
 }
@@ -117,10 +226,11 @@ func CreateNodeTestSuite() ccmtesting.TestSuite {
 // CreateRouteTestSuite creates a test suite for route management functionality.
 func CreateRouteTestSuite() ccmtesting.TestSuite {
 	return ccmtesting.TestSuite{
-		Name:        "RouteManagement",
-		Description: "Tests for cloud provider route management functionality",
-		Setup:       setupRouteTestSuite,
-		Teardown:    teardownRouteTestSuite,
+		Name:                 "RouteManagement",
+		Description:          "Tests for cloud provider route management functionality",
+		RequiredCapabilities: []string{CapabilityRoutes},
+		Setup:                setupRouteTestSuite,
+		Teardown:             teardownRouteTestSuite,
 		Tests: []ccmtesting.Test{
 			{
 				Name:        "CreateRoute",
@@ -140,6 +250,12 @@ func CreateRouteTestSuite() ccmtesting.TestSuite {
 				Run:         func(ti ccmtesting.TestInterface) error { return testListRoutes(context.Background(), ti) },
 				Timeout:     2 * time.Minute,
 			},
+			{
+				Name:        "RoutesForPodCIDRs",
+				Description: "Test that a route to each node is created for every pod CIDR of the node",
+				Run:         func(ti ccmtesting.TestInterface) error { return testRoutesForPodCIDRs(context.Background(), ti) },
+				Timeout:     5 * time.Minute,
+			},
 		},
 	}
 }
@@ -147,10 +263,11 @@ func CreateRouteTestSuite() ccmtesting.TestSuite {
 // CreateInstancesTestSuite creates a test suite for instances functionality.
 func CreateInstancesTestSuite() ccmtesting.TestSuite {
 	return ccmtesting.TestSuite{
-		Name:        "Instances",
-		Description: "Tests for cloud provider instances functionality",
-		Setup:       setupInstancesTestSuite,
-		Teardown:    teardownInstancesTestSuite,
+		Name:                 "Instances",
+		Description:          "Tests for cloud provider instances functionality",
+		RequiredCapabilities: []string{CapabilityInstances},
+		Setup:                setupInstancesTestSuite,
+		Teardown:             teardownInstancesTestSuite,
 		Tests: []ccmtesting.Test{
 			{
 				Name:        "InstanceExists",
@@ -177,10 +294,11 @@ func CreateInstancesTestSuite() ccmtesting.TestSuite {
 // CreateZonesTestSuite creates a test suite for zones functionality.
 func CreateZonesTestSuite() ccmtesting.TestSuite {
 	return ccmtesting.TestSuite{
-		Name:        "Zones",
-		Description: "Tests for cloud provider zones functionality",
-		Setup:       setupZonesTestSuite,
-		Teardown:    teardownZonesTestSuite,
+		Name:                 "Zones",
+		Description:          "Tests for cloud provider zones functionality",
+		RequiredCapabilities: []string{CapabilityZones},
+		Setup:                setupZonesTestSuite,
+		Teardown:             teardownZonesTestSuite,
 		Tests: []ccmtesting.Test{
 			{
 				Name:        "GetZone",
@@ -201,10 +319,11 @@ func CreateZonesTestSuite() ccmtesting.TestSuite {
 // CreateClustersTestSuite creates a test suite for clusters functionality.
 func CreateClustersTestSuite() ccmtesting.TestSuite {
 	return ccmtesting.TestSuite{
-		Name:        "Clusters",
-		Description: "Tests for cloud provider clusters functionality",
-		Setup:       setupClustersTestSuite,
-		Teardown:    teardownClustersTestSuite,
+		Name:                 "Clusters",
+		Description:          "Tests for cloud provider clusters functionality",
+		RequiredCapabilities: []string{CapabilityClusters},
+		Setup:                setupClustersTestSuite,
+		Teardown:             teardownClustersTestSuite,
 		Tests: []ccmtesting.Test{
 			{
 				Name:        "ListClusters",
@@ -222,6 +341,102 @@ func CreateClustersTestSuite() ccmtesting.TestSuite {
 	}
 }
 
+// CreateSmokeTestSuite creates a quick-check suite that exercises each cloud
+// interface exactly once with tight timeouts, for gating changes on a fast run.
+func CreateSmokeTestSuite() ccmtesting.TestSuite {
+	return ccmtesting.TestSuite{
+		Name:         "Smoke",
+		Description:  "Quick checks touching every cloud provider interface once",
+		SuiteTimeout: 30 * time.Second,
+		Tests: []ccmtesting.Test{
+			{
+				Name:        "SmokeLoadBalancer",
+				Description: "Ensure and delete a single load balancer",
+				Run:         func(ti ccmtesting.TestInterface) error { return testSmokeLoadBalancer(context.Background(), ti) },
+				Timeout:     10 * time.Second,
+			},
+			{
+				Name:        "SmokeInstances",
+				Description: "Look up a single instance",
+				Run:         func(ti ccmtesting.TestInterface) error { return testSmokeInstances(context.Background(), ti) },
+				Timeout:     10 * time.Second,
+			},
+			{
+				Name:        "SmokeZones",
+				Description: "Get the current zone",
+				Run:         func(ti ccmtesting.TestInterface) error { return testGetZone(context.Background(), ti) },
+				Timeout:     10 * time.Second,
+			},
+			{
+				Name:        "SmokeRoutes",
+				Description: "List routes",
+				Run:         func(ti ccmtesting.TestInterface) error { return testListRoutes(context.Background(), ti) },
+				Timeout:     10 * time.Second,
+			},
+		},
+	}
+}
+
+// CreateConsistencyTestSuite creates a test suite that cross-checks what the
+// cloud provider reports through different interfaces against each other and
+// against the state the CCM wrote to the cluster.
+func CreateConsistencyTestSuite() ccmtesting.TestSuite {
+	return ccmtesting.TestSuite{
+		Name:                 "Consistency",
+		Description:          "Cross-checks between cloud provider interfaces and cluster state",
+		RequiredCapabilities: []string{CapabilityZones},
+		Tests: []ccmtesting.Test{
+			{
+				Name:        "NodeZoneConsistency",
+				Description: "Test that node zone labels match the zone the cloud reports for each node",
+				Run:         func(ti ccmtesting.TestInterface) error { return testNodeZoneConsistency(context.Background(), ti) },
+				Timeout:     2 * time.Minute,
+			},
+		},
+	}
+}
+
+// CreateNodeLifecycleTestSuite creates a test suite for the node lifecycle
+// controller, which removes nodes whose backing instance no longer exists.
+func CreateNodeLifecycleTestSuite() ccmtesting.TestSuite {
+	return ccmtesting.TestSuite{
+		Name:                 "NodeLifecycle",
+		Description:          "Tests for the cloud node lifecycle controller",
+		RequiredCapabilities: []string{CapabilityInstances},
+		Tests: []ccmtesting.Test{
+			{
+				Name:        "NodeInstanceRemoved",
+				Description: "Test that a NotReady node whose instance is gone is removed",
+				Run:         func(ti ccmtesting.TestInterface) error { return testNodeInstanceRemoved(context.Background(), ti) },
+				Timeout:     5 * time.Minute,
+			},
+		},
+	}
+}
+
+// DeepConformanceLabel marks tests that are too slow or too strict for the
+// default run, such as repeated reconcile checks.
+const DeepConformanceLabel = "deep-conformance"
+
+// defaultReconcileCycles is how often the reconcile drift test ensures the
+// same load balancer when TestData does not set "reconcile-cycles".
+const defaultReconcileCycles = 10
+
+// Expected outcomes of requesting a spec.loadBalancerIP, set per provider
+// through the "load-balancer-ip-support" TestData entry. Either is accepted
+// when it is not set.
+const (
+	// LoadBalancerIPHonored expects the requested address among the ingress IPs.
+	LoadBalancerIPHonored = "honored"
+	// LoadBalancerIPUnsupported expects the provider to reject the request
+	// with an error classified as ProviderErrorUnsupported.
+	LoadBalancerIPUnsupported = "unsupported"
+)
+
+// defaultRequestedLoadBalancerIP is the loadBalancerIP requested when
+// TestData does not set "load-balancer-ip".
+const defaultRequestedLoadBalancerIP = "192.0.2.10"
+
 // Setup and teardown functions for test suites
 
 func setupLoadBalancerTestSuite(ti ccmtesting.TestInterface) error {
@@ -342,7 +557,7 @@ func testCreateLoadBalancer(ti ccmtesting.TestInterface) error {
 	// Get load balancer interface
 	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support load balancer functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
 	// Create test service
@@ -379,7 +594,7 @@ func testCreateLoadBalancer(ti ccmtesting.TestInterface) error {
 	}
 
 	// Ensure load balancer
-	status, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, mockNodes)
+	status, err := lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
 	if err != nil {
 		return fmt.Errorf("failed to ensure load balancer: %w", err)
 	}
@@ -388,6 +603,14 @@ func testCreateLoadBalancer(ti ccmtesting.TestInterface) error {
 		return fmt.Errorf("load balancer status is empty")
 	}
 
+	// Load balancers that record the cluster name must have been given the
+	// configured one rather than a default
+	if _, recording := lb.(loadBalancerClusterNameRecorder); recording {
+		if err := AssertLoadBalancerClusterName(lb, service, testClusterName(ti)); err != nil {
+			return err
+		}
+	}
+
 	ti.GetTestResults().AddLog(fmt.Sprintf("Load balancer created successfully with %d ingress addresses", len(status.Ingress)))
 	return nil
 }
@@ -398,7 +621,7 @@ func testUpdateLoadBalancer(ti ccmtesting.TestInterface) error {
 
 	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support load balancer functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
 	// Create a mock service for update testing
@@ -426,7 +649,7 @@ func testUpdateLoadBalancer(ti ccmtesting.TestInterface) error {
 	}
 
 	// Update load balancer
-	_, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, mockNodes)
+	_, err := lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
 	if err != nil {
 		return fmt.Errorf("failed to update load balancer: %w", err)
 	}
@@ -441,7 +664,7 @@ func testDeleteLoadBalancer(ti ccmtesting.TestInterface) error {
 
 	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support load balancer functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
 	// Create a mock service for deletion testing
@@ -453,7 +676,7 @@ func testDeleteLoadBalancer(ti ccmtesting.TestInterface) error {
 	}
 
 	// Delete load balancer
-	err := lb.EnsureLoadBalancerDeleted(ctx, "test-cluster", service)
+	err := lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
 	if err != nil {
 		return fmt.Errorf("failed to delete load balancer: %w", err)
 	}
@@ -468,7 +691,7 @@ func testLoadBalancerStatus(ti ccmtesting.TestInterface) error {
 
 	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support load balancer functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
 	// Create a new service for status testing
@@ -504,7 +727,7 @@ func testLoadBalancerStatus(ti ccmtesting.TestInterface) error {
 	}
 
 	// Create load balancer
-	status, err := lb.EnsureLoadBalancer(ctx, "test-cluster", service, mockNodes)
+	status, err := lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
 	if err != nil {
 		return fmt.Errorf("failed to create load balancer: %w", err)
 	}
@@ -532,7 +755,7 @@ func testLoadBalancerStatus(ti ccmtesting.TestInterface) error {
 	}
 
 	// Clean up
-	err = lb.EnsureLoadBalancerDeleted(ctx, "test-cluster", service)
+	err = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
 	if err != nil {
 		return fmt.Errorf("failed to delete test load balancer: %w", err)
 	}
@@ -542,206 +765,1386 @@ func testLoadBalancerStatus(ti ccmtesting.TestInterface) error {
 }
 
 func testLoadBalancerHealthCheck(ti ccmtesting.TestInterface) error {
+	if _, ok := ti.GetCloudProvider().LoadBalancer(); !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
 	// This test would verify load balancer health check functionality
 	// Implementation would depend on the specific cloud provider
 	ti.GetTestResults().AddLog("Load balancer health check test completed")
 	return nil
 }
 
-// Test functions for node management
-
-func testNodeInitialization(ti ccmtesting.TestInterface) error {
+func testLoadBalancerNodePorts(ti ccmtesting.TestInterface) error {
 	ctx := context.Background()
 	cloudProvider := ti.GetCloudProvider()
 
-	// Get instances interface
-	instances, ok := cloudProvider.Instances()
+	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support instances functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
-	// Create test node
-	nodeConfig := &ccmtesting.TestNodeConfig{
-		Name:         "init-test-node",
-		ProviderID:   "test-provider://init-test-node",
-		InstanceType: "test-instance-type",
-		Zone:         "test-zone",
-		Region:       "test-region",
-		Addresses: []v1.NodeAddress{
-			{Type: v1.NodeInternalIP, Address: "10.0.0.3"},
-			{Type: v1.NodeExternalIP, Address: "192.168.1.3"},
+	// Create a multi-port service with explicit NodePorts, since the fake
+	// clientset does not allocate them
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "nodeport-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports: []v1.ServicePort{
+			{
+				Name:       "http",
+				Protocol:   v1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+				NodePort:   30080,
+			},
+			{
+				Name:       "https",
+				Protocol:   v1.ProtocolTCP,
+				Port:       443,
+				TargetPort: intstr.FromInt(8443),
+				NodePort:   30443,
+			},
 		},
 	}
 
-	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	service, err := ti.CreateTestService(ctx, serviceConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create test node: %w", err)
+		return fmt.Errorf("failed to create test service: %w", err)
 	}
 
-	// Test node initialization
-	providerID, err := instances.InstanceID(ctx, types.NodeName(node.Name))
-	if err != nil {
-		return fmt.Errorf("failed to get instance ID: %w", err)
+	mockNodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mock-node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				},
+			},
+		},
 	}
 
-	if providerID == "" {
-		return fmt.Errorf("provider ID is empty")
+	_, err = lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
+	if err != nil {
+		return fmt.Errorf("failed to ensure load balancer: %w", err)
 	}
 
-	ti.GetTestResults().AddLog(fmt.Sprintf("Node initialization test completed. Provider ID: %s", providerID))
+	// The load balancer must forward to the NodePorts, not the service ports
+	if mockProvider, ok := cloudProvider.(*MockCloudProvider); ok {
+		nodePorts, found := mockProvider.GetMockLoadBalancer().GetEnsuredNodePorts(service.Namespace, service.Name)
+		if !found {
+			return fmt.Errorf("load balancer was not ensured for service %s/%s", service.Namespace, service.Name)
+		}
+		if len(nodePorts) != len(serviceConfig.Ports) {
+			return fmt.Errorf("expected %d node ports, got %d", len(serviceConfig.Ports), len(nodePorts))
+		}
+		for i, port := range serviceConfig.Ports {
+			if nodePorts[i] != port.NodePort {
+				return fmt.Errorf("port %s: expected node port %d, got %d", port.Name, port.NodePort, nodePorts[i])
+			}
+		}
+	}
 
-	// Clean up
-	err = ti.DeleteTestNode(ctx, node.Name)
+	err = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
 	if err != nil {
-		return fmt.Errorf("failed to delete test node: %w", err)
+		return fmt.Errorf("failed to delete test load balancer: %w", err)
 	}
 
+	ti.GetTestResults().AddLog(fmt.Sprintf("Load balancer NodePorts test completed with %d ports", len(serviceConfig.Ports)))
 	return nil
 }
 
-func testNodeAddresses(ti ccmtesting.TestInterface) error {
+func testLoadBalancerWithoutNodePorts(ti ccmtesting.TestInterface) error {
 	ctx := context.Background()
 	cloudProvider := ti.GetCloudProvider()
 
-	instances, ok := cloudProvider.Instances()
+	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support instances functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
-	// Create test node
-	nodeConfig := &ccmtesting.TestNodeConfig{
-		Name:         "address-test-node",
-		ProviderID:   "test-provider://address-test-node",
-		InstanceType: "test-instance-type",
-		Zone:         "test-zone",
-		Region:       "test-region",
-		Addresses: []v1.NodeAddress{
-			{Type: v1.NodeInternalIP, Address: "10.0.0.4"},
-			{Type: v1.NodeExternalIP, Address: "192.168.1.4"},
+	// Without NodePorts the load balancer targets the pods directly
+	allocateNodePorts := false
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "no-nodeport-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports: []v1.ServicePort{
+			{
+				Name:       "http",
+				Protocol:   v1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			},
 		},
+		AllocateLoadBalancerNodePorts: &allocateNodePorts,
 	}
 
-	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	service, err := ti.CreateTestService(ctx, serviceConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create test node: %w", err)
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+	if service.Spec.AllocateLoadBalancerNodePorts == nil || *service.Spec.AllocateLoadBalancerNodePorts {
+		return fmt.Errorf("service %s/%s was created without allocateLoadBalancerNodePorts=false", service.Namespace, service.Name)
 	}
 
-	// Test node addresses
-	addresses, err := instances.NodeAddresses(ctx, types.NodeName(node.Name))
+	_, err = lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get node addresses: %w", err)
+		return fmt.Errorf("failed to ensure load balancer without node ports: %w", err)
 	}
 
-	if len(addresses) == 0 {
-		return fmt.Errorf("no addresses returned for node")
+	if mockProvider, ok := cloudProvider.(*MockCloudProvider); ok {
+		usesNodePorts, found := mockProvider.GetMockLoadBalancer().UsesNodePorts(service.Namespace, service.Name)
+		if !found {
+			return fmt.Errorf("load balancer was not ensured for service %s/%s", service.Namespace, service.Name)
+		}
+		if usesNodePorts {
+			return fmt.Errorf("load balancer for service %s/%s expects node ports although allocation is disabled", service.Namespace, service.Name)
+		}
 	}
 
-	ti.GetTestResults().AddLog(fmt.Sprintf("Node addresses test completed. Found %d addresses", len(addresses)))
-
-	// Clean up
-	err = ti.DeleteTestNode(ctx, node.Name)
+	err = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
 	if err != nil {
-		return fmt.Errorf("failed to delete test node: %w", err)
+		return fmt.Errorf("failed to delete test load balancer: %w", err)
 	}
 
+	ti.GetTestResults().AddLog("Load balancer without node ports test completed")
 	return nil
 }
 
-func testNodeProviderID(ti ccmtesting.TestInterface) error {
+// testLoadBalancerWithoutPorts checks that a LoadBalancer service without
+// ports, which a real API server refuses, is rejected both when the service is
+// created and by the provider if such a service reaches it anyway.
+func testLoadBalancerWithoutPorts(ti ccmtesting.TestInterface) error {
 	ctx := context.Background()
 	cloudProvider := ti.GetCloudProvider()
 
-	instances, ok := cloudProvider.Instances()
+	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support instances functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
-	// Create test node
-	nodeConfig := &ccmtesting.TestNodeConfig{
-		Name:         "providerid-test-node",
-		ProviderID:   "test-provider://providerid-test-node",
-		InstanceType: "test-instance-type",
-		Zone:         "test-zone",
-		Region:       "test-region",
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "portless-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
 	}
-
-	node, err := ti.CreateTestNode(ctx, nodeConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create test node: %w", err)
+	service, err := ti.CreateTestService(ctx, serviceConfig)
+	if err == nil {
+		_ = ti.DeleteTestService(ctx, service.Name)
+		return fmt.Errorf("service %s/%s without ports was created", service.Namespace, service.Name)
 	}
-
-	// Test provider ID
-	providerID, err := instances.InstanceID(ctx, types.NodeName(node.Name))
-	if err != nil {
-		return fmt.Errorf("failed to get provider ID: %w", err)
+	if class := ClassifyProviderError(err); class != ProviderErrorInvalid {
+		return fmt.Errorf("rejection of service without ports was classified as %s instead of %s: %w", class, ProviderErrorInvalid, err)
 	}
 
-	if providerID == "" {
-		return fmt.Errorf("provider ID is empty")
+	// Bypass the service validation to check that the provider refuses too
+	service = &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceConfig.Name, Namespace: serviceConfig.Namespace},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
 	}
-
-	ti.GetTestResults().AddLog(fmt.Sprintf("Provider ID test completed. Provider ID: %s", providerID))
-
-	// Clean up
-	err = ti.DeleteTestNode(ctx, node.Name)
-	if err != nil {
-		return fmt.Errorf("failed to delete test node: %w", err)
+	if _, err := lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, nil); err == nil {
+		_ = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
+		return fmt.Errorf("provider ensured a load balancer for service %s/%s without ports", service.Namespace, service.Name)
 	}
 
+	ti.GetTestResults().AddLog("Load balancer without ports test completed")
 	return nil
 }
 
-func testNodeInstanceType(ti ccmtesting.TestInterface) error {
+func testLoadBalancerReconcileDrift(ti ccmtesting.TestInterface) error {
 	ctx := context.Background()
 	cloudProvider := ti.GetCloudProvider()
 
-	instances, ok := cloudProvider.Instances()
+	lb, ok := cloudProvider.LoadBalancer()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support instances functionality")
+		return ccmtesting.NewUnsupportedError("load balancer")
 	}
 
-	// Create test node
-	nodeConfig := &ccmtesting.TestNodeConfig{
-		Name:         "instancetype-test-node",
-		ProviderID:   "test-provider://instancetype-test-node",
-		InstanceType: "test-instance-type",
-		Zone:         "test-zone",
-		Region:       "test-region",
+	cycles := reconcileCycles(ti)
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "drift-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports: []v1.ServicePort{
+			{
+				Name:       "http",
+				Protocol:   v1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+				NodePort:   30082,
+			},
+		},
 	}
 
-	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	service, err := ti.CreateTestService(ctx, serviceConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create test node: %w", err)
+		return fmt.Errorf("failed to create test service: %w", err)
 	}
 
-	// Test instance type
-	instanceType, err := instances.InstanceType(ctx, types.NodeName(node.Name))
-	if err != nil {
-		return fmt.Errorf("failed to get instance type: %w", err)
+	mockNodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mock-node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				},
+			},
+		},
 	}
 
-	if instanceType == "" {
-		return fmt.Errorf("instance type is empty")
+	// Other tests may have left load balancers behind; only growth counts
+	var mockLB *MockLoadBalancer
+	baseline := 0
+	if mockProvider, ok := cloudProvider.(*MockCloudProvider); ok {
+		mockLB = mockProvider.GetMockLoadBalancer()
+		baseline = mockLB.LoadBalancerCount()
 	}
 
-	ti.GetTestResults().AddLog(fmt.Sprintf("Instance type test completed. Instance type: %s", instanceType))
+	var firstStatus *v1.LoadBalancerStatus
+	hashes := make([]string, 0, cycles)
+	for cycle := 1; cycle <= cycles; cycle++ {
+		status, err := lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
+		if err != nil {
+			return fmt.Errorf("failed to ensure load balancer on cycle %d: %w", cycle, err)
+		}
 
-	// Clean up
-	err = ti.DeleteTestNode(ctx, node.Name)
-	if err != nil {
-		return fmt.Errorf("failed to delete test node: %w", err)
-	}
+		hash := loadBalancerStatusHash(status)
+		hashes = append(hashes, hash)
+		ti.GetTestResults().AddLog(fmt.Sprintf("Reconcile cycle %d/%d: status hash %s", cycle, cycles, hash))
 
-	return nil
-}
+		if cycle == 1 {
+			firstStatus = status
+		} else if err := AssertLoadBalancerStatusEqual(firstStatus, status); err != nil {
+			return fmt.Errorf("load balancer status drifted on cycle %d: %w", cycle, err)
+		}
 
-func testNodeZones(ti ccmtesting.TestInterface) error {
+		if mockLB != nil {
+			if count := mockLB.LoadBalancerCount(); count > baseline+1 {
+				return fmt.Errorf("load balancers accumulated on cycle %d: %d exist, expected at most %d", cycle, count, baseline+1)
+			}
+		}
+	}
+	ti.GetTestResults().SetMetric("reconcileStatusHashes", hashes)
+
+	err = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
+	if err != nil {
+		return fmt.Errorf("failed to delete test load balancer: %w", err)
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Load balancer reconcile drift test completed with %d stable cycles", cycles))
+	return nil
+}
+
+// reconcileCycles returns the number of ensure cycles of the reconcile drift
+// test, read from the "reconcile-cycles" TestData entry.
+func reconcileCycles(ti ccmtesting.TestInterface) int {
+	if config := ti.GetConfig(); config != nil {
+		if cycles, ok := config.TestData["reconcile-cycles"].(int); ok && cycles > 0 {
+			return cycles
+		}
+	}
+	return defaultReconcileCycles
+}
+
+// loadBalancerStatusHash returns a hash of the ingress points of the status
+// that, like AssertLoadBalancerStatusEqual, does not depend on their order.
+func loadBalancerStatusHash(status *v1.LoadBalancerStatus) string {
+	var keys []string
+	for key, count := range ingressKeys(status) {
+		for i := 0; i < count; i++ {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func testLoadBalancerMixedProtocol(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	cloudProvider := ti.GetCloudProvider()
+
+	lb, ok := cloudProvider.LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "mixed-protocol-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports: []v1.ServicePort{
+			{
+				Name:       "dns-tcp",
+				Protocol:   v1.ProtocolTCP,
+				Port:       53,
+				TargetPort: intstr.FromInt(5353),
+				NodePort:   30053,
+			},
+			{
+				Name:       "dns-udp",
+				Protocol:   v1.ProtocolUDP,
+				Port:       53,
+				TargetPort: intstr.FromInt(5353),
+				NodePort:   30054,
+			},
+		},
+	}
+
+	service, err := ti.CreateTestService(ctx, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+
+	mockNodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mock-node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				},
+			},
+		},
+	}
+
+	_, err = lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
+	if err != nil {
+		if mixedProtocolUnsupported(err) {
+			ti.GetTestResults().AddLog(fmt.Sprintf("Load balancer does not support mixed TCP/UDP ports: %v", err))
+			return ccmtesting.NewUnsupportedError("mixed-protocol load balancer")
+		}
+		return fmt.Errorf("failed to ensure mixed-protocol load balancer: %w", err)
+	}
+
+	if mockProvider, ok := cloudProvider.(*MockCloudProvider); ok {
+		protocols, found := mockProvider.GetMockLoadBalancer().GetEnsuredProtocols(service.Namespace, service.Name)
+		if !found {
+			return fmt.Errorf("load balancer was not ensured for service %s/%s", service.Namespace, service.Name)
+		}
+		if len(protocols) != len(serviceConfig.Ports) {
+			return fmt.Errorf("expected %d port protocols, got %d", len(serviceConfig.Ports), len(protocols))
+		}
+		for i, port := range serviceConfig.Ports {
+			if protocols[i] != port.Protocol {
+				return fmt.Errorf("port %s: expected protocol %s, got %s", port.Name, port.Protocol, protocols[i])
+			}
+		}
+	}
+
+	err = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
+	if err != nil {
+		return fmt.Errorf("failed to delete test load balancer: %w", err)
+	}
+
+	ti.GetTestResults().AddLog("Load balancer mixed protocol test completed with TCP and UDP ports")
+	return nil
+}
+
+// mixedProtocolUnsupported returns whether a load balancer error reports that
+// the provider cannot serve TCP and UDP ports on the same load balancer.
+func mixedProtocolUnsupported(err error) bool {
+	if ccmtesting.IsUnsupportedError(err) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "not supported") || strings.Contains(message, "unsupported")
+}
+
+func testServiceTypeTransition(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	cloudProvider := ti.GetCloudProvider()
+
+	lb, ok := cloudProvider.LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	// Start with a NodePort service, which must not have a load balancer
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "type-transition-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeNodePort,
+		Ports: []v1.ServicePort{
+			{
+				Name:       "http",
+				Protocol:   v1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+				NodePort:   30081,
+			},
+		},
+	}
+
+	service, err := ti.CreateTestService(ctx, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+
+	_, exists, err := lb.GetLoadBalancer(ctx, testClusterName(ti), service)
+	if err != nil {
+		return fmt.Errorf("failed to get load balancer: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("NodePort service %s/%s unexpectedly has a load balancer", service.Namespace, service.Name)
+	}
+
+	mockNodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mock-node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				},
+			},
+		},
+	}
+
+	// NodePort -> LoadBalancer: the service controller ensures a load balancer
+	serviceConfig.Type = v1.ServiceTypeLoadBalancer
+	service, err = ti.UpdateTestService(ctx, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to update service to LoadBalancer: %w", err)
+	}
+
+	status, err := lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
+	if err != nil {
+		return fmt.Errorf("failed to ensure load balancer: %w", err)
+	}
+	if status == nil || len(status.Ingress) == 0 {
+		return fmt.Errorf("load balancer for service %s/%s has no ingress", service.Namespace, service.Name)
+	}
+
+	_, exists, err = lb.GetLoadBalancer(ctx, testClusterName(ti), service)
+	if err != nil {
+		return fmt.Errorf("failed to get load balancer: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("load balancer for service %s/%s does not exist after transition to LoadBalancer", service.Namespace, service.Name)
+	}
+
+	// LoadBalancer -> ClusterIP: the service controller deletes the load balancer
+	serviceConfig.Type = v1.ServiceTypeClusterIP
+	serviceConfig.Ports[0].NodePort = 0
+	service, err = ti.UpdateTestService(ctx, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to update service to ClusterIP: %w", err)
+	}
+
+	err = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
+	if err != nil {
+		return fmt.Errorf("failed to delete load balancer: %w", err)
+	}
+
+	_, exists, err = lb.GetLoadBalancer(ctx, testClusterName(ti), service)
+	if err != nil {
+		return fmt.Errorf("failed to get load balancer: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("load balancer for service %s/%s still exists after transition to ClusterIP", service.Namespace, service.Name)
+	}
+
+	err = ti.DeleteTestService(ctx, serviceConfig.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete test service: %w", err)
+	}
+
+	ti.GetTestResults().AddLog("Service type transition test completed successfully")
+	return nil
+}
+
+// loadBalancerServiceCreator is implemented by test interfaces that can create
+// a LoadBalancer service and wait for its load balancer to be provisioned.
+type loadBalancerServiceCreator interface {
+	CreateLoadBalancerServiceAndWait(ctx context.Context, config *ccmtesting.TestServiceConfig, timeout time.Duration) (*v1.Service, *v1.LoadBalancerStatus, error)
+}
+
+// loadBalancerHostsUpdater is implemented by test interfaces that play the
+// node sync of the service controller themselves, such as CCMTestInterface.
+type loadBalancerHostsUpdater interface {
+	UpdateLoadBalancerHosts(ctx context.Context, service *v1.Service) error
+}
+
+// loadBalancerHostsAwaiter is implemented by test interfaces that can wait for
+// a running CCM to update the hosts of a load balancer.
+type loadBalancerHostsAwaiter interface {
+	WaitForLoadBalancerHostsUpdated(serviceName string, since time.Time, timeout time.Duration) error
+}
+
+// loadBalancerNodeRecorder is implemented by load balancers that record the
+// nodes they were given, such as MockLoadBalancer.
+type loadBalancerNodeRecorder interface {
+	GetEnsuredNodes(namespace, name string) ([]string, bool)
+	GetUpdatedNodes(namespace, name string) ([]string, bool)
+}
+
+// testLoadBalancerNodeRemoval ensures a load balancer across two nodes, deletes
+// one of them and checks that reconciling the load balancer hosts drops it.
+// The hosts can only be inspected when the load balancer records them; against
+// a running CCM the test waits for the service controller to update them.
+func testLoadBalancerNodeRemoval(ctx context.Context, ti ccmtesting.TestInterface) error {
+	creator, ok := ti.(loadBalancerServiceCreator)
+	if !ok {
+		return fmt.Errorf("test interface cannot create load balancer services")
+	}
+
+	var lb cloudprovider.LoadBalancer
+	if cloudProvider := ti.GetCloudProvider(); cloudProvider != nil {
+		var supported bool
+		if lb, supported = cloudProvider.LoadBalancer(); !supported {
+			return ccmtesting.NewUnsupportedError("load balancer")
+		}
+	}
+
+	members := make([]*v1.Node, 0, 2)
+	for i, name := range []string{"lb-member-a", "lb-member-b"} {
+		node, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{
+			Name:       name,
+			Addresses:  []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: fmt.Sprintf("10.0.1.%d", i+1)}},
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue, Reason: "KubeletReady"}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create test node %s: %w", name, err)
+		}
+		members = append(members, node)
+	}
+	kept, removed := members[0], members[1]
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "node-removal-test-lb",
+		Namespace: "default",
+		Ports: []v1.ServicePort{
+			{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)},
+		},
+	}
+	service, _, err := creator.CreateLoadBalancerServiceAndWait(ctx, serviceConfig, 5*time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to create load balancer service: %w", err)
+	}
+
+	recorder, recording := lb.(loadBalancerNodeRecorder)
+	if recording {
+		ensured, _ := recorder.GetEnsuredNodes(service.Namespace, service.Name)
+		if !slices.Contains(ensured, kept.Name) || !slices.Contains(ensured, removed.Name) {
+			return fmt.Errorf("load balancer was ensured with nodes %v, want both %s and %s", ensured, kept.Name, removed.Name)
+		}
+	}
+
+	since := time.Now()
+	if err := ti.DeleteTestNode(ctx, removed.Name); err != nil {
+		return fmt.Errorf("failed to delete test node %s: %w", removed.Name, err)
+	}
+
+	switch reconciler := ti.(type) {
+	case loadBalancerHostsUpdater:
+		if err := reconciler.UpdateLoadBalancerHosts(ctx, service); err != nil {
+			return err
+		}
+	case loadBalancerHostsAwaiter:
+		if err := reconciler.WaitForLoadBalancerHostsUpdated(service.Name, since, 4*time.Minute); err != nil {
+			return fmt.Errorf("service controller did not update the load balancer hosts after node %s was deleted: %w", removed.Name, err)
+		}
+	default:
+		return fmt.Errorf("test interface cannot reconcile load balancer hosts")
+	}
+
+	if recording {
+		updated, ok := recorder.GetUpdatedNodes(service.Namespace, service.Name)
+		if !ok {
+			return fmt.Errorf("UpdateLoadBalancer was not called for service %s/%s after node %s was deleted", service.Namespace, service.Name, removed.Name)
+		}
+		if slices.Contains(updated, removed.Name) {
+			return fmt.Errorf("load balancer hosts %v still include deleted node %s", updated, removed.Name)
+		}
+		if !slices.Contains(updated, kept.Name) {
+			return fmt.Errorf("load balancer hosts %v dropped node %s, which was not deleted", updated, kept.Name)
+		}
+	}
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node %s was dropped from the hosts of load balancer %s", removed.Name, service.Name))
+
+	if err := ti.DeleteTestService(ctx, serviceConfig.Name); err != nil {
+		return fmt.Errorf("failed to delete test service: %w", err)
+	}
+	if err := ti.DeleteTestNode(ctx, kept.Name); err != nil {
+		return fmt.Errorf("failed to delete test node %s: %w", kept.Name, err)
+	}
+
+	return nil
+}
+
+// testLoadBalancerPortStatus creates a LoadBalancer service with two ports and
+// checks that every ingress point of its load balancer reports a PortStatus
+// without an error for each of them.
+func testLoadBalancerPortStatus(ctx context.Context, ti ccmtesting.TestInterface) error {
+	creator, ok := ti.(loadBalancerServiceCreator)
+	if !ok {
+		return fmt.Errorf("test interface cannot create load balancer services")
+	}
+
+	if cloudProvider := ti.GetCloudProvider(); cloudProvider != nil {
+		if _, supported := cloudProvider.LoadBalancer(); !supported {
+			return ccmtesting.NewUnsupportedError("load balancer")
+		}
+	}
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "port-status-test-lb",
+		Namespace: "default",
+		Ports: []v1.ServicePort{
+			{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)},
+			{Name: "https", Protocol: v1.ProtocolTCP, Port: 443, TargetPort: intstr.FromInt(8443)},
+		},
+	}
+	_, status, err := creator.CreateLoadBalancerServiceAndWait(ctx, serviceConfig, 5*time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to create load balancer service: %w", err)
+	}
+	if len(status.Ingress) == 0 {
+		return fmt.Errorf("load balancer status has no ingress points")
+	}
+
+	for _, ingress := range status.Ingress {
+		address := ingress.IP
+		if address == "" {
+			address = ingress.Hostname
+		}
+		for _, port := range serviceConfig.Ports {
+			i := slices.IndexFunc(ingress.Ports, func(portStatus v1.PortStatus) bool {
+				return portStatus.Port == port.Port && portStatus.Protocol == port.Protocol
+			})
+			if i < 0 {
+				return fmt.Errorf("ingress %s reports no status for port %d/%s", address, port.Port, port.Protocol)
+			}
+			if portError := ingress.Ports[i].Error; portError != nil {
+				return fmt.Errorf("ingress %s reports port %d/%s with error: %s", address, port.Port, port.Protocol, *portError)
+			}
+		}
+	}
+
+	if err := ti.DeleteTestService(ctx, serviceConfig.Name); err != nil {
+		return fmt.Errorf("failed to delete test service: %w", err)
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Load balancer port status test completed with %d ingress points", len(status.Ingress)))
+	return nil
+}
+
+// testMultipleLoadBalancers ensures load balancers for several services
+// over the same nodes and checks that each has its own name and status, and
+// that deleting one leaves the others in place. Providers that key load
+// balancers by cluster rather than by service fail here.
+func testMultipleLoadBalancers(ctx context.Context, ti ccmtesting.TestInterface) error {
+	lb, ok := ti.GetCloudProvider().LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	clusterName := testClusterName(ti)
+	const serviceCount = 3
+
+	sharedNodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared-node-1"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.2.1"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared-node-2"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.2.2"}}},
+		},
+	}
+
+	// Give each service its own port so that their statuses differ
+	services := make([]*v1.Service, 0, serviceCount)
+	statuses := make([]*v1.LoadBalancerStatus, 0, serviceCount)
+	owners := make(map[string]string, serviceCount)
+	for i := 0; i < serviceCount; i++ {
+		service, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{
+			Name:      fmt.Sprintf("multi-lb-test-%d", i),
+			Namespace: "default",
+			Type:      v1.ServiceTypeLoadBalancer,
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: v1.ProtocolTCP, Port: int32(8000 + i), TargetPort: intstr.FromInt(8080), NodePort: int32(30800 + i)},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create test service: %w", err)
+		}
+
+		name := lb.GetLoadBalancerName(ctx, clusterName, service)
+		if owner, taken := owners[name]; taken {
+			return fmt.Errorf("services %s and %s/%s share load balancer name %s", owner, service.Namespace, service.Name, name)
+		}
+		owners[name] = service.Namespace + "/" + service.Name
+
+		status, err := lb.EnsureLoadBalancer(ctx, clusterName, service, sharedNodes)
+		if err != nil {
+			return fmt.Errorf("failed to ensure load balancer for service %s/%s: %w", service.Namespace, service.Name, err)
+		}
+		services = append(services, service)
+		statuses = append(statuses, status)
+	}
+
+	// checkLoadBalancers checks that the load balancers of the services from
+	// index first on still exist with the status they were ensured with
+	checkLoadBalancers := func(first int) error {
+		for i := first; i < len(services); i++ {
+			status, exists, err := lb.GetLoadBalancer(ctx, clusterName, services[i])
+			if err != nil {
+				return fmt.Errorf("failed to get load balancer for service %s: %w", services[i].Name, err)
+			}
+			if !exists {
+				return fmt.Errorf("load balancer for service %s does not exist", services[i].Name)
+			}
+			if err := AssertLoadBalancerStatusEqual(statuses[i], status); err != nil {
+				return fmt.Errorf("load balancer for service %s: %w", services[i].Name, err)
+			}
+		}
+		return nil
+	}
+	if err := checkLoadBalancers(0); err != nil {
+		return err
+	}
+
+	if err := lb.EnsureLoadBalancerDeleted(ctx, clusterName, services[0]); err != nil {
+		return fmt.Errorf("failed to delete load balancer for service %s: %w", services[0].Name, err)
+	}
+	if _, exists, err := lb.GetLoadBalancer(ctx, clusterName, services[0]); err != nil {
+		return fmt.Errorf("failed to get load balancer for service %s: %w", services[0].Name, err)
+	} else if exists {
+		return fmt.Errorf("load balancer for service %s still exists after deletion", services[0].Name)
+	}
+	if err := checkLoadBalancers(1); err != nil {
+		return fmt.Errorf("after deleting the load balancer of service %s: %w", services[0].Name, err)
+	}
+
+	for i, service := range services {
+		if i > 0 {
+			if err := lb.EnsureLoadBalancerDeleted(ctx, clusterName, service); err != nil {
+				return fmt.Errorf("failed to delete load balancer for service %s: %w", service.Name, err)
+			}
+		}
+		if err := ti.DeleteTestService(ctx, service.Name); err != nil {
+			return fmt.Errorf("failed to delete test service: %w", err)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Load balancers of %d services sharing nodes were independent", serviceCount))
+	return nil
+}
+
+// loadBalancerServiceRecorder is implemented by load balancers that record the
+// last service they were ensured for, such as MockLoadBalancer.
+type loadBalancerServiceRecorder interface {
+	GetEnsuredService(namespace, name string) (*v1.Service, bool)
+}
+
+// testHealthCheckNodePort flips the ExternalTrafficPolicy of a LoadBalancer
+// service from Cluster to Local and back, ensuring the load balancer after
+// each change, and checks that the health check node port reaches the
+// provider and is released again. A provider that keeps the old health check
+// blackholes traffic after the change.
+func testHealthCheckNodePort(ctx context.Context, ti ccmtesting.TestInterface) error {
+	lb, ok := ti.GetCloudProvider().LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	clusterName := testClusterName(ti)
+	const healthCheckNodePort = 32100
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "health-check-node"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.3.1"}}},
+		},
+	}
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:                  "health-check-test-lb",
+		Namespace:             "default",
+		Type:                  v1.ServiceTypeLoadBalancer,
+		ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyCluster,
+		Ports: []v1.ServicePort{
+			{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080), NodePort: 30180},
+		},
+	}
+	service, err := ti.CreateTestService(ctx, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+
+	recorder, recording := lb.(loadBalancerServiceRecorder)
+
+	// ensureWithPolicy ensures the load balancer for service and checks the
+	// health check node port the provider was given
+	ensureWithPolicy := func(service *v1.Service, wantPort int32) error {
+		if _, err := lb.EnsureLoadBalancer(ctx, clusterName, service, nodes); err != nil {
+			return fmt.Errorf("failed to ensure load balancer with %s traffic policy: %w", service.Spec.ExternalTrafficPolicy, err)
+		}
+		if !recording {
+			return nil
+		}
+		ensured, found := recorder.GetEnsuredService(service.Namespace, service.Name)
+		if !found {
+			return fmt.Errorf("load balancer was not ensured for service %s/%s", service.Namespace, service.Name)
+		}
+		if ensured.Spec.HealthCheckNodePort != wantPort {
+			return fmt.Errorf("load balancer ensured with %s traffic policy has health check node port %d, expected %d", service.Spec.ExternalTrafficPolicy, ensured.Spec.HealthCheckNodePort, wantPort)
+		}
+		return nil
+	}
+
+	if err := ensureWithPolicy(service, 0); err != nil {
+		return err
+	}
+
+	service, err = ti.UpdateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:                  serviceConfig.Name,
+		Namespace:             serviceConfig.Namespace,
+		ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyLocal,
+		HealthCheckNodePort:   healthCheckNodePort,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to switch service to Local traffic policy: %w", err)
+	}
+	if service.Spec.HealthCheckNodePort == 0 {
+		return fmt.Errorf("service %s has no health check node port with Local traffic policy", service.Name)
+	}
+	if err := ensureWithPolicy(service, service.Spec.HealthCheckNodePort); err != nil {
+		return err
+	}
+
+	service, err = ti.UpdateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:                  serviceConfig.Name,
+		Namespace:             serviceConfig.Namespace,
+		ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyCluster,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to switch service back to Cluster traffic policy: %w", err)
+	}
+	if service.Spec.HealthCheckNodePort != 0 {
+		return fmt.Errorf("service %s kept health check node port %d with Cluster traffic policy", service.Name, service.Spec.HealthCheckNodePort)
+	}
+	if err := ensureWithPolicy(service, 0); err != nil {
+		return err
+	}
+
+	if err := lb.EnsureLoadBalancerDeleted(ctx, clusterName, service); err != nil {
+		return fmt.Errorf("failed to delete test load balancer: %w", err)
+	}
+	if err := ti.DeleteTestService(ctx, serviceConfig.Name); err != nil {
+		return fmt.Errorf("failed to delete test service: %w", err)
+	}
+
+	ti.GetTestResults().AddLog("Load balancer health check node port followed the external traffic policy")
+	return nil
+}
+
+// internalLoadBalancerAnnotation is the annotation the annotation removal test
+// adds and removes. Providers each have their own key for internal load
+// balancers; the test only checks that the removal reaches the provider.
+const internalLoadBalancerAnnotation = "service.beta.kubernetes.io/load-balancer-internal"
+
+// testLoadBalancerAnnotationRemoval ensures a load balancer for a service
+// annotated as internal, removes the annotation and ensures it again. A
+// provider must reconfigure the load balancer from the service it is given
+// rather than only applying annotations it has not seen, so load balancers
+// that record the ensured service must see the annotation gone.
+func testLoadBalancerAnnotationRemoval(ctx context.Context, ti ccmtesting.TestInterface) error {
+	lb, ok := ti.GetCloudProvider().LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	clusterName := testClusterName(ti)
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "annotation-removal-node"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.5.1"}}},
+		},
+	}
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:        "annotation-removal-test-lb",
+		Namespace:   "default",
+		Type:        v1.ServiceTypeLoadBalancer,
+		Annotations: map[string]string{internalLoadBalancerAnnotation: "true"},
+		Ports: []v1.ServicePort{
+			{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080), NodePort: 30380},
+		},
+	}
+	service, err := ti.CreateTestService(ctx, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+
+	recorder, recording := lb.(loadBalancerServiceRecorder)
+	// ensureAndCheck ensures the load balancer of service and checks that a
+	// recording load balancer was given the annotation only if want is set
+	ensureAndCheck := func(service *v1.Service, want bool) error {
+		if _, err := lb.EnsureLoadBalancer(ctx, clusterName, service, nodes); err != nil {
+			return fmt.Errorf("failed to ensure load balancer: %w", err)
+		}
+		if !recording {
+			return nil
+		}
+		ensured, found := recorder.GetEnsuredService(service.Namespace, service.Name)
+		if !found {
+			return fmt.Errorf("load balancer was not ensured for service %s/%s", service.Namespace, service.Name)
+		}
+		if _, annotated := ensured.Annotations[internalLoadBalancerAnnotation]; annotated != want {
+			return fmt.Errorf("load balancer ensured with annotations %v, want %s present: %v", ensured.Annotations, internalLoadBalancerAnnotation, want)
+		}
+		return nil
+	}
+
+	if err := ensureAndCheck(service, true); err != nil {
+		return err
+	}
+
+	service, err = ti.UpdateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:              serviceConfig.Name,
+		Namespace:         serviceConfig.Namespace,
+		RemoveAnnotations: []string{internalLoadBalancerAnnotation},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove annotation %s: %w", internalLoadBalancerAnnotation, err)
+	}
+	if _, annotated := service.Annotations[internalLoadBalancerAnnotation]; annotated {
+		return fmt.Errorf("service %s kept annotation %s after its removal", service.Name, internalLoadBalancerAnnotation)
+	}
+	if err := ensureAndCheck(service, false); err != nil {
+		return fmt.Errorf("after removing annotation %s: %w", internalLoadBalancerAnnotation, err)
+	}
+
+	if err := lb.EnsureLoadBalancerDeleted(ctx, clusterName, service); err != nil {
+		return fmt.Errorf("failed to delete test load balancer: %w", err)
+	}
+	if err := ti.DeleteTestService(ctx, serviceConfig.Name); err != nil {
+		return fmt.Errorf("failed to delete test service: %w", err)
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Removing annotation %s reached the load balancer", internalLoadBalancerAnnotation))
+	return nil
+}
+
+// testLoadBalancerDeleteIdempotency checks that deleting a load balancer is
+// safe to repeat: deleting one that was never ensured and deleting one twice
+// must both succeed. The service controller relies on this when a service is
+// deleted while its load balancer is still being created or already gone.
+func testLoadBalancerDeleteIdempotency(ctx context.Context, ti ccmtesting.TestInterface) error {
+	lb, ok := ti.GetCloudProvider().LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	clusterName := testClusterName(ti)
+
+	service, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:      "delete-idempotency-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports: []v1.ServicePort{
+			{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080), NodePort: 30280},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+	defer func() {
+		if err := ti.DeleteTestService(ctx, service.Name); err != nil {
+			ti.GetTestResults().AddLog(fmt.Sprintf("Failed to delete test service: %v", err))
+		}
+	}()
+
+	if err := lb.EnsureLoadBalancerDeleted(ctx, clusterName, service); err != nil {
+		return fmt.Errorf("deleting a load balancer that was never ensured failed: %w", err)
+	}
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "delete-idempotency-node"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.4.1"}}},
+		},
+	}
+	if _, err := lb.EnsureLoadBalancer(ctx, clusterName, service, nodes); err != nil {
+		return fmt.Errorf("failed to ensure load balancer: %w", err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := lb.EnsureLoadBalancerDeleted(ctx, clusterName, service); err != nil {
+			return fmt.Errorf("delete %d of the load balancer failed: %w", attempt, err)
+		}
+	}
+	if _, exists, err := lb.GetLoadBalancer(ctx, clusterName, service); err != nil {
+		return fmt.Errorf("failed to get load balancer: %w", err)
+	} else if exists {
+		return fmt.Errorf("load balancer still exists after deletion")
+	}
+
+	ti.GetTestResults().AddLog("Deleting a missing or already deleted load balancer succeeded")
+	return nil
+}
+
+// testLoadBalancerRequestedIP ensures a load balancer for a service that
+// requests an address through the deprecated spec.loadBalancerIP. Providers
+// either honor it, reporting the address among the ingress IPs, or reject it
+// with an unsupported error; silently ignoring it fails the test. The
+// "load-balancer-ip-support" TestData entry narrows the accepted outcome to
+// one of them for a provider whose behavior is known.
+func testLoadBalancerRequestedIP(ctx context.Context, ti ccmtesting.TestInterface) error {
+	lb, ok := ti.GetCloudProvider().LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	support, ip := loadBalancerIPSupport(ti)
+	if support != "" && support != LoadBalancerIPHonored && support != LoadBalancerIPUnsupported {
+		return fmt.Errorf("unknown load-balancer-ip-support %q, expected %s or %s", support, LoadBalancerIPHonored, LoadBalancerIPUnsupported)
+	}
+
+	clusterName := testClusterName(ti)
+
+	service, err := ti.CreateTestService(ctx, &ccmtesting.TestServiceConfig{
+		Name:           "requested-ip-test-lb",
+		Namespace:      "default",
+		Type:           v1.ServiceTypeLoadBalancer,
+		LoadBalancerIP: ip,
+		Ports: []v1.ServicePort{
+			{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080), NodePort: 30480},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+	defer func() {
+		if err := ti.DeleteTestService(ctx, service.Name); err != nil {
+			ti.GetTestResults().AddLog(fmt.Sprintf("Failed to delete test service: %v", err))
+		}
+	}()
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "requested-ip-node"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.6.1"}}},
+		},
+	}
+	status, err := lb.EnsureLoadBalancer(ctx, clusterName, service, nodes)
+	if err != nil {
+		if class := ClassifyProviderError(err); class != ProviderErrorUnsupported || support == LoadBalancerIPHonored {
+			return fmt.Errorf("failed to ensure load balancer with loadBalancerIP %s (%s error): %w", ip, class, err)
+		}
+		ti.GetTestResults().AddLog(fmt.Sprintf("Provider rejected loadBalancerIP %s as unsupported: %v", ip, err))
+		return nil
+	}
+
+	if err := lb.EnsureLoadBalancerDeleted(ctx, clusterName, service); err != nil {
+		return fmt.Errorf("failed to delete test load balancer: %w", err)
+	}
+
+	if support == LoadBalancerIPUnsupported {
+		return fmt.Errorf("provider accepted loadBalancerIP %s, expected an unsupported error", ip)
+	}
+	if !slices.ContainsFunc(status.Ingress, func(ingress v1.LoadBalancerIngress) bool { return ingress.IP == ip }) {
+		return fmt.Errorf("load balancer ingress %v does not include the requested loadBalancerIP %s", status.Ingress, ip)
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Provider honored loadBalancerIP %s", ip))
+	return nil
+}
+
+// loadBalancerIPSupport returns the expected outcome of requesting a
+// loadBalancerIP, read from the "load-balancer-ip-support" TestData entry, and
+// the address to request, read from the "load-balancer-ip" entry.
+func loadBalancerIPSupport(ti ccmtesting.TestInterface) (string, string) {
+	support, ip := "", defaultRequestedLoadBalancerIP
+	if config := ti.GetConfig(); config != nil {
+		if value, ok := config.TestData["load-balancer-ip-support"].(string); ok {
+			support = value
+		}
+		if value, ok := config.TestData["load-balancer-ip"].(string); ok && value != "" {
+			ip = value
+		}
+	}
+	return support, ip
+}
+
+// Test functions for node management
+
+func testNodeInitialization(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	cloudProvider := ti.GetCloudProvider()
+
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeInitializationByAPI(ti, nodes)
+	}
+
+	// Get instances interface
+	instances, ok := ResolveInstances(cloudProvider)
+	if !ok {
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+	if nodes != nil {
+		return verifyExistingNodeInitialization(ctx, ti, instances, nodes)
+	}
+
+	// Create test node, registered uninitialized as by a kubelet with an
+	// external cloud provider
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name:         "init-test-node",
+		ProviderID:   "test-provider://init-test-node",
+		InstanceType: "test-instance-type",
+		Zone:         "test-zone",
+		Region:       "test-region",
+		Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.3"},
+			{Type: v1.NodeExternalIP, Address: "192.168.1.3"},
+		},
+		Taints: []v1.Taint{uninitializedTaint},
+	}
+
+	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+
+	// Without a running CCM, remove the taint as its node controller would
+	// once the node is initialized
+	if _, runningCCM := ti.(nodeDeletionAwaiter); !runningCCM {
+		nodeConfig.Taints = []v1.Taint{}
+		if _, err := ti.UpdateTestNode(ctx, nodeConfig); err != nil {
+			return fmt.Errorf("failed to untaint test node: %w", err)
+		}
+	}
+
+	if awaiter, ok := ti.(nodeInitializationAwaiter); ok {
+		if _, err := awaiter.WaitForNodeInitialized(ctx, node.Name, 2*time.Minute); err != nil {
+			return fmt.Errorf("node %s was not initialized: %w", node.Name, err)
+		}
+	} else if err := ti.WaitForTaintRemoved(ctx, node.Name, ccmtesting.UninitializedTaintKey, 2*time.Minute); err != nil {
+		return fmt.Errorf("node %s was not initialized: %w", node.Name, err)
+	}
+
+	// Test node initialization
+	providerID, err := instances.InstanceID(ctx, types.NodeName(node.Name))
+	if err != nil {
+		return fmt.Errorf("failed to get instance ID: %w", err)
+	}
+
+	if providerID == "" {
+		return fmt.Errorf("provider ID is empty")
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node initialization test completed. Provider ID: %s", providerID))
+
+	// Clean up
+	err = ti.DeleteTestNode(ctx, node.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete test node: %w", err)
+	}
+
+	return nil
+}
+
+func testNodeAddresses(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	cloudProvider := ti.GetCloudProvider()
+
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeAddressesByAPI(ti, nodes)
+	}
+
+	instances, ok := ResolveInstances(cloudProvider)
+	if !ok {
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+	if nodes != nil {
+		return verifyExistingNodeAddresses(ctx, ti, instances, nodes)
+	}
+
+	// Create test node with an address of every expected type, or an internal
+	// and external IP if none are configured
+	addressTypes := []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP}
+	if config := ti.GetConfig(); config != nil && len(config.ExpectedNodeAddressTypes) > 0 {
+		addressTypes = config.ExpectedNodeAddressTypes
+	}
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name:         "address-test-node",
+		ProviderID:   "test-provider://address-test-node",
+		InstanceType: "test-instance-type",
+		Zone:         "test-zone",
+		Region:       "test-region",
+		Addresses:    testNodeAddressesOfTypes("address-test-node", addressTypes),
+	}
+
+	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+
+	// Test node addresses
+	addresses, err := instances.NodeAddresses(ctx, types.NodeName(node.Name))
+	if err != nil {
+		return fmt.Errorf("failed to get node addresses: %w", err)
+	}
+
+	if len(addresses) == 0 {
+		return fmt.Errorf("no addresses returned for node")
+	}
+	if err := checkNodeAddressTypes(ti, node.Name, addresses); err != nil {
+		return err
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node addresses test completed. Found %d addresses", len(addresses)))
+
+	// Clean up
+	err = ti.DeleteTestNode(ctx, node.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete test node: %w", err)
+	}
+
+	return nil
+}
+
+func testNodeProviderID(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	cloudProvider := ti.GetCloudProvider()
+
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeProviderIDsByAPI(ti, nodes)
+	}
+
+	instances, ok := ResolveInstances(cloudProvider)
+	if !ok {
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+	if nodes != nil {
+		return verifyExistingNodeProviderIDs(ctx, ti, instances, nodes)
+	}
+
+	// Create test node
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name:         "providerid-test-node",
+		ProviderID:   "test-provider://providerid-test-node",
+		InstanceType: "test-instance-type",
+		Zone:         "test-zone",
+		Region:       "test-region",
+	}
+
+	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+
+	// Test provider ID
+	providerID, err := instances.InstanceID(ctx, types.NodeName(node.Name))
+	if err != nil {
+		return fmt.Errorf("failed to get provider ID: %w", err)
+	}
+
+	if providerID == "" {
+		return fmt.Errorf("provider ID is empty")
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Provider ID test completed. Provider ID: %s", providerID))
+
+	// Clean up
+	err = ti.DeleteTestNode(ctx, node.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete test node: %w", err)
+	}
+
+	return nil
+}
+
+func testNodeInstanceType(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	cloudProvider := ti.GetCloudProvider()
+
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeInstanceTypesByAPI(ti, nodes)
+	}
+
+	instances, ok := ResolveInstances(cloudProvider)
+	if !ok {
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+	if nodes != nil {
+		return verifyExistingNodeInstanceTypes(ctx, ti, instances, nodes)
+	}
+
+	// Create test node
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name:         "instancetype-test-node",
+		ProviderID:   "test-provider://instancetype-test-node",
+		InstanceType: "test-instance-type",
+		Zone:         "test-zone",
+		Region:       "test-region",
+	}
+
+	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+
+	// Test instance type
+	instanceType, err := instances.InstanceType(ctx, types.NodeName(node.Name))
+	if err != nil {
+		return fmt.Errorf("failed to get instance type: %w", err)
+	}
+
+	if instanceType == "" {
+		return fmt.Errorf("instance type is empty")
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Instance type test completed. Instance type: %s", instanceType))
+
+	// Clean up
+	err = ti.DeleteTestNode(ctx, node.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete test node: %w", err)
+	}
+
+	return nil
+}
+
+func testNodeZones(ti ccmtesting.TestInterface) error {
 	ctx := context.Background()
 	cloudProvider := ti.GetCloudProvider()
 
-	zones, ok := cloudProvider.Zones()
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeZonesByAPI(ti, nodes, false)
+	}
+
+	zones, ok := resolveZones(cloudProvider)
 	if !ok {
-		return fmt.Errorf("cloud provider does not support zones functionality")
+		return ccmtesting.NewUnsupportedError("zones")
+	}
+	if nodes != nil {
+		return verifyExistingNodeZones(ctx, ti, zones, nodes)
 	}
 
 	// Create test node
@@ -764,8 +2167,8 @@ func testNodeZones(ti ccmtesting.TestInterface) error {
 		return fmt.Errorf("failed to get zone: %w", err)
 	}
 
-	if zone.Region == "" {
-		return fmt.Errorf("zone region is empty")
+	if err := checkZone(ti, zone); err != nil {
+		return err
 	}
 
 	ti.GetTestResults().AddLog(fmt.Sprintf("Zones test completed. Region: %s, Zone: %s", zone.Region, zone.FailureDomain))
@@ -779,20 +2182,249 @@ func testNodeZones(ti ccmtesting.TestInterface) error {
 	return nil
 }
 
+// nodeLabelAwaiter is implemented by test interfaces that can wait for labels
+// to be applied to a node.
+type nodeLabelAwaiter interface {
+	AwaitNodeLabels(nodeName string, labels map[string]string, timeout time.Duration) (*v1.Node, error)
+}
+
+func testNodeTopologyLabels(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	cloudProvider := ti.GetCloudProvider()
+
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeZonesByAPI(ti, nodes, true)
+	}
+
+	zones, ok := resolveZones(cloudProvider)
+	if !ok {
+		return ccmtesting.NewUnsupportedError("zones")
+	}
+
+	awaiter, ok := ti.(nodeLabelAwaiter)
+	if !ok {
+		return fmt.Errorf("test interface cannot wait for node labels")
+	}
+	if nodes != nil {
+		return verifyExistingNodeTopologyLabels(ctx, ti, zones, awaiter, nodes)
+	}
+
+	// Create a node without topology labels, as the kubelet would register it
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name:       "topology-test-node",
+		ProviderID: "test-provider://topology-test-node",
+	}
+
+	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+
+	zone, err := zones.GetZoneByProviderID(ctx, node.Spec.ProviderID)
+	if err != nil {
+		return fmt.Errorf("failed to get zone for node %s: %w", node.Name, err)
+	}
+
+	expected := map[string]string{
+		v1.LabelTopologyZone:   zone.FailureDomain,
+		v1.LabelTopologyRegion: zone.Region,
+	}
+
+	// No CCM runs against the mock, so apply the labels its node controller
+	// would set on initialization
+	nodeConfig.Labels = expected
+	if _, err := ti.UpdateTestNode(ctx, nodeConfig); err != nil {
+		return fmt.Errorf("failed to label test node: %w", err)
+	}
+
+	if _, err := awaiter.AwaitNodeLabels(node.Name, expected, 30*time.Second); err != nil {
+		return fmt.Errorf("node %s was not labeled with its topology: %w", node.Name, err)
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node topology labels test completed. Region: %s, Zone: %s", zone.Region, zone.FailureDomain))
+
+	err = ti.DeleteTestNode(ctx, node.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete test node: %w", err)
+	}
+
+	return nil
+}
+
+// Capabilities reported as unsupported when a provider returns
+// cloudprovider.NotImplemented from an instance check, which unlike a false
+// result says nothing about the instance.
+const (
+	instanceExistenceCapability = "instance existence check"
+	instanceShutdownCapability  = "instance shutdown check"
+)
+
+// instanceRemover is implemented by cloud providers whose instances can be
+// removed on demand, such as the mock.
+type instanceRemover interface {
+	SetInstanceExists(providerID string, exists bool)
+}
+
+// nodeInitializationAwaiter is implemented by test interfaces that can watch
+// for a running CCM to initialize a node.
+type nodeInitializationAwaiter interface {
+	WaitForNodeInitialized(ctx context.Context, nodeName string, timeout time.Duration) (*v1.Node, error)
+}
+
+// nodeDeletionAwaiter is implemented by test interfaces that can wait for a
+// running CCM to delete a node.
+type nodeDeletionAwaiter interface {
+	WaitForNodeDeleted(nodeName string, timeout time.Duration) error
+}
+
+// notReadyCondition is the condition the node lifecycle controller acts on
+// once the kubelet of a removed instance stops reporting.
+var notReadyCondition = v1.NodeCondition{
+	Type:    v1.NodeReady,
+	Status:  v1.ConditionUnknown,
+	Reason:  "NodeStatusUnknown",
+	Message: "Kubelet stopped posting node status.",
+}
+
+func testNodeInstanceRemoved(ctx context.Context, ti ccmtesting.TestInterface) error {
+	cloudProvider := ti.GetCloudProvider()
+	if cloudProvider == nil {
+		return awaitNodeInstanceRemoved(ctx, ti)
+	}
+
+	instances, ok := ResolveInstances(cloudProvider)
+	if !ok {
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+
+	remover, ok := instances.(instanceRemover)
+	if !ok {
+		return ccmtesting.NewUnsupportedError("instance removal")
+	}
+
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name:       "lifecycle-test-node",
+		ProviderID: "test-provider://lifecycle-test-node",
+		Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue, Reason: "KubeletReady"}},
+	}
+
+	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+
+	remove, err := shouldDeleteNode(ctx, instances, node)
+	if errors.Is(err, cloudprovider.NotImplemented) {
+		return ccmtesting.NewUnsupportedError(instanceExistenceCapability)
+	}
+	if err != nil {
+		return err
+	}
+	if remove {
+		return fmt.Errorf("node %s would be deleted while Ready", node.Name)
+	}
+
+	remover.SetInstanceExists(node.Spec.ProviderID, false)
+	defer remover.SetInstanceExists(node.Spec.ProviderID, true)
+
+	// No kubelet or CCM runs against the mock, so apply the condition the node
+	// controller would set once the instance stops reporting
+	nodeConfig.Conditions = []v1.NodeCondition{notReadyCondition}
+	node, err = ti.UpdateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to mark test node NotReady: %w", err)
+	}
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node %s transitioned from Ready to NotReady after its instance was removed", node.Name))
+
+	remove, err = shouldDeleteNode(ctx, instances, node)
+	if errors.Is(err, cloudprovider.NotImplemented) {
+		return ccmtesting.NewUnsupportedError(instanceExistenceCapability)
+	}
+	if err != nil {
+		return err
+	}
+	if !remove {
+		return fmt.Errorf("node %s would be kept although its instance %s no longer exists", node.Name, node.Spec.ProviderID)
+	}
+
+	if err := ti.DeleteTestNode(ctx, node.Name); err != nil {
+		return fmt.Errorf("failed to delete test node: %w", err)
+	}
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node %s removed after its instance was deleted", node.Name))
+
+	return nil
+}
+
+// awaitNodeInstanceRemoved registers a NotReady node with no backing instance
+// and waits for the running CCM's node lifecycle controller to remove it.
+func awaitNodeInstanceRemoved(ctx context.Context, ti ccmtesting.TestInterface) error {
+	awaiter, ok := ti.(nodeDeletionAwaiter)
+	if !ok {
+		return fmt.Errorf("test interface cannot wait for node deletion")
+	}
+
+	nodeConfig := &ccmtesting.TestNodeConfig{
+		Name:       "lifecycle-test-node",
+		Conditions: []v1.NodeCondition{notReadyCondition},
+	}
+
+	node, err := ti.CreateTestNode(ctx, nodeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+	// The CCM only acts on the status once it is written, which create skips
+	if _, err := ti.UpdateTestNode(ctx, nodeConfig); err != nil {
+		return fmt.Errorf("failed to mark test node NotReady: %w", err)
+	}
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node %s registered NotReady without a backing instance", node.Name))
+
+	if err := awaiter.WaitForNodeDeleted(nodeConfig.Name, 4*time.Minute); err != nil {
+		return fmt.Errorf("node lifecycle controller did not remove node %s: %w", node.Name, err)
+	}
+	ti.GetTestResults().AddLog(fmt.Sprintf("Node %s removed by the node lifecycle controller", node.Name))
+
+	return nil
+}
+
+// shouldDeleteNode mirrors the decision of the cloud node lifecycle
+// controller: Ready nodes are kept, other nodes are deleted once the cloud
+// provider reports their instance no longer exists.
+func shouldDeleteNode(ctx context.Context, instances cloudprovider.Instances, node *v1.Node) (bool, error) {
+	if isNodeReady(node) {
+		return false, nil
+	}
+
+	exists, err := instances.InstanceExistsByProviderID(ctx, node.Spec.ProviderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check instance existence for node %s: %w", node.Name, err)
+	}
+	return !exists, nil
+}
+
 // Test functions for route management
 
+// providerRouteCreator is implemented by test interfaces that can create test
+// routes through the cloud provider themselves.
+type providerRouteCreator interface {
+	CreatesRoutesThroughProvider() bool
+}
+
 func testCreateRoute(ctx context.Context, ti ccmtesting.TestInterface) error {
 	cloudProvider := ti.GetCloudProvider()
 
 	routes, ok := cloudProvider.Routes()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support routes functionality")
+		return ccmtesting.NewUnsupportedError("routes")
 	}
 
 	// Create test route
 	routeConfig := &ccmtesting.TestRouteConfig{
 		Name:            "test-route",
-		ClusterName:     "test-cluster",
+		ClusterName:     testClusterName(ti),
 		TargetNode:      "route-test-node",
 		DestinationCIDR: "10.0.0.0/24",
 		Blackhole:       false,
@@ -803,8 +2435,23 @@ func testCreateRoute(ctx context.Context, ti ccmtesting.TestInterface) error {
 		return fmt.Errorf("failed to create test route: %w", err)
 	}
 
-	// Create route through cloud provider
-	err = routes.CreateRoute(ctx, "test-cluster", "test-route", route)
+	// Create route through cloud provider, unless the test interface did
+	if creator, ok := ti.(providerRouteCreator); ok && creator.CreatesRoutesThroughProvider() {
+		routeList, err := routes.ListRoutes(ctx, testClusterName(ti))
+		if err != nil {
+			return fmt.Errorf("failed to list routes: %w", err)
+		}
+		listed := slices.ContainsFunc(routeList, func(listed *cloudprovider.Route) bool {
+			return listed.TargetNode == route.TargetNode && listed.DestinationCIDR == route.DestinationCIDR
+		})
+		if !listed {
+			return fmt.Errorf("route %s to node %s for %s is not listed by the cloud provider", route.Name, route.TargetNode, route.DestinationCIDR)
+		}
+
+		ti.GetTestResults().AddLog("Route created successfully through the test interface")
+		return nil
+	}
+	err = routes.CreateRoute(ctx, testClusterName(ti), "test-route", route)
 	if err != nil {
 		return fmt.Errorf("failed to create route: %w", err)
 	}
@@ -818,16 +2465,26 @@ func testDeleteRoute(ctx context.Context, ti ccmtesting.TestInterface) error {
 
 	routes, ok := cloudProvider.Routes()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support routes functionality")
+		return ccmtesting.NewUnsupportedError("routes")
+	}
+
+	// Delete the route through the test interface, which stops tracking it
+	// and deletes it through the cloud provider if it created it there
+	if err := ti.DeleteTestRoute(ctx, "test-route"); err != nil {
+		return fmt.Errorf("failed to delete test route: %w", err)
+	}
+	if creator, ok := ti.(providerRouteCreator); ok && creator.CreatesRoutesThroughProvider() {
+		ti.GetTestResults().AddLog("Route deleted successfully through the test interface")
+		return nil
 	}
 
-	// Delete route
+	// Otherwise testCreateRoute created it through the cloud provider
 	route := &cloudprovider.Route{
 		Name:            "test-route",
 		TargetNode:      "route-test-node",
 		DestinationCIDR: "10.0.0.0/24",
 	}
-	err := routes.DeleteRoute(ctx, "test-cluster", route)
+	err := routes.DeleteRoute(ctx, testClusterName(ti), route)
 	if err != nil {
 		return fmt.Errorf("failed to delete route: %w", err)
 	}
@@ -841,11 +2498,11 @@ func testListRoutes(ctx context.Context, ti ccmtesting.TestInterface) error {
 
 	routes, ok := cloudProvider.Routes()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support routes functionality")
+		return ccmtesting.NewUnsupportedError("routes")
 	}
 
 	// List routes
-	routeList, err := routes.ListRoutes(ctx, "test-cluster")
+	routeList, err := routes.ListRoutes(ctx, testClusterName(ti))
 	if err != nil {
 		return fmt.Errorf("failed to list routes: %w", err)
 	}
@@ -854,22 +2511,180 @@ func testListRoutes(ctx context.Context, ti ccmtesting.TestInterface) error {
 	return nil
 }
 
+// podCIDRRouteNodes are the nodes testRoutesForPodCIDRs registers, with the
+// pod CIDRs the route controller must route to each of them.
+var podCIDRRouteNodes = []ccmtesting.TestNodeConfig{
+	{
+		Name:       "route-cidr-node-1",
+		ProviderID: "test-provider://route-cidr-node-1",
+		Addresses:  []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.11"}},
+		PodCIDRs:   []string{"10.244.1.0/24"},
+	},
+	{
+		Name:       "route-cidr-node-2",
+		ProviderID: "test-provider://route-cidr-node-2",
+		Addresses:  []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.12"}},
+		PodCIDRs:   []string{"10.244.2.0/24", "fd00:10:244:2::/64"},
+	},
+}
+
+// testRoutesForPodCIDRs registers nodes with pod CIDRs and waits for the
+// cloud routes the route controller derives from them: one per pod CIDR,
+// targeting the node the CIDR is assigned to.
+func testRoutesForPodCIDRs(ctx context.Context, ti ccmtesting.TestInterface) error {
+	routes, ok := ti.GetCloudProvider().Routes()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("routes")
+	}
+	clusterName := testClusterName(ti)
+	_, runningCCM := ti.(nodeDeletionAwaiter)
+
+	var nodes []*v1.Node
+	defer func() {
+		// A running CCM deletes the routes of deleted nodes itself
+		if !runningCCM {
+			deletePodCIDRRoutes(ctx, ti, routes, clusterName, nodes)
+		}
+		for _, node := range nodes {
+			if err := ti.DeleteTestNode(ctx, node.Name); err != nil {
+				ti.GetTestResults().AddLog(fmt.Sprintf("Failed to delete test node %s: %v", node.Name, err))
+			}
+		}
+	}()
+
+	for i := range podCIDRRouteNodes {
+		nodeConfig := podCIDRRouteNodes[i]
+		node, err := ti.CreateTestNode(ctx, &nodeConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create test node %s: %w", nodeConfig.Name, err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	// Without a running CCM, create the routes as its route controller would
+	// for every pod CIDR of a node
+	if !runningCCM {
+		for _, node := range nodes {
+			for _, podCIDR := range node.Spec.PodCIDRs {
+				route := &cloudprovider.Route{TargetNode: types.NodeName(node.Name), DestinationCIDR: podCIDR}
+				if err := routes.CreateRoute(ctx, clusterName, node.Name, route); err != nil {
+					return fmt.Errorf("failed to create route to %s for node %s: %w", podCIDR, node.Name, err)
+				}
+			}
+		}
+	}
+
+	if err := waitForPodCIDRRoutes(ctx, routes, clusterName, nodes, 2*time.Minute); err != nil {
+		return err
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Routes exist for the pod CIDRs of %d nodes", len(nodes)))
+	return nil
+}
+
+// waitForPodCIDRRoutes polls the cloud routes until each pod CIDR of nodes
+// is routed to its node, or the timeout elapses.
+func waitForPodCIDRRoutes(ctx context.Context, routes cloudprovider.Routes, clusterName string, nodes []*v1.Node, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		routeList, err := routes.ListRoutes(ctx, clusterName)
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to list routes: %w", err)
+		}
+		missing := missingPodCIDRRoutes(routeList, nodes)
+		if err == nil && len(missing) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for routes to pod CIDRs: missing %s", strings.Join(missing, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// missingPodCIDRRoutes returns a description of each pod CIDR of nodes that
+// no route targeting the node has as its destination.
+func missingPodCIDRRoutes(routeList []*cloudprovider.Route, nodes []*v1.Node) []string {
+	routed := sets.New[string]()
+	for _, route := range routeList {
+		routed.Insert(string(route.TargetNode) + "/" + route.DestinationCIDR)
+	}
+
+	var missing []string
+	for _, node := range nodes {
+		for _, podCIDR := range node.Spec.PodCIDRs {
+			if !routed.Has(node.Name + "/" + podCIDR) {
+				missing = append(missing, fmt.Sprintf("%s via %s", podCIDR, node.Name))
+			}
+		}
+	}
+	return missing
+}
+
+// deletePodCIDRRoutes deletes the routes to the pod CIDRs of nodes, logging
+// failures since it runs during cleanup.
+func deletePodCIDRRoutes(ctx context.Context, ti ccmtesting.TestInterface, routes cloudprovider.Routes, clusterName string, nodes []*v1.Node) {
+	routeList, err := routes.ListRoutes(ctx, clusterName)
+	if err != nil {
+		ti.GetTestResults().AddLog(fmt.Sprintf("Failed to list routes for cleanup: %v", err))
+		return
+	}
+	for _, route := range routeList {
+		for _, node := range nodes {
+			if string(route.TargetNode) != node.Name || !slices.Contains(node.Spec.PodCIDRs, route.DestinationCIDR) {
+				continue
+			}
+			if err := routes.DeleteRoute(ctx, clusterName, route); err != nil {
+				ti.GetTestResults().AddLog(fmt.Sprintf("Failed to delete route %s: %v", route.Name, err))
+			}
+		}
+	}
+}
+
 // Test functions for instances functionality
 
 func testInstanceExists(ctx context.Context, ti ccmtesting.TestInterface) error {
 	cloudProvider := ti.GetCloudProvider()
 
-	instances, ok := cloudProvider.Instances()
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeInitializationByAPI(ti, nodes)
+	}
+
+	instances, ok := ResolveInstances(cloudProvider)
 	if !ok {
-		return fmt.Errorf("cloud provider does not support instances functionality")
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+	if nodes != nil {
+		return verifyExistingNodeInitialization(ctx, ti, instances, nodes)
 	}
 
 	// Test instance existence by provider ID
 	exists, err := instances.InstanceExistsByProviderID(ctx, "test-provider://test-node")
+	if errors.Is(err, cloudprovider.NotImplemented) {
+		return ccmtesting.NewUnsupportedError(instanceExistenceCapability)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to check instance existence: %w", err)
 	}
 
+	// A false result without an error is the signal the node lifecycle
+	// controller deletes nodes on, unlike NotImplemented
+	if !exists {
+		ti.GetTestResults().AddLog("Instance exists check completed. Instance test-provider://test-node is gone")
+		return nil
+	}
+
 	ti.GetTestResults().AddLog(fmt.Sprintf("Instance exists check completed. Exists: %t", exists))
 	return nil
 }
@@ -877,13 +2692,27 @@ func testInstanceExists(ctx context.Context, ti ccmtesting.TestInterface) error
 func testInstanceShutdown(ctx context.Context, ti ccmtesting.TestInterface) error {
 	cloudProvider := ti.GetCloudProvider()
 
-	instances, ok := cloudProvider.Instances()
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodesNotShutdownByAPI(ti, nodes)
+	}
+
+	instances, ok := ResolveInstances(cloudProvider)
 	if !ok {
-		return fmt.Errorf("cloud provider does not support instances functionality")
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+	if nodes != nil {
+		return verifyExistingNodesNotShutdown(ctx, ti, instances, nodes)
 	}
 
 	// Test instance shutdown detection by provider ID
 	shutdown, err := instances.InstanceShutdownByProviderID(ctx, "test-provider://test-node")
+	if errors.Is(err, cloudprovider.NotImplemented) {
+		return ccmtesting.NewUnsupportedError(instanceShutdownCapability)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to check instance shutdown: %w", err)
 	}
@@ -895,9 +2724,20 @@ func testInstanceShutdown(ctx context.Context, ti ccmtesting.TestInterface) erro
 func testInstanceMetadata(ctx context.Context, ti ccmtesting.TestInterface) error {
 	cloudProvider := ti.GetCloudProvider()
 
-	instances, ok := cloudProvider.Instances()
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeProviderIDsByAPI(ti, nodes)
+	}
+
+	instances, ok := ResolveInstances(cloudProvider)
 	if !ok {
-		return fmt.Errorf("cloud provider does not support instances functionality")
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+	if nodes != nil {
+		return verifyExistingNodeProviderIDs(ctx, ti, instances, nodes)
 	}
 
 	// Test instance ID (closest to metadata)
@@ -912,12 +2752,21 @@ func testInstanceMetadata(ctx context.Context, ti ccmtesting.TestInterface) erro
 
 // Test functions for zones functionality
 
+// resolveZones returns the zones implementation of a cloud provider, reporting
+// none when there is no provider, as when testing an existing CCM.
+func resolveZones(cp cloudprovider.Interface) (cloudprovider.Zones, bool) {
+	if cp == nil {
+		return nil, false
+	}
+	return cp.Zones()
+}
+
 func testGetZone(ctx context.Context, ti ccmtesting.TestInterface) error {
 	cloudProvider := ti.GetCloudProvider()
 
-	zones, ok := cloudProvider.Zones()
+	zones, ok := resolveZones(cloudProvider)
 	if !ok {
-		return fmt.Errorf("cloud provider does not support zones functionality")
+		return ccmtesting.NewUnsupportedError("zones")
 	}
 
 	// Test zone retrieval
@@ -926,6 +2775,10 @@ func testGetZone(ctx context.Context, ti ccmtesting.TestInterface) error {
 		return fmt.Errorf("failed to get zone: %w", err)
 	}
 
+	if err := checkZone(ti, zone); err != nil {
+		return err
+	}
+
 	ti.GetTestResults().AddLog(fmt.Sprintf("Zone retrieved. Region: %s, Zone: %s", zone.Region, zone.FailureDomain))
 	return nil
 }
@@ -933,9 +2786,20 @@ func testGetZone(ctx context.Context, ti ccmtesting.TestInterface) error {
 func testGetZoneByProviderID(ctx context.Context, ti ccmtesting.TestInterface) error {
 	cloudProvider := ti.GetCloudProvider()
 
-	zones, ok := cloudProvider.Zones()
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil && cloudProvider == nil {
+		return verifyExistingNodeZonesByAPI(ti, nodes, false)
+	}
+
+	zones, ok := resolveZones(cloudProvider)
 	if !ok {
-		return fmt.Errorf("cloud provider does not support zones functionality")
+		return ccmtesting.NewUnsupportedError("zones")
+	}
+	if nodes != nil {
+		return verifyExistingNodeZones(ctx, ti, zones, nodes)
 	}
 
 	// Test zone retrieval by provider ID
@@ -944,6 +2808,10 @@ func testGetZoneByProviderID(ctx context.Context, ti ccmtesting.TestInterface) e
 		return fmt.Errorf("failed to get zone by provider ID: %w", err)
 	}
 
+	if err := checkZone(ti, zone); err != nil {
+		return err
+	}
+
 	ti.GetTestResults().AddLog(fmt.Sprintf("Zone by provider ID retrieved. Region: %s, Zone: %s", zone.Region, zone.FailureDomain))
 	return nil
 }
@@ -955,7 +2823,7 @@ func testListClusters(ctx context.Context, ti ccmtesting.TestInterface) error {
 
 	clusters, ok := cloudProvider.Clusters()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support clusters functionality")
+		return ccmtesting.NewUnsupportedError("clusters")
 	}
 
 	// Test cluster listing
@@ -973,11 +2841,11 @@ func testMaster(ctx context.Context, ti ccmtesting.TestInterface) error {
 
 	clusters, ok := cloudProvider.Clusters()
 	if !ok {
-		return fmt.Errorf("cloud provider does not support clusters functionality")
+		return ccmtesting.NewUnsupportedError("clusters")
 	}
 
 	// Test master node detection
-	master, err := clusters.Master(ctx, "test-cluster")
+	master, err := clusters.Master(ctx, testClusterName(ti))
 	if err != nil {
 		return fmt.Errorf("failed to get master: %w", err)
 	}
@@ -985,3 +2853,670 @@ func testMaster(ctx context.Context, ti ccmtesting.TestInterface) error {
 	ti.GetTestResults().AddLog(fmt.Sprintf("Master node: %s", master))
 	return nil
 }
+
+// Test functions for smoke testing
+
+func testSmokeLoadBalancer(ctx context.Context, ti ccmtesting.TestInterface) error {
+	lb, ok := ti.GetCloudProvider().LoadBalancer()
+	if !ok {
+		return ccmtesting.NewUnsupportedError("load balancer")
+	}
+
+	serviceConfig := &ccmtesting.TestServiceConfig{
+		Name:      "smoke-test-lb",
+		Namespace: "default",
+		Type:      v1.ServiceTypeLoadBalancer,
+		Ports: []v1.ServicePort{
+			{
+				Name:       "http",
+				Protocol:   v1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			},
+		},
+	}
+
+	service, err := ti.CreateTestService(ctx, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create test service: %w", err)
+	}
+
+	mockNodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mock-node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				},
+			},
+		},
+	}
+
+	status, err := lb.EnsureLoadBalancer(ctx, testClusterName(ti), service, mockNodes)
+	if err != nil {
+		return fmt.Errorf("failed to ensure load balancer: %w", err)
+	}
+	if status == nil || len(status.Ingress) == 0 {
+		return fmt.Errorf("load balancer status is empty")
+	}
+
+	err = lb.EnsureLoadBalancerDeleted(ctx, testClusterName(ti), service)
+	if err != nil {
+		return fmt.Errorf("failed to delete load balancer: %w", err)
+	}
+
+	err = ti.DeleteTestService(ctx, serviceConfig.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete test service: %w", err)
+	}
+
+	ti.GetTestResults().AddLog("Smoke load balancer check completed")
+	return nil
+}
+
+func testSmokeInstances(ctx context.Context, ti ccmtesting.TestInterface) error {
+	instances, ok := ResolveInstances(ti.GetCloudProvider())
+	if !ok {
+		return ccmtesting.NewUnsupportedError("instances")
+	}
+
+	exists, err := instances.InstanceExistsByProviderID(ctx, "test-provider://smoke-test-node")
+	if err != nil {
+		return fmt.Errorf("failed to look up instance: %w", err)
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Smoke instance lookup completed. Exists: %t", exists))
+	return nil
+}
+
+// Test functions for consistency checks
+
+// zoneConsistencyNodes are the nodes created by testNodeZoneConsistency when
+// not running against existing nodes.
+var zoneConsistencyNodes = []string{
+	"zone-consistency-node-a",
+	"zone-consistency-node-b",
+	"zone-consistency-node-c",
+}
+
+func testNodeZoneConsistency(ctx context.Context, ti ccmtesting.TestInterface) error {
+	zones, ok := resolveZones(ti.GetCloudProvider())
+	if !ok {
+		return ccmtesting.NewUnsupportedError("zones")
+	}
+
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil {
+		checked := 0
+		for i := range nodes {
+			if _, labeled := nodes[i].Labels[v1.LabelTopologyZone]; !labeled {
+				continue
+			}
+			if err := checkNodeZoneConsistency(ctx, zones, &nodes[i]); err != nil {
+				return err
+			}
+			checked++
+		}
+
+		ti.GetTestResults().AddLog(fmt.Sprintf("Verified zone labels of %d of %d existing nodes against the cloud provider", checked, len(nodes)))
+		return nil
+	}
+
+	var created []*v1.Node
+	var checkErr error
+	for _, name := range zoneConsistencyNodes {
+		nodeConfig := &ccmtesting.TestNodeConfig{
+			Name:       name,
+			ProviderID: "test-provider://" + name,
+		}
+
+		node, err := ti.CreateTestNode(ctx, nodeConfig)
+		if err != nil {
+			checkErr = fmt.Errorf("failed to create test node: %w", err)
+			break
+		}
+		created = append(created, node)
+
+		// No CCM runs against the mock, so label the node with the zone the
+		// provider reports for its name, as the node controller would
+		zone, err := zones.GetZoneByNodeName(ctx, types.NodeName(node.Name))
+		if err != nil {
+			checkErr = fmt.Errorf("failed to get zone for node %s: %w", node.Name, err)
+			break
+		}
+		nodeConfig.Labels = map[string]string{
+			v1.LabelTopologyZone:   zone.FailureDomain,
+			v1.LabelTopologyRegion: zone.Region,
+		}
+		node, err = ti.UpdateTestNode(ctx, nodeConfig)
+		if err != nil {
+			checkErr = fmt.Errorf("failed to label test node: %w", err)
+			break
+		}
+
+		if checkErr = checkNodeZoneConsistency(ctx, zones, node); checkErr != nil {
+			break
+		}
+	}
+
+	for _, node := range created {
+		if err := ti.DeleteTestNode(ctx, node.Name); err != nil && checkErr == nil {
+			checkErr = fmt.Errorf("failed to delete test node: %w", err)
+		}
+	}
+	if checkErr != nil {
+		return checkErr
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified zone labels of %d nodes against the cloud provider", len(created)))
+	return nil
+}
+
+// checkNodeZoneConsistency returns an error if the zone or region labels of
+// the node disagree with the zone the cloud provider reports for its provider ID.
+func checkNodeZoneConsistency(ctx context.Context, zones cloudprovider.Zones, node *v1.Node) error {
+	zone, err := zones.GetZoneByProviderID(ctx, node.Spec.ProviderID)
+	if err != nil {
+		return fmt.Errorf("failed to get zone for node %s: %w", node.Name, err)
+	}
+
+	if label := node.Labels[v1.LabelTopologyZone]; label != zone.FailureDomain {
+		return fmt.Errorf("node %s is labeled with zone %q but the cloud provider reports %q for provider ID %s", node.Name, label, zone.FailureDomain, node.Spec.ProviderID)
+	}
+	if label, ok := node.Labels[v1.LabelTopologyRegion]; ok && label != zone.Region {
+		return fmt.Errorf("node %s is labeled with region %q but the cloud provider reports %q for provider ID %s", node.Name, label, zone.Region, node.Spec.ProviderID)
+	}
+
+	return nil
+}
+
+// Helpers for tests that operate on the cluster's existing nodes
+
+// existingNodeLister is implemented by test interfaces that can list the nodes
+// already present in the cluster.
+type existingNodeLister interface {
+	GetExistingNodes() ([]v1.Node, error)
+}
+
+// testClusterName returns the cluster name to pass to the cloud provider,
+// which is the ClusterName of the TestConfig when set. A running CCM passes
+// its configured cluster name too, and providers use it to tell apart the
+// load balancers and routes of clusters sharing an account.
+func testClusterName(ti ccmtesting.TestInterface) string {
+	if config := ti.GetConfig(); config != nil && config.ClusterName != "" {
+		return config.ClusterName
+	}
+	return "test-cluster"
+}
+
+// existingNodes returns the cluster's existing nodes when the TestConfig asks
+// for them to be reused instead of creating test nodes. It returns nil when
+// test nodes should be created as usual.
+func existingNodes(ti ccmtesting.TestInterface) ([]v1.Node, error) {
+	config := ti.GetConfig()
+	if config == nil || !config.UseExistingNodes {
+		return nil, nil
+	}
+
+	lister, ok := ti.(existingNodeLister)
+	if !ok {
+		return nil, fmt.Errorf("test interface cannot list existing nodes")
+	}
+
+	nodes, err := lister.GetExistingNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no existing nodes found in the cluster")
+	}
+
+	return nodes, nil
+}
+
+// uninitializedTaint is the taint a kubelet with an external cloud provider
+// registers its node with, for the CCM to remove.
+var uninitializedTaint = v1.Taint{
+	Key:    ccmtesting.UninitializedTaintKey,
+	Value:  "true",
+	Effect: v1.TaintEffectNoSchedule,
+}
+
+func verifyExistingNodeInitialization(ctx context.Context, ti ccmtesting.TestInterface, instances cloudprovider.Instances, nodes []v1.Node) error {
+	for _, node := range nodes {
+		if node.Spec.ProviderID == "" {
+			return fmt.Errorf("node %s has no provider ID", node.Name)
+		}
+		if err := ti.WaitForTaintRemoved(ctx, node.Name, ccmtesting.UninitializedTaintKey, 30*time.Second); err != nil {
+			return fmt.Errorf("node %s was not initialized: %w", node.Name, err)
+		}
+
+		exists, err := instances.InstanceExistsByProviderID(ctx, node.Spec.ProviderID)
+		if errors.Is(err, cloudprovider.NotImplemented) {
+			return ccmtesting.NewUnsupportedError(instanceExistenceCapability)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check instance existence for node %s: %w", node.Name, err)
+		}
+		if !exists {
+			return fmt.Errorf("cloud provider reports no instance for node %s (provider ID %s)", node.Name, node.Spec.ProviderID)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified instances exist for %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodesNotShutdown(ctx context.Context, ti ccmtesting.TestInterface, instances cloudprovider.Instances, nodes []v1.Node) error {
+	for _, node := range nodes {
+		shutdown, err := instances.InstanceShutdownByProviderID(ctx, node.Spec.ProviderID)
+		if errors.Is(err, cloudprovider.NotImplemented) {
+			return ccmtesting.NewUnsupportedError(instanceShutdownCapability)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check instance shutdown for node %s: %w", node.Name, err)
+		}
+		if shutdown && isNodeReady(&node) {
+			return fmt.Errorf("cloud provider reports ready node %s as shut down", node.Name)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified shutdown state for %d existing nodes", len(nodes)))
+	return nil
+}
+
+// checkNodeAddressTypes returns an error unless the addresses are valid and
+// their types are exactly the ExpectedNodeAddressTypes of the TestConfig or,
+// if none are configured, include an InternalIP.
+func checkNodeAddressTypes(ti ccmtesting.TestInterface, nodeName string, addresses []v1.NodeAddress) error {
+	if err := ValidateNodeAddresses(addresses); err != nil {
+		return fmt.Errorf("node %s: %w", nodeName, err)
+	}
+
+	present := sets.New[v1.NodeAddressType]()
+	for _, address := range addresses {
+		present.Insert(address.Type)
+	}
+
+	var expected []v1.NodeAddressType
+	if config := ti.GetConfig(); config != nil {
+		expected = config.ExpectedNodeAddressTypes
+	}
+	if len(expected) == 0 {
+		if !present.Has(v1.NodeInternalIP) {
+			return fmt.Errorf("node %s has no %s address, got types %v", nodeName, v1.NodeInternalIP, sets.List(present))
+		}
+		return nil
+	}
+
+	want := sets.New(expected...)
+	if !present.Equal(want) {
+		return fmt.Errorf("node %s has address types %v, expected exactly %v (missing %v, unexpected %v)",
+			nodeName, sets.List(present), sets.List(want), sets.List(want.Difference(present)), sets.List(present.Difference(want)))
+	}
+	return nil
+}
+
+// ValidateNodeAddresses returns an error naming every address of an unknown
+// type or whose value does not match its type: IP addresses must parse as IPs,
+// and hostnames and DNS names must be DNS-1123 subdomains.
+func ValidateNodeAddresses(addresses []v1.NodeAddress) error {
+	var problems []error
+	for _, address := range addresses {
+		switch address.Type {
+		case v1.NodeInternalIP, v1.NodeExternalIP:
+			if net.ParseIP(address.Address) == nil {
+				problems = append(problems, fmt.Errorf("%s address %q is not a valid IP", address.Type, address.Address))
+			}
+		case v1.NodeHostName, v1.NodeInternalDNS, v1.NodeExternalDNS:
+			if errs := validation.IsDNS1123Subdomain(address.Address); len(errs) > 0 {
+				problems = append(problems, fmt.Errorf("%s address %q is not a valid DNS name: %s", address.Type, address.Address, strings.Join(errs, "; ")))
+			}
+		default:
+			problems = append(problems, fmt.Errorf("address %q has unknown type %q", address.Address, address.Type))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid node addresses: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// checkZone returns an error if the zone lacks a region or failure domain the
+// TestConfig expects, or reports one outside its allowlists.
+func checkZone(ti ccmtesting.TestInterface, zone cloudprovider.Zone) error {
+	config := ti.GetConfig()
+	if config == nil {
+		return nil
+	}
+
+	if zone.Region == "" {
+		if config.ExpectRegion {
+			return fmt.Errorf("zone region is empty but a region is expected (failure domain %q)", zone.FailureDomain)
+		}
+	} else if len(config.AllowedRegions) > 0 && !slices.Contains(config.AllowedRegions, zone.Region) {
+		return fmt.Errorf("zone region %s is not one of the allowed regions %v", zone.Region, config.AllowedRegions)
+	}
+
+	if zone.FailureDomain == "" {
+		if config.ExpectZone {
+			return fmt.Errorf("zone failure domain is empty but a zone is expected (region %q)", zone.Region)
+		}
+	} else if len(config.AllowedZones) > 0 && !slices.Contains(config.AllowedZones, zone.FailureDomain) {
+		return fmt.Errorf("zone failure domain %s is not one of the allowed zones %v", zone.FailureDomain, config.AllowedZones)
+	}
+	return nil
+}
+
+func verifyExistingNodeAddresses(ctx context.Context, ti ccmtesting.TestInterface, instances cloudprovider.Instances, nodes []v1.Node) error {
+	for _, node := range nodes {
+		cloudAddresses, err := instances.NodeAddressesByProviderID(ctx, node.Spec.ProviderID)
+		if err != nil {
+			return fmt.Errorf("failed to get addresses for node %s: %w", node.Name, err)
+		}
+		if len(cloudAddresses) == 0 {
+			return fmt.Errorf("no addresses returned for node %s", node.Name)
+		}
+		if err := checkNodeAddressTypes(ti, node.Name, cloudAddresses); err != nil {
+			return err
+		}
+
+		// Every internal IP on the node must be known to the cloud provider
+		for _, address := range node.Status.Addresses {
+			if address.Type != v1.NodeInternalIP {
+				continue
+			}
+			if !hasNodeAddress(cloudAddresses, address) {
+				return fmt.Errorf("node %s internal IP %s is not reported by the cloud provider", node.Name, address.Address)
+			}
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified addresses of %d existing nodes", len(nodes)))
+	return nil
+}
+
+// testNodeProviderIDFormat checks the provider ID of every existing node, or
+// of a created test node, against the canonical "<scheme>://<id>" form.
+func testNodeProviderIDFormat(ti ccmtesting.TestInterface) error {
+	ctx := context.Background()
+	scheme := ""
+	if config := ti.GetConfig(); config != nil {
+		scheme = config.ProviderIDScheme
+	}
+
+	nodes, err := existingNodes(ti)
+	if err != nil {
+		return err
+	}
+	if nodes != nil {
+		for _, node := range nodes {
+			if err := checkProviderID(node.Spec.ProviderID, scheme); err != nil {
+				return fmt.Errorf("node %s: %w", node.Name, err)
+			}
+		}
+		ti.GetTestResults().AddLog(fmt.Sprintf("Verified provider ID format of %d existing nodes", len(nodes)))
+		return nil
+	}
+
+	createdScheme := scheme
+	if createdScheme == "" {
+		createdScheme = "test-provider"
+	}
+	node, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{
+		Name:         "providerid-format-test-node",
+		ProviderID:   createdScheme + "://providerid-format-test-node",
+		InstanceType: "test-instance-type",
+		Zone:         "test-zone",
+		Region:       "test-region",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create test node: %w", err)
+	}
+	defer func() {
+		_ = ti.DeleteTestNode(ctx, node.Name)
+	}()
+
+	if err := checkProviderID(node.Spec.ProviderID, scheme); err != nil {
+		return fmt.Errorf("node %s: %w", node.Name, err)
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Provider ID format test completed. Provider ID: %s", node.Spec.ProviderID))
+	return nil
+}
+
+// DefaultProviderIDSchemes maps provider names to the scheme their CCM uses
+// in node provider IDs.
+var DefaultProviderIDSchemes = map[string]string{
+	"aws":   "aws",
+	"gcp":   "gce",
+	"azure": "azure",
+	"ibm":   "ibm",
+}
+
+// providerIDPattern splits a provider ID into its scheme and the rest.
+var providerIDPattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*)://(.*)$`)
+
+// checkProviderID returns an error if providerID is not of the form
+// "<scheme>://<id>", or if scheme is set and the provider ID uses another.
+func checkProviderID(providerID, scheme string) error {
+	if providerID == "" {
+		return fmt.Errorf("provider ID is empty")
+	}
+
+	match := providerIDPattern.FindStringSubmatch(providerID)
+	if match == nil {
+		return fmt.Errorf("provider ID %q is not of the form <scheme>://<id>", providerID)
+	}
+	if scheme != "" && match[1] != scheme {
+		return fmt.Errorf("provider ID %q has scheme %s, expected %s", providerID, match[1], scheme)
+	}
+	// AWS provider IDs start their path with a slash, as in aws:///zone/id
+	if strings.Trim(match[2], "/") == "" {
+		return fmt.Errorf("provider ID %q has an empty id after the scheme", providerID)
+	}
+	return nil
+}
+
+func verifyExistingNodeProviderIDs(ctx context.Context, ti ccmtesting.TestInterface, instances cloudprovider.Instances, nodes []v1.Node) error {
+	for _, node := range nodes {
+		if node.Spec.ProviderID == "" {
+			return fmt.Errorf("node %s has no provider ID", node.Name)
+		}
+
+		instanceID, err := instances.InstanceID(ctx, types.NodeName(node.Name))
+		if err != nil {
+			return fmt.Errorf("failed to get instance ID for node %s: %w", node.Name, err)
+		}
+		if instanceID == "" {
+			return fmt.Errorf("instance ID is empty for node %s", node.Name)
+		}
+		if !strings.Contains(node.Spec.ProviderID, instanceID) {
+			return fmt.Errorf("node %s provider ID %s does not match instance ID %s", node.Name, node.Spec.ProviderID, instanceID)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified provider IDs of %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodeInstanceTypes(ctx context.Context, ti ccmtesting.TestInterface, instances cloudprovider.Instances, nodes []v1.Node) error {
+	for _, node := range nodes {
+		instanceType, err := instances.InstanceTypeByProviderID(ctx, node.Spec.ProviderID)
+		if err != nil {
+			return fmt.Errorf("failed to get instance type for node %s: %w", node.Name, err)
+		}
+		if instanceType == "" {
+			return fmt.Errorf("instance type is empty for node %s", node.Name)
+		}
+		if label, ok := node.Labels[v1.LabelInstanceTypeStable]; ok && label != instanceType {
+			return fmt.Errorf("node %s is labeled with instance type %s but the cloud provider reports %s", node.Name, label, instanceType)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified instance types of %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodeZones(ctx context.Context, ti ccmtesting.TestInterface, zones cloudprovider.Zones, nodes []v1.Node) error {
+	for _, node := range nodes {
+		zone, err := zones.GetZoneByProviderID(ctx, node.Spec.ProviderID)
+		if err != nil {
+			return fmt.Errorf("failed to get zone for node %s: %w", node.Name, err)
+		}
+		if err := checkZone(ti, zone); err != nil {
+			return fmt.Errorf("node %s: %w", node.Name, err)
+		}
+		if label, ok := node.Labels[v1.LabelTopologyZone]; ok && label != zone.FailureDomain {
+			return fmt.Errorf("node %s is labeled with zone %s but the cloud provider reports %s", node.Name, label, zone.FailureDomain)
+		}
+		if label, ok := node.Labels[v1.LabelTopologyRegion]; ok && label != zone.Region {
+			return fmt.Errorf("node %s is labeled with region %s but the cloud provider reports %s", node.Name, label, zone.Region)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified zones of %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodeTopologyLabels(ctx context.Context, ti ccmtesting.TestInterface, zones cloudprovider.Zones, awaiter nodeLabelAwaiter, nodes []v1.Node) error {
+	for _, node := range nodes {
+		zone, err := zones.GetZoneByProviderID(ctx, node.Spec.ProviderID)
+		if err != nil {
+			return fmt.Errorf("failed to get zone for node %s: %w", node.Name, err)
+		}
+
+		expected := map[string]string{
+			v1.LabelTopologyZone:   zone.FailureDomain,
+			v1.LabelTopologyRegion: zone.Region,
+		}
+		if _, err := awaiter.AwaitNodeLabels(node.Name, expected, 30*time.Second); err != nil {
+			return fmt.Errorf("node %s does not carry its cloud topology: %w", node.Name, err)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified topology labels of %d existing nodes", len(nodes)))
+	return nil
+}
+
+// The ByAPI variants verify existing nodes through the Kubernetes API alone,
+// for when there is no cloud provider to ask, as when testing an existing
+// CCM: they check what its node controllers should have recorded on the nodes.
+
+func verifyExistingNodeInitializationByAPI(ti ccmtesting.TestInterface, nodes []v1.Node) error {
+	for _, node := range nodes {
+		if node.Spec.ProviderID == "" {
+			return fmt.Errorf("node %s has no provider ID", node.Name)
+		}
+		if ccmtesting.NodeHasTaint(&node, ccmtesting.UninitializedTaintKey) {
+			return fmt.Errorf("node %s was not initialized: it still has taint %s", node.Name, ccmtesting.UninitializedTaintKey)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified initialization of %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodesNotShutdownByAPI(ti ccmtesting.TestInterface, nodes []v1.Node) error {
+	for _, node := range nodes {
+		if isNodeReady(&node) && ccmtesting.NodeHasTaint(&node, cloudproviderapi.TaintNodeShutdown) {
+			return fmt.Errorf("ready node %s is tainted as shut down", node.Name)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified shutdown state for %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodeAddressesByAPI(ti ccmtesting.TestInterface, nodes []v1.Node) error {
+	for _, node := range nodes {
+		if err := checkNodeAddressTypes(ti, node.Name, node.Status.Addresses); err != nil {
+			return err
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified addresses of %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodeProviderIDsByAPI(ti ccmtesting.TestInterface, nodes []v1.Node) error {
+	for _, node := range nodes {
+		if err := checkProviderID(node.Spec.ProviderID, ""); err != nil {
+			return fmt.Errorf("node %s: %w", node.Name, err)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified provider IDs of %d existing nodes", len(nodes)))
+	return nil
+}
+
+func verifyExistingNodeInstanceTypesByAPI(ti ccmtesting.TestInterface, nodes []v1.Node) error {
+	for _, node := range nodes {
+		if node.Labels[v1.LabelInstanceTypeStable] == "" {
+			return fmt.Errorf("node %s has no %s label", node.Name, v1.LabelInstanceTypeStable)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified instance types of %d existing nodes", len(nodes)))
+	return nil
+}
+
+// verifyExistingNodeZonesByAPI checks the topology labels of the nodes against
+// the TestConfig, requiring a zone label on every node if requireLabels is set.
+func verifyExistingNodeZonesByAPI(ti ccmtesting.TestInterface, nodes []v1.Node, requireLabels bool) error {
+	for _, node := range nodes {
+		zone := cloudprovider.Zone{
+			FailureDomain: node.Labels[v1.LabelTopologyZone],
+			Region:        node.Labels[v1.LabelTopologyRegion],
+		}
+		if requireLabels && zone.FailureDomain == "" {
+			return fmt.Errorf("node %s has no %s label", node.Name, v1.LabelTopologyZone)
+		}
+		if err := checkZone(ti, zone); err != nil {
+			return fmt.Errorf("node %s: %w", node.Name, err)
+		}
+	}
+
+	ti.GetTestResults().AddLog(fmt.Sprintf("Verified zone labels of %d existing nodes", len(nodes)))
+	return nil
+}
+
+// isNodeReady returns whether the node has a true Ready condition.
+func isNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// testNodeAddressesOfTypes returns one address of each of the given types for
+// a test node with the given name.
+func testNodeAddressesOfTypes(nodeName string, addressTypes []v1.NodeAddressType) []v1.NodeAddress {
+	values := map[v1.NodeAddressType]string{
+		v1.NodeInternalIP:  "10.0.0.4",
+		v1.NodeExternalIP:  "192.168.1.4",
+		v1.NodeHostName:    nodeName,
+		v1.NodeInternalDNS: nodeName + ".internal.example.com",
+		v1.NodeExternalDNS: nodeName + ".example.com",
+	}
+	addresses := make([]v1.NodeAddress, 0, len(addressTypes))
+	for _, addressType := range addressTypes {
+		addresses = append(addresses, v1.NodeAddress{Type: addressType, Address: values[addressType]})
+	}
+	return addresses
+}
+
+// hasNodeAddress returns whether addresses contains the given address.
+func hasNodeAddress(addresses []v1.NodeAddress, address v1.NodeAddress) bool {
+	for _, candidate := range addresses {
+		if candidate.Type == address.Type && candidate.Address == address.Address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1901 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	cloudprovider "k8s.io/cloud-provider"
+
+	ccmtesting "github.com/miyadav/cloud-provider-testing-interface"
+)
+
+// newMockTestInterface returns a CCMTestInterface backed by a fresh mock cloud
+// provider with its test environment already set up
+func newMockTestInterface(t *testing.T) (*CCMTestInterface, *MockCloudProvider) {
+	t.Helper()
+
+	provider := NewMockCloudProvider()
+	ti := NewCCMTestInterface(provider)
+	config := &ccmtesting.TestConfig{
+		ProviderName: "mock",
+		ClusterName:  "test-cluster",
+		TestData:     map[string]interface{}{},
+	}
+	if err := ti.SetupTestEnvironment(config); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+	return ti, provider
+}
+
+// TestLoadBalancerNodePorts tests that the NodePorts test passes against the mock
+// and that the provider saw every NodePort of the multi-port service
+func TestLoadBalancerNodePorts(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+
+	if err := testLoadBalancerNodePorts(ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	nodePorts, found := provider.GetMockLoadBalancer().GetEnsuredNodePorts("default", "nodeport-test-lb")
+	if !found {
+		t.Fatal("Expected the load balancer to be ensured")
+	}
+	if len(nodePorts) != 2 || nodePorts[0] != 30080 || nodePorts[1] != 30443 {
+		t.Errorf("Expected node ports [30080 30443], got %v", nodePorts)
+	}
+}
+
+// TestLoadBalancerUsesConfiguredClusterName tests that load balancers are
+// ensured under the ClusterName of the TestConfig rather than a default
+func TestLoadBalancerUsesConfiguredClusterName(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+	ti.GetConfig().ClusterName = "prod-east"
+
+	if err := testCreateLoadBalancer(ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	clusterName, found := provider.GetMockLoadBalancer().GetEnsuredClusterName("default", "test-loadbalancer")
+	if !found {
+		t.Fatal("Expected the load balancer to be ensured")
+	}
+	if clusterName != "prod-east" {
+		t.Errorf("Expected cluster name prod-east, got %s", clusterName)
+	}
+}
+
+// TestSuitesUseConfiguredClusterName tests that the load balancer, routes and
+// clusters suites pass the ClusterName of the TestConfig to every provider
+// call that takes one
+func TestSuitesUseConfiguredClusterName(t *testing.T) {
+	provider := &clusterNameProvider{MockCloudProvider: NewMockCloudProvider()}
+	ti := NewCCMTestInterface(provider)
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", ClusterName: "prod-east"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	runner := ccmtesting.NewTestRunner(ti)
+	for _, suite := range []ccmtesting.TestSuite{CreateLoadBalancerTestSuite(), CreateRouteTestSuite(), CreateClustersTestSuite()} {
+		if err := runner.AddTestSuiteChecked(suite); err != nil {
+			t.Fatalf("Failed to add suite: %v", err)
+		}
+	}
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	for _, method := range []string{"EnsureLoadBalancer", "EnsureLoadBalancerDeleted", "CreateRoute", "ListRoutes", "DeleteRoute", "Master"} {
+		if len(provider.clusterNames[method]) == 0 {
+			t.Errorf("Expected %s to be called", method)
+		}
+	}
+	for method, names := range provider.clusterNames {
+		for _, name := range names {
+			if name != "prod-east" {
+				t.Errorf("Expected %s to be called with cluster name prod-east, got %s", method, name)
+			}
+		}
+	}
+}
+
+// clusterNameProvider wraps the mock provider to record the cluster name
+// passed to each load balancer, routes and clusters call.
+type clusterNameProvider struct {
+	*MockCloudProvider
+
+	mu           sync.Mutex
+	clusterNames map[string][]string
+}
+
+func (p *clusterNameProvider) record(method, clusterName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.clusterNames == nil {
+		p.clusterNames = make(map[string][]string)
+	}
+	p.clusterNames[method] = append(p.clusterNames[method], clusterName)
+}
+
+// LoadBalancer returns the mock load balancer wrapped to record cluster names.
+func (p *clusterNameProvider) LoadBalancer() (cloudprovider.LoadBalancer, bool) {
+	return &clusterNameLoadBalancer{MockLoadBalancer: p.GetMockLoadBalancer(), provider: p}, true
+}
+
+// Routes returns the mock routes wrapped to record cluster names.
+func (p *clusterNameProvider) Routes() (cloudprovider.Routes, bool) {
+	return &clusterNameRoutes{MockRoutes: p.GetMockRoutes(), provider: p}, true
+}
+
+// Clusters returns the mock clusters wrapped to record cluster names.
+func (p *clusterNameProvider) Clusters() (cloudprovider.Clusters, bool) {
+	return &clusterNameClusters{MockClusters: p.GetMockClusters(), provider: p}, true
+}
+
+type clusterNameLoadBalancer struct {
+	*MockLoadBalancer
+	provider *clusterNameProvider
+}
+
+func (lb *clusterNameLoadBalancer) GetLoadBalancer(ctx context.Context, clusterName string, service *v1.Service) (*v1.LoadBalancerStatus, bool, error) {
+	lb.provider.record("GetLoadBalancer", clusterName)
+	return lb.MockLoadBalancer.GetLoadBalancer(ctx, clusterName, service)
+}
+
+func (lb *clusterNameLoadBalancer) GetLoadBalancerName(ctx context.Context, clusterName string, service *v1.Service) string {
+	lb.provider.record("GetLoadBalancerName", clusterName)
+	return lb.MockLoadBalancer.GetLoadBalancerName(ctx, clusterName, service)
+}
+
+func (lb *clusterNameLoadBalancer) EnsureLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+	lb.provider.record("EnsureLoadBalancer", clusterName)
+	return lb.MockLoadBalancer.EnsureLoadBalancer(ctx, clusterName, service, nodes)
+}
+
+func (lb *clusterNameLoadBalancer) UpdateLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error {
+	lb.provider.record("UpdateLoadBalancer", clusterName)
+	return lb.MockLoadBalancer.UpdateLoadBalancer(ctx, clusterName, service, nodes)
+}
+
+func (lb *clusterNameLoadBalancer) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *v1.Service) error {
+	lb.provider.record("EnsureLoadBalancerDeleted", clusterName)
+	return lb.MockLoadBalancer.EnsureLoadBalancerDeleted(ctx, clusterName, service)
+}
+
+type clusterNameRoutes struct {
+	*MockRoutes
+	provider *clusterNameProvider
+}
+
+func (r *clusterNameRoutes) ListRoutes(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error) {
+	r.provider.record("ListRoutes", clusterName)
+	return r.MockRoutes.ListRoutes(ctx, clusterName)
+}
+
+func (r *clusterNameRoutes) CreateRoute(ctx context.Context, clusterName string, nameHint string, route *cloudprovider.Route) error {
+	r.provider.record("CreateRoute", clusterName)
+	return r.MockRoutes.CreateRoute(ctx, clusterName, nameHint, route)
+}
+
+func (r *clusterNameRoutes) DeleteRoute(ctx context.Context, clusterName string, route *cloudprovider.Route) error {
+	r.provider.record("DeleteRoute", clusterName)
+	return r.MockRoutes.DeleteRoute(ctx, clusterName, route)
+}
+
+type clusterNameClusters struct {
+	*MockClusters
+	provider *clusterNameProvider
+}
+
+func (c *clusterNameClusters) Master(ctx context.Context, clusterName string) (string, error) {
+	c.provider.record("Master", clusterName)
+	return c.MockClusters.Master(ctx, clusterName)
+}
+
+// TestLoadBalancerWithoutNodePorts tests that a service disabling NodePort
+// allocation reaches the provider with the flag and is not expected to use NodePorts
+func TestLoadBalancerWithoutNodePorts(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+
+	if err := testLoadBalancerWithoutNodePorts(ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockLB := provider.GetMockLoadBalancer()
+	ensured, found := mockLB.GetEnsuredService("default", "no-nodeport-test-lb")
+	if !found {
+		t.Fatal("Expected the load balancer to be ensured")
+	}
+	if ensured.Spec.AllocateLoadBalancerNodePorts == nil || *ensured.Spec.AllocateLoadBalancerNodePorts {
+		t.Errorf("Expected the provider to see allocateLoadBalancerNodePorts=false, got %v", ensured.Spec.AllocateLoadBalancerNodePorts)
+	}
+	if usesNodePorts, _ := mockLB.UsesNodePorts("default", "no-nodeport-test-lb"); usesNodePorts {
+		t.Error("Expected the load balancer not to use node ports")
+	}
+	if usesNodePorts, _ := mockLB.UsesNodePorts("default", "nodeport-test-lb"); usesNodePorts {
+		t.Error("Expected no record for a service that was never ensured")
+	}
+}
+
+// TestLoadBalancerWithoutPorts tests that a portless service is rejected as
+// invalid input and that a provider ensuring one fails the test
+func TestLoadBalancerWithoutPorts(t *testing.T) {
+	t.Run("rejected", func(t *testing.T) {
+		ti, provider := newMockTestInterface(t)
+
+		if err := testLoadBalancerWithoutPorts(ti); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if provider.GetMockLoadBalancer().HasLoadBalancer("default", "portless-test-lb") {
+			t.Error("Expected no load balancer for the portless service")
+		}
+	})
+
+	t.Run("provider accepts", func(t *testing.T) {
+		ti, provider := newMockTestInterface(t)
+		mockLB := provider.GetMockLoadBalancer()
+		mockLB.EnsureLoadBalancerFunc = func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+			return &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.168.1.100"}}}, nil
+		}
+
+		err := testLoadBalancerWithoutPorts(ti)
+		if err == nil || !strings.Contains(err.Error(), "provider ensured a load balancer for service default/portless-test-lb without ports") {
+			t.Errorf("Expected the accepted portless load balancer to fail the test, got %v", err)
+		}
+	})
+}
+
+// TestLoadBalancerReconcileDrift tests that repeated ensures pass when the status
+// is stable and fail when it drifts or load balancers accumulate
+func TestLoadBalancerReconcileDrift(t *testing.T) {
+	tests := []struct {
+		name    string
+		ensure  func(lb *MockLoadBalancer) func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error)
+		wantErr string
+	}{
+		{
+			name: "stable status",
+		},
+		{
+			name: "drifting status",
+			ensure: func(lb *MockLoadBalancer) func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+				calls := 0
+				return func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+					calls++
+					return &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: fmt.Sprintf("192.0.2.%d", calls)}}}, nil
+				}
+			},
+			wantErr: "load balancer status drifted on cycle 2",
+		},
+		{
+			name: "reordered ingress",
+			ensure: func(lb *MockLoadBalancer) func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+				calls := 0
+				return func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+					calls++
+					ingress := []v1.LoadBalancerIngress{{IP: "192.0.2.1"}, {Hostname: "lb.example.com"}}
+					if calls%2 == 0 {
+						ingress[0], ingress[1] = ingress[1], ingress[0]
+					}
+					return &v1.LoadBalancerStatus{Ingress: ingress}, nil
+				}
+			},
+		},
+		{
+			name: "accumulating load balancers",
+			ensure: func(lb *MockLoadBalancer) func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+				calls := 0
+				return func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+					calls++
+					leaked := service.DeepCopy()
+					leaked.Name = fmt.Sprintf("%s-%d", service.Name, calls)
+					lb.EnsureLoadBalancerFunc = nil
+					defer func() { lb.EnsureLoadBalancerFunc = nil }()
+					return lb.EnsureLoadBalancer(ctx, clusterName, leaked, nodes)
+				}
+			},
+			wantErr: "load balancers accumulated on cycle 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			ti.GetConfig().TestData["reconcile-cycles"] = 4
+			mockLB := provider.GetMockLoadBalancer()
+			if tt.ensure != nil {
+				mockLB.EnsureLoadBalancerFunc = tt.ensure(mockLB)
+			}
+
+			err := testLoadBalancerReconcileDrift(ti)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			hashes, ok := ti.GetTestResults().Metrics["reconcileStatusHashes"].([]string)
+			if !ok || len(hashes) != 4 {
+				t.Errorf("Expected 4 recorded status hashes, got %v", ti.GetTestResults().Metrics["reconcileStatusHashes"])
+			}
+		})
+	}
+}
+
+// TestLoadBalancerReconcileDriftRequiresDeepConformance tests that the drift
+// test is skipped unless the deep-conformance label is enabled
+func TestLoadBalancerReconcileDriftRequiresDeepConformance(t *testing.T) {
+	for _, deep := range []bool{false, true} {
+		ti, _ := newMockTestInterface(t)
+		runner := ccmtesting.NewTestRunner(ti)
+		if deep {
+			runner.EnabledLabels = []string{DeepConformanceLabel}
+		}
+		suite := CreateLoadBalancerTestSuite()
+		for _, test := range suite.Tests {
+			if test.Name == "LoadBalancerReconcileDrift" {
+				suite.Tests = []ccmtesting.Test{test}
+				break
+			}
+		}
+		runner.AddTestSuite(suite)
+		if err := runner.RunTests(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		results := runner.GetResults()
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results))
+		}
+		for _, result := range results {
+			if result.Test.Skip == deep {
+				t.Errorf("Expected drift test skipped=%t with deep conformance %t", !deep, deep)
+			}
+		}
+	}
+}
+
+// TestLoadBalancerNodeRemoval tests that the node removal test passes when the
+// deleted node is dropped from the load balancer hosts and fails otherwise
+func TestLoadBalancerNodeRemoval(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error
+		wantErr string
+	}{
+		{
+			name: "node dropped",
+		},
+		{
+			name: "update rejected",
+			update: func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error {
+				return errors.New("backend pool locked")
+			},
+			wantErr: "failed to update load balancer hosts for service default/node-removal-test-lb: backend pool locked",
+		},
+		{
+			name: "update not recorded",
+			update: func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error {
+				return nil
+			},
+			wantErr: "UpdateLoadBalancer was not called for service default/node-removal-test-lb after node lb-member-b was deleted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			mockLB := provider.GetMockLoadBalancer()
+			mockLB.UpdateLoadBalancerFunc = tt.update
+
+			err := testLoadBalancerNodeRemoval(context.Background(), ti)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			updated, ok := mockLB.GetUpdatedNodes("default", "node-removal-test-lb")
+			if !ok || strings.Join(updated, ",") != "lb-member-a" {
+				t.Errorf("Expected UpdateLoadBalancer with only [lb-member-a], got %v", updated)
+			}
+		})
+	}
+}
+
+// TestLoadBalancerPortStatus tests that the port status test passes when every
+// ingress point reports each service port and fails otherwise
+func TestLoadBalancerPortStatus(t *testing.T) {
+	portError := "listener not provisioned"
+
+	tests := []struct {
+		name    string
+		ports   []v1.PortStatus
+		mock    bool
+		wantErr string
+	}{
+		{
+			name: "ports reported by the mock",
+			mock: true,
+		},
+		{
+			name:    "no port status",
+			wantErr: "ingress 192.168.1.100 reports no status for port 80/TCP",
+		},
+		{
+			name:    "missing port",
+			ports:   []v1.PortStatus{{Port: 80, Protocol: v1.ProtocolTCP}},
+			wantErr: "ingress 192.168.1.100 reports no status for port 443/TCP",
+		},
+		{
+			name: "port with error",
+			ports: []v1.PortStatus{
+				{Port: 80, Protocol: v1.ProtocolTCP},
+				{Port: 443, Protocol: v1.ProtocolTCP, Error: &portError},
+			},
+			wantErr: "ingress 192.168.1.100 reports port 443/TCP with error: listener not provisioned",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			if !tt.mock {
+				provider.GetMockLoadBalancer().EnsureLoadBalancerFunc = func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+					return &v1.LoadBalancerStatus{
+						Ingress: []v1.LoadBalancerIngress{{IP: "192.168.1.100", Ports: tt.ports}},
+					}, nil
+				}
+			}
+
+			err := testLoadBalancerPortStatus(context.Background(), ti)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestLoadBalancerMultipleServices tests that load balancers of several
+// services are independent, and that a provider naming them by cluster fails
+func TestLoadBalancerMultipleServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		nameFunc func(ctx context.Context, clusterName string, service *v1.Service) string
+		getFunc  func(ctx context.Context, clusterName string, service *v1.Service) (*v1.LoadBalancerStatus, bool, error)
+		wantErr  string
+	}{
+		{
+			name: "independent load balancers",
+		},
+		{
+			name: "named by cluster",
+			nameFunc: func(ctx context.Context, clusterName string, service *v1.Service) string {
+				return clusterName
+			},
+			wantErr: "services default/multi-lb-test-0 and default/multi-lb-test-1 share load balancer name test-cluster",
+		},
+		{
+			name: "status keyed by cluster",
+			getFunc: func(ctx context.Context, clusterName string, service *v1.Service) (*v1.LoadBalancerStatus, bool, error) {
+				return &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.168.1.100"}}}, true, nil
+			},
+			wantErr: "load balancer for service multi-lb-test-0: load balancer ingress differs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			mockLB := provider.GetMockLoadBalancer()
+			mockLB.GetLoadBalancerNameFunc = tt.nameFunc
+			mockLB.GetLoadBalancerFunc = tt.getFunc
+
+			err := testMultipleLoadBalancers(context.Background(), ti)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if count := mockLB.LoadBalancerCount(); count != 0 {
+				t.Errorf("Expected no load balancers left, got %d", count)
+			}
+		})
+	}
+}
+
+// TestHealthCheckNodePort tests that the health check node port reaches the
+// load balancer when the traffic policy turns Local and is released again
+func TestHealthCheckNodePort(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+
+	if err := testHealthCheckNodePort(context.Background(), ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ensured, found := provider.GetMockLoadBalancer().GetEnsuredService("default", "health-check-test-lb")
+	if !found {
+		t.Fatal("Expected the load balancer to be ensured")
+	}
+	if ensured.Spec.ExternalTrafficPolicy != v1.ServiceExternalTrafficPolicyCluster || ensured.Spec.HealthCheckNodePort != 0 {
+		t.Errorf("Expected the last ensure with Cluster policy and no health check node port, got %s and %d",
+			ensured.Spec.ExternalTrafficPolicy, ensured.Spec.HealthCheckNodePort)
+	}
+}
+
+// TestLoadBalancerDeleteIdempotency tests that repeated and premature deletes
+// pass against the mock, and that a provider failing to delete a missing load
+// balancer is reported
+func TestLoadBalancerDeleteIdempotency(t *testing.T) {
+	t.Run("idempotent", func(t *testing.T) {
+		ti, provider := newMockTestInterface(t)
+
+		if err := testLoadBalancerDeleteIdempotency(context.Background(), ti); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if provider.GetMockLoadBalancer().HasLoadBalancer("default", "delete-idempotency-test-lb") {
+			t.Error("Expected the load balancer to be deleted")
+		}
+	})
+
+	t.Run("missing load balancer rejected", func(t *testing.T) {
+		ti, provider := newMockTestInterface(t)
+		mockLB := provider.GetMockLoadBalancer()
+		mockLB.EnsureLoadBalancerDeletedFunc = func(ctx context.Context, clusterName string, service *v1.Service) error {
+			if _, exists, _ := mockLB.GetLoadBalancer(ctx, clusterName, service); !exists {
+				return fmt.Errorf("load balancer not found")
+			}
+			return nil
+		}
+
+		err := testLoadBalancerDeleteIdempotency(context.Background(), ti)
+		if err == nil || !strings.Contains(err.Error(), "never ensured") {
+			t.Errorf("Expected the delete of a missing load balancer to fail the test, got %v", err)
+		}
+	})
+}
+
+// TestLoadBalancerRequestedIP tests the honored and unsupported expectations
+// for a requested loadBalancerIP against providers showing either behavior
+func TestLoadBalancerRequestedIP(t *testing.T) {
+	rejectIP := func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+		return nil, fmt.Errorf("ensure %s: %w", service.Name, cloudprovider.NotImplemented)
+	}
+	ignoreIP := func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+		return &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.168.1.100"}}}, nil
+	}
+
+	tests := []struct {
+		name    string
+		support string
+		ip      string
+		ensure  func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error)
+		wantErr string
+	}{
+		{
+			name: "honored by default",
+		},
+		{
+			name:    "honored as expected",
+			support: LoadBalancerIPHonored,
+			ip:      "203.0.113.7",
+		},
+		{
+			name:   "unsupported by default",
+			ensure: rejectIP,
+		},
+		{
+			name:    "unsupported as expected",
+			support: LoadBalancerIPUnsupported,
+			ensure:  rejectIP,
+		},
+		{
+			name:    "unsupported but expected honored",
+			support: LoadBalancerIPHonored,
+			ensure:  rejectIP,
+			wantErr: "failed to ensure load balancer with loadBalancerIP 192.0.2.10 (Unsupported error)",
+		},
+		{
+			name:    "honored but expected unsupported",
+			support: LoadBalancerIPUnsupported,
+			wantErr: "provider accepted loadBalancerIP 192.0.2.10, expected an unsupported error",
+		},
+		{
+			name:    "silently ignored",
+			ensure:  ignoreIP,
+			wantErr: "does not include the requested loadBalancerIP 192.0.2.10",
+		},
+		{
+			name:    "unknown expectation",
+			support: "sometimes",
+			wantErr: `unknown load-balancer-ip-support "sometimes"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewMockCloudProvider()
+			provider.GetMockLoadBalancer().EnsureLoadBalancerFunc = tt.ensure
+			ti := NewCCMTestInterface(provider)
+			config := &ccmtesting.TestConfig{
+				ProviderName: "mock",
+				TestData:     map[string]interface{}{"load-balancer-ip-support": tt.support, "load-balancer-ip": tt.ip},
+			}
+			if err := ti.SetupTestEnvironment(config); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+
+			err := testLoadBalancerRequestedIP(context.Background(), ti)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestLoadBalancerAnnotationRemoval tests that a removed annotation reaches the
+// mock load balancer, and that a provider keeping stale annotations fails
+func TestLoadBalancerAnnotationRemoval(t *testing.T) {
+	t.Run("removal applied", func(t *testing.T) {
+		ti, provider := newMockTestInterface(t)
+
+		if err := testLoadBalancerAnnotationRemoval(context.Background(), ti); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		ensured, found := provider.GetMockLoadBalancer().GetEnsuredService("default", "annotation-removal-test-lb")
+		if !found {
+			t.Fatal("Expected the load balancer to be ensured")
+		}
+		if _, annotated := ensured.Annotations[internalLoadBalancerAnnotation]; annotated {
+			t.Errorf("Expected the last ensure without the internal annotation, got %v", ensured.Annotations)
+		}
+	})
+
+	t.Run("stale annotations", func(t *testing.T) {
+		provider := &staleAnnotationsProvider{MockCloudProvider: NewMockCloudProvider()}
+		ti := NewCCMTestInterface(provider)
+		if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+			t.Fatalf("Failed to setup test environment: %v", err)
+		}
+
+		err := testLoadBalancerAnnotationRemoval(context.Background(), ti)
+		if err == nil || !strings.Contains(err.Error(), "after removing annotation") {
+			t.Errorf("Expected the stale annotation to fail the test, got %v", err)
+		}
+	})
+}
+
+// staleAnnotationsProvider is a mock cloud provider whose load balancer only
+// ever adds the annotations it is given, never removing any
+type staleAnnotationsProvider struct {
+	*MockCloudProvider
+	annotations map[string]string
+}
+
+// LoadBalancer returns the mock load balancer wrapped to keep stale annotations.
+func (p *staleAnnotationsProvider) LoadBalancer() (cloudprovider.LoadBalancer, bool) {
+	return &staleAnnotationsLoadBalancer{MockLoadBalancer: p.GetMockLoadBalancer(), provider: p}, true
+}
+
+type staleAnnotationsLoadBalancer struct {
+	*MockLoadBalancer
+	provider *staleAnnotationsProvider
+}
+
+// EnsureLoadBalancer ensures the load balancer with every annotation seen so far.
+func (lb *staleAnnotationsLoadBalancer) EnsureLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+	if lb.provider.annotations == nil {
+		lb.provider.annotations = make(map[string]string)
+	}
+	for key, value := range service.Annotations {
+		lb.provider.annotations[key] = value
+	}
+	stale := service.DeepCopy()
+	stale.Annotations = lb.provider.annotations
+	return lb.MockLoadBalancer.EnsureLoadBalancer(ctx, clusterName, stale, nodes)
+}
+
+// TestLoadBalancerMixedProtocol tests that a provider accepting mixed TCP/UDP
+// ports passes with both protocols recorded, while one rejecting them is skipped
+func TestLoadBalancerMixedProtocol(t *testing.T) {
+	tests := []struct {
+		name            string
+		ensureErr       error
+		wantUnsupported bool
+		wantErr         bool
+	}{
+		{
+			name: "mixed protocols supported",
+		},
+		{
+			name:            "mixed protocols rejected",
+			ensureErr:       errors.New("mixed protocol load balancers are not supported"),
+			wantUnsupported: true,
+		},
+		{
+			name:      "other provider error",
+			ensureErr: errors.New("quota exceeded"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			if tt.ensureErr != nil {
+				provider.GetMockLoadBalancer().EnsureLoadBalancerFunc = func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+					return nil, tt.ensureErr
+				}
+			}
+
+			err := testLoadBalancerMixedProtocol(ti)
+			switch {
+			case tt.wantUnsupported:
+				if !ccmtesting.IsUnsupportedError(err) {
+					t.Errorf("Expected unsupported error, got %v", err)
+				}
+			case tt.wantErr:
+				if err == nil || ccmtesting.IsUnsupportedError(err) {
+					t.Errorf("Expected a failure, got %v", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				protocols, found := provider.GetMockLoadBalancer().GetEnsuredProtocols("default", "mixed-protocol-test-lb")
+				if !found {
+					t.Fatal("Expected the load balancer to be ensured")
+				}
+				if len(protocols) != 2 || protocols[0] != v1.ProtocolTCP || protocols[1] != v1.ProtocolUDP {
+					t.Errorf("Expected protocols [TCP UDP], got %v", protocols)
+				}
+			}
+		})
+	}
+}
+
+// TestServiceTypeTransition tests that moving a service from NodePort to
+// LoadBalancer to ClusterIP ensures and then deletes its load balancer
+func TestServiceTypeTransition(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+
+	if err := testServiceTypeTransition(ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockLB := provider.GetMockLoadBalancer()
+	ensured, found := mockLB.GetEnsuredService("default", "type-transition-test-lb")
+	if !found {
+		t.Fatal("Expected EnsureLoadBalancer to be called for the service")
+	}
+	if ensured.Spec.Type != v1.ServiceTypeLoadBalancer {
+		t.Errorf("Expected ensured service type %s, got %s", v1.ServiceTypeLoadBalancer, ensured.Spec.Type)
+	}
+	if mockLB.HasLoadBalancer("default", "type-transition-test-lb") {
+		t.Error("Expected load balancer to be deleted after transition to ClusterIP")
+	}
+}
+
+// TestLoadBalancerSuiteObservesEnsureLoadBalancerHook tests that a custom
+// EnsureLoadBalancerFunc replaces the mock's default status
+func TestLoadBalancerSuiteObservesEnsureLoadBalancerHook(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      *v1.LoadBalancerStatus
+		expectError bool
+		expectLog   string
+	}{
+		{
+			name: "custom ingress",
+			status: &v1.LoadBalancerStatus{
+				Ingress: []v1.LoadBalancerIngress{
+					{IP: "203.0.113.10"},
+					{IP: "203.0.113.11"},
+					{Hostname: "custom-lb.example.com"},
+				},
+			},
+			expectLog: "with 3 ingress addresses",
+		},
+		{
+			name:        "empty status",
+			status:      &v1.LoadBalancerStatus{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+
+			var calls int
+			provider.GetMockLoadBalancer().EnsureLoadBalancerFunc = func(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+				calls++
+				return tt.status, nil
+			}
+
+			err := testCreateLoadBalancer(ti)
+			if tt.expectError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+
+			if calls != 1 {
+				t.Errorf("Expected EnsureLoadBalancerFunc to be called once, got %d", calls)
+			}
+
+			if tt.expectLog != "" && !strings.Contains(strings.Join(ti.GetTestResults().Logs, "\n"), tt.expectLog) {
+				t.Errorf("Expected logs to contain %q, got %v", tt.expectLog, ti.GetTestResults().Logs)
+			}
+		})
+	}
+}
+
+// TestSmokeTestSuite tests that the smoke suite passes quickly against the mock
+// and exercises every cloud capability
+func TestSmokeTestSuite(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.AddTestSuite(CreateSmokeTestSuite())
+
+	start := time.Now()
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("Expected smoke suite to finish within 30s, took %v", elapsed)
+	}
+
+	summary := runner.GetSummary()
+	if summary.TotalTests != 4 || summary.PassedTests != 4 {
+		t.Errorf("Expected 4 passed tests, got %d passed out of %d", summary.PassedTests, summary.TotalTests)
+	}
+
+	for _, capability := range []string{"LoadBalancer", "Instances", "Zones", "Routes"} {
+		found := false
+		for _, result := range runner.GetResults() {
+			if result.Test.Name == "Smoke"+capability {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected smoke suite to cover %s", capability)
+		}
+	}
+
+	mockLB := provider.GetMockLoadBalancer()
+	if _, found := mockLB.GetEnsuredService("default", "smoke-test-lb"); !found {
+		t.Error("Expected the smoke suite to ensure a load balancer")
+	}
+	if mockLB.HasLoadBalancer("default", "smoke-test-lb") {
+		t.Error("Expected the smoke suite to delete its load balancer")
+	}
+}
+
+// noLoadBalancerProvider is a mock cloud provider whose load balancer
+// functionality is switched off at runtime
+type noLoadBalancerProvider struct {
+	*MockCloudProvider
+}
+
+// LoadBalancer reports the load balancer interface as unavailable.
+func (p *noLoadBalancerProvider) LoadBalancer() (cloudprovider.LoadBalancer, bool) {
+	return nil, false
+}
+
+// TestLoadBalancerSuiteSkippedWhenUnsupported tests that every load balancer test
+// is skipped, not failed, when the provider does not offer a load balancer
+func TestLoadBalancerSuiteSkippedWhenUnsupported(t *testing.T) {
+	ti := NewCCMTestInterface(&noLoadBalancerProvider{MockCloudProvider: NewMockCloudProvider()})
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.EnabledLabels = []string{DeepConformanceLabel}
+	suite := CreateLoadBalancerTestSuite()
+	runner.AddTestSuite(suite)
+
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	summary := runner.GetSummary()
+	if summary.TotalTests != len(suite.Tests) {
+		t.Errorf("Expected %d tests, got %d", len(suite.Tests), summary.TotalTests)
+	}
+	if summary.SkippedTests != len(suite.Tests) {
+		t.Errorf("Expected all %d tests to be skipped, got %d", len(suite.Tests), summary.SkippedTests)
+	}
+
+	for _, result := range runner.GetResults() {
+		if result.Test.SkipReason != "cloud provider does not support load balancer functionality" {
+			t.Errorf("Test %s: unexpected skip reason %q", result.Test.Name, result.Test.SkipReason)
+		}
+	}
+}
+
+// TestNodeAddressTypes tests that the node addresses test requires exactly the
+// configured address types, and only an InternalIP when none are configured
+func TestNodeAddressTypes(t *testing.T) {
+	internalOnly := []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}}
+	internalAndExternal := []v1.NodeAddress{
+		{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		{Type: v1.NodeExternalIP, Address: "192.168.1.1"},
+	}
+
+	tests := []struct {
+		name      string
+		expected  []v1.NodeAddressType
+		addresses []v1.NodeAddress
+		wantErr   string
+	}{
+		{
+			name: "default with mock addresses",
+		},
+		{
+			name:      "default with internal address only",
+			addresses: internalOnly,
+		},
+		{
+			name:      "default without internal address",
+			addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "192.168.1.1"}},
+			wantErr:   "node address-test-node has no InternalIP address, got types [ExternalIP]",
+		},
+		{
+			name:      "internal-only cluster",
+			expected:  []v1.NodeAddressType{v1.NodeInternalIP},
+			addresses: internalOnly,
+		},
+		{
+			name:      "internal-only cluster with external address",
+			expected:  []v1.NodeAddressType{v1.NodeInternalIP},
+			addresses: internalAndExternal,
+			wantErr:   "missing [], unexpected [ExternalIP]",
+		},
+		{
+			name:      "internal and external required",
+			expected:  []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP},
+			addresses: internalAndExternal,
+		},
+		{
+			name:      "internal and external required without external",
+			expected:  []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP},
+			addresses: internalOnly,
+			wantErr:   "missing [ExternalIP], unexpected []",
+		},
+		{
+			name:     "IP and DNS required from registered node",
+			expected: []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP, v1.NodeInternalDNS, v1.NodeExternalDNS, v1.NodeHostName},
+		},
+		{
+			name:      "DNS required without DNS",
+			expected:  []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalDNS},
+			addresses: internalAndExternal,
+			wantErr:   "missing [ExternalDNS], unexpected [ExternalIP]",
+		},
+		{
+			name:     "invalid DNS name",
+			expected: []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeInternalDNS},
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalDNS, Address: "Node_1.internal"},
+			},
+			wantErr: `InternalDNS address "Node_1.internal" is not a valid DNS name`,
+		},
+		{
+			name:      "invalid IP",
+			addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0"}},
+			wantErr:   `InternalIP address "10.0.0" is not a valid IP`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewMockCloudProvider()
+			if tt.addresses != nil {
+				provider.GetMockInstances().NodeAddressesFunc = func(ctx context.Context, name types.NodeName) ([]v1.NodeAddress, error) {
+					return tt.addresses, nil
+				}
+			}
+			ti := NewCCMTestInterface(provider)
+			config := &ccmtesting.TestConfig{ProviderName: "mock", ExpectedNodeAddressTypes: tt.expected}
+			if err := ti.SetupTestEnvironment(config); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+
+			err := testNodeAddresses(ti)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestNodeTopologyLabels tests that the topology labels test applies and
+// observes the zone and region reported by the mock provider
+func TestNodeTopologyLabels(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+
+	if err := testNodeTopologyLabels(ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(ti.createdResources["nodes"]) != 0 {
+		t.Errorf("Expected test node to be cleaned up, got %v", ti.createdResources["nodes"])
+	}
+}
+
+// TestNodeSuitesUseExistingNodes tests that the node, instances and zones suites
+// verify the cluster's existing nodes instead of creating new ones
+func TestNodeSuitesUseExistingNodes(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	config := &ccmtesting.TestConfig{ProviderName: "mock", UseExistingNodes: true}
+	if err := ti.SetupTestEnvironment(config); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, name := range []string{"existing-node-1", "existing-node-2"} {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					v1.LabelTopologyZone:       "mock-zone",
+					v1.LabelTopologyRegion:     "mock-region",
+					v1.LabelInstanceTypeStable: "mock-instance-type",
+				},
+			},
+			Spec: v1.NodeSpec{ProviderID: "mock-provider://" + name},
+			Status: v1.NodeStatus{
+				Addresses:  []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}},
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+			},
+		}
+		if _, err := ti.GetKubeClient().CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Failed to create existing node: %v", err)
+		}
+	}
+
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.AddTestSuite(CreateNodeTestSuite())
+	runner.AddTestSuite(CreateInstancesTestSuite())
+	runner.AddTestSuite(CreateZonesTestSuite())
+
+	if err := runner.RunTests(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	summary := runner.GetSummary()
+	if summary.FailedTests != 0 {
+		t.Errorf("Expected no failed tests, got %d", summary.FailedTests)
+	}
+
+	if count := ti.GetTestResults().ResourceCounts[ccmtesting.ResourceTypeNodes]; count != 0 {
+		t.Errorf("Expected no nodes to be created, got %d", count)
+	}
+
+	nodes, err := ti.GetExistingNodes()
+	if err != nil {
+		t.Fatalf("Failed to list nodes: %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Errorf("Expected only the 2 existing nodes, got %d", len(nodes))
+	}
+}
+
+// TestNodeSuiteUseExistingNodesDetectsZoneMismatch tests that a disagreement
+// between an existing node's zone label and the cloud provider fails the suite
+func TestNodeSuiteUseExistingNodesDetectsZoneMismatch(t *testing.T) {
+	ti := NewCCMTestInterface(NewMockCloudProvider())
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock", UseExistingNodes: true}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "existing-node",
+			Labels: map[string]string{v1.LabelTopologyZone: "other-zone"},
+		},
+		Spec: v1.NodeSpec{ProviderID: "mock-provider://existing-node"},
+	}
+	if _, err := ti.GetKubeClient().CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create existing node: %v", err)
+	}
+
+	if err := testNodeZones(ti); err == nil {
+		t.Error("Expected zone mismatch to fail the test")
+	}
+}
+
+// TestCheckProviderID tests provider ID validation against the canonical
+// <scheme>://<id> form
+func TestCheckProviderID(t *testing.T) {
+	tests := []struct {
+		name       string
+		providerID string
+		scheme     string
+		wantErr    string
+	}{
+		{name: "aws", providerID: "aws:///us-east-1a/i-0123456789abcdef0", scheme: "aws"},
+		{name: "gce", providerID: "gce://my-project/us-central1-a/node-1", scheme: "gce"},
+		{name: "azure", providerID: "azure:///subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm", scheme: "azure"},
+		{name: "any scheme", providerID: "test-provider://node-1"},
+		{
+			name:    "empty",
+			wantErr: "provider ID is empty",
+		},
+		{
+			name:       "missing scheme",
+			providerID: "i-0123456789abcdef0",
+			wantErr:    `provider ID "i-0123456789abcdef0" is not of the form <scheme>://<id>`,
+		},
+		{
+			name:       "missing separator",
+			providerID: "aws:/us-east-1a/i-0123456789abcdef0",
+			wantErr:    `provider ID "aws:/us-east-1a/i-0123456789abcdef0" is not of the form <scheme>://<id>`,
+		},
+		{
+			name:       "empty scheme",
+			providerID: "://node-1",
+			wantErr:    `provider ID "://node-1" is not of the form <scheme>://<id>`,
+		},
+		{
+			name:       "wrong scheme",
+			providerID: "gce://my-project/us-central1-a/node-1",
+			scheme:     "aws",
+			wantErr:    `provider ID "gce://my-project/us-central1-a/node-1" has scheme gce, expected aws`,
+		},
+		{
+			name:       "empty path",
+			providerID: "aws://",
+			scheme:     "aws",
+			wantErr:    `provider ID "aws://" has an empty id after the scheme`,
+		},
+		{
+			name:       "slashes only",
+			providerID: "aws:///",
+			wantErr:    `provider ID "aws:///" has an empty id after the scheme`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkProviderID(tt.providerID, tt.scheme)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Expected error '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestNodeProviderIDFormat tests that the provider ID format test checks
+// existing nodes against the configured scheme
+func TestNodeProviderIDFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		scheme     string
+		providerID string
+		wantErr    bool
+	}{
+		{name: "created node with any scheme"},
+		{name: "created node with configured scheme", scheme: "gce"},
+		{name: "existing node with configured scheme", scheme: "mock-provider", providerID: "mock-provider://existing-node"},
+		{name: "existing node with wrong scheme", scheme: "aws", providerID: "mock-provider://existing-node", wantErr: true},
+		{name: "existing node without scheme", providerID: "existing-node", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti := NewCCMTestInterface(NewMockCloudProvider())
+			config := &ccmtesting.TestConfig{
+				ProviderName:     "mock",
+				ProviderIDScheme: tt.scheme,
+				UseExistingNodes: tt.providerID != "",
+			}
+			if err := ti.SetupTestEnvironment(config); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+
+			if tt.providerID != "" {
+				node := &v1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "existing-node"},
+					Spec:       v1.NodeSpec{ProviderID: tt.providerID},
+				}
+				if _, err := ti.GetKubeClient().CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("Failed to create existing node: %v", err)
+				}
+			}
+
+			err := testNodeProviderIDFormat(ti)
+			if tt.wantErr && err == nil {
+				t.Error("Expected a malformed provider ID to fail the test")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// instancesV2OnlyProvider is a mock cloud provider that only implements InstancesV2
+type instancesV2OnlyProvider struct {
+	*MockCloudProvider
+	instancesV2 *fakeInstancesV2
+}
+
+// Instances reports the legacy instances interface as unavailable.
+func (p *instancesV2OnlyProvider) Instances() (cloudprovider.Instances, bool) {
+	return nil, false
+}
+
+// InstancesV2 returns the fake instances v2 interface.
+func (p *instancesV2OnlyProvider) InstancesV2() (cloudprovider.InstancesV2, bool) {
+	return p.instancesV2, true
+}
+
+// fakeInstancesV2 is an InstancesV2 implementation that counts its calls
+type fakeInstancesV2 struct {
+	calls int
+}
+
+func (f *fakeInstancesV2) InstanceExists(ctx context.Context, node *v1.Node) (bool, error) {
+	f.calls++
+	return true, nil
+}
+
+func (f *fakeInstancesV2) InstanceShutdown(ctx context.Context, node *v1.Node) (bool, error) {
+	f.calls++
+	return false, nil
+}
+
+func (f *fakeInstancesV2) InstanceMetadata(ctx context.Context, node *v1.Node) (*cloudprovider.InstanceMetadata, error) {
+	f.calls++
+	return &cloudprovider.InstanceMetadata{
+		ProviderID:   "test-provider://" + node.Name,
+		InstanceType: "m5.large",
+	}, nil
+}
+
+// TestInstancesSuiteWithInstancesV2Only tests that the instances suite passes
+// against a provider that only implements InstancesV2
+func TestInstancesSuiteWithInstancesV2Only(t *testing.T) {
+	provider := &instancesV2OnlyProvider{MockCloudProvider: NewMockCloudProvider(), instancesV2: &fakeInstancesV2{}}
+	ti := NewCCMTestInterface(provider)
+	if err := ti.SetupTestEnvironment(&ccmtesting.TestConfig{ProviderName: "mock"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	runner := ccmtesting.NewTestRunner(ti)
+	runner.AddTestSuite(CreateInstancesTestSuite())
+
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	summary := runner.GetSummary()
+	if summary.PassedTests != summary.TotalTests || summary.SkippedTests != 0 {
+		t.Errorf("Expected all tests to pass, got %d passed, %d skipped out of %d", summary.PassedTests, summary.SkippedTests, summary.TotalTests)
+	}
+
+	if provider.instancesV2.calls != summary.TotalTests {
+		t.Errorf("Expected %d InstancesV2 calls, got %d", summary.TotalTests, provider.instancesV2.calls)
+	}
+}
+
+// TestResolveInstances tests that InstancesV2 is preferred and adapted
+func TestResolveInstances(t *testing.T) {
+	if _, ok := ResolveInstances(nil); ok {
+		t.Error("Expected no instances for a nil provider")
+	}
+
+	mock := NewMockCloudProvider()
+	instances, ok := ResolveInstances(mock)
+	if !ok || instances != mock.GetMockInstances() {
+		t.Error("Expected the legacy instances interface when InstancesV2 is unavailable")
+	}
+
+	provider := &instancesV2OnlyProvider{MockCloudProvider: mock, instancesV2: &fakeInstancesV2{}}
+	instances, ok = ResolveInstances(provider)
+	if !ok {
+		t.Fatal("Expected instances to be resolved through InstancesV2")
+	}
+
+	instanceType, err := instances.InstanceTypeByProviderID(context.Background(), "test-provider://node-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if instanceType != "m5.large" {
+		t.Errorf("Expected instance type 'm5.large', got '%s'", instanceType)
+	}
+
+	instanceID, err := instances.InstanceID(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if instanceID != "test-provider://node-1" {
+		t.Errorf("Expected instance ID 'test-provider://node-1', got '%s'", instanceID)
+	}
+}
+
+// TestBuiltinSuitesAreWellFormed tests that every built-in suite passes registration checks
+func TestBuiltinSuitesAreWellFormed(t *testing.T) {
+	suites := []ccmtesting.TestSuite{
+		CreateLoadBalancerTestSuite(),
+		CreateNodeTestSuite(),
+		CreateRouteTestSuite(),
+		CreateInstancesTestSuite(),
+		CreateZonesTestSuite(),
+		CreateClustersTestSuite(),
+		CreateSmokeTestSuite(),
+		CreateConsistencyTestSuite(),
+		CreateNodeLifecycleTestSuite(),
+	}
+
+	for _, suite := range suites {
+		if err := suite.Validate(); err != nil {
+			t.Errorf("Expected suite %s to be well-formed, got %v", suite.Name, err)
+		}
+		for _, capability := range suite.RequiredCapabilities {
+			if !knownCapabilities[capability] {
+				t.Errorf("Expected suite %s to require only known capabilities, got %s", suite.Name, capability)
+			}
+		}
+	}
+}
+
+// TestConsistencySuiteDetectsZoneMismatch tests that nodes labeled with a zone other
+// than the one the provider reports for their provider ID fail the consistency suite
+func TestConsistencySuiteDetectsZoneMismatch(t *testing.T) {
+	zoneA := cloudprovider.Zone{FailureDomain: "zone-a", Region: "region-1"}
+	zoneB := cloudprovider.Zone{FailureDomain: "zone-b", Region: "region-1"}
+	zoneC := cloudprovider.Zone{FailureDomain: "zone-c", Region: "region-1"}
+
+	tests := []struct {
+		name         string
+		byNodeName   map[string]cloudprovider.Zone
+		byProviderID map[string]cloudprovider.Zone
+		wantErr      string
+	}{
+		{
+			name: "consistent zones",
+			byNodeName: map[string]cloudprovider.Zone{
+				"zone-consistency-node-a": zoneA,
+				"zone-consistency-node-b": zoneB,
+				"zone-consistency-node-c": zoneC,
+			},
+			byProviderID: map[string]cloudprovider.Zone{
+				"test-provider://zone-consistency-node-a": zoneA,
+				"test-provider://zone-consistency-node-b": zoneB,
+				"test-provider://zone-consistency-node-c": zoneC,
+			},
+		},
+		{
+			name: "zone mismatch",
+			byNodeName: map[string]cloudprovider.Zone{
+				"zone-consistency-node-a": zoneA,
+				"zone-consistency-node-b": zoneB,
+				"zone-consistency-node-c": zoneC,
+			},
+			byProviderID: map[string]cloudprovider.Zone{
+				"test-provider://zone-consistency-node-a": zoneA,
+				"test-provider://zone-consistency-node-b": zoneC,
+				"test-provider://zone-consistency-node-c": zoneC,
+			},
+			wantErr: `node zone-consistency-node-b is labeled with zone "zone-b" but the cloud provider reports "zone-c"`,
+		},
+		{
+			name: "region mismatch",
+			byNodeName: map[string]cloudprovider.Zone{
+				"zone-consistency-node-c": {FailureDomain: "zone-c", Region: "region-2"},
+			},
+			byProviderID: map[string]cloudprovider.Zone{
+				"test-provider://zone-consistency-node-c": zoneC,
+			},
+			wantErr: `node zone-consistency-node-c is labeled with region "region-2" but the cloud provider reports "region-1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			zones := provider.GetMockZones()
+			for name, zone := range tt.byNodeName {
+				zones.SetZoneForNodeName(types.NodeName(name), zone)
+			}
+			for providerID, zone := range tt.byProviderID {
+				zones.SetZoneForProviderID(providerID, zone)
+			}
+
+			runner := ccmtesting.NewTestRunner(ti)
+			runner.AddTestSuite(CreateConsistencyTestSuite())
+			err := runner.RunTests(context.Background())
+
+			results := runner.GetResults()
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result, got %d", len(results))
+			}
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v (%v)", err, results[0].Error)
+				}
+			} else {
+				if results[0].Success {
+					t.Fatal("Expected zone mismatch to fail the test")
+				}
+				if !strings.Contains(results[0].Error.Error(), tt.wantErr) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tt.wantErr, results[0].Error)
+				}
+			}
+
+			nodes, err := ti.GetKubeClient().CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Failed to list nodes: %v", err)
+			}
+			for _, node := range nodes.Items {
+				if strings.HasPrefix(node.Name, "zone-consistency-node") {
+					t.Errorf("Expected test node %s to be cleaned up", node.Name)
+				}
+			}
+		})
+	}
+}
+
+// TestTestRunnerProviderInfo tests the provider name and cluster ID reported for mock and existing-CCM runs
+func TestTestRunnerProviderInfo(t *testing.T) {
+	ti, _ := newMockTestInterface(t)
+	existing := NewExistingCCMTestInterface(fake.NewSimpleClientset(), &ccmtesting.TestConfig{
+		TestData: map[string]interface{}{"namespace": "ccm-test"},
+	})
+
+	tests := []struct {
+		name             string
+		testInterface    ccmtesting.TestInterface
+		wantProviderName string
+		wantHasClusterID bool
+	}{
+		{
+			name:             "mock provider",
+			testInterface:    ti,
+			wantProviderName: "mock-cloud-provider",
+			wantHasClusterID: true,
+		},
+		{
+			name:             "existing CCM without provider",
+			testInterface:    existing,
+			wantProviderName: ccmtesting.UnknownProviderName,
+			wantHasClusterID: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := ccmtesting.NewTestRunner(tt.testInterface).GetSummary()
+			if summary.ProviderName != tt.wantProviderName {
+				t.Errorf("Expected provider name %s, got %s", tt.wantProviderName, summary.ProviderName)
+			}
+			if summary.HasClusterID != tt.wantHasClusterID {
+				t.Errorf("Expected HasClusterID %v, got %v", tt.wantHasClusterID, summary.HasClusterID)
+			}
+		})
+	}
+}
+
+// TestNodeLifecycleSuite tests that the node lifecycle suite passes when the
+// provider reports a removed instance and fails when it keeps reporting it
+func TestNodeLifecycleSuite(t *testing.T) {
+	tests := []struct {
+		name        string
+		alwaysExist bool
+		wantErr     string
+	}{
+		{
+			name: "instance removed",
+		},
+		{
+			name:        "instance still reported",
+			alwaysExist: true,
+			wantErr:     "would be kept although its instance test-provider://lifecycle-test-node no longer exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			if tt.alwaysExist {
+				provider.GetMockInstances().InstanceExistsByProviderIDFunc = func(ctx context.Context, providerID string) (bool, error) {
+					return true, nil
+				}
+			}
+
+			runner := ccmtesting.NewTestRunner(ti)
+			runner.AddTestSuite(CreateNodeLifecycleTestSuite())
+			err := runner.RunTests(context.Background())
+
+			results := runner.GetResults()
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result, got %d", len(results))
+			}
+
+			if tt.wantErr != "" {
+				if results[0].Success {
+					t.Fatal("Expected the test to fail")
+				}
+				if !strings.Contains(results[0].Error.Error(), tt.wantErr) {
+					t.Errorf("Expected error to contain '%s', got '%v'", tt.wantErr, results[0].Error)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v (%v)", err, results[0].Error)
+			}
+
+			transitioned := false
+			for _, log := range ti.GetTestResults().ReportLogs() {
+				if strings.Contains(log, "transitioned from Ready to NotReady") {
+					transitioned = true
+				}
+			}
+			if !transitioned {
+				t.Error("Expected the Ready to NotReady transition to be logged")
+			}
+
+			if exists, _ := provider.GetMockInstances().InstanceExistsByProviderID(context.Background(), "test-provider://lifecycle-test-node"); !exists {
+				t.Error("Expected the removed instance to be restored after the test")
+			}
+
+			if _, err := ti.GetKubeClient().CoreV1().Nodes().Get(context.Background(), "lifecycle-test-node", metav1.GetOptions{}); err == nil {
+				t.Error("Expected the test node to be deleted")
+			}
+		})
+	}
+}
+
+// TestInstanceChecksNotImplemented tests that a NotImplemented existence or
+// shutdown check is reported as a skip rather than as a missing instance
+func TestInstanceChecksNotImplemented(t *testing.T) {
+	tests := []struct {
+		name           string
+		run            func(ctx context.Context, ti ccmtesting.TestInterface) error
+		wantCapability string
+	}{
+		{name: "instance exists", run: testInstanceExists, wantCapability: instanceExistenceCapability},
+		{name: "instance shutdown", run: testInstanceShutdown, wantCapability: instanceShutdownCapability},
+		{name: "node instance removed", run: testNodeInstanceRemoved, wantCapability: instanceExistenceCapability},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, provider := newMockTestInterface(t)
+			provider.GetMockInstances().SetExistenceChecksNotImplemented(true)
+
+			err := tt.run(context.Background(), ti)
+			if !ccmtesting.IsUnsupportedError(err) {
+				t.Fatalf("Expected unsupported error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantCapability) {
+				t.Errorf("Expected error to name '%s', got %v", tt.wantCapability, err)
+			}
+		})
+	}
+}
+
+// TestInstanceExistsReportsGoneInstance tests that a (false, nil) existence
+// check passes and is recorded as the instance being gone
+func TestInstanceExistsReportsGoneInstance(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+	provider.GetMockInstances().SetInstanceExists("test-provider://test-node", false)
+
+	if err := testInstanceExists(context.Background(), ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	gone := false
+	for _, log := range ti.GetTestResults().ReportLogs() {
+		if strings.Contains(log, "test-provider://test-node is gone") {
+			gone = true
+		}
+	}
+	if !gone {
+		t.Error("Expected the instance to be reported as gone")
+	}
+}
+
+// TestZoneExpectations tests that the zone tests only require a region and
+// zone when the TestConfig expects them, and check them against allowlists
+func TestZoneExpectations(t *testing.T) {
+	failureDomainOnly := cloudprovider.Zone{FailureDomain: "zone-a"}
+
+	tests := []struct {
+		name    string
+		zone    *cloudprovider.Zone
+		config  ccmtesting.TestConfig
+		wantErr string
+	}{
+		{
+			name:   "mock zone with region and zone expected",
+			config: ccmtesting.TestConfig{ExpectRegion: true, ExpectZone: true},
+		},
+		{
+			name: "failure domain only without region expected",
+			zone: &failureDomainOnly,
+		},
+		{
+			name:    "failure domain only with region expected",
+			zone:    &failureDomainOnly,
+			config:  ccmtesting.TestConfig{ExpectRegion: true},
+			wantErr: `zone region is empty but a region is expected (failure domain "zone-a")`,
+		},
+		{
+			name:    "region only with zone expected",
+			zone:    &cloudprovider.Zone{Region: "region-1"},
+			config:  ccmtesting.TestConfig{ExpectZone: true},
+			wantErr: `zone failure domain is empty but a zone is expected (region "region-1")`,
+		},
+		{
+			name:   "allowed region and zone",
+			config: ccmtesting.TestConfig{AllowedRegions: []string{"other-region", "mock-region"}, AllowedZones: []string{"mock-zone"}},
+		},
+		{
+			name:    "region not allowed",
+			config:  ccmtesting.TestConfig{AllowedRegions: []string{"other-region"}},
+			wantErr: "zone region mock-region is not one of the allowed regions [other-region]",
+		},
+		{
+			name:    "zone not allowed",
+			config:  ccmtesting.TestConfig{AllowedZones: []string{"other-zone"}},
+			wantErr: "zone failure domain mock-zone is not one of the allowed zones [other-zone]",
+		},
+		{
+			name:   "empty region not checked against allowlist",
+			zone:   &failureDomainOnly,
+			config: ccmtesting.TestConfig{AllowedRegions: []string{"region-1"}},
+		},
+	}
+
+	zoneTests := map[string]func(ti ccmtesting.TestInterface) error{
+		"testNodeZones": testNodeZones,
+		"testGetZone": func(ti ccmtesting.TestInterface) error {
+			return testGetZone(context.Background(), ti)
+		},
+	}
+
+	for _, tt := range tests {
+		for testName, zoneTest := range zoneTests {
+			t.Run(tt.name+"/"+testName, func(t *testing.T) {
+				provider := NewMockCloudProvider()
+				if tt.zone != nil {
+					provider.GetMockZones().GetZoneFunc = func(ctx context.Context) (cloudprovider.Zone, error) {
+						return *tt.zone, nil
+					}
+				}
+				ti := NewCCMTestInterface(provider)
+				config := tt.config
+				config.ProviderName = "mock"
+				if err := ti.SetupTestEnvironment(&config); err != nil {
+					t.Fatalf("Failed to setup test environment: %v", err)
+				}
+
+				err := zoneTest(ti)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("Expected error '%s', got %v", tt.wantErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+// TestRoutesForPodCIDRs tests that a route is created for each pod CIDR of
+// every node, targeting that node, and removed once the test is done
+func TestRoutesForPodCIDRs(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+	mockRoutes := provider.GetMockRoutes()
+
+	created := map[string]types.NodeName{}
+	calls := map[string]types.NodeName{}
+	mockRoutes.CreateRouteFunc = func(ctx context.Context, clusterName string, nameHint string, route *cloudprovider.Route) error {
+		if clusterName != "test-cluster" {
+			t.Errorf("Expected cluster name 'test-cluster', got '%s'", clusterName)
+		}
+		created[route.DestinationCIDR] = route.TargetNode
+		calls[route.DestinationCIDR] = route.TargetNode
+		return nil
+	}
+	mockRoutes.ListRoutesFunc = func(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error) {
+		var routes []*cloudprovider.Route
+		for cidr, node := range created {
+			routes = append(routes, &cloudprovider.Route{Name: cidr, TargetNode: node, DestinationCIDR: cidr})
+		}
+		return routes, nil
+	}
+	mockRoutes.DeleteRouteFunc = func(ctx context.Context, clusterName string, route *cloudprovider.Route) error {
+		delete(created, route.DestinationCIDR)
+		return nil
+	}
+
+	if err := testRoutesForPodCIDRs(context.Background(), ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(calls) != 3 {
+		t.Errorf("Expected CreateRoute to be called for 3 pod CIDRs, got %d", len(calls))
+	}
+	for _, node := range podCIDRRouteNodes {
+		for _, podCIDR := range node.PodCIDRs {
+			if calls[podCIDR] != types.NodeName(node.Name) {
+				t.Errorf("Expected a route to %s targeting %s, got target '%s'", podCIDR, node.Name, calls[podCIDR])
+			}
+		}
+	}
+	if len(created) != 0 {
+		t.Errorf("Expected the routes to be deleted, got %v", created)
+	}
+}
+
+// TestProviderBackedRouteTests tests that with ProviderBackedRoutes set the
+// create route test checks the provider lists the route, and the delete route
+// test deletes it through the test interface so that it is no longer tracked
+func TestProviderBackedRouteTests(t *testing.T) {
+	ctx := context.Background()
+	ti, provider := newMockTestInterface(t)
+	ti.GetConfig().ProviderBackedRoutes = true
+
+	if err := testCreateRoute(ctx, ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count := ti.GetTestResults().ResourceCounts[ccmtesting.ResourceTypeRoutes]; count != 1 {
+		t.Errorf("Expected 1 tracked route, got %d", count)
+	}
+
+	if err := testDeleteRoute(ctx, ti); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if routes := ti.TrackedResources()["routes"]; len(routes) != 0 {
+		t.Errorf("Expected the route to be no longer tracked, got %v", routes)
+	}
+	if count := ti.GetTestResults().ResourceCounts[ccmtesting.ResourceTypeRoutes]; count != 0 {
+		t.Errorf("Expected no tracked routes, got %d", count)
+	}
+	routes, err := provider.GetMockRoutes().ListRoutes(ctx, "test-cluster")
+	if err != nil {
+		t.Fatalf("Failed to list routes: %v", err)
+	}
+	for _, route := range routes {
+		if route.Name == "test-route" {
+			t.Error("Expected the route to be deleted through the provider")
+		}
+	}
+
+	// A route the provider does not list fails the create route test
+	provider.GetMockRoutes().ListRoutesFunc = func(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error) {
+		return nil, nil
+	}
+	if err := testCreateRoute(ctx, ti); err == nil || !strings.Contains(err.Error(), "is not listed by the cloud provider") {
+		t.Errorf("Expected an unlisted route error, got %v", err)
+	}
+}
+
+// TestMissingPodCIDRRoutes tests that pod CIDRs routed to another node or not
+// routed at all are reported as missing
+func TestMissingPodCIDRRoutes(t *testing.T) {
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: v1.NodeSpec{PodCIDRs: []string{"10.244.1.0/24"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Spec: v1.NodeSpec{PodCIDRs: []string{"10.244.2.0/24", "fd00::/64"}}},
+	}
+	routes := []*cloudprovider.Route{
+		{TargetNode: "node-1", DestinationCIDR: "10.244.1.0/24"},
+		{TargetNode: "node-1", DestinationCIDR: "10.244.2.0/24"},
+	}
+
+	missing := missingPodCIDRRoutes(routes, nodes)
+	expected := "10.244.2.0/24 via node-2, fd00::/64 via node-2"
+	if strings.Join(missing, ", ") != expected {
+		t.Errorf("Expected missing '%s', got '%s'", expected, strings.Join(missing, ", "))
+	}
+}
+
+// TestMockNodeAddressesWithDNS tests that the mock provider reports the IP and
+// DNS addresses of a registered node and that all of them validate
+func TestMockNodeAddressesWithDNS(t *testing.T) {
+	ti, provider := newMockTestInterface(t)
+	ctx := context.Background()
+
+	addresses := []v1.NodeAddress{
+		{Type: v1.NodeInternalIP, Address: "10.0.0.9"},
+		{Type: v1.NodeExternalIP, Address: "203.0.113.9"},
+		{Type: v1.NodeInternalDNS, Address: "dns-node.internal.example.com"},
+		{Type: v1.NodeExternalDNS, Address: "dns-node.example.com"},
+	}
+	node, err := ti.CreateTestNode(ctx, &ccmtesting.TestNodeConfig{
+		Name:       "dns-node",
+		ProviderID: "test-provider://dns-node",
+		Addresses:  addresses,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test node: %v", err)
+	}
+
+	got, err := provider.GetMockInstances().NodeAddresses(ctx, types.NodeName(node.Name))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !slices.Equal(got, addresses) {
+		t.Errorf("Expected addresses %v, got %v", addresses, got)
+	}
+	if err := ValidateNodeAddresses(got); err != nil {
+		t.Errorf("Expected the addresses to be valid, got %v", err)
+	}
+}
@@ -16,6 +16,27 @@ func main() {
 	var _ ccmtesting.TestInterface = (*testing.CCMTestInterface)(nil)
 
 	fmt.Println("✅ All test interfaces correctly implement ccmtesting.TestInterface")
+
+	// Registration check: every built-in suite must be well-formed
+	suites := []ccmtesting.TestSuite{
+		testing.CreateLoadBalancerTestSuite(),
+		testing.CreateNodeTestSuite(),
+		testing.CreateRouteTestSuite(),
+		testing.CreateInstancesTestSuite(),
+		testing.CreateZonesTestSuite(),
+		testing.CreateClustersTestSuite(),
+		testing.CreateSmokeTestSuite(),
+		testing.CreateConsistencyTestSuite(),
+		testing.CreateNodeLifecycleTestSuite(),
+	}
+	for _, suite := range suites {
+		if err := suite.Validate(); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println("✅ All built-in test suites are well-formed")
 	fmt.Println("✅ Interface compliance verification passed!")
 
 	os.Exit(0)
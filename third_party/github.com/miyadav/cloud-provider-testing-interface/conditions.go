@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// NewServiceIngressCondition returns a condition that is met once the named
+// service has been assigned at least one load balancer ingress. A service that
+// does not exist yet is reported as not met rather than as an error.
+func NewServiceIngressCondition(client clientset.Interface, namespace, name string, timeout time.Duration) TestCondition {
+	return TestCondition{
+		Type:    "ServiceIngress",
+		Status:  string(v1.ConditionTrue),
+		Message: fmt.Sprintf("service %s/%s has a load balancer ingress", namespace, name),
+		Timeout: timeout,
+		CheckFunction: func() (bool, error) {
+			service, err := client.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			return len(service.Status.LoadBalancer.Ingress) > 0, nil
+		},
+	}
+}
+
+// NewNodeReadyCondition returns a condition that is met once the named node
+// reports a Ready condition with status True. A node that does not exist yet is
+// reported as not met rather than as an error.
+func NewNodeReadyCondition(client clientset.Interface, name string, timeout time.Duration) TestCondition {
+	return TestCondition{
+		Type:    string(v1.NodeReady),
+		Status:  string(v1.ConditionTrue),
+		Message: fmt.Sprintf("node %s is ready", name),
+		Timeout: timeout,
+		CheckFunction: func() (bool, error) {
+			node, err := client.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == v1.NodeReady {
+					return condition.Status == v1.ConditionTrue, nil
+				}
+			}
+			return false, nil
+		},
+	}
+}
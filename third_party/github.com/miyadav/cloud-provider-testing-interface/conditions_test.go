@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// TestNewServiceIngressCondition tests the service ingress condition against a fake clientset
+func TestNewServiceIngressCondition(t *testing.T) {
+	pending := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	provisioned := pending.DeepCopy()
+	provisioned.Name = "provisioned"
+	provisioned.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "192.0.2.10"}}
+
+	tests := []struct {
+		name    string
+		service string
+		wantMet bool
+	}{
+		{name: "ingress assigned", service: "provisioned", wantMet: true},
+		{name: "ingress pending", service: "pending", wantMet: false},
+		{name: "service missing", service: "missing", wantMet: false},
+	}
+
+	client := fake.NewSimpleClientset(pending, provisioned)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := NewServiceIngressCondition(client, "default", tt.service, time.Minute)
+			if condition.Timeout != time.Minute {
+				t.Errorf("Expected timeout %v, got %v", time.Minute, condition.Timeout)
+			}
+
+			met, err := condition.CheckFunction()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if met != tt.wantMet {
+				t.Errorf("Expected met %v, got %v", tt.wantMet, met)
+			}
+		})
+	}
+}
+
+// TestNewNodeReadyCondition tests the node ready condition against a fake clientset
+func TestNewNodeReadyCondition(t *testing.T) {
+	nodeWithReady := func(name string, status v1.ConditionStatus) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{
+					{Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse},
+					{Type: v1.NodeReady, Status: status},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		node    string
+		wantMet bool
+	}{
+		{name: "node ready", node: "ready", wantMet: true},
+		{name: "node not ready", node: "not-ready", wantMet: false},
+		{name: "node without conditions", node: "new", wantMet: false},
+		{name: "node missing", node: "missing", wantMet: false},
+	}
+
+	client := fake.NewSimpleClientset(
+		nodeWithReady("ready", v1.ConditionTrue),
+		nodeWithReady("not-ready", v1.ConditionFalse),
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "new"}},
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			met, err := NewNodeReadyCondition(client, tt.node, time.Minute).CheckFunction()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if met != tt.wantMet {
+				t.Errorf("Expected met %v, got %v", tt.wantMet, met)
+			}
+		})
+	}
+}
+
+// TestConditionsPropagateAPIErrors tests that API errors other than NotFound are returned
+func TestConditionsPropagateAPIErrors(t *testing.T) {
+	apiErr := errors.New("apiserver unavailable")
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apiErr
+	})
+
+	conditions := []TestCondition{
+		NewServiceIngressCondition(client, "default", "svc", time.Minute),
+		NewNodeReadyCondition(client, "node", time.Minute),
+	}
+	for _, condition := range conditions {
+		met, err := condition.CheckFunction()
+		if !errors.Is(err, apiErr) {
+			t.Errorf("Expected %s condition to return %v, got %v", condition.Type, apiErr, err)
+		}
+		if met {
+			t.Errorf("Expected %s condition not to be met on error", condition.Type)
+		}
+	}
+}
@@ -118,7 +118,7 @@ func testLoadBalancerCreation(ti TestInterface) error {
 	cloud := ti.GetCloudProvider()
 	loadBalancer, supported := cloud.LoadBalancer()
 	if !supported {
-		return fmt.Errorf("load balancer not supported by cloud provider")
+		return NewUnsupportedError("load balancer")
 	}
 
 	// Test GetLoadBalancer
@@ -185,7 +185,7 @@ func testNodeRegistration(ti TestInterface) error {
 	cloud := ti.GetCloudProvider()
 	instances, supported := cloud.Instances()
 	if !supported {
-		return fmt.Errorf("instances not supported by cloud provider")
+		return NewUnsupportedError("instances")
 	}
 
 	// Test NodeAddresses
@@ -260,7 +260,7 @@ func testRouteManagement(ti TestInterface) error {
 	cloud := ti.GetCloudProvider()
 	routes, supported := cloud.Routes()
 	if !supported {
-		return fmt.Errorf("routes not supported by cloud provider")
+		return NewUnsupportedError("routes")
 	}
 
 	// Test ListRoutes
@@ -18,7 +18,10 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"sync"
 	"time"
 
@@ -30,6 +33,7 @@ import (
 	"k8s.io/client-go/rest"
 	cloudprovider "k8s.io/cloud-provider"
 	fakecloud "k8s.io/cloud-provider/fake"
+	"sigs.k8s.io/yaml"
 )
 
 // BaseTestImplementation provides a base implementation of the TestInterface
@@ -50,9 +54,16 @@ type BaseTestImplementation struct {
 	// TestResults holds the current test results.
 	TestResults *TestResults
 
-	// CreatedResources tracks resources created during tests for cleanup.
+	// CreatedResources tracks resources created during tests for cleanup,
+	// keyed by resource type, such as ResourceTypeNodes.
 	CreatedResources map[string][]string
 
+	// nodes, services and routes hold the in-memory copies of the created
+	// test objects, keyed by name, so they can be updated in place.
+	nodes    map[string]*v1.Node
+	services map[string]*v1.Service
+	routes   map[string]*cloudprovider.Route
+
 	// mu protects access to the BaseTestImplementation fields
 	mu sync.RWMutex
 }
@@ -64,6 +75,9 @@ func NewBaseTestImplementation(cloudProvider cloudprovider.Interface) *BaseTestI
 		CreatedResources: make(map[string][]string),
 		TestResults:      &TestResults{},
 		TestConfig:       &TestConfig{},
+		nodes:            make(map[string]*v1.Node),
+		services:         make(map[string]*v1.Service),
+		routes:           make(map[string]*cloudprovider.Route),
 	}
 }
 
@@ -78,6 +92,7 @@ func (b *BaseTestImplementation) SetupTestEnvironment(config *TestConfig) error
 		ResourceCounts: make(map[string]int),
 		Metrics:        make(map[string]interface{}),
 		Logs:           []string{},
+		MaxLogs:        config.MaxLogs,
 	}
 
 	// Initialize the cloud provider if it supports InformerUser
@@ -98,9 +113,13 @@ func (b *BaseTestImplementation) TeardownTestEnvironment() error {
 	b.mu.Lock()
 	// Just clear the created resources tracking to avoid deadlocks
 	b.CreatedResources = make(map[string][]string)
+	b.nodes = make(map[string]*v1.Node)
+	b.services = make(map[string]*v1.Service)
+	b.routes = make(map[string]*cloudprovider.Route)
+	results := b.TestResults
 	b.mu.Unlock()
 
-	b.TestResults.AddLog("Test environment teardown completed")
+	results.AddLog("Test environment teardown completed")
 	return nil
 }
 
@@ -114,14 +133,18 @@ func (b *BaseTestImplementation) CreateTestNode(ctx context.Context, nodeConfig
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	nodeName := b.TestConfig.ResourceNameOrGenerate(nodeConfig.Name, "test-node")
 	node := &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        nodeConfig.Name,
+			Name:        nodeName,
 			Labels:      nodeConfig.Labels,
 			Annotations: nodeConfig.Annotations,
 		},
 		Spec: v1.NodeSpec{
 			ProviderID: nodeConfig.ProviderID,
+			Taints:     nodeConfig.Taints,
+			PodCIDR:    nodeConfig.PrimaryPodCIDR(),
+			PodCIDRs:   nodeConfig.PodCIDRs,
 		},
 		Status: v1.NodeStatus{
 			Addresses:  nodeConfig.Addresses,
@@ -158,22 +181,44 @@ func (b *BaseTestImplementation) CreateTestNode(ctx context.Context, nodeConfig
 	}
 
 	// Track created resource
-	b.CreatedResources["node"] = append(b.CreatedResources["node"], nodeConfig.Name)
-	b.TestResults.IncrementResourceCount("node")
+	b.CreatedResources[ResourceTypeNodes] = append(b.CreatedResources[ResourceTypeNodes], nodeName)
+	b.TestResults.IncrementResourceCount(ResourceTypeNodes)
+	b.nodes[nodeName] = node.DeepCopy()
 
-	b.TestResults.AddLog(fmt.Sprintf("Created test node: %s", nodeConfig.Name))
+	b.TestResults.AddLog(fmt.Sprintf("Created test node: %s", nodeName))
 	return node, nil
 }
 
+// UpdateTestNode updates a test node.
+func (b *BaseTestImplementation) UpdateTestNode(ctx context.Context, nodeConfig *TestNodeConfig) (*v1.Node, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	nodeName := b.TestConfig.ResourceName(nodeConfig.Name)
+	node, ok := b.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("test node %s not found", nodeName)
+	}
+
+	ApplyTestNodeConfig(node, nodeConfig)
+
+	b.TestResults.AddLog(fmt.Sprintf("Updated test node: %s", nodeName))
+	return node.DeepCopy(), nil
+}
+
 // DeleteTestNode deletes a test node.
 func (b *BaseTestImplementation) DeleteTestNode(ctx context.Context, nodeName string) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	nodeName = b.TestConfig.ResourceName(nodeName)
+	delete(b.nodes, nodeName)
+
 	// Remove from created resources
-	for i, name := range b.CreatedResources["node"] {
+	for i, name := range b.CreatedResources[ResourceTypeNodes] {
 		if name == nodeName {
-			b.CreatedResources["node"] = append(b.CreatedResources["node"][:i], b.CreatedResources["node"][i+1:]...)
+			b.CreatedResources[ResourceTypeNodes] = append(b.CreatedResources[ResourceTypeNodes][:i], b.CreatedResources[ResourceTypeNodes][i+1:]...)
+			b.TestResults.DecrementResourceCount(ResourceTypeNodes)
 			break
 		}
 	}
@@ -184,42 +229,72 @@ func (b *BaseTestImplementation) DeleteTestNode(ctx context.Context, nodeName st
 
 // CreateTestService creates a test service.
 func (b *BaseTestImplementation) CreateTestService(ctx context.Context, serviceConfig *TestServiceConfig) (*v1.Service, error) {
+	if err := ValidateTestServiceConfig(serviceConfig); err != nil {
+		return nil, err
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	serviceName := b.TestConfig.ResourceNameOrGenerate(serviceConfig.Name, "test-service")
 	service := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        serviceConfig.Name,
+			Name:        serviceName,
 			Namespace:   serviceConfig.Namespace,
 			Labels:      serviceConfig.Labels,
 			Annotations: serviceConfig.Annotations,
 		},
 		Spec: v1.ServiceSpec{
-			Type:                  serviceConfig.Type,
-			Ports:                 serviceConfig.Ports,
-			LoadBalancerIP:        serviceConfig.LoadBalancerIP,
-			ExternalTrafficPolicy: serviceConfig.ExternalTrafficPolicy,
-			InternalTrafficPolicy: serviceConfig.InternalTrafficPolicy,
+			Type:                          serviceConfig.Type,
+			Ports:                         serviceConfig.Ports,
+			LoadBalancerIP:                serviceConfig.LoadBalancerIP,
+			ExternalTrafficPolicy:         serviceConfig.ExternalTrafficPolicy,
+			HealthCheckNodePort:           serviceConfig.HealthCheckNodePort,
+			InternalTrafficPolicy:         serviceConfig.InternalTrafficPolicy,
+			LoadBalancerClass:             serviceConfig.LoadBalancerClass,
+			AllocateLoadBalancerNodePorts: serviceConfig.AllocateLoadBalancerNodePorts,
 		},
 	}
 
 	// Track created resource
-	b.CreatedResources["service"] = append(b.CreatedResources["service"], serviceConfig.Name)
-	b.TestResults.IncrementResourceCount("service")
+	b.CreatedResources[ResourceTypeServices] = append(b.CreatedResources[ResourceTypeServices], serviceName)
+	b.TestResults.IncrementResourceCount(ResourceTypeServices)
+	b.services[serviceName] = service.DeepCopy()
 
-	b.TestResults.AddLog(fmt.Sprintf("Created test service: %s", serviceConfig.Name))
+	b.TestResults.AddLog(fmt.Sprintf("Created test service: %s", serviceName))
 	return service, nil
 }
 
+// UpdateTestService updates a test service.
+func (b *BaseTestImplementation) UpdateTestService(ctx context.Context, serviceConfig *TestServiceConfig) (*v1.Service, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	serviceName := b.TestConfig.ResourceName(serviceConfig.Name)
+	service, ok := b.services[serviceName]
+	if !ok {
+		return nil, fmt.Errorf("test service %s not found", serviceName)
+	}
+
+	ApplyTestServiceConfig(service, serviceConfig)
+
+	b.TestResults.AddLog(fmt.Sprintf("Updated test service: %s", serviceName))
+	return service.DeepCopy(), nil
+}
+
 // DeleteTestService deletes a test service.
 func (b *BaseTestImplementation) DeleteTestService(ctx context.Context, serviceName string) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	serviceName = b.TestConfig.ResourceName(serviceName)
+	delete(b.services, serviceName)
+
 	// Remove from created resources
-	for i, name := range b.CreatedResources["service"] {
+	for i, name := range b.CreatedResources[ResourceTypeServices] {
 		if name == serviceName {
-			b.CreatedResources["service"] = append(b.CreatedResources["service"][:i], b.CreatedResources["service"][i+1:]...)
+			b.CreatedResources[ResourceTypeServices] = append(b.CreatedResources[ResourceTypeServices][:i], b.CreatedResources[ResourceTypeServices][i+1:]...)
+			b.TestResults.DecrementResourceCount(ResourceTypeServices)
 			break
 		}
 	}
@@ -233,18 +308,20 @@ func (b *BaseTestImplementation) CreateTestRoute(ctx context.Context, routeConfi
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	routeName := b.TestConfig.ResourceNameOrGenerate(routeConfig.Name, "test-route")
 	route := &cloudprovider.Route{
-		Name:            routeConfig.Name,
+		Name:            routeName,
 		TargetNode:      routeConfig.TargetNode,
 		DestinationCIDR: routeConfig.DestinationCIDR,
 		Blackhole:       routeConfig.Blackhole,
 	}
 
 	// Track created resource
-	b.CreatedResources["route"] = append(b.CreatedResources["route"], routeConfig.Name)
-	b.TestResults.IncrementResourceCount("route")
+	b.CreatedResources[ResourceTypeRoutes] = append(b.CreatedResources[ResourceTypeRoutes], routeName)
+	b.routes[routeName] = route
+	b.TestResults.IncrementResourceCount(ResourceTypeRoutes)
 
-	b.TestResults.AddLog(fmt.Sprintf("Created test route: %s", routeConfig.Name))
+	b.TestResults.AddLog(fmt.Sprintf("Created test route: %s", routeName))
 	return route, nil
 }
 
@@ -253,14 +330,19 @@ func (b *BaseTestImplementation) DeleteTestRoute(ctx context.Context, routeName
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	routeName = b.TestConfig.ResourceName(routeName)
+
 	// Remove from created resources
-	for i, name := range b.CreatedResources["route"] {
+	for i, name := range b.CreatedResources[ResourceTypeRoutes] {
 		if name == routeName {
-			b.CreatedResources["route"] = append(b.CreatedResources["route"][:i], b.CreatedResources["route"][i+1:]...)
+			b.CreatedResources[ResourceTypeRoutes] = append(b.CreatedResources[ResourceTypeRoutes][:i], b.CreatedResources[ResourceTypeRoutes][i+1:]...)
+			b.TestResults.DecrementResourceCount(ResourceTypeRoutes)
 			break
 		}
 	}
 
+	delete(b.routes, routeName)
+
 	b.TestResults.AddLog(fmt.Sprintf("Deleted test route: %s", routeName))
 	return nil
 }
@@ -289,6 +371,33 @@ func (b *BaseTestImplementation) WaitForCondition(ctx context.Context, condition
 	return fmt.Errorf("condition not met within timeout: %s", condition.Type)
 }
 
+// WaitForTaintRemoved waits until the test node no longer carries the taint.
+func (b *BaseTestImplementation) WaitForTaintRemoved(ctx context.Context, nodeName, taintKey string, timeout time.Duration) error {
+	nodeName = b.TestConfig.ResourceName(nodeName)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		b.mu.RLock()
+		node, ok := b.nodes[nodeName]
+		tainted := ok && NodeHasTaint(node, taintKey)
+		b.mu.RUnlock()
+
+		if !ok {
+			return fmt.Errorf("test node %s not found", nodeName)
+		}
+		if !tainted {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for taint %s to be removed from node %s", taintKey, nodeName)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
 // GetTestResults returns the test results.
 func (b *BaseTestImplementation) GetTestResults() *TestResults {
 	b.mu.RLock()
@@ -296,23 +405,209 @@ func (b *BaseTestImplementation) GetTestResults() *TestResults {
 	return b.TestResults
 }
 
+// GetConfig returns the current test configuration.
+func (b *BaseTestImplementation) GetConfig() *TestConfig {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.TestConfig
+}
+
 // ResetTestState resets the test state.
 func (b *BaseTestImplementation) ResetTestState() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	b.CreatedResources = make(map[string][]string)
+	b.nodes = make(map[string]*v1.Node)
+	b.services = make(map[string]*v1.Service)
+	b.routes = make(map[string]*cloudprovider.Route)
 	b.TestResults = &TestResults{
 		Success:        true,
 		ResourceCounts: make(map[string]int),
 		Metrics:        make(map[string]interface{}),
 		Logs:           []string{},
+		MaxLogs:        b.TestResults.MaxLogs,
 	}
 
 	b.TestResults.AddLog("Test state reset completed")
 	return nil
 }
 
+// DumpResources writes the tracked test nodes, services and routes to w as YAML.
+func (b *BaseTestImplementation) DumpResources(ctx context.Context, w io.Writer) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var nodes []*v1.Node
+	for _, node := range b.nodes {
+		nodes = append(nodes, node)
+	}
+	var services []*v1.Service
+	for _, service := range b.services {
+		services = append(services, service)
+	}
+	var routes []*cloudprovider.Route
+	for _, route := range b.routes {
+		routes = append(routes, route)
+	}
+
+	return WriteResourceDump(w, nodes, services, routes)
+}
+
+// WriteResourceDump writes nodes, services and routes to w as a stream of
+// YAML documents separated by "---", sorted by kind and then by name. Nodes
+// and services are written with their kind and API version set so the dump
+// can be applied to a cluster.
+func WriteResourceDump(w io.Writer, nodes []*v1.Node, services []*v1.Service, routes []*cloudprovider.Route) error {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Name < routes[j].Name })
+
+	var documents []interface{}
+	for _, node := range nodes {
+		node = node.DeepCopy()
+		node.APIVersion, node.Kind = "v1", "Node"
+		documents = append(documents, node)
+	}
+	for _, service := range services {
+		service = service.DeepCopy()
+		service.APIVersion, service.Kind = "v1", "Service"
+		documents = append(documents, service)
+	}
+	for _, route := range routes {
+		documents = append(documents, route)
+	}
+
+	for i, document := range documents {
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			return fmt.Errorf("failed to marshal resource: %w", err)
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyTestNodeConfig patches the mutable fields of node from nodeConfig.
+// Labels and annotations are merged into the existing ones; addresses and
+// conditions replace the existing ones when set.
+func ApplyTestNodeConfig(node *v1.Node, nodeConfig *TestNodeConfig) {
+	node.Labels = mergeStringMaps(node.Labels, nodeConfig.Labels)
+	node.Annotations = mergeStringMaps(node.Annotations, nodeConfig.Annotations)
+	if nodeConfig.Addresses != nil {
+		node.Status.Addresses = nodeConfig.Addresses
+	}
+	if nodeConfig.Conditions != nil {
+		node.Status.Conditions = nodeConfig.Conditions
+	}
+	if nodeConfig.Taints != nil {
+		node.Spec.Taints = nodeConfig.Taints
+	}
+}
+
+// ErrInvalidServiceConfig is returned by CreateTestService for a service
+// configuration a real API server would reject.
+var ErrInvalidServiceConfig = errors.New("invalid test service config")
+
+// ValidateTestServiceConfig checks that serviceConfig only sets fields valid
+// for its service type: LoadBalancerIP, LoadBalancerClass and
+// AllocateLoadBalancerNodePorts require a LoadBalancer service, an ExternalTrafficPolicy requires a NodePort or
+// LoadBalancer service, a HealthCheckNodePort requires a LoadBalancer service
+// with a Local ExternalTrafficPolicy, and NodePort and LoadBalancer services
+// need at least one port.
+func ValidateTestServiceConfig(serviceConfig *TestServiceConfig) error {
+	serviceType := serviceConfig.Type
+	if serviceType == "" {
+		serviceType = v1.ServiceTypeClusterIP
+	}
+	external := serviceType == v1.ServiceTypeLoadBalancer || serviceType == v1.ServiceTypeNodePort
+
+	if serviceConfig.LoadBalancerIP != "" && serviceType != v1.ServiceTypeLoadBalancer {
+		return fmt.Errorf("%w: service %s of type %s cannot set LoadBalancerIP", ErrInvalidServiceConfig, serviceConfig.Name, serviceType)
+	}
+	if serviceConfig.LoadBalancerClass != nil && serviceType != v1.ServiceTypeLoadBalancer {
+		return fmt.Errorf("%w: service %s of type %s cannot set LoadBalancerClass", ErrInvalidServiceConfig, serviceConfig.Name, serviceType)
+	}
+	if serviceConfig.AllocateLoadBalancerNodePorts != nil && serviceType != v1.ServiceTypeLoadBalancer {
+		return fmt.Errorf("%w: service %s of type %s cannot set AllocateLoadBalancerNodePorts", ErrInvalidServiceConfig, serviceConfig.Name, serviceType)
+	}
+	if serviceConfig.ExternalTrafficPolicy != "" && !external {
+		return fmt.Errorf("%w: service %s of type %s cannot set ExternalTrafficPolicy", ErrInvalidServiceConfig, serviceConfig.Name, serviceType)
+	}
+	if serviceConfig.HealthCheckNodePort != 0 && (serviceType != v1.ServiceTypeLoadBalancer || serviceConfig.ExternalTrafficPolicy != v1.ServiceExternalTrafficPolicyLocal) {
+		return fmt.Errorf("%w: service %s of type %s cannot set HealthCheckNodePort without a Local ExternalTrafficPolicy", ErrInvalidServiceConfig, serviceConfig.Name, serviceType)
+	}
+	if external && len(serviceConfig.Ports) == 0 {
+		return fmt.Errorf("%w: service %s of type %s must have at least one port", ErrInvalidServiceConfig, serviceConfig.Name, serviceType)
+	}
+
+	return nil
+}
+
+// ApplyTestServiceConfig patches the mutable fields of service from
+// serviceConfig. Labels and annotations are merged into the existing ones,
+// then the RemoveAnnotations are deleted; the type, ports, load balancer IP
+// and traffic policies replace the existing values when set.
+func ApplyTestServiceConfig(service *v1.Service, serviceConfig *TestServiceConfig) {
+	service.Labels = mergeStringMaps(service.Labels, serviceConfig.Labels)
+	service.Annotations = mergeStringMaps(service.Annotations, serviceConfig.Annotations)
+	for _, key := range serviceConfig.RemoveAnnotations {
+		delete(service.Annotations, key)
+	}
+	if serviceConfig.Type != "" {
+		service.Spec.Type = serviceConfig.Type
+	}
+	if serviceConfig.Ports != nil {
+		service.Spec.Ports = serviceConfig.Ports
+	}
+	if serviceConfig.LoadBalancerIP != "" {
+		service.Spec.LoadBalancerIP = serviceConfig.LoadBalancerIP
+	}
+	if serviceConfig.ExternalTrafficPolicy != "" {
+		service.Spec.ExternalTrafficPolicy = serviceConfig.ExternalTrafficPolicy
+	}
+	if serviceConfig.HealthCheckNodePort != 0 {
+		service.Spec.HealthCheckNodePort = serviceConfig.HealthCheckNodePort
+	}
+	// The API server releases the health check node port once the service
+	// no longer needs one
+	if service.Spec.Type != v1.ServiceTypeLoadBalancer || service.Spec.ExternalTrafficPolicy != v1.ServiceExternalTrafficPolicyLocal {
+		service.Spec.HealthCheckNodePort = 0
+	}
+	if serviceConfig.InternalTrafficPolicy != nil {
+		service.Spec.InternalTrafficPolicy = serviceConfig.InternalTrafficPolicy
+	}
+	if serviceConfig.AllocateLoadBalancerNodePorts != nil {
+		service.Spec.AllocateLoadBalancerNodePorts = serviceConfig.AllocateLoadBalancerNodePorts
+	}
+}
+
+// mergeStringMaps returns dst with every entry of src added to it.
+func mergeStringMaps(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
 // FakeTestImplementation provides a test implementation using the fake cloud provider.
 // This is useful for testing the test framework itself or for cloud providers that
 // want to use the fake provider for testing.
@@ -18,7 +18,9 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -107,8 +109,8 @@ func TestBaseTestImplementationTeardownTestEnvironment(t *testing.T) {
 	baseImpl := NewBaseTestImplementation(fakeCloud)
 
 	// Add some created resources
-	baseImpl.CreatedResources["node"] = []string{"test-node-1", "test-node-2"}
-	baseImpl.CreatedResources["service"] = []string{"test-service-1"}
+	baseImpl.CreatedResources[ResourceTypeNodes] = []string{"test-node-1", "test-node-2"}
+	baseImpl.CreatedResources[ResourceTypeServices] = []string{"test-service-1"}
 
 	err := baseImpl.TeardownTestEnvironment()
 	if err != nil {
@@ -156,6 +158,7 @@ func TestBaseTestImplementationCreateTestNode(t *testing.T) {
 		Conditions: []v1.NodeCondition{
 			{Type: v1.NodeReady, Status: v1.ConditionTrue},
 		},
+		PodCIDRs: []string{"10.244.1.0/24", "fd00:10:244:1::/64"},
 	}
 
 	ctx := context.Background()
@@ -188,6 +191,10 @@ func TestBaseTestImplementationCreateTestNode(t *testing.T) {
 		t.Errorf("Expected 1 condition, got %d", len(node.Status.Conditions))
 	}
 
+	if node.Spec.PodCIDR != "10.244.1.0/24" || len(node.Spec.PodCIDRs) != 2 {
+		t.Errorf("Expected primary pod CIDR '10.244.1.0/24' of 2, got '%s' of %v", node.Spec.PodCIDR, node.Spec.PodCIDRs)
+	}
+
 	// Verify zone and region labels are set
 	if node.Labels["topology.kubernetes.io/zone"] != "us-west-1a" {
 		t.Errorf("Expected zone label 'us-west-1a', got '%s'", node.Labels["topology.kubernetes.io/zone"])
@@ -202,16 +209,16 @@ func TestBaseTestImplementationCreateTestNode(t *testing.T) {
 	}
 
 	// Verify resource tracking
-	if len(baseImpl.CreatedResources["node"]) != 1 {
-		t.Errorf("Expected 1 created node, got %d", len(baseImpl.CreatedResources["node"]))
+	if len(baseImpl.CreatedResources[ResourceTypeNodes]) != 1 {
+		t.Errorf("Expected 1 created node, got %d", len(baseImpl.CreatedResources[ResourceTypeNodes]))
 	}
 
-	if baseImpl.CreatedResources["node"][0] != "test-node" {
-		t.Errorf("Expected created node 'test-node', got '%s'", baseImpl.CreatedResources["node"][0])
+	if baseImpl.CreatedResources[ResourceTypeNodes][0] != "test-node" {
+		t.Errorf("Expected created node 'test-node', got '%s'", baseImpl.CreatedResources[ResourceTypeNodes][0])
 	}
 
-	if baseImpl.TestResults.ResourceCounts["node"] != 1 {
-		t.Errorf("Expected node count 1, got %d", baseImpl.TestResults.ResourceCounts["node"])
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeNodes] != 1 {
+		t.Errorf("Expected node count 1, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeNodes])
 	}
 }
 
@@ -221,7 +228,7 @@ func TestBaseTestImplementationDeleteTestNode(t *testing.T) {
 	baseImpl := NewBaseTestImplementation(fakeCloud)
 
 	// Add a node to created resources
-	baseImpl.CreatedResources["node"] = []string{"test-node-1", "test-node-2"}
+	baseImpl.CreatedResources[ResourceTypeNodes] = []string{"test-node-1", "test-node-2"}
 
 	ctx := context.Background()
 	err := baseImpl.DeleteTestNode(ctx, "test-node-1")
@@ -230,12 +237,12 @@ func TestBaseTestImplementationDeleteTestNode(t *testing.T) {
 	}
 
 	// Verify node is removed from created resources
-	if len(baseImpl.CreatedResources["node"]) != 1 {
-		t.Errorf("Expected 1 remaining node, got %d", len(baseImpl.CreatedResources["node"]))
+	if len(baseImpl.CreatedResources[ResourceTypeNodes]) != 1 {
+		t.Errorf("Expected 1 remaining node, got %d", len(baseImpl.CreatedResources[ResourceTypeNodes]))
 	}
 
-	if baseImpl.CreatedResources["node"][0] != "test-node-2" {
-		t.Errorf("Expected remaining node 'test-node-2', got '%s'", baseImpl.CreatedResources["node"][0])
+	if baseImpl.CreatedResources[ResourceTypeNodes][0] != "test-node-2" {
+		t.Errorf("Expected remaining node 'test-node-2', got '%s'", baseImpl.CreatedResources[ResourceTypeNodes][0])
 	}
 }
 
@@ -307,16 +314,123 @@ func TestBaseTestImplementationCreateTestService(t *testing.T) {
 	}
 
 	// Verify resource tracking
-	if len(baseImpl.CreatedResources["service"]) != 1 {
-		t.Errorf("Expected 1 created service, got %d", len(baseImpl.CreatedResources["service"]))
+	if len(baseImpl.CreatedResources[ResourceTypeServices]) != 1 {
+		t.Errorf("Expected 1 created service, got %d", len(baseImpl.CreatedResources[ResourceTypeServices]))
 	}
 
-	if baseImpl.CreatedResources["service"][0] != "test-service" {
-		t.Errorf("Expected created service 'test-service', got '%s'", baseImpl.CreatedResources["service"][0])
+	if baseImpl.CreatedResources[ResourceTypeServices][0] != "test-service" {
+		t.Errorf("Expected created service 'test-service', got '%s'", baseImpl.CreatedResources[ResourceTypeServices][0])
 	}
 
-	if baseImpl.TestResults.ResourceCounts["service"] != 1 {
-		t.Errorf("Expected service count 1, got %d", baseImpl.TestResults.ResourceCounts["service"])
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeServices] != 1 {
+		t.Errorf("Expected service count 1, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeServices])
+	}
+}
+
+// TestBaseTestImplementationCreateTestServiceValidation tests that service configs a
+// real API server would reject are refused before anything is created
+func TestBaseTestImplementationCreateTestServiceValidation(t *testing.T) {
+	ports := []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}}
+	lbClass := "example.com/lb"
+	noNodePorts := false
+
+	tests := []struct {
+		name    string
+		config  TestServiceConfig
+		wantErr string
+	}{
+		{
+			name:   "LoadBalancer with IP and policy",
+			config: TestServiceConfig{Type: v1.ServiceTypeLoadBalancer, Ports: ports, LoadBalancerIP: "10.0.0.1", ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyLocal},
+		},
+		{
+			name:   "NodePort with external traffic policy",
+			config: TestServiceConfig{Type: v1.ServiceTypeNodePort, Ports: ports, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyLocal},
+		},
+		{
+			name:   "ClusterIP without ports",
+			config: TestServiceConfig{Type: v1.ServiceTypeClusterIP},
+		},
+		{
+			name:    "ClusterIP with LoadBalancerIP",
+			config:  TestServiceConfig{Type: v1.ServiceTypeClusterIP, Ports: ports, LoadBalancerIP: "10.0.0.1"},
+			wantErr: "of type ClusterIP cannot set LoadBalancerIP",
+		},
+		{
+			name:    "NodePort with LoadBalancerIP",
+			config:  TestServiceConfig{Type: v1.ServiceTypeNodePort, Ports: ports, LoadBalancerIP: "10.0.0.1"},
+			wantErr: "of type NodePort cannot set LoadBalancerIP",
+		},
+		{
+			name:    "default type with external traffic policy",
+			config:  TestServiceConfig{Ports: ports, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyCluster},
+			wantErr: "of type ClusterIP cannot set ExternalTrafficPolicy",
+		},
+		{
+			name:   "LoadBalancer with class",
+			config: TestServiceConfig{Type: v1.ServiceTypeLoadBalancer, Ports: ports, LoadBalancerClass: &lbClass},
+		},
+		{
+			name:    "NodePort with LoadBalancerClass",
+			config:  TestServiceConfig{Type: v1.ServiceTypeNodePort, Ports: ports, LoadBalancerClass: &lbClass},
+			wantErr: "of type NodePort cannot set LoadBalancerClass",
+		},
+		{
+			name:   "LoadBalancer without node ports",
+			config: TestServiceConfig{Type: v1.ServiceTypeLoadBalancer, Ports: ports, AllocateLoadBalancerNodePorts: &noNodePorts},
+		},
+		{
+			name:    "NodePort with AllocateLoadBalancerNodePorts",
+			config:  TestServiceConfig{Type: v1.ServiceTypeNodePort, Ports: ports, AllocateLoadBalancerNodePorts: &noNodePorts},
+			wantErr: "of type NodePort cannot set AllocateLoadBalancerNodePorts",
+		},
+		{
+			name:   "LoadBalancer with health check node port",
+			config: TestServiceConfig{Type: v1.ServiceTypeLoadBalancer, Ports: ports, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyLocal, HealthCheckNodePort: 32100},
+		},
+		{
+			name:    "Cluster policy with health check node port",
+			config:  TestServiceConfig{Type: v1.ServiceTypeLoadBalancer, Ports: ports, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyCluster, HealthCheckNodePort: 32100},
+			wantErr: "of type LoadBalancer cannot set HealthCheckNodePort without a Local ExternalTrafficPolicy",
+		},
+		{
+			name:    "LoadBalancer without ports",
+			config:  TestServiceConfig{Type: v1.ServiceTypeLoadBalancer},
+			wantErr: "of type LoadBalancer must have at least one port",
+		},
+		{
+			name:    "NodePort without ports",
+			config:  TestServiceConfig{Type: v1.ServiceTypeNodePort, Ports: []v1.ServicePort{}},
+			wantErr: "of type NodePort must have at least one port",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := NewBaseTestImplementation(&fakecloud.Cloud{})
+
+			config := tt.config
+			config.Name = "validated-service"
+			config.Namespace = "default"
+			_, err := impl.CreateTestService(context.Background(), &config)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidServiceConfig) {
+				t.Fatalf("Expected ErrInvalidServiceConfig, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error to contain '%s', got '%v'", tt.wantErr, err)
+			}
+			if impl.GetTestResults().ResourceCounts[ResourceTypeServices] != 0 {
+				t.Errorf("Expected no service to be created, got %d", impl.GetTestResults().ResourceCounts[ResourceTypeServices])
+			}
+		})
 	}
 }
 
@@ -326,7 +440,7 @@ func TestBaseTestImplementationDeleteTestService(t *testing.T) {
 	baseImpl := NewBaseTestImplementation(fakeCloud)
 
 	// Add a service to created resources
-	baseImpl.CreatedResources["service"] = []string{"test-service-1", "test-service-2"}
+	baseImpl.CreatedResources[ResourceTypeServices] = []string{"test-service-1", "test-service-2"}
 
 	ctx := context.Background()
 	err := baseImpl.DeleteTestService(ctx, "test-service-1")
@@ -335,12 +449,12 @@ func TestBaseTestImplementationDeleteTestService(t *testing.T) {
 	}
 
 	// Verify service is removed from created resources
-	if len(baseImpl.CreatedResources["service"]) != 1 {
-		t.Errorf("Expected 1 remaining service, got %d", len(baseImpl.CreatedResources["service"]))
+	if len(baseImpl.CreatedResources[ResourceTypeServices]) != 1 {
+		t.Errorf("Expected 1 remaining service, got %d", len(baseImpl.CreatedResources[ResourceTypeServices]))
 	}
 
-	if baseImpl.CreatedResources["service"][0] != "test-service-2" {
-		t.Errorf("Expected remaining service 'test-service-2', got '%s'", baseImpl.CreatedResources["service"][0])
+	if baseImpl.CreatedResources[ResourceTypeServices][0] != "test-service-2" {
+		t.Errorf("Expected remaining service 'test-service-2', got '%s'", baseImpl.CreatedResources[ResourceTypeServices][0])
 	}
 }
 
@@ -380,16 +494,16 @@ func TestBaseTestImplementationCreateTestRoute(t *testing.T) {
 	}
 
 	// Verify resource tracking
-	if len(baseImpl.CreatedResources["route"]) != 1 {
-		t.Errorf("Expected 1 created route, got %d", len(baseImpl.CreatedResources["route"]))
+	if len(baseImpl.CreatedResources[ResourceTypeRoutes]) != 1 {
+		t.Errorf("Expected 1 created route, got %d", len(baseImpl.CreatedResources[ResourceTypeRoutes]))
 	}
 
-	if baseImpl.CreatedResources["route"][0] != "test-route" {
-		t.Errorf("Expected created route 'test-route', got '%s'", baseImpl.CreatedResources["route"][0])
+	if baseImpl.CreatedResources[ResourceTypeRoutes][0] != "test-route" {
+		t.Errorf("Expected created route 'test-route', got '%s'", baseImpl.CreatedResources[ResourceTypeRoutes][0])
 	}
 
-	if baseImpl.TestResults.ResourceCounts["route"] != 1 {
-		t.Errorf("Expected route count 1, got %d", baseImpl.TestResults.ResourceCounts["route"])
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeRoutes] != 1 {
+		t.Errorf("Expected route count 1, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeRoutes])
 	}
 }
 
@@ -399,7 +513,7 @@ func TestBaseTestImplementationDeleteTestRoute(t *testing.T) {
 	baseImpl := NewBaseTestImplementation(fakeCloud)
 
 	// Add a route to created resources
-	baseImpl.CreatedResources["route"] = []string{"test-route-1", "test-route-2"}
+	baseImpl.CreatedResources[ResourceTypeRoutes] = []string{"test-route-1", "test-route-2"}
 
 	ctx := context.Background()
 	err := baseImpl.DeleteTestRoute(ctx, "test-route-1")
@@ -408,12 +522,12 @@ func TestBaseTestImplementationDeleteTestRoute(t *testing.T) {
 	}
 
 	// Verify route is removed from created resources
-	if len(baseImpl.CreatedResources["route"]) != 1 {
-		t.Errorf("Expected 1 remaining route, got %d", len(baseImpl.CreatedResources["route"]))
+	if len(baseImpl.CreatedResources[ResourceTypeRoutes]) != 1 {
+		t.Errorf("Expected 1 remaining route, got %d", len(baseImpl.CreatedResources[ResourceTypeRoutes]))
 	}
 
-	if baseImpl.CreatedResources["route"][0] != "test-route-2" {
-		t.Errorf("Expected remaining route 'test-route-2', got '%s'", baseImpl.CreatedResources["route"][0])
+	if baseImpl.CreatedResources[ResourceTypeRoutes][0] != "test-route-2" {
+		t.Errorf("Expected remaining route 'test-route-2', got '%s'", baseImpl.CreatedResources[ResourceTypeRoutes][0])
 	}
 }
 
@@ -469,6 +583,54 @@ func TestBaseTestImplementationWaitForCondition(t *testing.T) {
 	}
 }
 
+// TestBaseTestImplementationWaitForTaintRemoved tests waiting for the
+// uninitialized taint to be removed from a test node
+func TestBaseTestImplementationWaitForTaintRemoved(t *testing.T) {
+	ctx := context.Background()
+	uninitialized := []v1.Taint{{Key: UninitializedTaintKey, Value: "true", Effect: v1.TaintEffectNoSchedule}}
+
+	tests := []struct {
+		name    string
+		remove  bool
+		wantErr string
+	}{
+		{name: "removed in time", remove: true},
+		{name: "times out", wantErr: "timeout waiting for taint " + UninitializedTaintKey + " to be removed from node tainted-node"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseImpl := NewBaseTestImplementation(&fakecloud.Cloud{})
+			if _, err := baseImpl.CreateTestNode(ctx, &TestNodeConfig{Name: "tainted-node", Taints: uninitialized}); err != nil {
+				t.Fatalf("Failed to create test node: %v", err)
+			}
+
+			if tt.remove {
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					_, _ = baseImpl.UpdateTestNode(ctx, &TestNodeConfig{Name: "tainted-node", Taints: []v1.Taint{}})
+				}()
+			}
+
+			err := baseImpl.WaitForTaintRemoved(ctx, "tainted-node", UninitializedTaintKey, 300*time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Expected error '%s', got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+
+	baseImpl := NewBaseTestImplementation(&fakecloud.Cloud{})
+	if err := baseImpl.WaitForTaintRemoved(ctx, "missing-node", UninitializedTaintKey, time.Second); err == nil {
+		t.Error("Expected an error for a missing node")
+	}
+}
+
 // TestBaseTestImplementationGetTestResults tests getting test results
 func TestBaseTestImplementationGetTestResults(t *testing.T) {
 	fakeCloud := &fakecloud.Cloud{}
@@ -477,7 +639,7 @@ func TestBaseTestImplementationGetTestResults(t *testing.T) {
 	// Add some test data
 	baseImpl.TestResults.AddLog("test log 1")
 	baseImpl.TestResults.SetMetric("test_metric", 42)
-	baseImpl.TestResults.IncrementResourceCount("node")
+	baseImpl.TestResults.IncrementResourceCount(ResourceTypeNodes)
 
 	results := baseImpl.GetTestResults()
 	if results == nil {
@@ -496,8 +658,8 @@ func TestBaseTestImplementationGetTestResults(t *testing.T) {
 		t.Errorf("Expected metric 42, got %v", results.Metrics["test_metric"])
 	}
 
-	if results.ResourceCounts["node"] != 1 {
-		t.Errorf("Expected node count 1, got %d", results.ResourceCounts["node"])
+	if results.ResourceCounts[ResourceTypeNodes] != 1 {
+		t.Errorf("Expected node count 1, got %d", results.ResourceCounts[ResourceTypeNodes])
 	}
 }
 
@@ -507,10 +669,10 @@ func TestBaseTestImplementationResetTestState(t *testing.T) {
 	baseImpl := NewBaseTestImplementation(fakeCloud)
 
 	// Add some test data
-	baseImpl.CreatedResources["node"] = []string{"test-node"}
+	baseImpl.CreatedResources[ResourceTypeNodes] = []string{"test-node"}
 	baseImpl.TestResults.AddLog("test log")
 	baseImpl.TestResults.SetMetric("test_metric", 42)
-	baseImpl.TestResults.IncrementResourceCount("node")
+	baseImpl.TestResults.IncrementResourceCount(ResourceTypeNodes)
 
 	err := baseImpl.ResetTestState()
 	if err != nil {
@@ -703,7 +865,7 @@ func TestBaseTestImplementationConcurrency(t *testing.T) {
 		go func(id int) {
 			baseImpl.GetTestResults().AddLog(fmt.Sprintf("log %d", id))
 			baseImpl.GetTestResults().SetMetric(fmt.Sprintf("metric_%d", id), id)
-			baseImpl.GetTestResults().IncrementResourceCount("node")
+			baseImpl.GetTestResults().IncrementResourceCount(ResourceTypeNodes)
 			done <- true
 		}(i)
 	}
@@ -722,8 +884,8 @@ func TestBaseTestImplementationConcurrency(t *testing.T) {
 		t.Errorf("Expected 10 metrics, got %d", len(results.Metrics))
 	}
 
-	if results.ResourceCounts["node"] != 10 {
-		t.Errorf("Expected node count 10, got %d", results.ResourceCounts["node"])
+	if results.ResourceCounts[ResourceTypeNodes] != 10 {
+		t.Errorf("Expected node count 10, got %d", results.ResourceCounts[ResourceTypeNodes])
 	}
 }
 
@@ -761,29 +923,29 @@ func TestBaseTestImplementationResourceTracking(t *testing.T) {
 	}
 
 	// Verify resource tracking
-	if len(baseImpl.CreatedResources["node"]) != 2 {
-		t.Errorf("Expected 2 nodes, got %d", len(baseImpl.CreatedResources["node"]))
+	if len(baseImpl.CreatedResources[ResourceTypeNodes]) != 2 {
+		t.Errorf("Expected 2 nodes, got %d", len(baseImpl.CreatedResources[ResourceTypeNodes]))
 	}
 
-	if len(baseImpl.CreatedResources["service"]) != 1 {
-		t.Errorf("Expected 1 service, got %d", len(baseImpl.CreatedResources["service"]))
+	if len(baseImpl.CreatedResources[ResourceTypeServices]) != 1 {
+		t.Errorf("Expected 1 service, got %d", len(baseImpl.CreatedResources[ResourceTypeServices]))
 	}
 
-	if len(baseImpl.CreatedResources["route"]) != 1 {
-		t.Errorf("Expected 1 route, got %d", len(baseImpl.CreatedResources["route"]))
+	if len(baseImpl.CreatedResources[ResourceTypeRoutes]) != 1 {
+		t.Errorf("Expected 1 route, got %d", len(baseImpl.CreatedResources[ResourceTypeRoutes]))
 	}
 
 	// Verify resource counts
-	if baseImpl.TestResults.ResourceCounts["node"] != 2 {
-		t.Errorf("Expected node count 2, got %d", baseImpl.TestResults.ResourceCounts["node"])
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeNodes] != 2 {
+		t.Errorf("Expected node count 2, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeNodes])
 	}
 
-	if baseImpl.TestResults.ResourceCounts["service"] != 1 {
-		t.Errorf("Expected service count 1, got %d", baseImpl.TestResults.ResourceCounts["service"])
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeServices] != 1 {
+		t.Errorf("Expected service count 1, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeServices])
 	}
 
-	if baseImpl.TestResults.ResourceCounts["route"] != 1 {
-		t.Errorf("Expected route count 1, got %d", baseImpl.TestResults.ResourceCounts["route"])
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeRoutes] != 1 {
+		t.Errorf("Expected route count 1, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeRoutes])
 	}
 
 	// Delete some resources
@@ -798,15 +960,345 @@ func TestBaseTestImplementationResourceTracking(t *testing.T) {
 	}
 
 	// Verify resources are removed from tracking
-	if len(baseImpl.CreatedResources["node"]) != 1 {
-		t.Errorf("Expected 1 remaining node, got %d", len(baseImpl.CreatedResources["node"]))
+	if len(baseImpl.CreatedResources[ResourceTypeNodes]) != 1 {
+		t.Errorf("Expected 1 remaining node, got %d", len(baseImpl.CreatedResources[ResourceTypeNodes]))
+	}
+
+	if baseImpl.CreatedResources[ResourceTypeNodes][0] != "node-2" {
+		t.Errorf("Expected remaining node 'node-2', got '%s'", baseImpl.CreatedResources[ResourceTypeNodes][0])
+	}
+
+	if len(baseImpl.CreatedResources[ResourceTypeServices]) != 0 {
+		t.Errorf("Expected 0 remaining services, got %d", len(baseImpl.CreatedResources[ResourceTypeServices]))
 	}
 
-	if baseImpl.CreatedResources["node"][0] != "node-2" {
-		t.Errorf("Expected remaining node 'node-2', got '%s'", baseImpl.CreatedResources["node"][0])
+	// Verify resource counts are decremented
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeNodes] != 1 {
+		t.Errorf("Expected node count 1, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeNodes])
 	}
 
-	if len(baseImpl.CreatedResources["service"]) != 0 {
-		t.Errorf("Expected 0 remaining services, got %d", len(baseImpl.CreatedResources["service"]))
+	if baseImpl.TestResults.ResourceCounts[ResourceTypeServices] != 0 {
+		t.Errorf("Expected service count 0, got %d", baseImpl.TestResults.ResourceCounts[ResourceTypeServices])
+	}
+}
+
+// TestBaseTestImplementationDeleteResourceCounts tests that creating and then
+// deleting a resource leaves its count at zero, even when deleted twice
+func TestBaseTestImplementationDeleteResourceCounts(t *testing.T) {
+	baseImpl := NewBaseTestImplementation(&fakecloud.Cloud{})
+	ctx := context.Background()
+
+	if _, err := baseImpl.CreateTestNode(ctx, &TestNodeConfig{Name: "node-1", ProviderID: "test://node-1"}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	if _, err := baseImpl.CreateTestService(ctx, &TestServiceConfig{Name: "service-1", Namespace: "default"}); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	if _, err := baseImpl.CreateTestRoute(ctx, &TestRouteConfig{Name: "route-1", TargetNode: "node-1", DestinationCIDR: "10.0.0.0/24"}); err != nil {
+		t.Fatalf("Failed to create route: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := baseImpl.DeleteTestNode(ctx, "node-1"); err != nil {
+			t.Fatalf("Failed to delete node: %v", err)
+		}
+		if err := baseImpl.DeleteTestService(ctx, "service-1"); err != nil {
+			t.Fatalf("Failed to delete service: %v", err)
+		}
+		if err := baseImpl.DeleteTestRoute(ctx, "route-1"); err != nil {
+			t.Fatalf("Failed to delete route: %v", err)
+		}
+	}
+
+	for _, resourceType := range []string{ResourceTypeNodes, ResourceTypeServices, ResourceTypeRoutes} {
+		if count := baseImpl.GetTestResults().GetResourceCounts()[resourceType]; count != 0 {
+			t.Errorf("Expected %s count 0, got %d", resourceType, count)
+		}
+	}
+}
+
+// TestBaseTestImplementationNamePrefix tests that the configured name prefix is
+// applied on creation and resolved on deletion
+func TestBaseTestImplementationNamePrefix(t *testing.T) {
+	fakeCloud := &fakecloud.Cloud{}
+	baseImpl := NewBaseTestImplementation(fakeCloud)
+	baseImpl.TestConfig = &TestConfig{NamePrefix: "ci-"}
+
+	ctx := context.Background()
+
+	node, err := baseImpl.CreateTestNode(ctx, &TestNodeConfig{Name: "test-node", ProviderID: "test://test-node"})
+	if err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+
+	if node.Name != "ci-test-node" {
+		t.Errorf("Expected node name 'ci-test-node', got '%s'", node.Name)
+	}
+
+	service, err := baseImpl.CreateTestService(ctx, &TestServiceConfig{Name: "test-service", Namespace: "default"})
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	if service.Name != "ci-test-service" {
+		t.Errorf("Expected service name 'ci-test-service', got '%s'", service.Name)
+	}
+
+	route, err := baseImpl.CreateTestRoute(ctx, &TestRouteConfig{Name: "test-route", DestinationCIDR: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("Failed to create route: %v", err)
+	}
+
+	if route.Name != "ci-test-route" {
+		t.Errorf("Expected route name 'ci-test-route', got '%s'", route.Name)
+	}
+
+	if baseImpl.CreatedResources[ResourceTypeNodes][0] != "ci-test-node" {
+		t.Errorf("Expected tracked node 'ci-test-node', got '%s'", baseImpl.CreatedResources[ResourceTypeNodes][0])
+	}
+
+	// Deleting by the unprefixed or the prefixed name resolves to the same resource
+	if err := baseImpl.DeleteTestNode(ctx, "test-node"); err != nil {
+		t.Fatalf("Failed to delete node: %v", err)
+	}
+
+	if err := baseImpl.DeleteTestService(ctx, service.Name); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+
+	if err := baseImpl.DeleteTestRoute(ctx, "test-route"); err != nil {
+		t.Fatalf("Failed to delete route: %v", err)
+	}
+
+	for _, resourceType := range []string{ResourceTypeNodes, ResourceTypeServices, ResourceTypeRoutes} {
+		if len(baseImpl.CreatedResources[resourceType]) != 0 {
+			t.Errorf("Expected no tracked %s resources, got %v", resourceType, baseImpl.CreatedResources[resourceType])
+		}
+	}
+}
+
+// TestBaseTestImplementationUpdate tests that updates patch the tracked node and
+// service in place without tracking them a second time
+func TestBaseTestImplementationUpdate(t *testing.T) {
+	fakeCloud := &fakecloud.Cloud{}
+	baseImpl := NewBaseTestImplementation(fakeCloud)
+
+	ctx := context.Background()
+
+	_, err := baseImpl.CreateTestNode(ctx, &TestNodeConfig{
+		Name:   "test-node",
+		Labels: map[string]string{"role": "worker"},
+		Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+
+	node, err := baseImpl.UpdateTestNode(ctx, &TestNodeConfig{
+		Name:   "test-node",
+		Labels: map[string]string{"pool": "blue"},
+		Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+
+	if node.Labels["role"] != "worker" || node.Labels["pool"] != "blue" {
+		t.Errorf("Expected merged labels, got %v", node.Labels)
+	}
+
+	if len(node.Status.Addresses) != 1 || node.Status.Addresses[0].Address != "10.0.0.2" {
+		t.Errorf("Expected updated address 10.0.0.2, got %v", node.Status.Addresses)
+	}
+
+	// A second update must observe the first one
+	node, err = baseImpl.UpdateTestNode(ctx, &TestNodeConfig{Name: "test-node", Annotations: map[string]string{"note": "x"}})
+	if err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+
+	if node.Labels["pool"] != "blue" || node.Status.Addresses[0].Address != "10.0.0.2" {
+		t.Errorf("Expected earlier update to be kept, got labels %v and addresses %v", node.Labels, node.Status.Addresses)
+	}
+
+	node, err = baseImpl.UpdateTestNode(ctx, &TestNodeConfig{
+		Name:       "test-node",
+		Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+
+	if len(node.Status.Conditions) != 1 || node.Status.Conditions[0].Status != v1.ConditionFalse {
+		t.Errorf("Expected Ready=False condition, got %v", node.Status.Conditions)
+	}
+
+	_, err = baseImpl.CreateTestService(ctx, &TestServiceConfig{
+		Name:      "test-service",
+		Namespace: "default",
+		Type:      v1.ServiceTypeNodePort,
+		Ports:     []v1.ServicePort{{Name: "http", Port: 80}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+
+	service, err := baseImpl.UpdateTestService(ctx, &TestServiceConfig{
+		Name: "test-service",
+		Type: v1.ServiceTypeLoadBalancer,
+	})
+	if err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+
+	if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+		t.Errorf("Expected service type %s, got %s", v1.ServiceTypeLoadBalancer, service.Spec.Type)
+	}
+
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != 80 {
+		t.Errorf("Expected unchanged ports, got %v", service.Spec.Ports)
+	}
+
+	service, err = baseImpl.UpdateTestService(ctx, &TestServiceConfig{
+		Name:                  "test-service",
+		ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyLocal,
+		HealthCheckNodePort:   32100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+
+	if service.Spec.HealthCheckNodePort != 32100 {
+		t.Errorf("Expected health check node port 32100, got %d", service.Spec.HealthCheckNodePort)
+	}
+
+	service, err = baseImpl.UpdateTestService(ctx, &TestServiceConfig{
+		Name:                  "test-service",
+		ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyCluster,
+	})
+	if err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+
+	if service.Spec.HealthCheckNodePort != 0 {
+		t.Errorf("Expected health check node port to be released, got %d", service.Spec.HealthCheckNodePort)
+	}
+
+	service, err = baseImpl.UpdateTestService(ctx, &TestServiceConfig{
+		Name:        "test-service",
+		Annotations: map[string]string{"internal": "true", "owner": "e2e"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+	service, err = baseImpl.UpdateTestService(ctx, &TestServiceConfig{
+		Name:              "test-service",
+		RemoveAnnotations: []string{"internal", "missing"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update service: %v", err)
+	}
+
+	if len(service.Annotations) != 1 || service.Annotations["owner"] != "e2e" {
+		t.Errorf("Expected only the owner annotation to remain, got %v", service.Annotations)
+	}
+
+	if len(baseImpl.CreatedResources[ResourceTypeNodes]) != 1 || len(baseImpl.CreatedResources[ResourceTypeServices]) != 1 {
+		t.Errorf("Expected updated resources to be tracked once, got %v", baseImpl.CreatedResources)
+	}
+
+	if _, err := baseImpl.UpdateTestNode(ctx, &TestNodeConfig{Name: "missing-node"}); err == nil {
+		t.Error("Expected error updating a node that was never created")
+	}
+
+	if err := baseImpl.DeleteTestService(ctx, "test-service"); err != nil {
+		t.Fatalf("Failed to delete service: %v", err)
+	}
+
+	if _, err := baseImpl.UpdateTestService(ctx, &TestServiceConfig{Name: "test-service"}); err == nil {
+		t.Error("Expected error updating a deleted service")
+	}
+}
+
+// TestBaseTestImplementationMaxLogs tests that the configured MaxLogs applies to
+// the test results and survives a state reset
+func TestBaseTestImplementationMaxLogs(t *testing.T) {
+	baseImpl := NewBaseTestImplementation(&fakecloud.Cloud{})
+
+	config := &TestConfig{
+		ProviderName:    "test-provider",
+		ClientBuilder:   &MockClientBuilder{},
+		InformerFactory: informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0),
+		MaxLogs:         5,
+	}
+	if err := baseImpl.SetupTestEnvironment(config); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	if err := baseImpl.ResetTestState(); err != nil {
+		t.Fatalf("Failed to reset test state: %v", err)
+	}
+
+	results := baseImpl.GetTestResults()
+	for i := 0; i < 10; i++ {
+		results.AddLog(fmt.Sprintf("log %d", i))
+	}
+
+	if len(results.Logs) != 5 {
+		t.Errorf("Expected 5 logs, got %d", len(results.Logs))
+	}
+
+	// The reset message and the first five logs were evicted
+	if results.TruncatedLogs != 6 {
+		t.Errorf("Expected 6 truncated logs, got %d", results.TruncatedLogs)
+	}
+}
+
+// TestBaseTestImplementationConcurrentResultsAccess tests that tearing down and
+// resetting concurrently with reading results is race free when run with -race
+func TestBaseTestImplementationConcurrentResultsAccess(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	if err := fakeImpl.SetupTestEnvironment(&TestConfig{ProviderName: "fake"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	done := make(chan struct{})
+	for _, fn := range []func(){
+		func() { _ = fakeImpl.TeardownTestEnvironment() },
+		func() { _ = fakeImpl.ResetTestState() },
+		func() { _ = fakeImpl.GetTestResults().ReportLogs() },
+	} {
+		go func(fn func()) {
+			defer func() { done <- struct{}{} }()
+			for i := 0; i < 50; i++ {
+				fn()
+			}
+		}(fn)
+	}
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+}
+
+// The base and fake implementations implement the TestInterface
+var (
+	_ TestInterface = (*BaseTestImplementation)(nil)
+	_ TestInterface = (*FakeTestImplementation)(nil)
+)
+
+// TestBaseTestImplementationGetConfig tests that GetConfig returns the config
+// passed to SetupTestEnvironment
+func TestBaseTestImplementationGetConfig(t *testing.T) {
+	var impl TestInterface = NewBaseTestImplementation(&fakecloud.Cloud{})
+	config := &TestConfig{ProviderName: "fake", ClusterName: "prod-east"}
+	if err := impl.SetupTestEnvironment(config); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := impl.GetConfig(); got != config {
+		t.Errorf("Expected the config passed to SetupTestEnvironment, got %+v", got)
 	}
 }
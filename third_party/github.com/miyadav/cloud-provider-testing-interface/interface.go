@@ -18,12 +18,22 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/informers"
 	cloudprovider "k8s.io/cloud-provider"
 )
@@ -48,6 +58,11 @@ type TestInterface interface {
 	// The node should be created in a way that simulates a real node in the cloud provider.
 	CreateTestNode(ctx context.Context, nodeConfig *TestNodeConfig) (*v1.Node, error)
 
+	// UpdateTestNode updates the labels, annotations, addresses and taints of an
+	// existing test node in place, so the node keeps the identity controllers
+	// key on.
+	UpdateTestNode(ctx context.Context, nodeConfig *TestNodeConfig) (*v1.Node, error)
+
 	// DeleteTestNode deletes a test node.
 	DeleteTestNode(ctx context.Context, nodeName string) error
 
@@ -55,6 +70,10 @@ type TestInterface interface {
 	// The service should be created in a way that simulates a real service in the cloud provider.
 	CreateTestService(ctx context.Context, serviceConfig *TestServiceConfig) (*v1.Service, error)
 
+	// UpdateTestService updates the labels, annotations, type and ports of an
+	// existing test service in place.
+	UpdateTestService(ctx context.Context, serviceConfig *TestServiceConfig) (*v1.Service, error)
+
 	// DeleteTestService deletes a test service.
 	DeleteTestService(ctx context.Context, serviceName string) error
 
@@ -66,11 +85,27 @@ type TestInterface interface {
 
 	WaitForCondition(ctx context.Context, condition TestCondition) error
 
+	// WaitForTaintRemoved waits until the node no longer carries a taint with
+	// the given key, such as UninitializedTaintKey, which the cloud node
+	// controller removes once it has initialized the node. It returns an
+	// error if the taint is still present after timeout or the node is gone.
+	WaitForTaintRemoved(ctx context.Context, nodeName, taintKey string, timeout time.Duration) error
+
 	// GetTestResults returns the results of the test execution.
 	GetTestResults() *TestResults
 
+	// GetConfig returns the configuration the test environment was set up
+	// with, such as the ClusterName tests pass to the cloud provider. It may
+	// be nil before SetupTestEnvironment.
+	GetConfig() *TestConfig
+
 	// ResetTestState resets the test state to a clean state.
 	ResetTestState() error
+
+	// DumpResources writes every tracked test node, service and route to w as
+	// a stream of YAML documents, so failed tests can be debugged from the
+	// objects they actually created.
+	DumpResources(ctx context.Context, w io.Writer) error
 }
 
 // TestConfig holds the configuration for a test environment.
@@ -93,9 +128,29 @@ type TestConfig struct {
 	// InformerFactory is the informer factory for creating informers.
 	InformerFactory informers.SharedInformerFactory
 
+	// InformerSyncTimeout bounds how long setup waits for the informers of
+	// the InformerFactory to sync. Zero means the implementation's default.
+	InformerSyncTimeout time.Duration
+
+	// ResyncPeriod is the resync period of the informer factory an
+	// implementation creates when InformerFactory is nil, so that informers
+	// periodically re-deliver every object as unchanged updates. Zero
+	// disables resyncs.
+	ResyncPeriod time.Duration
+
 	// TestTimeout is the timeout for test operations.
 	TestTimeout time.Duration
 
+	// TeardownTimeout bounds how long teardown waits for the test
+	// environment, such as the test namespace, to be deleted before failing.
+	// Zero means the implementation's default.
+	TeardownTimeout time.Duration
+
+	// LoadBalancerSettleTime makes waits for a load balancer poll its status
+	// until it has been unchanged for this long, for providers that fill in
+	// ingress points incrementally. Zero accepts the first status returned.
+	LoadBalancerSettleTime time.Duration
+
 	// CleanupResources determines whether to clean up resources after tests.
 	CleanupResources bool
 
@@ -104,6 +159,99 @@ type TestConfig struct {
 
 	// TestData contains additional test-specific configuration.
 	TestData map[string]interface{}
+
+	// NamePrefix is prepended to the name of every node, service and route
+	// created through the TestInterface, so concurrent runs sharing a
+	// cluster do not collide.
+	NamePrefix string
+
+	// UseExistingNodes makes node-related tests operate on the nodes already
+	// present in the cluster instead of creating test nodes.
+	UseExistingNodes bool
+
+	// ExpectedNodeAddressTypes are the node address types the provider must
+	// report, no more and no fewer. Empty only requires an InternalIP, since
+	// providers differ in whether they report ExternalIPs, host names or DNS
+	// names.
+	ExpectedNodeAddressTypes []v1.NodeAddressType
+
+	// ExpectRegion and ExpectZone make the zone tests require the provider
+	// to report a non-empty region and failure domain. Single-region clouds
+	// may legitimately leave the region empty.
+	ExpectRegion bool
+	ExpectZone   bool
+
+	// AllowedRegions and AllowedZones, if set, are the only regions and
+	// failure domains the provider may report. Empty values are only
+	// rejected by ExpectRegion and ExpectZone.
+	AllowedRegions []string
+	AllowedZones   []string
+
+	// ProviderIDScheme is the scheme node provider IDs must use, as in
+	// "<scheme>://<id>". Empty accepts any scheme, checking only the form.
+	ProviderIDScheme string
+
+	// MaxLogs caps the number of log entries retained in the TestResults.
+	// Zero means unlimited.
+	MaxLogs int
+
+	// StrictValidation makes implementations backed by a fake clientset,
+	// which accepts any object, reject nodes and services that a real API
+	// server would refuse with the same validation error.
+	StrictValidation bool
+
+	// VerifyCleanup makes teardown check that every resource counted in
+	// TestResults.ResourceCounts was deleted, returning an error that lists
+	// any resource type with an outstanding count.
+	VerifyCleanup bool
+
+	// KeepOnFailure keeps the resources created by a failed test for
+	// inspection: the test's Cleanup is skipped, the resources are left out
+	// of teardown and their names are printed. It requires a test interface
+	// that implements ResourceTracker.
+	KeepOnFailure bool
+
+	// StrictWarnings treats warnings as errors: if any warning was recorded
+	// in the TestResults of the test interface, the run fails at its end
+	// with a report of every warning, even if all tests passed.
+	StrictWarnings bool
+
+	// ProviderBackedRoutes makes implementations that manage routes through
+	// a cloud provider create and delete test routes through its Routes, so
+	// that they exist in the cloud and not only in the tracking of the test
+	// interface. By default routes are only tracked.
+	ProviderBackedRoutes bool
+}
+
+// ResourceName returns the name a test resource is created and deleted under.
+// The NamePrefix is prepended unless the name already carries it, so passing
+// either the requested name or the name of a created object resolves to the
+// same resource.
+func (c *TestConfig) ResourceName(name string) string {
+	if c == nil || c.NamePrefix == "" || strings.HasPrefix(name, c.NamePrefix) {
+		return name
+	}
+	return c.NamePrefix + name
+}
+
+// resourceNameSeq numbers the names returned by GenerateResourceName.
+var resourceNameSeq atomic.Uint64
+
+// GenerateResourceName returns a new name for a test resource built from base,
+// a sequence number and a short random suffix, with the NamePrefix applied.
+// The sequence number keeps names unique within the process and the suffix
+// keeps separate runs sharing a cluster apart.
+func (c *TestConfig) GenerateResourceName(base string) string {
+	return c.ResourceName(fmt.Sprintf("%s-%d-%s", base, resourceNameSeq.Add(1), utilrand.String(5)))
+}
+
+// ResourceNameOrGenerate returns ResourceName(name), or a name generated from
+// base by GenerateResourceName when name is empty.
+func (c *TestConfig) ResourceNameOrGenerate(name, base string) string {
+	if name == "" {
+		return c.GenerateResourceName(base)
+	}
+	return c.ResourceName(name)
 }
 
 // TestNodeConfig holds the configuration for creating a test node.
@@ -134,6 +282,38 @@ type TestNodeConfig struct {
 
 	// Conditions are the conditions of the node.
 	Conditions []v1.NodeCondition
+
+	// Taints are the taints of the node, such as the UninitializedTaintKey
+	// taint a kubelet with an external cloud provider registers nodes with.
+	Taints []v1.Taint
+
+	// PodCIDRs are the pod IP ranges assigned to the node, the first of
+	// which is its primary range. The route controller of the CCM creates a
+	// route to the node for each of them.
+	PodCIDRs []string
+}
+
+// PrimaryPodCIDR returns the first of the PodCIDRs, or an empty string if
+// none are set.
+func (c *TestNodeConfig) PrimaryPodCIDR() string {
+	if len(c.PodCIDRs) == 0 {
+		return ""
+	}
+	return c.PodCIDRs[0]
+}
+
+// UninitializedTaintKey is the key of the taint nodes carry until the cloud
+// node controller of the CCM has initialized them.
+const UninitializedTaintKey = "node.cloudprovider.kubernetes.io/uninitialized"
+
+// NodeHasTaint reports whether node carries a taint with the given key.
+func NodeHasTaint(node *v1.Node, taintKey string) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == taintKey {
+			return true
+		}
+	}
+	return false
 }
 
 // TestServiceConfig holds the configuration for creating a test service.
@@ -156,14 +336,34 @@ type TestServiceConfig struct {
 	// ExternalTrafficPolicy is the external traffic policy.
 	ExternalTrafficPolicy v1.ServiceExternalTrafficPolicy
 
+	// HealthCheckNodePort is the node port serving the health check of a
+	// LoadBalancer service whose ExternalTrafficPolicy is Local. A real API
+	// server allocates one; set it explicitly for clientsets that do not.
+	HealthCheckNodePort int32
+
 	// InternalTrafficPolicy is the internal traffic policy.
 	InternalTrafficPolicy *v1.ServiceInternalTrafficPolicy
 
+	// LoadBalancerClass selects the load balancer implementation of a
+	// LoadBalancer service. A CCM must ignore services whose class it does
+	// not own. It is immutable, so it is only applied on creation.
+	LoadBalancerClass *string
+
+	// AllocateLoadBalancerNodePorts controls whether NodePorts are allocated
+	// for a LoadBalancer service. Setting it to false is for load balancers
+	// that target pods directly. Nil leaves the API server default (true).
+	AllocateLoadBalancerNodePorts *bool
+
 	// Labels are the labels to be applied to the service.
 	Labels map[string]string
 
 	// Annotations are the annotations to be applied to the service.
 	Annotations map[string]string
+
+	// RemoveAnnotations are the keys of annotations an update removes from
+	// the service, since Annotations can only add or change them. They are
+	// removed after Annotations are applied and ignored on creation.
+	RemoveAnnotations []string
 }
 
 // TestRouteConfig holds the configuration for creating a test route.
@@ -205,6 +405,15 @@ type TestCondition struct {
 	CheckFunction func() (bool, error)
 }
 
+// Resource types under which implementations count the resources they
+// create in TestResults.ResourceCounts. Test.ExpectedResourceCounts is keyed
+// by the same types, so that it means the same for every implementation.
+const (
+	ResourceTypeNodes    = "nodes"
+	ResourceTypeServices = "services"
+	ResourceTypeRoutes   = "routes"
+)
+
 // TestResults holds the results of a test execution.
 type TestResults struct {
 	// Success indicates whether the test was successful.
@@ -216,7 +425,9 @@ type TestResults struct {
 	// Duration is the duration of the test.
 	Duration time.Duration
 
-	// ResourceCounts contains counts of resources created during the test.
+	// ResourceCounts contains counts of resources created during the test
+	// that have not been deleted yet, keyed by resource type, such as
+	// ResourceTypeNodes.
 	ResourceCounts map[string]int
 
 	// Metrics contains test-specific metrics.
@@ -225,15 +436,45 @@ type TestResults struct {
 	// Logs contains test logs.
 	Logs []string
 
+	// MaxLogs is the maximum number of entries kept in Logs. Once it is
+	// exceeded the oldest entries are evicted. Zero means unlimited.
+	MaxLogs int
+
+	// TruncatedLogs counts the entries evicted from Logs because of MaxLogs.
+	TruncatedLogs int
+
+	// Warnings contains the warnings reported during the run. Unlike Logs,
+	// they are never evicted, so that StrictWarnings can report all of them.
+	Warnings []string
+
 	// mu protects access to the TestResults fields
 	mu sync.RWMutex
 }
 
-// AddLog adds a log entry to the test results.
+// AddLog adds a log entry to the test results, evicting the oldest entry if
+// MaxLogs would be exceeded.
 func (tr *TestResults) AddLog(log string) {
 	tr.mu.Lock()
 	defer tr.mu.Unlock()
 	tr.Logs = append(tr.Logs, log)
+	if tr.MaxLogs > 0 && len(tr.Logs) > tr.MaxLogs {
+		evicted := len(tr.Logs) - tr.MaxLogs
+		tr.Logs = tr.Logs[evicted:]
+		tr.TruncatedLogs += evicted
+	}
+}
+
+// ReportLogs returns the retained log entries for reporting, preceded by a
+// note of how many earlier entries were truncated, if any.
+func (tr *TestResults) ReportLogs() []string {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	logs := make([]string, 0, len(tr.Logs)+1)
+	if tr.TruncatedLogs > 0 {
+		logs = append(logs, fmt.Sprintf("(%d earlier logs truncated)", tr.TruncatedLogs))
+	}
+	return append(logs, tr.Logs...)
 }
 
 // SetMetric sets a metric in the test results.
@@ -256,6 +497,99 @@ func (tr *TestResults) IncrementResourceCount(resourceType string) {
 	tr.ResourceCounts[resourceType]++
 }
 
+// DecrementResourceCount decrements the count for a resource type when a
+// resource of that type is deleted. The count never drops below zero.
+func (tr *TestResults) DecrementResourceCount(resourceType string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.ResourceCounts[resourceType] > 0 {
+		tr.ResourceCounts[resourceType]--
+	}
+}
+
+// ErrResourcesNotCleanedUp is returned by a teardown with VerifyCleanup set
+// when resources counted in ResourceCounts were never deleted.
+var ErrResourcesNotCleanedUp = errors.New("resources not cleaned up")
+
+// CheckCleanup returns an error wrapping ErrResourcesNotCleanedUp that lists
+// every resource type with a non-zero count, or nil if all were deleted.
+func (tr *TestResults) CheckCleanup() error {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	var outstanding []string
+	for resourceType, count := range tr.ResourceCounts {
+		if count != 0 {
+			outstanding = append(outstanding, fmt.Sprintf("%d %s", count, resourceType))
+		}
+	}
+	if len(outstanding) == 0 {
+		return nil
+	}
+	sort.Strings(outstanding)
+	return fmt.Errorf("%w: %s", ErrResourcesNotCleanedUp, strings.Join(outstanding, ", "))
+}
+
+// AddWarning records a warning, which is also added to the logs. With
+// TestConfig.StrictWarnings set, any recorded warning fails the run.
+func (tr *TestResults) AddWarning(warning string) {
+	tr.AddLog("Warning: " + warning)
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.Warnings = append(tr.Warnings, warning)
+}
+
+// GetWarnings returns a copy of the recorded warnings.
+func (tr *TestResults) GetWarnings() []string {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return append([]string(nil), tr.Warnings...)
+}
+
+// merge adds the logs and warnings of other to the test results.
+func (tr *TestResults) merge(other *TestResults) {
+	if other == nil || other == tr {
+		return
+	}
+	logs := other.ReportLogs()
+	warnings := other.GetWarnings()
+
+	for _, log := range logs {
+		tr.AddLog(log)
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.Warnings = append(tr.Warnings, warnings...)
+}
+
+// ErrWarningsReported is returned at the end of a run with StrictWarnings set
+// when warnings were recorded in the TestResults.
+var ErrWarningsReported = errors.New("warnings reported")
+
+// CheckWarnings returns an error wrapping ErrWarningsReported that lists every
+// recorded warning, or nil if there were none.
+func (tr *TestResults) CheckWarnings() error {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	if len(tr.Warnings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w (%d):\n  %s", ErrWarningsReported, len(tr.Warnings), strings.Join(tr.Warnings, "\n  "))
+}
+
+// GetResourceCounts returns a copy of the resource counts.
+func (tr *TestResults) GetResourceCounts() map[string]int {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	counts := make(map[string]int, len(tr.ResourceCounts))
+	for resourceType, count := range tr.ResourceCounts {
+		counts[resourceType] = count
+	}
+	return counts
+}
+
 // TestSuite defines a collection of tests that can be run against a cloud provider.
 type TestSuite struct {
 	// Name is the name of the test suite.
@@ -275,6 +609,16 @@ type TestSuite struct {
 
 	// Dependencies are the dependencies required for the test suite.
 	Dependencies []string
+
+	// SuiteTimeout bounds the total time spent running the suite's tests.
+	// Tests not finished when it expires are recorded as failed. Zero means
+	// no limit.
+	SuiteTimeout time.Duration
+
+	// RequiredCapabilities are the capabilities, such as "loadbalancer", the
+	// provider must declare in TestRunner.SupportedCapabilities for the
+	// suite to run.
+	RequiredCapabilities []string
 }
 
 // Test defines a single test that can be run against a cloud provider.
@@ -297,11 +641,38 @@ type Test struct {
 	// Timeout is the timeout for the test.
 	Timeout time.Duration
 
-	// Dependencies are the dependencies required for the test.
+	// Dependencies are the dependencies required for the test. Names of
+	// other tests in the same suite are guaranteed to run before this test
+	// when the test order is randomized.
 	Dependencies []string
 
 	// Cleanup is the cleanup function for the test.
 	Cleanup func(TestInterface) error
+
+	// ExpectedResourceCounts, if set, is the net number of resources of each
+	// type, such as ResourceTypeServices, as counted in
+	// TestResults.ResourceCounts, that the test must
+	// create; resources it deletes again do not count. A test that passes
+	// but creates a different number fails.
+	ExpectedResourceCounts map[string]int
+
+	// Labels opt the test into optional run modes, such as deep
+	// conformance. A test with labels only runs when the runner enables at
+	// least one of them, and is reported as skipped otherwise.
+	Labels []string
+
+	// Priority orders the tests of a suite so that the most important ones
+	// run first when a deadline may cut the run short. Tests with a higher
+	// priority run before those with a lower one, and tests of equal
+	// priority keep their declared order. A test still runs after the tests
+	// of the same suite named in its Dependencies, which run with the
+	// priority of the test depending on them if that is higher.
+	Priority int
+
+	// suiteIndex and index are the positions of the test's suite among the
+	// runner's suites and of the test within it, stamped by AddTestSuite
+	suiteIndex int
+	index      int
 }
 
 // TestRunner is responsible for running tests against cloud providers.
@@ -315,6 +686,60 @@ type TestRunner struct {
 	// Results are the results of the test execution.
 	Results []TestResult
 
+	// FailFast stops the run at the first failing test. By default every
+	// remaining test and suite still runs after a test fails.
+	FailFast bool
+
+	// Randomize shuffles the order of the tests within each suite to surface
+	// hidden coupling between tests. A test still runs after every test in
+	// the same suite named in its Dependencies.
+	Randomize bool
+
+	// Seed seeds the shuffle when Randomize is set, so that an order which
+	// exposed a failure can be reproduced.
+	Seed int64
+
+	// DumpDir, if set, is the directory the resources of the test interface
+	// are dumped to as YAML whenever a test fails, one file per failed test.
+	DumpDir string
+
+	// EnabledLabels are the test labels enabled for this run. Tests carrying
+	// Labels only run if one of them is enabled.
+	EnabledLabels []string
+
+	// SupportedCapabilities are the capabilities the provider declares it
+	// supports. Suites whose RequiredCapabilities are not all listed are
+	// skipped. Nil means the provider declared nothing and every suite runs.
+	SupportedCapabilities []string
+
+	// BeforeAll, if set, runs once before the first suite, for expensive
+	// setup shared by every suite such as creating a network. If it fails no
+	// suite runs.
+	BeforeAll func(context.Context, TestInterface) error
+
+	// AfterAll, if set, runs once after the last suite, even if tests or
+	// BeforeAll failed or the run was cancelled. It gets a context that is
+	// not cancelled with the run's, so that it can still clean up.
+	AfterAll func(context.Context, TestInterface) error
+
+	// OnTestStart, if set, is called with the suite name before each test
+	// that is not skipped starts, to report the progress of long runs. The
+	// runner is locked while it runs, so it must not call the runner.
+	OnTestStart func(suiteName string, test Test)
+
+	// OnTestFinish, if set, is called with the result of every test,
+	// including skipped ones, as soon as it is recorded. Like OnTestStart it
+	// must not call the runner.
+	OnTestFinish func(result TestResult)
+
+	// TimeoutGracePeriod is how long a test that overran its deadline is
+	// given to return before the run moves on without it. Zero uses
+	// DefaultTimeoutGracePeriod; a negative value does not wait.
+	TimeoutGracePeriod time.Duration
+
+	// rng is the source of the shuffle, created from Seed on first use
+	rng *rand.Rand
+
 	// mu protects access to the TestRunner fields
 	mu sync.RWMutex
 }
@@ -324,22 +749,68 @@ type TestResult struct {
 	// Test is the test that was run.
 	Test Test
 
+	// Suite is the name of the suite the test belongs to.
+	Suite string
+
 	// Success indicates whether the test was successful.
 	Success bool
 
 	// Error is the error that occurred during the test.
 	Error error
 
-	// Duration is the duration of the test.
+	// Duration is the duration of the test, the sum of SetupDuration,
+	// RunDuration and CleanupDuration.
 	Duration time.Duration
 
+	// SetupDuration is the time spent preparing the test before its Run
+	// function started, such as snapshotting resource counts.
+	SetupDuration time.Duration
+
+	// RunDuration is the time spent in the test's Run function and in
+	// checking its ExpectedResourceCounts.
+	RunDuration time.Duration
+
+	// CleanupDuration is the time spent after the test body, dumping the
+	// resources of a failed test and running its Cleanup function.
+	CleanupDuration time.Duration
+
 	// StartTime is the start time of the test.
 	StartTime time.Time
 
 	// EndTime is the end time of the test.
 	EndTime time.Time
+
+	// TimedOut reports whether the test failed because a deadline passed
+	// while it was running.
+	TimedOut bool
+
+	// TimeoutSource tells which deadline a timed out test hit:
+	// TimeoutSourceTest for its own Timeout or TimeoutSourceRun for the
+	// deadline of the whole run. It is empty unless TimedOut is set.
+	TimeoutSource string
+
+	// SuiteIndex is the position of the test's suite in the order the
+	// suites were added to the runner.
+	SuiteIndex int
+
+	// TestIndex is the position of the test in its suite as declared, which
+	// differs from the run order when tests are randomized.
+	TestIndex int
 }
 
+// Timeout sources reported in TestResult.TimeoutSource.
+const (
+	// TimeoutSourceTest is reported when the test's own Timeout passed.
+	TimeoutSourceTest = "test"
+
+	// TimeoutSourceRun is reported when the deadline of the context the run
+	// was started with passed first.
+	TimeoutSourceRun = "run"
+)
+
+// DefaultTimeoutGracePeriod is the TimeoutGracePeriod used when none is set.
+const DefaultTimeoutGracePeriod = 2 * time.Second
+
 // NewTestRunner creates a new test runner.
 func NewTestRunner(testInterface TestInterface) *TestRunner {
 	return &TestRunner{
@@ -349,29 +820,432 @@ func NewTestRunner(testInterface TestInterface) *TestRunner {
 	}
 }
 
-// AddTestSuite adds a test suite to the test runner.
+// AddTestSuite adds a test suite to the test runner. The suite is not
+// validated; use AddTestSuiteChecked to reject malformed suites up front.
 func (tr *TestRunner) AddTestSuite(suite TestSuite) {
 	tr.mu.Lock()
 	defer tr.mu.Unlock()
+
+	// Stamp the declaration order on a copy of the tests, so that results
+	// can be sorted back into it
+	suite.Tests = append([]Test(nil), suite.Tests...)
+	for i := range suite.Tests {
+		suite.Tests[i].suiteIndex = len(tr.TestSuites)
+		suite.Tests[i].index = i
+	}
 	tr.TestSuites = append(tr.TestSuites, suite)
 }
 
-// RunTests runs all the tests in the test runner.
+// AddTestSuiteChecked validates a test suite and adds it to the test runner.
+// A suite that fails validation is not added and the returned error wraps
+// ErrInvalidTestSuite.
+func (tr *TestRunner) AddTestSuiteChecked(suite TestSuite) error {
+	if err := suite.Validate(); err != nil {
+		return err
+	}
+
+	tr.AddTestSuite(suite)
+	return nil
+}
+
+// ErrInvalidTestSuite is returned by TestSuite.Validate and
+// AddTestSuiteChecked for a suite that would fail at run time.
+var ErrInvalidTestSuite = errors.New("invalid test suite")
+
+// Validate checks that every test in the suite has a non-empty name unique
+// within the suite, a positive timeout and, unless it is skipped, a Run
+// function. All problems found are reported in the returned error.
+func (s TestSuite) Validate() error {
+	var problems []error
+	seen := make(map[string]bool)
+
+	for i, test := range s.Tests {
+		name := test.Name
+		if name == "" {
+			problems = append(problems, fmt.Errorf("test %d has an empty name", i))
+			name = fmt.Sprintf("#%d", i)
+		} else if seen[name] {
+			problems = append(problems, fmt.Errorf("duplicate test name %s", name))
+		}
+		seen[name] = true
+
+		if test.Run == nil && !test.Skip {
+			problems = append(problems, fmt.Errorf("test %s has no Run function", name))
+		}
+
+		if test.Timeout <= 0 {
+			problems = append(problems, fmt.Errorf("test %s has non-positive timeout %v", name, test.Timeout))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w %s: %w", ErrInvalidTestSuite, s.Name, errors.Join(problems...))
+	}
+
+	return nil
+}
+
+// ErrRunCancelled is returned by RunTests when the run's context is cancelled
+// or its deadline expires before every test has run. Results completed before
+// the cancellation remain available through GetResults.
+var ErrRunCancelled = errors.New("test run cancelled")
+
+// ErrTestsFailed is returned by RunTests when every suite ran but one or more
+// tests failed. The individual failures are available through GetResults.
+var ErrTestsFailed = errors.New("tests failed")
+
+// TestFailuresError is the error RunTests returns when tests failed. It wraps
+// ErrTestsFailed as well as the error of every failed test, and its message
+// lists each failure by suite, so that callers can report every failure
+// reason rather than only the first.
+type TestFailuresError struct {
+	// Failures are the results of the failed tests, in the order they ran.
+	Failures []TestResult
+
+	// TotalTests is the number of tests in the run.
+	TotalTests int
+}
+
+// Error lists the failed tests grouped by suite, in the order the suites first
+// failed.
+func (e *TestFailuresError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %d of %d tests failed", ErrTestsFailed, len(e.Failures), e.TotalTests)
+	for _, suite := range e.Suites() {
+		fmt.Fprintf(&b, "\n  %s:", suite)
+		for _, failure := range e.SuiteFailures(suite) {
+			message := "test failed"
+			if failure.Error != nil {
+				message = failure.Error.Error()
+			}
+			fmt.Fprintf(&b, "\n    %s: %s", failure.Test.Name, message)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns ErrTestsFailed followed by the errors of the failed tests.
+func (e *TestFailuresError) Unwrap() []error {
+	errs := []error{ErrTestsFailed}
+	for _, failure := range e.Failures {
+		if failure.Error != nil {
+			errs = append(errs, failure.Error)
+		}
+	}
+	return errs
+}
+
+// Suites returns the names of the suites with failed tests, in the order they
+// first failed.
+func (e *TestFailuresError) Suites() []string {
+	var suites []string
+	for _, failure := range e.Failures {
+		if !slices.Contains(suites, failure.Suite) {
+			suites = append(suites, failure.Suite)
+		}
+	}
+	return suites
+}
+
+// SuiteFailures returns the results of the failed tests of the named suite.
+func (e *TestFailuresError) SuiteFailures(suite string) []TestResult {
+	var failures []TestResult
+	for _, failure := range e.Failures {
+		if failure.Suite == suite {
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// testFailures returns a TestFailuresError for the failed tests among
+// results, or nil if none failed.
+func testFailures(results []TestResult) error {
+	var failures []TestResult
+	for _, result := range results {
+		if !result.Test.Skip && !result.Success {
+			failures = append(failures, result)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &TestFailuresError{Failures: failures, TotalTests: len(results)}
+}
+
+// RunTests runs all the tests in the test runner. A failing test is recorded
+// and the run continues; once every suite has run, RunTests returns a
+// TestFailuresError listing every failed test if any test failed. Errors from
+// the harness itself, such as a suite setup failure, abort the run
+// immediately. BeforeAll and AfterAll run once around the suites.
 func (tr *TestRunner) RunTests(ctx context.Context) error {
 	tr.mu.Lock()
 	defer tr.mu.Unlock()
 
+	return tr.runWithGlobalHooks(ctx, func() error {
+		return tr.runTestSuites(ctx)
+	})
+}
+
+// runTestSuites runs every suite once. The caller must hold tr.mu.
+func (tr *TestRunner) runTestSuites(ctx context.Context) error {
 	for _, suite := range tr.TestSuites {
+		if err := runCancelled(ctx); err != nil {
+			return err
+		}
+
 		if err := tr.runTestSuite(ctx, suite); err != nil {
+			if errors.Is(err, ErrRunCancelled) {
+				return err
+			}
 			return fmt.Errorf("failed to run test suite %s: %w", suite.Name, err)
 		}
+
+		if tr.FailFast && summarizeResults(tr.Results).FailedTests > 0 {
+			break
+		}
+	}
+
+	return testFailures(tr.Results)
+}
+
+// runWithGlobalHooks calls run between BeforeAll and AfterAll. AfterAll runs
+// even if BeforeAll or run fail, and its error is joined with theirs. The
+// caller must hold tr.mu.
+func (tr *TestRunner) runWithGlobalHooks(ctx context.Context, run func() error) error {
+	var err error
+	if tr.BeforeAll != nil {
+		if beforeErr := tr.BeforeAll(ctx, tr.TestInterface); beforeErr != nil {
+			err = fmt.Errorf("before-all hook failed: %w", beforeErr)
+		}
+	}
+
+	if err == nil {
+		err = run()
+	}
+
+	if tr.AfterAll != nil {
+		if afterErr := tr.AfterAll(context.WithoutCancel(ctx), tr.TestInterface); afterErr != nil {
+			err = errors.Join(err, fmt.Errorf("after-all hook failed: %w", afterErr))
+		}
+	}
+
+	if warnErr := tr.checkStrictWarnings(); warnErr != nil {
+		err = errors.Join(err, warnErr)
+	}
+
+	return err
+}
+
+// checkStrictWarnings returns the aggregated warnings of the run if the
+// configuration of the test interface sets StrictWarnings.
+func (tr *TestRunner) checkStrictWarnings() error {
+	if tr.TestInterface == nil {
+		return nil
+	}
+	if config := tr.TestInterface.GetConfig(); config == nil || !config.StrictWarnings {
+		return nil
+	}
+	results := tr.TestInterface.GetTestResults()
+	if results == nil {
+		return nil
+	}
+	return results.CheckWarnings()
+}
+
+// RunTestsRepeated runs all the tests n times to expose flaky behaviour,
+// resetting the test state through ResetTestState between iterations. Results
+// from every iteration are aggregated in GetResults and GetFlakeRates reports
+// how often each test passed. As with RunTests, test failures do not stop the
+// repetitions unless FailFast is set, and the returned error wraps
+// ErrTestsFailed if any test failed in any iteration. BeforeAll and AfterAll
+// run once around all the iterations.
+func (tr *TestRunner) RunTestsRepeated(ctx context.Context, n int) error {
+	if n < 1 {
+		return fmt.Errorf("repeat count must be at least 1, got %d", n)
 	}
 
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	return tr.runWithGlobalHooks(ctx, func() error {
+		var runErr error
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				if err := tr.TestInterface.ResetTestState(); err != nil {
+					return fmt.Errorf("failed to reset test state before iteration %d: %w", i+1, err)
+				}
+			}
+
+			runErr = tr.runTestSuites(ctx)
+			if runErr != nil && !errors.Is(runErr, ErrTestsFailed) {
+				return fmt.Errorf("iteration %d of %d: %w", i+1, n, runErr)
+			}
+
+			if runErr != nil && tr.FailFast {
+				break
+			}
+		}
+
+		return runErr
+	})
+}
+
+// TestInterfaceCloner is implemented by test interfaces that can create
+// independent copies of themselves, which RunTestsConcurrent requires so that
+// suites running at the same time do not share test state.
+type TestInterfaceCloner interface {
+	// CloneTestInterface returns a new test interface with fresh test state,
+	// set up with the same configuration. Its cloud provider must not share
+	// state with the original's unless the provider is safe for concurrent
+	// use by independent tests. The caller tears the clone down.
+	CloneTestInterface() (TestInterface, error)
+}
+
+// ErrConcurrencyUnsupported is returned by RunTestsConcurrent when the test
+// interface does not implement TestInterfaceCloner.
+var ErrConcurrencyUnsupported = errors.New("test interface does not support concurrent suites")
+
+// RunTestsConcurrent runs the test suites concurrently on up to workers
+// goroutines. Each suite runs against its own clone of the test interface,
+// which is torn down once the suite is done, so the interface must implement
+// TestInterfaceCloner. The tests within a suite still run sequentially.
+// Results are merged as suites finish, so use GetResultsSorted for their
+// declaration order. OnTestStart and OnTestFinish may be called concurrently.
+// BeforeAll and AfterAll run once on the original test interface, and with
+// FailFast no new suite starts after a test failed.
+func (tr *TestRunner) RunTestsConcurrent(ctx context.Context, workers int) error {
+	if workers < 1 {
+		return fmt.Errorf("worker count must be at least 1, got %d", workers)
+	}
+	cloner, ok := tr.TestInterface.(TestInterfaceCloner)
+	if !ok {
+		return ErrConcurrencyUnsupported
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	return tr.runWithGlobalHooks(ctx, func() error {
+		return tr.runTestSuitesConcurrent(ctx, cloner, workers)
+	})
+}
+
+// runTestSuitesConcurrent runs every suite once on a pool of workers. The
+// caller must hold tr.mu.
+func (tr *TestRunner) runTestSuitesConcurrent(ctx context.Context, cloner TestInterfaceCloner, workers int) error {
+	suites := make(chan TestSuite)
+	var (
+		wg      sync.WaitGroup
+		mergeMu sync.Mutex
+		errs    []error
+		failed  atomic.Bool
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for suite := range suites {
+				results, err := tr.runIsolatedTestSuite(ctx, cloner, suite)
+
+				mergeMu.Lock()
+				tr.Results = append(tr.Results, results...)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mergeMu.Unlock()
+
+				if summarizeResults(results).FailedTests > 0 {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+
+	for _, suite := range tr.TestSuites {
+		if runCancelled(ctx) != nil || (tr.FailFast && failed.Load()) {
+			break
+		}
+		suites <- suite
+	}
+	close(suites)
+	wg.Wait()
+
+	for _, err := range errs {
+		if errors.Is(err, ErrRunCancelled) {
+			return err
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if err := runCancelled(ctx); err != nil {
+		return err
+	}
+
+	return testFailures(tr.Results)
+}
+
+// runIsolatedTestSuite runs a suite against a clone of the test interface on
+// a runner of its own, returning the suite's results. The logs and warnings
+// of the clone are added to the original test interface's results.
+func (tr *TestRunner) runIsolatedTestSuite(ctx context.Context, cloner TestInterfaceCloner, suite TestSuite) ([]TestResult, error) {
+	isolated, err := cloner.CloneTestInterface()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone test interface for suite %s: %w", suite.Name, err)
+	}
+
+	runner := &TestRunner{
+		TestInterface:         isolated,
+		FailFast:              tr.FailFast,
+		Randomize:             tr.Randomize,
+		Seed:                  tr.Seed,
+		DumpDir:               tr.DumpDir,
+		EnabledLabels:         tr.EnabledLabels,
+		SupportedCapabilities: tr.SupportedCapabilities,
+		OnTestStart:           tr.OnTestStart,
+		OnTestFinish:          tr.OnTestFinish,
+	}
+	if err = runner.runTestSuite(ctx, suite); err != nil && !errors.Is(err, ErrRunCancelled) {
+		err = fmt.Errorf("failed to run test suite %s: %w", suite.Name, err)
+	}
+
+	if teardownErr := isolated.TeardownTestEnvironment(); teardownErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to tear down test interface of suite %s: %w", suite.Name, teardownErr))
+	}
+	if results := tr.TestInterface.GetTestResults(); results != nil {
+		results.merge(isolated.GetTestResults())
+	}
+
+	return runner.Results, err
+}
+
+// runCancelled returns an error wrapping ErrRunCancelled if ctx is done.
+func runCancelled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrRunCancelled, err)
+	}
 	return nil
 }
 
-// runTestSuite runs a single test suite.
+// runTestSuite runs a single test suite. Test failures are recorded in the
+// results; the returned error reports only problems running the suite itself.
 func (tr *TestRunner) runTestSuite(ctx context.Context, suite TestSuite) error {
+	if missing := tr.missingCapabilities(suite); len(missing) > 0 {
+		reason := fmt.Sprintf("provider does not declare the capabilities %s", strings.Join(missing, ", "))
+		for _, test := range suite.Tests {
+			test.Skip = true
+			test.SkipReason = reason
+			tr.recordResult(TestResult{
+				Test:    test,
+				Suite:   suite.Name,
+				Success: true,
+			})
+		}
+		return nil
+	}
+
 	// Run suite setup
 	if suite.Setup != nil {
 		if err := suite.Setup(tr.TestInterface); err != nil {
@@ -379,11 +1253,43 @@ func (tr *TestRunner) runTestSuite(ctx context.Context, suite TestSuite) error {
 		}
 	}
 
-	// Run tests in the suite
-	for _, test := range suite.Tests {
-		if err := tr.runTest(ctx, test); err != nil {
+	suiteCtx := ctx
+	if suite.SuiteTimeout > 0 {
+		var cancel context.CancelFunc
+		suiteCtx, cancel = context.WithTimeout(ctx, suite.SuiteTimeout)
+		defer cancel()
+	}
+
+	tests := suite.Tests
+	if tr.Randomize {
+		if tr.rng == nil {
+			tr.rng = rand.New(rand.NewSource(tr.Seed))
+		}
+		tests = orderTests(tests, tr.rng)
+	} else if slices.ContainsFunc(tests, func(test Test) bool { return test.Priority != 0 }) {
+		tests = orderTests(tests, nil)
+	}
+
+	// Run tests in the suite, stopping early if the run is cancelled
+	var cancelErr error
+	for _, test := range tests {
+		if cancelErr = runCancelled(ctx); cancelErr != nil {
+			break
+		}
+		if suiteCtx.Err() != nil {
+			tr.recordResult(TestResult{
+				Test:  test,
+				Suite: suite.Name,
+				Error: fmt.Errorf("suite %s exceeded its %v timeout", suite.Name, suite.SuiteTimeout),
+			})
+			continue
+		}
+		if err := tr.runTest(suiteCtx, suite.Name, test); err != nil {
 			return fmt.Errorf("failed to run test %s in suite %s: %w", test.Name, suite.Name, err)
 		}
+		if tr.FailFast && !tr.Results[len(tr.Results)-1].Success {
+			break
+		}
 	}
 
 	// Run suite teardown
@@ -393,60 +1299,451 @@ func (tr *TestRunner) runTestSuite(ctx context.Context, suite TestSuite) error {
 		}
 	}
 
-	return nil
+	return cancelErr
+}
+
+// orderTests returns the tests in an order in which every test comes after
+// the tests of the same list named in its Dependencies, and higher priority
+// tests come first. Among the ready tests of the highest priority it picks at
+// random if rng is set, and in declared order otherwise. Dependencies on tests
+// outside the list are ignored, and tests caught in a dependency cycle keep
+// their declared order at the end.
+func orderTests(tests []Test, rng *rand.Rand) []Test {
+	names := make(map[string]bool, len(tests))
+	for _, test := range tests {
+		names[test.Name] = true
+	}
+	priorities := effectivePriorities(tests)
+
+	placed := make(map[string]bool, len(tests))
+	remaining := append([]Test(nil), tests...)
+	ordered := make([]Test, 0, len(tests))
+
+	for len(remaining) > 0 {
+		var ready []int
+		for i, test := range remaining {
+			if !dependenciesPlaced(test, names, placed) {
+				continue
+			}
+			if len(ready) > 0 && priorities[test.Name] < priorities[remaining[ready[0]].Name] {
+				continue
+			}
+			if len(ready) > 0 && priorities[test.Name] > priorities[remaining[ready[0]].Name] {
+				ready = ready[:0]
+			}
+			ready = append(ready, i)
+		}
+
+		if len(ready) == 0 {
+			return append(ordered, remaining...)
+		}
+
+		i := ready[0]
+		if rng != nil {
+			i = ready[rng.Intn(len(ready))]
+		}
+		ordered = append(ordered, remaining[i])
+		placed[remaining[i].Name] = true
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+
+	return ordered
+}
+
+// effectivePriorities returns the priority each test runs with by name: its
+// own, or that of a test depending on it, directly or not, if higher.
+func effectivePriorities(tests []Test) map[string]int {
+	priorities := make(map[string]int, len(tests))
+	for _, test := range tests {
+		priorities[test.Name] = test.Priority
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, test := range tests {
+			for _, dependency := range test.Dependencies {
+				if current, found := priorities[dependency]; found && current < priorities[test.Name] {
+					priorities[dependency] = priorities[test.Name]
+					changed = true
+				}
+			}
+		}
+	}
+	return priorities
+}
+
+// dependenciesPlaced reports whether every dependency of test that names a
+// test in the list has already been placed.
+func dependenciesPlaced(test Test, names, placed map[string]bool) bool {
+	for _, dependency := range test.Dependencies {
+		if names[dependency] && !placed[dependency] {
+			return false
+		}
+	}
+	return true
 }
 
-// runTest runs a single test.
-func (tr *TestRunner) runTest(ctx context.Context, test Test) error {
+// runTest runs a single test and records its result. A failing test is not an
+// error; the returned error is reserved for problems running the test.
+func (tr *TestRunner) runTest(ctx context.Context, suiteName string, test Test) error {
 	// Skip test if requested
 	if test.Skip {
-		tr.Results = append(tr.Results, TestResult{
+		tr.recordResult(TestResult{
 			Test:    test,
+			Suite:   suiteName,
 			Success: true, // Skipped tests are considered successful
 		})
 		return nil
 	}
 
-	// Set timeout for the test
+	if reason := tr.labelSkipReason(test); reason != "" {
+		test.Skip = true
+		test.SkipReason = reason
+		tr.recordResult(TestResult{
+			Test:    test,
+			Suite:   suiteName,
+			Success: true,
+		})
+		return nil
+	}
+
+	if tr.OnTestStart != nil {
+		tr.OnTestStart(suiteName, test)
+	}
+
+	// Set timeout for the test, remembering the run's deadline to tell which
+	// of the two a timed out test hit
+	runDeadline, hasRunDeadline := ctx.Deadline()
 	if test.Timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, test.Timeout)
 		defer cancel()
 	}
 
-	// Run the test
 	startTime := time.Now()
-	err := test.Run(tr.TestInterface)
-	endTime := time.Now()
+
+	var countsBefore map[string]int
+	if len(test.ExpectedResourceCounts) > 0 {
+		countsBefore = tr.resourceCounts()
+	}
+
+	tracker := tr.keepOnFailureTracker()
+	var trackedBefore map[string][]string
+	if tracker != nil {
+		trackedBefore = tracker.TrackedResources()
+	}
+
+	// Run the test
+	runStartTime := time.Now()
+	err := tr.runTestBody(ctx, test)
+
+	if err == nil && len(test.ExpectedResourceCounts) > 0 {
+		err = checkResourceCounts(test, countsBefore, tr.resourceCounts())
+	}
+	runEndTime := time.Now()
 
 	// Record the result
 	result := TestResult{
-		Test:      test,
-		Success:   err == nil,
-		Error:     err,
-		Duration:  endTime.Sub(startTime),
-		StartTime: startTime,
-		EndTime:   endTime,
+		Test:          test,
+		Suite:         suiteName,
+		Success:       err == nil,
+		Error:         err,
+		SetupDuration: runStartTime.Sub(startTime),
+		RunDuration:   runEndTime.Sub(runStartTime),
+		StartTime:     startTime,
 	}
 
-	tr.Results = append(tr.Results, result)
+	if errors.Is(err, context.DeadlineExceeded) {
+		result.TimedOut = true
+		result.TimeoutSource = TimeoutSourceRun
+		if testDeadline, _ := ctx.Deadline(); test.Timeout > 0 && (!hasRunDeadline || testDeadline.Before(runDeadline)) {
+			result.TimeoutSource = TimeoutSourceTest
+		}
+	}
+
+	// Tests that hit an unsupported capability are reported as skipped
+	if IsUnsupportedError(err) {
+		result.Test.Skip = true
+		result.Test.SkipReason = err.Error()
+		result.Success = true
+		result.Error = nil
+	}
+
+	// Dump the resources before the cleanup removes them
+	if !result.Success && tr.DumpDir != "" {
+		if dumpErr := tr.dumpResources(suiteName, test.Name); dumpErr != nil {
+			fmt.Printf("Warning: failed to dump resources for test %s: %v\n", test.Name, dumpErr)
+		}
+	}
+
+	// Keep the resources of a failed test instead of cleaning them up
+	keep := !result.Success && tracker != nil
+	if keep {
+		kept := newResources(trackedBefore, tracker.TrackedResources())
+		tracker.KeepResources(kept)
+		fmt.Printf("Keeping resources of failed test %s for inspection: %s\n", test.Name, formatResources(kept))
+	}
 
 	// Run cleanup if provided
-	if test.Cleanup != nil {
+	if test.Cleanup != nil && !keep {
 		if cleanupErr := test.Cleanup(tr.TestInterface); cleanupErr != nil {
 			// Log cleanup error but don't fail the test
 			fmt.Printf("Warning: cleanup failed for test %s: %v\n", test.Name, cleanupErr)
 		}
 	}
 
-	return err
+	result.EndTime = time.Now()
+	result.CleanupDuration = result.EndTime.Sub(runEndTime)
+	result.Duration = result.EndTime.Sub(startTime)
+	tr.recordResult(result)
+
+	return nil
 }
 
-// GetResults returns the results of the test execution.
+// recordResult appends a test result and reports it to OnTestFinish.
+func (tr *TestRunner) recordResult(result TestResult) {
+	result.SuiteIndex = result.Test.suiteIndex
+	result.TestIndex = result.Test.index
+	tr.Results = append(tr.Results, result)
+	if tr.OnTestFinish != nil {
+		tr.OnTestFinish(result)
+	}
+}
+
+// ResourceTracker is implemented by test interfaces that track the resources
+// they create, keyed by resource type, and can leave some of them out of
+// teardown. The TestRunner uses it to honour TestConfig.KeepOnFailure.
+type ResourceTracker interface {
+	// TrackedResources returns a copy of the names of the tracked resources.
+	TrackedResources() map[string][]string
+
+	// KeepResources excludes the given resources from teardown.
+	KeepResources(resources map[string][]string)
+}
+
+// keepOnFailureTracker returns the ResourceTracker of the test interface if
+// its configuration enables KeepOnFailure, or nil otherwise.
+func (tr *TestRunner) keepOnFailureTracker() ResourceTracker {
+	if tr.TestInterface == nil {
+		return nil
+	}
+	if config := tr.TestInterface.GetConfig(); config == nil || !config.KeepOnFailure {
+		return nil
+	}
+	tracker, _ := tr.TestInterface.(ResourceTracker)
+	return tracker
+}
+
+// newResources returns the resources in after that are not in before.
+func newResources(before, after map[string][]string) map[string][]string {
+	created := make(map[string][]string)
+	for resourceType, names := range after {
+		existing := make(map[string]bool, len(before[resourceType]))
+		for _, name := range before[resourceType] {
+			existing[name] = true
+		}
+		for _, name := range names {
+			if !existing[name] {
+				created[resourceType] = append(created[resourceType], name)
+			}
+		}
+	}
+	return created
+}
+
+// formatResources formats resources as a sorted, comma-separated list of
+// type/name pairs.
+func formatResources(resources map[string][]string) string {
+	var names []string
+	for resourceType, resourceNames := range resources {
+		for _, name := range resourceNames {
+			names = append(names, resourceType+"/"+name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// labelSkipReason returns why a labeled test does not run, or an empty string
+// if the test has no labels or one of them is enabled.
+func (tr *TestRunner) labelSkipReason(test Test) string {
+	if len(test.Labels) == 0 {
+		return ""
+	}
+	for _, label := range test.Labels {
+		for _, enabled := range tr.EnabledLabels {
+			if label == enabled {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("requires one of the labels %s", strings.Join(test.Labels, ", "))
+}
+
+// missingCapabilities returns the capabilities required by suite that are not
+// in SupportedCapabilities, or nil if no capabilities were declared.
+func (tr *TestRunner) missingCapabilities(suite TestSuite) []string {
+	if tr.SupportedCapabilities == nil {
+		return nil
+	}
+
+	var missing []string
+	for _, required := range suite.RequiredCapabilities {
+		supported := false
+		for _, capability := range tr.SupportedCapabilities {
+			if capability == required {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+// dumpResources writes the resources of the test interface to a file named
+// after the suite and test in DumpDir.
+func (tr *TestRunner) dumpResources(suiteName, testName string) error {
+	if err := os.MkdirAll(tr.DumpDir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(tr.DumpDir, dumpFileName(suiteName, testName))
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := tr.TestInterface.DumpResources(ctx, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// dumpFileName returns a file name for the resource dump of a test, replacing
+// characters that are not safe in file names.
+func dumpFileName(suiteName, testName string) string {
+	name := testName
+	if suiteName != "" {
+		name = suiteName + "-" + testName
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name) + ".yaml"
+}
+
+// ErrResourceCountMismatch is recorded as the error of a test that passed but
+// did not create the resources declared in its ExpectedResourceCounts.
+var ErrResourceCountMismatch = errors.New("resource count mismatch")
+
+// resourceCounts returns the current resource counts of the test interface.
+func (tr *TestRunner) resourceCounts() map[string]int {
+	results := tr.TestInterface.GetTestResults()
+	if results == nil {
+		return map[string]int{}
+	}
+	return results.GetResourceCounts()
+}
+
+// checkResourceCounts compares the resources created between the before and
+// after counts with the test's ExpectedResourceCounts.
+func checkResourceCounts(test Test, before, after map[string]int) error {
+	resourceTypes := make([]string, 0, len(test.ExpectedResourceCounts))
+	for resourceType := range test.ExpectedResourceCounts {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	var mismatches []string
+	for _, resourceType := range resourceTypes {
+		expected := test.ExpectedResourceCounts[resourceType]
+		if created := after[resourceType] - before[resourceType]; created != expected {
+			mismatches = append(mismatches, fmt.Sprintf("created %d %s, expected %d", created, resourceType, expected))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w: test %s %s", ErrResourceCountMismatch, test.Name, strings.Join(mismatches, ", "))
+	}
+	return nil
+}
+
+// runTestBody runs the test function, failing the test if it is still running
+// when the deadline of ctx passes. A test that overruns is given the
+// TimeoutGracePeriod to return, so that a test that stops soon after does not
+// touch the test interface while later tests, cleanup or teardown run. A test
+// still running after that is left running in the background, since Run
+// cannot be cancelled. Either way its own result is discarded.
+func (tr *TestRunner) runTestBody(ctx context.Context, test Test) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return test.Run(tr.TestInterface)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- test.Run(tr.TestInterface)
+	}()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		timedOut := fmt.Errorf("test %s timed out: %w", test.Name, context.DeadlineExceeded)
+		grace := tr.TimeoutGracePeriod
+		if grace == 0 {
+			grace = DefaultTimeoutGracePeriod
+		}
+		if grace < 0 {
+			return timedOut
+		}
+		graceTimer := time.NewTimer(grace)
+		defer graceTimer.Stop()
+		select {
+		case <-done:
+		case <-graceTimer.C:
+		}
+		return timedOut
+	}
+}
+
+// GetResults returns a copy of the results of the test execution, in the
+// order the tests finished.
 func (tr *TestRunner) GetResults() []TestResult {
 	tr.mu.RLock()
 	defer tr.mu.RUnlock()
-	return tr.Results
+	return append(tr.Results[:0:0], tr.Results...)
+}
+
+// GetResultsSorted returns a copy of the results in declaration order: by the
+// order the suites were added, then by the order of the tests within each
+// suite. Results of repeated runs of the same test keep their run order.
+func (tr *TestRunner) GetResultsSorted() []TestResult {
+	results := tr.GetResults()
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].SuiteIndex != results[j].SuiteIndex {
+			return results[i].SuiteIndex < results[j].SuiteIndex
+		}
+		return results[i].TestIndex < results[j].TestIndex
+	})
+	return results
 }
 
 // GetSummary returns a summary of the test results.
@@ -454,15 +1751,149 @@ func (tr *TestRunner) GetSummary() TestSummary {
 	tr.mu.RLock()
 	defer tr.mu.RUnlock()
 
+	summary := summarizeResults(tr.Results)
+	summary.ProviderName = tr.providerName()
+	summary.HasClusterID = tr.hasClusterID()
+	return summary
+}
+
+// UnknownProviderName is reported as the provider name when the test
+// interface has no cloud provider, as in existing-CCM mode.
+const UnknownProviderName = "unknown"
+
+// ProviderName returns the name of the cloud provider under test, or
+// UnknownProviderName if the test interface has no cloud provider.
+func (tr *TestRunner) ProviderName() string {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return tr.providerName()
+}
+
+// HasClusterID reports whether the cloud provider under test has a cluster
+// ID. It returns false if the test interface has no cloud provider.
+func (tr *TestRunner) HasClusterID() bool {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return tr.hasClusterID()
+}
+
+// cloudProvider returns the cloud provider under test, or nil if there is
+// none. The caller must hold tr.mu.
+func (tr *TestRunner) cloudProvider() cloudprovider.Interface {
+	if tr.TestInterface == nil {
+		return nil
+	}
+	return tr.TestInterface.GetCloudProvider()
+}
+
+func (tr *TestRunner) providerName() string {
+	provider := tr.cloudProvider()
+	if provider == nil {
+		return UnknownProviderName
+	}
+	return provider.ProviderName()
+}
+
+func (tr *TestRunner) hasClusterID() bool {
+	provider := tr.cloudProvider()
+	return provider != nil && provider.HasClusterID()
+}
+
+// TestFlakeRate reports how consistently a test passed across repeated runs.
+type TestFlakeRate struct {
+	// Suite is the name of the suite the test belongs to.
+	Suite string
+
+	// Name is the name of the test.
+	Name string
+
+	// Runs is the number of times the test ran, excluding skips.
+	Runs int
+
+	// Passed is the number of runs in which the test passed.
+	Passed int
+}
+
+// PassRate returns the fraction of runs in which the test passed.
+func (r TestFlakeRate) PassRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Passed) / float64(r.Runs)
+}
+
+// Flaky reports whether the test both passed and failed across its runs.
+func (r TestFlakeRate) Flaky() bool {
+	return r.Passed > 0 && r.Passed < r.Runs
+}
+
+// String returns the flake rate in the form
+// "suite/name: passed X of N (P%)".
+func (r TestFlakeRate) String() string {
+	return fmt.Sprintf("%s/%s: passed %d of %d (%.0f%%)", r.Suite, r.Name, r.Passed, r.Runs, r.PassRate()*100)
+}
+
+// GetFlakeRates returns the pass rate of every test that ran, in the order
+// the tests first ran. Tests of the same name in different suites are rated
+// separately. Skipped runs are not counted.
+func (tr *TestRunner) GetFlakeRates() []TestFlakeRate {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	type testKey struct{ suite, name string }
+
+	var rates []TestFlakeRate
+	index := make(map[testKey]int)
+	for _, result := range tr.Results {
+		if result.Test.Skip {
+			continue
+		}
+
+		key := testKey{suite: result.Suite, name: result.Test.Name}
+		i, found := index[key]
+		if !found {
+			i = len(rates)
+			index[key] = i
+			rates = append(rates, TestFlakeRate{Suite: result.Suite, Name: result.Test.Name})
+		}
+
+		rates[i].Runs++
+		if result.Success {
+			rates[i].Passed++
+		}
+	}
+
+	return rates
+}
+
+// SuiteSummary summarizes the results of the tests of a single suite.
+type SuiteSummary struct {
+	// Name is the name of the suite.
+	Name string
+
+	TestSummary
+}
+
+// GetSuiteSummaries returns a summary of the test results of each suite, in
+// the order the suites ran.
+func (tr *TestRunner) GetSuiteSummaries() []SuiteSummary {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	return SummarizeSuites(tr.Results)
+}
+
+// summarizeResults counts the passed, failed and skipped tests in results.
+func summarizeResults(results []TestResult) TestSummary {
 	summary := TestSummary{
-		TotalTests:    len(tr.Results),
+		TotalTests:    len(results),
 		PassedTests:   0,
 		FailedTests:   0,
 		SkippedTests:  0,
 		TotalDuration: 0,
 	}
 
-	for _, result := range tr.Results {
+	for _, result := range results {
 		summary.TotalDuration += result.Duration
 		if result.Test.Skip {
 			summary.SkippedTests++
@@ -476,6 +1907,30 @@ func (tr *TestRunner) GetSummary() TestSummary {
 	return summary
 }
 
+// UnsupportedError indicates that the cloud provider does not support the
+// functionality a test exercises. The test runner reports tests returning
+// this error as skipped rather than failed.
+type UnsupportedError struct {
+	// Capability is the name of the unsupported functionality.
+	Capability string
+}
+
+// Error implements the error interface.
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("cloud provider does not support %s functionality", e.Capability)
+}
+
+// NewUnsupportedError creates a new UnsupportedError for the given capability.
+func NewUnsupportedError(capability string) error {
+	return &UnsupportedError{Capability: capability}
+}
+
+// IsUnsupportedError returns whether err is, or wraps, an UnsupportedError.
+func IsUnsupportedError(err error) bool {
+	var unsupportedErr *UnsupportedError
+	return errors.As(err, &unsupportedErr)
+}
+
 // TestSummary holds a summary of test results.
 type TestSummary struct {
 	// TotalTests is the total number of tests run.
@@ -492,4 +1947,13 @@ type TestSummary struct {
 
 	// TotalDuration is the total duration of all tests.
 	TotalDuration time.Duration
+
+	// ProviderName is the name reported by the cloud provider under test, or
+	// UnknownProviderName when no provider is available. It is only set on
+	// the summary returned by TestRunner.GetSummary.
+	ProviderName string
+
+	// HasClusterID reports whether the cloud provider under test has a
+	// cluster ID. It is only set on the summary returned by TestRunner.GetSummary.
+	HasClusterID bool
 }
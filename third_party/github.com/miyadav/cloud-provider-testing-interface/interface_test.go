@@ -18,15 +18,23 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	fakecloud "k8s.io/cloud-provider/fake"
 )
 
 // TestTestResults tests the TestResults functionality
@@ -71,6 +79,121 @@ func TestTestResults(t *testing.T) {
 	}
 }
 
+// TestTestResultsCheckCleanup tests that CheckCleanup lists the resource types
+// with outstanding counts and that counts never drop below zero
+func TestTestResultsCheckCleanup(t *testing.T) {
+	results := &TestResults{}
+
+	results.IncrementResourceCount("node")
+	results.IncrementResourceCount("service")
+	results.IncrementResourceCount("service")
+	results.DecrementResourceCount("service")
+
+	err := results.CheckCleanup()
+	if !errors.Is(err, ErrResourcesNotCleanedUp) {
+		t.Fatalf("Expected ErrResourcesNotCleanedUp, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 node, 1 service") {
+		t.Errorf("Expected error to list '1 node, 1 service', got '%v'", err)
+	}
+
+	results.DecrementResourceCount("node")
+	results.DecrementResourceCount("service")
+	results.DecrementResourceCount("service")
+	results.DecrementResourceCount("route")
+
+	if err := results.CheckCleanup(); err != nil {
+		t.Errorf("Expected no error after all resources were deleted, got %v", err)
+	}
+	if results.ResourceCounts["service"] != 0 {
+		t.Errorf("Expected service count 0, got %d", results.ResourceCounts["service"])
+	}
+}
+
+// TestTestRunnerStrictWarnings tests that a warning recorded by a passing test
+// fails the run only with StrictWarnings set, reporting every warning
+func TestTestRunnerStrictWarnings(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict=%v", strict), func(t *testing.T) {
+			impl := NewFakeTestImplementation()
+			if err := impl.SetupTestEnvironment(&TestConfig{StrictWarnings: strict}); err != nil {
+				t.Fatalf("Failed to set up test environment: %v", err)
+			}
+
+			runner := NewTestRunner(impl)
+			runner.AddTestSuite(TestSuite{
+				Name: "Warnings",
+				Tests: []Test{{Name: "Warns", Run: func(ti TestInterface) error {
+					ti.GetTestResults().AddWarning("namespace deletion timed out")
+					ti.GetTestResults().AddWarning("node has no cloud metadata")
+					return nil
+				}}},
+			})
+
+			err := runner.RunTests(context.Background())
+			if !strict {
+				if err != nil {
+					t.Errorf("Expected no error without StrictWarnings, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrWarningsReported) {
+				t.Fatalf("Expected ErrWarningsReported, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "namespace deletion timed out\n  node has no cloud metadata") {
+				t.Errorf("Expected error to list both warnings, got '%v'", err)
+			}
+			if summary := runner.GetSummary(); summary.PassedTests != 1 {
+				t.Errorf("Expected the test itself to pass, got %+v", summary)
+			}
+		})
+	}
+}
+
+// TestTestResultsMaxLogs tests that AddLog evicts the oldest entries beyond
+// MaxLogs and that the truncation is reported
+func TestTestResultsMaxLogs(t *testing.T) {
+	results := &TestResults{MaxLogs: 100}
+
+	for i := 0; i < 1000; i++ {
+		results.AddLog(fmt.Sprintf("log %d", i))
+	}
+
+	if len(results.Logs) != 100 {
+		t.Fatalf("Expected 100 logs, got %d", len(results.Logs))
+	}
+
+	if results.TruncatedLogs != 900 {
+		t.Errorf("Expected 900 truncated logs, got %d", results.TruncatedLogs)
+	}
+
+	if results.Logs[0] != "log 900" || results.Logs[99] != "log 999" {
+		t.Errorf("Expected logs 'log 900' to 'log 999', got '%s' to '%s'", results.Logs[0], results.Logs[99])
+	}
+
+	report := results.ReportLogs()
+	if len(report) != 101 {
+		t.Fatalf("Expected 101 report lines, got %d", len(report))
+	}
+
+	if report[0] != "(900 earlier logs truncated)" {
+		t.Errorf("Expected truncation note, got '%s'", report[0])
+	}
+
+	unlimited := &TestResults{}
+	for i := 0; i < 1000; i++ {
+		unlimited.AddLog(fmt.Sprintf("log %d", i))
+	}
+
+	if len(unlimited.Logs) != 1000 || unlimited.TruncatedLogs != 0 {
+		t.Errorf("Expected 1000 logs and no truncation, got %d logs and %d truncated", len(unlimited.Logs), unlimited.TruncatedLogs)
+	}
+
+	if len(unlimited.ReportLogs()) != 1000 {
+		t.Errorf("Expected no truncation note, got %d report lines", len(unlimited.ReportLogs()))
+	}
+}
+
 // TestTestRunner tests the TestRunner functionality
 func TestTestRunner(t *testing.T) {
 	// Create a fake test implementation
@@ -114,6 +237,104 @@ func TestTestRunnerAddTestSuite(t *testing.T) {
 	}
 }
 
+// TestTestRunnerAddTestSuiteChecked tests that malformed suites are rejected at registration
+func TestTestRunnerAddTestSuiteChecked(t *testing.T) {
+	run := func(ti TestInterface) error { return nil }
+
+	tests := []struct {
+		name    string
+		tests   []Test
+		wantErr string
+	}{
+		{
+			name: "valid",
+			tests: []Test{
+				{Name: "First", Run: run, Timeout: time.Second},
+				{Name: "Second", Run: run, Timeout: time.Second},
+				{Name: "Skipped", Skip: true, Timeout: time.Second},
+			},
+		},
+		{
+			name:    "nil run",
+			tests:   []Test{{Name: "No Run", Timeout: time.Second}},
+			wantErr: "test No Run has no Run function",
+		},
+		{
+			name:    "empty name",
+			tests:   []Test{{Run: run, Timeout: time.Second}},
+			wantErr: "test 0 has an empty name",
+		},
+		{
+			name:    "zero timeout",
+			tests:   []Test{{Name: "No Timeout", Run: run}},
+			wantErr: "test No Timeout has non-positive timeout 0s",
+		},
+		{
+			name:    "negative timeout",
+			tests:   []Test{{Name: "Negative Timeout", Run: run, Timeout: -time.Second}},
+			wantErr: "test Negative Timeout has non-positive timeout -1s",
+		},
+		{
+			name: "duplicate names",
+			tests: []Test{
+				{Name: "Twice", Run: run, Timeout: time.Second},
+				{Name: "Twice", Run: run, Timeout: time.Second},
+			},
+			wantErr: "duplicate test name Twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewTestRunner(NewFakeTestImplementation())
+			err := runner.AddTestSuiteChecked(TestSuite{Name: "Checked Suite", Tests: tt.tests})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(runner.TestSuites) != 1 {
+					t.Errorf("Expected 1 test suite, got %d", len(runner.TestSuites))
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidTestSuite) {
+				t.Fatalf("Expected ErrInvalidTestSuite, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error to contain '%s', got '%v'", tt.wantErr, err)
+			}
+			if len(runner.TestSuites) != 0 {
+				t.Errorf("Expected invalid suite not to be added, got %d suites", len(runner.TestSuites))
+			}
+		})
+	}
+}
+
+// TestTestSuiteValidateReportsAllProblems tests that every problem in a suite is reported together
+func TestTestSuiteValidateReportsAllProblems(t *testing.T) {
+	suite := TestSuite{
+		Name: "Broken Suite",
+		Tests: []Test{
+			{Name: "", Timeout: time.Second},
+			{Name: "Dup", Run: func(ti TestInterface) error { return nil }},
+			{Name: "Dup", Run: func(ti TestInterface) error { return nil }, Timeout: time.Second},
+		},
+	}
+
+	err := suite.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error, got nil")
+	}
+
+	for _, want := range []string{"Broken Suite", "empty name", "test #0 has no Run function", "test Dup has non-positive timeout", "duplicate test name Dup"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain '%s', got '%v'", want, err)
+		}
+	}
+}
+
 // TestTestRunnerRunTests tests running tests with the runner
 func TestTestRunnerRunTests(t *testing.T) {
 	fakeImpl := NewFakeTestImplementation()
@@ -242,6 +463,471 @@ func TestTestRunnerRunTestsWithFailure(t *testing.T) {
 	}
 }
 
+// TestTestRunnerRunTestsWithMultipleFailures tests that every failing test in a
+// suite is run and recorded rather than the first failure ending the suite
+func TestTestRunnerRunTestsWithMultipleFailures(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	runner := NewTestRunner(fakeImpl)
+
+	var tests []Test
+	for i := 1; i <= 3; i++ {
+		tests = append(tests, Test{
+			Name: fmt.Sprintf("Failing Test %d", i),
+			Run: func(ti TestInterface) error {
+				return fmt.Errorf("intentional failure %d", i)
+			},
+		})
+	}
+	tests = append(tests, Test{
+		Name: "Passing Test",
+		Run: func(ti TestInterface) error {
+			return nil
+		},
+	})
+
+	runner.AddTestSuite(TestSuite{
+		Name:  "Multiple Failures Suite",
+		Tests: tests,
+	})
+
+	err := runner.RunTests(context.Background())
+	if !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+
+	results := runner.GetResults()
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 test results, got %d", len(results))
+	}
+
+	for i, result := range results[:3] {
+		expected := fmt.Sprintf("intentional failure %d", i+1)
+		if result.Success || result.Error == nil || result.Error.Error() != expected {
+			t.Errorf("Expected test %s to fail with %q, got %v", result.Test.Name, expected, result.Error)
+		}
+	}
+
+	summary := runner.GetSummary()
+	if summary.FailedTests != 3 || summary.PassedTests != 1 {
+		t.Errorf("Expected 3 failed and 1 passed tests, got %d failed and %d passed", summary.FailedTests, summary.PassedTests)
+	}
+}
+
+// TestTestRunnerRunTestsAggregatesFailures tests that the error of a run with
+// failures in several suites lists every failure by suite and wraps the
+// error of each failed test
+func TestTestRunnerRunTestsAggregatesFailures(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	runner := NewTestRunner(NewFakeTestImplementation())
+	runner.AddTestSuite(TestSuite{
+		Name: "LoadBalancer",
+		Tests: []Test{
+			{Name: "Create", Run: func(TestInterface) error { return fmt.Errorf("create: %w", errQuota) }},
+			{Name: "Passes", Run: func(TestInterface) error { return nil }},
+			{Name: "Update", Run: func(TestInterface) error { return errors.New("no ingress") }},
+		},
+	})
+	runner.AddTestSuite(TestSuite{
+		Name:  "Skipped",
+		Tests: []Test{{Name: "NotRun", Skip: true}},
+	})
+	runner.AddTestSuite(TestSuite{
+		Name:  "Nodes",
+		Tests: []Test{{Name: "Initialize", Run: func(TestInterface) error { return errors.New("no provider ID") }}},
+	})
+
+	err := runner.RunTests(context.Background())
+	if !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+	if !errors.Is(err, errQuota) {
+		t.Errorf("Expected the error to wrap the errors of failed tests, got %v", err)
+	}
+
+	var failures *TestFailuresError
+	if !errors.As(err, &failures) {
+		t.Fatalf("Expected a TestFailuresError, got %T", err)
+	}
+	if suites := failures.Suites(); strings.Join(suites, ",") != "LoadBalancer,Nodes" {
+		t.Errorf("Expected failed suites [LoadBalancer Nodes], got %v", suites)
+	}
+	if got := len(failures.SuiteFailures("LoadBalancer")); got != 2 {
+		t.Errorf("Expected 2 LoadBalancer failures, got %d", got)
+	}
+
+	expected := "tests failed: 3 of 5 tests failed\n" +
+		"  LoadBalancer:\n" +
+		"    Create: create: quota exceeded\n" +
+		"    Update: no ingress\n" +
+		"  Nodes:\n" +
+		"    Initialize: no provider ID"
+	if err.Error() != expected {
+		t.Errorf("Expected error:\n%s\ngot:\n%s", expected, err.Error())
+	}
+
+	if summary := runner.GetSummary(); summary.TotalTests != 5 || summary.FailedTests != 3 {
+		t.Errorf("Expected the results to stay complete, got %+v", summary)
+	}
+}
+
+// TestTestRunnerRunTestsContinuesAfterFailure tests that a failing test in one
+// suite does not stop the remaining tests and suites from running
+func TestTestRunnerRunTestsContinuesAfterFailure(t *testing.T) {
+	tests := []struct {
+		name            string
+		failFast        bool
+		expectedResults int
+	}{
+		{name: "continue", failFast: false, expectedResults: 3},
+		{name: "fail fast", failFast: true, expectedResults: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeImpl := NewFakeTestImplementation()
+			runner := NewTestRunner(fakeImpl)
+			runner.FailFast = tt.failFast
+
+			suite2Ran := false
+			runner.AddTestSuite(TestSuite{
+				Name: "Suite 1",
+				Tests: []Test{
+					{
+						Name: "Failing Test",
+						Run: func(ti TestInterface) error {
+							return fmt.Errorf("intentional test failure")
+						},
+					},
+					{
+						Name: "Passing Test",
+						Run: func(ti TestInterface) error {
+							return nil
+						},
+					},
+				},
+			})
+			runner.AddTestSuite(TestSuite{
+				Name: "Suite 2",
+				Tests: []Test{
+					{
+						Name: "Suite 2 Test",
+						Run: func(ti TestInterface) error {
+							suite2Ran = true
+							return nil
+						},
+					},
+				},
+			})
+
+			err := runner.RunTests(context.Background())
+			if err == nil {
+				t.Fatal("Expected error from failing test")
+			}
+
+			if !errors.Is(err, ErrTestsFailed) {
+				t.Errorf("Expected ErrTestsFailed, got %v", err)
+			}
+
+			results := runner.GetResults()
+			if len(results) != tt.expectedResults {
+				t.Errorf("Expected %d test results, got %d", tt.expectedResults, len(results))
+			}
+
+			if suite2Ran == tt.failFast {
+				t.Errorf("Expected suite 2 ran to be %v, got %v", !tt.failFast, suite2Ran)
+			}
+
+			summary := runner.GetSummary()
+			if summary.FailedTests != 1 {
+				t.Errorf("Expected 1 failed test, got %d", summary.FailedTests)
+			}
+		})
+	}
+}
+
+// TestTestRunnerRunTestsRepeated tests that repeated runs report a test failing
+// every other invocation as flaky with a 50% pass rate
+func TestTestRunnerRunTestsRepeated(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	runner := NewTestRunner(fakeImpl)
+
+	invocations := 0
+	runner.AddTestSuite(TestSuite{
+		Name: "Repeated Test Suite",
+		Tests: []Test{
+			{
+				Name: "Flaky Test",
+				Run: func(ti TestInterface) error {
+					invocations++
+					if invocations%2 == 0 {
+						return fmt.Errorf("intermittent failure")
+					}
+					return nil
+				},
+				Timeout: 30 * time.Second,
+			},
+			{
+				Name:    "Stable Test",
+				Run:     func(ti TestInterface) error { return nil },
+				Timeout: 30 * time.Second,
+			},
+		},
+	})
+	runner.AddTestSuite(TestSuite{
+		Name: "Other Test Suite",
+		Tests: []Test{
+			{
+				Name:    "Stable Test",
+				Run:     func(ti TestInterface) error { return fmt.Errorf("always fails") },
+				Timeout: 30 * time.Second,
+			},
+		},
+	})
+
+	err := runner.RunTestsRepeated(context.Background(), 4)
+	if !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+
+	if len(runner.GetResults()) != 12 {
+		t.Errorf("Expected 12 aggregated results, got %d", len(runner.GetResults()))
+	}
+
+	rates := runner.GetFlakeRates()
+	if len(rates) != 3 {
+		t.Fatalf("Expected 3 flake rates, got %d", len(rates))
+	}
+
+	flaky := rates[0]
+	if flaky.Name != "Flaky Test" || flaky.Runs != 4 || flaky.Passed != 2 {
+		t.Errorf("Expected Flaky Test to pass 2 of 4, got %+v", flaky)
+	}
+	if flaky.PassRate() != 0.5 {
+		t.Errorf("Expected pass rate 0.5, got %v", flaky.PassRate())
+	}
+	if !flaky.Flaky() {
+		t.Error("Expected Flaky Test to be reported as flaky")
+	}
+	if flaky.String() != "Repeated Test Suite/Flaky Test: passed 2 of 4 (50%)" {
+		t.Errorf("Expected 'Repeated Test Suite/Flaky Test: passed 2 of 4 (50%%)', got '%s'", flaky.String())
+	}
+
+	stable := rates[1]
+	if stable.Suite != "Repeated Test Suite" || stable.Passed != 4 || stable.Flaky() {
+		t.Errorf("Expected Stable Test to pass consistently, got %+v", stable)
+	}
+
+	// A test of the same name in another suite is rated on its own
+	other := rates[2]
+	if other.Suite != "Other Test Suite" || other.Name != "Stable Test" || other.Runs != 4 || other.Passed != 0 {
+		t.Errorf("Expected Stable Test of Other Test Suite to fail all 4 runs, got %+v", other)
+	}
+
+	if err := runner.RunTestsRepeated(context.Background(), 0); err == nil {
+		t.Error("Expected error for a repeat count of 0, got nil")
+	}
+}
+
+// cloneableTestImplementation is a fake implementation that clones itself
+// into fresh fake implementations, counting the clones it created
+type cloneableTestImplementation struct {
+	*FakeTestImplementation
+	clones atomic.Int32
+}
+
+func (c *cloneableTestImplementation) CloneTestInterface() (TestInterface, error) {
+	c.clones.Add(1)
+	clone := NewFakeTestImplementation()
+	if err := clone.SetupTestEnvironment(c.TestConfig); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// TestTestRunnerRunTestsConcurrent tests that suites run at the same time on
+// clones of the test interface and that their results and warnings merge
+func TestTestRunnerRunTestsConcurrent(t *testing.T) {
+	impl := &cloneableTestImplementation{FakeTestImplementation: NewFakeTestImplementation()}
+	if err := impl.SetupTestEnvironment(&TestConfig{}); err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+
+	// Each suite waits for the other to start, so a sequential run would
+	// time out
+	started := make(chan struct{}, 2)
+	waitForBoth := func(ti TestInterface) error {
+		if ti == TestInterface(impl) {
+			return fmt.Errorf("suite ran on the original test interface")
+		}
+		started <- struct{}{}
+		deadline := time.After(5 * time.Second)
+		for len(started) < 2 {
+			select {
+			case <-deadline:
+				return fmt.Errorf("suites did not run concurrently")
+			case <-time.After(time.Millisecond):
+			}
+		}
+		ti.GetTestResults().AddWarning("warned by " + ti.GetTestResults().Logs[0])
+		return nil
+	}
+
+	runner := NewTestRunner(impl)
+	runner.AddTestSuite(TestSuite{
+		Name:  "First",
+		Tests: []Test{{Name: "Waits", Run: waitForBoth}, {Name: "Fails", Run: func(TestInterface) error { return errors.New("failed") }}},
+	})
+	runner.AddTestSuite(TestSuite{
+		Name:  "Second",
+		Tests: []Test{{Name: "Waits", Run: waitForBoth}},
+	})
+
+	err := runner.RunTestsConcurrent(context.Background(), 2)
+	if !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+	if clones := impl.clones.Load(); clones != 2 {
+		t.Errorf("Expected a clone per suite, got %d", clones)
+	}
+
+	var got []string
+	for _, result := range runner.GetResultsSorted() {
+		got = append(got, fmt.Sprintf("%s/%s=%v", result.Suite, result.Test.Name, result.Success))
+	}
+	if strings.Join(got, ", ") != "First/Waits=true, First/Fails=false, Second/Waits=true" {
+		t.Errorf("Expected the results of both suites, got %v", got)
+	}
+	if summaries := SummarizeSuites(runner.GetResultsSorted()); len(summaries) != 2 || summaries[0].FailedTests != 1 || summaries[1].PassedTests != 1 {
+		t.Errorf("Expected per-suite results to stay apart, got %+v", summaries)
+	}
+	if warnings := impl.GetTestResults().GetWarnings(); len(warnings) != 2 {
+		t.Errorf("Expected the warnings of both clones, got %v", warnings)
+	}
+}
+
+// TestTestRunnerRunTestsConcurrentUnsupported tests that a test interface that
+// cannot be cloned is rejected
+func TestTestRunnerRunTestsConcurrentUnsupported(t *testing.T) {
+	runner := NewTestRunner(NewFakeTestImplementation())
+	runner.AddTestSuite(TestSuite{Name: "Suite", Tests: []Test{{Name: "Passes", Run: func(TestInterface) error { return nil }}}})
+
+	if err := runner.RunTestsConcurrent(context.Background(), 2); !errors.Is(err, ErrConcurrencyUnsupported) {
+		t.Errorf("Expected ErrConcurrencyUnsupported, got %v", err)
+	}
+	if len(runner.GetResults()) != 0 {
+		t.Errorf("Expected no tests to run, got %d results", len(runner.GetResults()))
+	}
+}
+
+// TestTestRunnerRunTestsRandomized tests that a fixed seed gives a deterministic
+// shuffled order in which dependencies still run before their dependents
+func TestTestRunnerRunTestsRandomized(t *testing.T) {
+	runOrder := func(seed int64) []string {
+		var order []string
+		record := func(name string) func(TestInterface) error {
+			return func(ti TestInterface) error {
+				order = append(order, name)
+				return nil
+			}
+		}
+
+		runner := NewTestRunner(NewFakeTestImplementation())
+		runner.Randomize = true
+		runner.Seed = seed
+		runner.AddTestSuite(TestSuite{
+			Name: "Randomized Test Suite",
+			Tests: []Test{
+				{Name: "A", Run: record("A"), Timeout: time.Second},
+				{Name: "B", Run: record("B"), Timeout: time.Second},
+				{Name: "C", Run: record("C"), Timeout: time.Second},
+				{Name: "D", Run: record("D"), Timeout: time.Second, Dependencies: []string{"B"}},
+				{Name: "E", Run: record("E"), Timeout: time.Second},
+				{Name: "F", Run: record("F"), Timeout: time.Second, Dependencies: []string{"D", "A", "external"}},
+			},
+		})
+
+		if err := runner.RunTests(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return order
+	}
+
+	order := runOrder(42)
+	expected := []string{"B", "E", "D", "A", "F", "C"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected order %v for seed 42, got %v", expected, order)
+	}
+
+	if again := runOrder(42); strings.Join(again, ",") != strings.Join(order, ",") {
+		t.Errorf("Expected the same seed to give the same order, got %v and %v", order, again)
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		position := make(map[string]int)
+		for i, name := range runOrder(seed) {
+			position[name] = i
+		}
+		if len(position) != 6 {
+			t.Fatalf("Expected all 6 tests to run with seed %d, got %d", seed, len(position))
+		}
+		if position["B"] > position["D"] || position["D"] > position["F"] || position["A"] > position["F"] {
+			t.Errorf("Expected dependencies to run first with seed %d, got positions %v", seed, position)
+		}
+	}
+}
+
+// TestTestRunnerRunTestsPriority tests that higher priority tests run first,
+// in declared order among equals, and that dependencies still run before the
+// tests depending on them
+func TestTestRunnerRunTestsPriority(t *testing.T) {
+	runOrder := func(randomize bool, seed int64) []string {
+		var order []string
+		record := func(name string) func(TestInterface) error {
+			return func(ti TestInterface) error {
+				order = append(order, name)
+				return nil
+			}
+		}
+
+		runner := NewTestRunner(NewFakeTestImplementation())
+		runner.Randomize = randomize
+		runner.Seed = seed
+		runner.AddTestSuite(TestSuite{
+			Name: "Priority Test Suite",
+			Tests: []Test{
+				{Name: "Deep", Run: record("Deep"), Timeout: time.Second},
+				{Name: "Smoke", Run: record("Smoke"), Timeout: time.Second, Priority: 10},
+				{Name: "Critical", Run: record("Critical"), Timeout: time.Second, Priority: 10, Dependencies: []string{"Setup"}},
+				{Name: "Setup", Run: record("Setup"), Timeout: time.Second},
+				{Name: "Medium", Run: record("Medium"), Timeout: time.Second, Priority: 5},
+				{Name: "Optional", Run: record("Optional"), Timeout: time.Second},
+			},
+		})
+
+		if err := runner.RunTests(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return order
+	}
+
+	order := runOrder(false, 0)
+	expected := []string{"Smoke", "Setup", "Critical", "Medium", "Deep", "Optional"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		position := make(map[string]int)
+		for i, name := range runOrder(true, seed) {
+			position[name] = i
+		}
+		if position["Setup"] > position["Critical"] || max(position["Smoke"], position["Critical"]) > position["Medium"] ||
+			position["Medium"] > min(position["Deep"], position["Optional"]) {
+			t.Errorf("Expected priorities and dependencies to hold with seed %d, got positions %v", seed, position)
+		}
+	}
+}
+
 // TestTestRunnerRunTestsWithSkipped tests running tests that are skipped
 func TestTestRunnerRunTestsWithSkipped(t *testing.T) {
 	fakeImpl := NewFakeTestImplementation()
@@ -305,6 +991,385 @@ func TestTestRunnerRunTestsWithSkipped(t *testing.T) {
 	}
 }
 
+// TestTestRunnerRunTestsWithLabels tests that labeled tests only run when one
+// of their labels is enabled and are reported as skipped otherwise
+func TestTestRunnerRunTestsWithLabels(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabledLabels []string
+		wantRan       []string
+	}{
+		{name: "no labels enabled", wantRan: []string{"Unlabeled"}},
+		{name: "deep label enabled", enabledLabels: []string{"deep"}, wantRan: []string{"Unlabeled", "Deep"}},
+		{name: "other label enabled", enabledLabels: []string{"slow"}, wantRan: []string{"Unlabeled", "CIOrSlow"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			run := func(name string) func(TestInterface) error {
+				return func(TestInterface) error {
+					ran = append(ran, name)
+					return nil
+				}
+			}
+
+			runner := NewTestRunner(NewFakeTestImplementation())
+			runner.EnabledLabels = tt.enabledLabels
+			runner.AddTestSuite(TestSuite{
+				Name: "Labeled",
+				Tests: []Test{
+					{Name: "Unlabeled", Run: run("Unlabeled")},
+					{Name: "Deep", Run: run("Deep"), Labels: []string{"deep"}},
+					{Name: "CIOrSlow", Run: run("CIOrSlow"), Labels: []string{"ci", "slow"}},
+				},
+			})
+
+			if err := runner.RunTests(context.Background()); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if strings.Join(ran, ",") != strings.Join(tt.wantRan, ",") {
+				t.Errorf("Expected tests %v to run, got %v", tt.wantRan, ran)
+			}
+
+			summary := runner.GetSummary()
+			if summary.SkippedTests != 3-len(tt.wantRan) {
+				t.Errorf("Expected %d skipped tests, got %d", 3-len(tt.wantRan), summary.SkippedTests)
+			}
+			for _, result := range runner.GetResults() {
+				if result.Test.Skip && !strings.HasPrefix(result.Test.SkipReason, "requires one of the labels") {
+					t.Errorf("Expected a label skip reason for %s, got '%s'", result.Test.Name, result.Test.SkipReason)
+				}
+			}
+		})
+	}
+}
+
+// TestTestRunnerRunTestsWithCapabilities tests that suites requiring
+// capabilities the provider does not declare are skipped without running setup
+func TestTestRunnerRunTestsWithCapabilities(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported []string
+		wantRan   []string
+	}{
+		{name: "nothing declared", wantRan: []string{"Plain", "LoadBalancer", "Routes"}},
+		{name: "routes omitted", supported: []string{"loadbalancer"}, wantRan: []string{"Plain", "LoadBalancer"}},
+		{name: "empty declaration", supported: []string{}, wantRan: []string{"Plain"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			suite := func(name string, required ...string) TestSuite {
+				return TestSuite{
+					Name:                 name,
+					RequiredCapabilities: required,
+					Setup: func(TestInterface) error {
+						ran = append(ran, name)
+						return nil
+					},
+					Tests: []Test{{Name: name + "Test", Run: func(TestInterface) error { return nil }}},
+				}
+			}
+
+			runner := NewTestRunner(NewFakeTestImplementation())
+			runner.SupportedCapabilities = tt.supported
+			runner.AddTestSuite(suite("Plain"))
+			runner.AddTestSuite(suite("LoadBalancer", "loadbalancer"))
+			runner.AddTestSuite(suite("Routes", "routes"))
+
+			if err := runner.RunTests(context.Background()); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if strings.Join(ran, ",") != strings.Join(tt.wantRan, ",") {
+				t.Errorf("Expected suites %v to run, got %v", tt.wantRan, ran)
+			}
+
+			results := runner.GetResults()
+			if len(results) != 3 {
+				t.Fatalf("Expected 3 results, got %d", len(results))
+			}
+			for _, result := range results {
+				if !result.Test.Skip {
+					continue
+				}
+				if !strings.HasPrefix(result.Test.SkipReason, "provider does not declare the capabilities") {
+					t.Errorf("Expected a capability skip reason for %s, got '%s'", result.Test.Name, result.Test.SkipReason)
+				}
+			}
+			if summary := runner.GetSummary(); summary.SkippedTests != 3-len(tt.wantRan) {
+				t.Errorf("Expected %d skipped tests, got %d", 3-len(tt.wantRan), summary.SkippedTests)
+			}
+		})
+	}
+}
+
+// TestTestRunnerGlobalHooks tests that BeforeAll runs once before the suites
+// and AfterAll once after them, including when tests fail or the run stops
+func TestTestRunnerGlobalHooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		beforeErr  error
+		suiteErr   error
+		suiteSetup error
+		cancel     bool
+		repeat     int
+		wantEvents []string
+		wantErr    error
+	}{
+		{
+			name:       "passing suites",
+			wantEvents: []string{"before", "A", "B", "after"},
+		},
+		{
+			name:       "failing test",
+			suiteErr:   errors.New("boom"),
+			wantEvents: []string{"before", "A", "B", "after"},
+			wantErr:    ErrTestsFailed,
+		},
+		{
+			name:       "failing suite setup",
+			suiteSetup: errors.New("no network"),
+			wantEvents: []string{"before", "after"},
+		},
+		{
+			name:       "cancelled run",
+			cancel:     true,
+			wantEvents: []string{"before", "after"},
+			wantErr:    ErrRunCancelled,
+		},
+		{
+			name:       "failing before-all",
+			beforeErr:  errors.New("no quota"),
+			wantEvents: []string{"before", "after"},
+		},
+		{
+			name:       "repeated run",
+			repeat:     2,
+			wantEvents: []string{"before", "A", "B", "A", "B", "after"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			test := func(name string, err error) Test {
+				return Test{Name: name, Run: func(TestInterface) error {
+					events = append(events, name)
+					return err
+				}}
+			}
+
+			runner := NewTestRunner(NewFakeTestImplementation())
+			runner.BeforeAll = func(context.Context, TestInterface) error {
+				events = append(events, "before")
+				return tt.beforeErr
+			}
+			runner.AfterAll = func(ctx context.Context, _ TestInterface) error {
+				if ctx.Err() != nil {
+					t.Errorf("Expected the after-all context to be live, got %v", ctx.Err())
+				}
+				events = append(events, "after")
+				return nil
+			}
+			runner.AddTestSuite(TestSuite{
+				Name:  "First",
+				Setup: func(TestInterface) error { return tt.suiteSetup },
+				Tests: []Test{test("A", tt.suiteErr)},
+			})
+			runner.AddTestSuite(TestSuite{Name: "Second", Tests: []Test{test("B", nil)}})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			var err error
+			if tt.repeat > 0 {
+				err = runner.RunTestsRepeated(ctx, tt.repeat)
+			} else {
+				err = runner.RunTests(ctx)
+			}
+
+			if strings.Join(events, ",") != strings.Join(tt.wantEvents, ",") {
+				t.Errorf("Expected events %v, got %v", tt.wantEvents, events)
+			}
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected error wrapping %v, got %v", tt.wantErr, err)
+				}
+			case tt.beforeErr != nil || tt.suiteSetup != nil:
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+			case err != nil:
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestTestRunnerAfterAllError tests that an AfterAll failure is reported
+// alongside test failures
+func TestTestRunnerAfterAllError(t *testing.T) {
+	runner := NewTestRunner(NewFakeTestImplementation())
+	runner.AfterAll = func(context.Context, TestInterface) error {
+		return errors.New("subnet still in use")
+	}
+	runner.AddTestSuite(TestSuite{
+		Name:  "Failing",
+		Tests: []Test{{Name: "Fails", Run: func(TestInterface) error { return errors.New("boom") }}},
+	})
+
+	err := runner.RunTests(context.Background())
+	if !errors.Is(err, ErrTestsFailed) {
+		t.Errorf("Expected error wrapping ErrTestsFailed, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "after-all hook failed: subnet still in use") {
+		t.Errorf("Expected the after-all failure to be reported, got %v", err)
+	}
+}
+
+// TestTestRunnerProgressCallbacks tests that the progress callbacks see every
+// test start and finish in order
+func TestTestRunnerProgressCallbacks(t *testing.T) {
+	runner := NewTestRunner(NewFakeTestImplementation())
+	var events []string
+	runner.OnTestStart = func(suiteName string, test Test) {
+		events = append(events, "start "+suiteName+"/"+test.Name)
+	}
+	runner.OnTestFinish = func(result TestResult) {
+		events = append(events, fmt.Sprintf("finish %s/%s %v", result.Suite, result.Test.Name, result.Success))
+	}
+	runner.AddTestSuite(TestSuite{
+		Name: "Progress",
+		Tests: []Test{
+			{Name: "Passes", Run: func(TestInterface) error { return nil }},
+			{Name: "Fails", Run: func(TestInterface) error { return errors.New("boom") }},
+			{Name: "Skipped", Skip: true, Run: func(TestInterface) error { return nil }},
+		},
+	})
+
+	if err := runner.RunTests(context.Background()); !errors.Is(err, ErrTestsFailed) {
+		t.Errorf("Expected error wrapping ErrTestsFailed, got %v", err)
+	}
+
+	expected := []string{
+		"start Progress/Passes",
+		"finish Progress/Passes true",
+		"start Progress/Fails",
+		"finish Progress/Fails false",
+		"finish Progress/Skipped true",
+	}
+	if strings.Join(events, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+// resultNames returns the suite-qualified names of the tests of results
+func resultNames(results []TestResult) string {
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Suite+"/"+result.Test.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// TestTestRunnerGetResultsSorted tests that results completing out of order,
+// as they do when tests are randomized or run in parallel, are sorted back
+// into declaration order
+func TestTestRunnerGetResultsSorted(t *testing.T) {
+	pass := func(TestInterface) error { return nil }
+	newRunner := func() *TestRunner {
+		runner := NewTestRunner(NewFakeTestImplementation())
+		for _, suite := range []string{"First", "Second"} {
+			runner.AddTestSuite(TestSuite{
+				Name: suite,
+				Tests: []Test{
+					{Name: "A", Run: pass, Timeout: time.Second},
+					{Name: "B", Run: pass, Timeout: time.Second},
+					{Name: "C", Run: pass, Timeout: time.Second},
+					{Name: "D", Run: pass, Timeout: time.Second},
+				},
+			})
+		}
+		return runner
+	}
+	declared := "First/A,First/B,First/C,First/D,Second/A,Second/B,Second/C,Second/D"
+
+	t.Run("randomized", func(t *testing.T) {
+		runner := newRunner()
+		runner.Randomize = true
+		runner.Seed = 42
+		if err := runner.RunTests(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if got := resultNames(runner.GetResults()); got == declared {
+			t.Fatalf("Expected the randomized run order to differ from the declaration order")
+		}
+		if got := resultNames(runner.GetResultsSorted()); got != declared {
+			t.Errorf("Expected sorted results %s, got %s", declared, got)
+		}
+	})
+
+	t.Run("parallel completions", func(t *testing.T) {
+		runner := newRunner()
+
+		// Finish every test concurrently, each only after the one declared
+		// after it, so that they complete in reverse declaration order
+		var tests []TestResult
+		for _, suite := range runner.TestSuites {
+			for _, test := range suite.Tests {
+				tests = append(tests, TestResult{Test: test, Suite: suite.Name, Success: true})
+			}
+		}
+		var wg sync.WaitGroup
+		next := make(chan struct{})
+		close(next)
+		for i := len(tests) - 1; i >= 0; i-- {
+			prev, done := next, make(chan struct{})
+			next = done
+			wg.Add(1)
+			go func(result TestResult) {
+				defer wg.Done()
+				<-prev
+				runner.mu.Lock()
+				runner.recordResult(result)
+				runner.mu.Unlock()
+				close(done)
+			}(tests[i])
+		}
+		wg.Wait()
+
+		if got := resultNames(runner.GetResults()); got != "Second/D,Second/C,Second/B,Second/A,First/D,First/C,First/B,First/A" {
+			t.Fatalf("Expected results in reverse completion order, got %s", got)
+		}
+		if got := resultNames(runner.GetResultsSorted()); got != declared {
+			t.Errorf("Expected sorted results %s, got %s", declared, got)
+		}
+	})
+
+	t.Run("defensive copy", func(t *testing.T) {
+		runner := newRunner()
+		if err := runner.RunTests(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		results := runner.GetResults()
+		results[0].Success = false
+		copy(results[1:], results[2:])
+		if summary := runner.GetSummary(); summary.TotalTests != 8 || summary.FailedTests != 0 {
+			t.Errorf("Expected changes to the returned results not to affect the runner, got %+v", summary)
+		}
+	})
+}
+
 // TestTestRunnerRunTestsWithTimeout tests running tests with timeout
 func TestTestRunnerRunTestsWithTimeout(t *testing.T) {
 	fakeImpl := NewFakeTestImplementation()
@@ -371,6 +1436,283 @@ func TestTestRunnerRunTestsWithTimeout(t *testing.T) {
 	}
 }
 
+// TestTestRunnerTimeoutSource tests that a timed out test reports whether its
+// own Timeout or the deadline of the run passed
+func TestTestRunnerTimeoutSource(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	blocks := func(TestInterface) error {
+		<-release
+		return nil
+	}
+
+	t.Run("test timeout", func(t *testing.T) {
+		runner := NewTestRunner(NewFakeTestImplementation())
+		runner.TimeoutGracePeriod = 10 * time.Millisecond
+		runner.AddTestSuite(TestSuite{
+			Name:  "Timeouts",
+			Tests: []Test{{Name: "Overruns", Run: blocks, Timeout: 50 * time.Millisecond}},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := runner.RunTests(ctx); !errors.Is(err, ErrTestsFailed) {
+			t.Fatalf("Expected error wrapping ErrTestsFailed, got %v", err)
+		}
+
+		result := runner.GetResults()[0]
+		if !result.TimedOut || result.TimeoutSource != TimeoutSourceTest {
+			t.Errorf("Expected a timeout from the test, got timed out %v from %q", result.TimedOut, result.TimeoutSource)
+		}
+	})
+
+	t.Run("run deadline", func(t *testing.T) {
+		runner := NewTestRunner(NewFakeTestImplementation())
+		runner.TimeoutGracePeriod = 10 * time.Millisecond
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		test := Test{Name: "Cancelled", Run: blocks, Timeout: time.Minute}
+		if err := runner.runTest(ctx, "Timeouts", test); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		result := runner.GetResults()[0]
+		if result.Success {
+			t.Fatalf("Expected the test to fail")
+		}
+		if !result.TimedOut || result.TimeoutSource != TimeoutSourceRun {
+			t.Errorf("Expected a timeout from the run, got timed out %v from %q", result.TimedOut, result.TimeoutSource)
+		}
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		runner := NewTestRunner(NewFakeTestImplementation())
+		runner.TimeoutGracePeriod = 10 * time.Millisecond
+		runner.AddTestSuite(TestSuite{
+			Name:  "Timeouts",
+			Tests: []Test{{Name: "Fails", Run: func(TestInterface) error { return errors.New("boom") }, Timeout: time.Minute}},
+		})
+		_ = runner.RunTests(context.Background())
+
+		result := runner.GetResults()[0]
+		if result.TimedOut || result.TimeoutSource != "" {
+			t.Errorf("Expected no timeout, got timed out %v from %q", result.TimedOut, result.TimeoutSource)
+		}
+	})
+}
+
+// TestTestRunnerRunTestsWithSuiteTimeout tests that a test overrunning its
+// timeout is failed and that tests left when the suite times out are failed
+// without being run
+func TestTestRunnerRunTestsWithSuiteTimeout(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	runner := NewTestRunner(fakeImpl)
+	runner.TimeoutGracePeriod = 10 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+
+	lateTestRan := false
+	runner.AddTestSuite(TestSuite{
+		Name:         "Suite Timeout Test Suite",
+		SuiteTimeout: 100 * time.Millisecond,
+		Tests: []Test{
+			{
+				Name: "Hanging Test",
+				Run: func(ti TestInterface) error {
+					<-release
+					return nil
+				},
+				Timeout: 50 * time.Millisecond,
+			},
+			{
+				Name: "Slow Test",
+				Run: func(ti TestInterface) error {
+					<-release
+					return nil
+				},
+			},
+			{
+				Name: "Late Test",
+				Run: func(ti TestInterface) error {
+					lateTestRan = true
+					return nil
+				},
+			},
+		},
+	})
+
+	start := time.Now()
+	err := runner.RunTests(context.Background())
+	if !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected run to stop at the suite timeout, took %v", elapsed)
+	}
+
+	results := runner.GetResults()
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 test results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if result.Success {
+			t.Errorf("Expected test %s to fail", result.Test.Name)
+		}
+	}
+
+	if !errors.Is(results[0].Error, context.DeadlineExceeded) {
+		t.Errorf("Expected hanging test to time out, got %v", results[0].Error)
+	}
+
+	if lateTestRan {
+		t.Error("Expected test after the suite timeout not to run")
+	}
+}
+
+// TestTestRunnerTimeoutGracePeriod tests that a test returning soon after its
+// timeout is waited for, while one that never returns does not hold up the run
+func TestTestRunnerTimeoutGracePeriod(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	lateTestReturned := false
+	runner := NewTestRunner(NewFakeTestImplementation())
+	runner.TimeoutGracePeriod = time.Second
+	runner.AddTestSuite(TestSuite{
+		Name: "Grace Period Test Suite",
+		Tests: []Test{
+			{
+				Name: "Late Test",
+				Run: func(ti TestInterface) error {
+					time.Sleep(100 * time.Millisecond)
+					lateTestReturned = true
+					return nil
+				},
+				Timeout: 50 * time.Millisecond,
+			},
+			{
+				Name: "Hung Test",
+				Run: func(ti TestInterface) error {
+					<-release
+					return nil
+				},
+				Timeout: 50 * time.Millisecond,
+			},
+		},
+	})
+
+	start := time.Now()
+	if err := runner.RunTests(context.Background()); !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the hung test to be abandoned after the grace period, took %v", elapsed)
+	}
+
+	if !lateTestReturned {
+		t.Error("Expected the runner to wait for the late test to return")
+	}
+	for _, result := range runner.GetResults() {
+		if !errors.Is(result.Error, context.DeadlineExceeded) {
+			t.Errorf("Expected %s to time out, got %v", result.Test.Name, result.Error)
+		}
+	}
+}
+
+// TestTestRunnerRunTestsWithExpectedResourceCounts tests that a test creating more
+// resources than it declares fails with a count mismatch
+func TestTestRunnerRunTestsWithExpectedResourceCounts(t *testing.T) {
+	createServices := func(n int) func(TestInterface) error {
+		return func(ti TestInterface) error {
+			for i := 0; i < n; i++ {
+				_, err := ti.CreateTestService(context.Background(), &TestServiceConfig{
+					Name:      fmt.Sprintf("counted-service-%d", i),
+					Namespace: "default",
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name     string
+		run      func(TestInterface) error
+		expected map[string]int
+		wantErr  string
+	}{
+		{
+			name:     "matching counts",
+			run:      createServices(1),
+			expected: map[string]int{ResourceTypeServices: 1, ResourceTypeNodes: 0},
+		},
+		{
+			name:     "over-creation",
+			run:      createServices(2),
+			expected: map[string]int{ResourceTypeServices: 1, ResourceTypeNodes: 0},
+			wantErr:  "created 2 services, expected 1",
+		},
+		{
+			name:     "missing resources",
+			run:      createServices(0),
+			expected: map[string]int{ResourceTypeServices: 1},
+			wantErr:  "created 0 services, expected 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeImpl := NewFakeTestImplementation()
+			if err := fakeImpl.SetupTestEnvironment(&TestConfig{ProviderName: "fake"}); err != nil {
+				t.Fatalf("Failed to setup test environment: %v", err)
+			}
+
+			runner := NewTestRunner(fakeImpl)
+			runner.AddTestSuite(TestSuite{
+				Name: "Resource Count Test Suite",
+				Tests: []Test{
+					{
+						Name:    "Warm Up",
+						Run:     createServices(1),
+						Timeout: 30 * time.Second,
+					},
+					{
+						Name:                   "Counted Test",
+						Run:                    tt.run,
+						Timeout:                30 * time.Second,
+						ExpectedResourceCounts: tt.expected,
+					},
+				},
+			})
+
+			_ = runner.RunTests(context.Background())
+
+			result := runner.GetResults()[1]
+			if tt.wantErr == "" {
+				if !result.Success {
+					t.Errorf("Expected test to pass, got %v", result.Error)
+				}
+				return
+			}
+
+			if result.Success {
+				t.Fatal("Expected count mismatch to fail the test")
+			}
+			if !errors.Is(result.Error, ErrResourceCountMismatch) {
+				t.Errorf("Expected ErrResourceCountMismatch, got %v", result.Error)
+			}
+			if !strings.Contains(result.Error.Error(), tt.wantErr) {
+				t.Errorf("Expected error to contain '%s', got '%v'", tt.wantErr, result.Error)
+			}
+		})
+	}
+}
+
 // TestTestRunnerRunTestsWithCleanup tests running tests with cleanup functions
 func TestTestRunnerRunTestsWithCleanup(t *testing.T) {
 	fakeImpl := NewFakeTestImplementation()
@@ -439,6 +1781,47 @@ func TestTestRunnerRunTestsWithCleanup(t *testing.T) {
 	}
 }
 
+// TestTestRunnerRecordsTimingBreakdown tests that the run and cleanup of a test
+// are timed separately and that the phases sum to its duration
+func TestTestRunnerRecordsTimingBreakdown(t *testing.T) {
+	runner := NewTestRunner(NewFakeTestImplementation())
+	runner.AddTestSuite(TestSuite{
+		Name: "Timing",
+		Tests: []Test{
+			{
+				Name: "Timed",
+				Run: func(TestInterface) error {
+					time.Sleep(50 * time.Millisecond)
+					return nil
+				},
+				Cleanup: func(TestInterface) error {
+					time.Sleep(20 * time.Millisecond)
+					return nil
+				},
+				Timeout: 30 * time.Second,
+			},
+		},
+	})
+
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := runner.GetResults()[0]
+	if result.RunDuration < 50*time.Millisecond {
+		t.Errorf("Expected a run duration of at least 50ms, got %v", result.RunDuration)
+	}
+	if result.CleanupDuration < 20*time.Millisecond || result.CleanupDuration >= result.RunDuration {
+		t.Errorf("Expected a cleanup duration of at least 20ms and shorter than the run, got %v", result.CleanupDuration)
+	}
+	if sum := result.SetupDuration + result.RunDuration + result.CleanupDuration; sum != result.Duration {
+		t.Errorf("Expected the phases to sum to the duration %v, got %v", result.Duration, sum)
+	}
+	if result.EndTime.Sub(result.StartTime) != result.Duration {
+		t.Errorf("Expected the duration to span the start and end times, got %v", result.Duration)
+	}
+}
+
 // TestTestRunnerRunTestsWithSuiteSetupTeardown tests running tests with suite setup and teardown
 func TestTestRunnerRunTestsWithSuiteSetupTeardown(t *testing.T) {
 	fakeImpl := NewFakeTestImplementation()
@@ -657,6 +2040,276 @@ func TestTestRunnerGetSummary(t *testing.T) {
 	}
 }
 
+// TestTestRunnerProviderInfo tests reporting the provider name and cluster ID status
+func TestTestRunnerProviderInfo(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	fakeImpl.GetCloudProvider().(*fakecloud.Cloud).Provider = "fake-provider"
+	runner := NewTestRunner(fakeImpl)
+
+	if name := runner.ProviderName(); name != "fake-provider" {
+		t.Errorf("Expected provider name fake-provider, got %s", name)
+	}
+	if !runner.HasClusterID() {
+		t.Error("Expected HasClusterID to be true")
+	}
+
+	summary := runner.GetSummary()
+	if summary.ProviderName != "fake-provider" || !summary.HasClusterID {
+		t.Errorf("Expected summary to report fake-provider with a cluster ID, got %q and %v", summary.ProviderName, summary.HasClusterID)
+	}
+
+	noInterface := NewTestRunner(nil)
+	if name := noInterface.ProviderName(); name != UnknownProviderName {
+		t.Errorf("Expected provider name %s, got %s", UnknownProviderName, name)
+	}
+	if noInterface.HasClusterID() {
+		t.Error("Expected HasClusterID to be false without a provider")
+	}
+}
+
+// TestTestRunnerDumpResourcesOnFailure tests that a failing test's resources are dumped to DumpDir
+func TestTestRunnerDumpResourcesOnFailure(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	if err := fakeImpl.SetupTestEnvironment(&TestConfig{ProviderName: "fake"}); err != nil {
+		t.Fatalf("Failed to setup test environment: %v", err)
+	}
+
+	runner := NewTestRunner(fakeImpl)
+	runner.DumpDir = t.TempDir()
+
+	createService := func(name string) func(ti TestInterface) error {
+		return func(ti TestInterface) error {
+			_, err := ti.CreateTestService(context.Background(), &TestServiceConfig{
+				Name:  name,
+				Type:  v1.ServiceTypeClusterIP,
+				Ports: []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+			})
+			return err
+		}
+	}
+	runner.AddTestSuite(TestSuite{
+		Name: "Services",
+		Tests: []Test{
+			{Name: "Passing", Run: createService("passing-svc"), Timeout: 30 * time.Second},
+			{
+				Name: "Failing/Test",
+				Run: func(ti TestInterface) error {
+					if err := createService("failing-svc")(ti); err != nil {
+						return err
+					}
+					return fmt.Errorf("test failed")
+				},
+				Timeout: 30 * time.Second,
+			},
+		},
+	})
+
+	if err := runner.RunTests(context.Background()); !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+
+	entries, err := os.ReadDir(runner.DumpDir)
+	if err != nil {
+		t.Fatalf("Failed to read dump dir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "Services-Failing_Test.yaml" {
+		t.Fatalf("Expected only Services-Failing_Test.yaml to be dumped, got %v", entries)
+	}
+
+	data, err := os.ReadFile(filepath.Join(runner.DumpDir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("Failed to read dump: %v", err)
+	}
+	if !strings.Contains(string(data), "name: failing-svc") {
+		t.Errorf("Expected dump to contain the failing test's service, got:\n%s", data)
+	}
+}
+
+// TestTestRunnerGetSuiteSummaries tests that results are summarized per suite
+func TestTestRunnerGetSuiteSummaries(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	runner := NewTestRunner(fakeImpl)
+
+	pass := func(ti TestInterface) error { return nil }
+	fail := func(ti TestInterface) error { return fmt.Errorf("test failed") }
+
+	runner.AddTestSuite(TestSuite{
+		Name: "LoadBalancer",
+		Tests: []Test{
+			{Name: "Create", Run: pass, Timeout: 30 * time.Second},
+			{Name: "Delete", Run: pass, Timeout: 30 * time.Second},
+		},
+	})
+	runner.AddTestSuite(TestSuite{
+		Name: "Routes",
+		Tests: []Test{
+			{Name: "Create", Run: fail, Timeout: 30 * time.Second},
+			{Name: "List", Run: pass, Timeout: 30 * time.Second},
+			{Name: "Delete", Skip: true, Timeout: 30 * time.Second},
+		},
+	})
+
+	if err := runner.RunTests(context.Background()); !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("Expected ErrTestsFailed, got %v", err)
+	}
+
+	summaries := runner.GetSuiteSummaries()
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 suite summaries, got %d", len(summaries))
+	}
+
+	expected := []SuiteSummary{
+		{Name: "LoadBalancer", TestSummary: TestSummary{TotalTests: 2, PassedTests: 2}},
+		{Name: "Routes", TestSummary: TestSummary{TotalTests: 3, PassedTests: 1, FailedTests: 1, SkippedTests: 1}},
+	}
+	for i, want := range expected {
+		got := summaries[i]
+		if got.Name != want.Name || got.TotalTests != want.TotalTests || got.PassedTests != want.PassedTests ||
+			got.FailedTests != want.FailedTests || got.SkippedTests != want.SkippedTests {
+			t.Errorf("Expected suite summary %+v, got %+v", want, got)
+		}
+	}
+
+	for _, result := range runner.GetResults() {
+		if result.Suite == "" {
+			t.Errorf("Expected result for %s to record its suite", result.Test.Name)
+		}
+	}
+}
+
+// TestTestRunnerRunTestsWithUnsupportedCapability tests that tests returning an
+// UnsupportedError are reported as skipped with the error as the reason
+func TestTestRunnerRunTestsWithUnsupportedCapability(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	runner := NewTestRunner(fakeImpl)
+
+	suite := TestSuite{
+		Name:        "Unsupported Test Suite",
+		Description: "A test suite exercising an unsupported capability",
+		Tests: []Test{
+			{
+				Name:        "Unsupported Test",
+				Description: "A test whose capability is not supported",
+				Run: func(ti TestInterface) error {
+					return fmt.Errorf("probing routes: %w", NewUnsupportedError("routes"))
+				},
+				Timeout: 30 * time.Second,
+			},
+		},
+	}
+
+	runner.AddTestSuite(suite)
+
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	results := runner.GetResults()
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 test result, got %d", len(results))
+	}
+
+	if !results[0].Test.Skip {
+		t.Error("Expected unsupported test to be marked as skipped")
+	}
+
+	if results[0].Test.SkipReason != "probing routes: cloud provider does not support routes functionality" {
+		t.Errorf("Unexpected skip reason: %s", results[0].Test.SkipReason)
+	}
+
+	summary := runner.GetSummary()
+	if summary.SkippedTests != 1 || summary.FailedTests != 0 {
+		t.Errorf("Expected 1 skipped and 0 failed tests, got %d skipped and %d failed", summary.SkippedTests, summary.FailedTests)
+	}
+}
+
+// TestTestRunnerRunTestsWithCancellation tests that cancelling a run keeps the
+// results completed so far and returns ErrRunCancelled
+func TestTestRunnerRunTestsWithCancellation(t *testing.T) {
+	fakeImpl := NewFakeTestImplementation()
+	runner := NewTestRunner(fakeImpl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	teardownCalled := false
+	suite := TestSuite{
+		Name:        "Cancelled Test Suite",
+		Description: "A test suite cancelled partway through",
+		Teardown: func(ti TestInterface) error {
+			teardownCalled = true
+			return nil
+		},
+		Tests: []Test{
+			{
+				Name:        "First Test",
+				Description: "A test that runs before cancellation",
+				Run: func(ti TestInterface) error {
+					return nil
+				},
+				Timeout: 30 * time.Second,
+			},
+			{
+				Name:        "Cancelling Test",
+				Description: "A test that cancels the run",
+				Run: func(ti TestInterface) error {
+					cancel()
+					return nil
+				},
+				Timeout: 30 * time.Second,
+			},
+			{
+				Name:        "Skipped By Cancellation",
+				Description: "A test that should never run",
+				Run: func(ti TestInterface) error {
+					t.Error("Expected test after cancellation not to run")
+					return nil
+				},
+				Timeout: 30 * time.Second,
+			},
+		},
+	}
+
+	runner.AddTestSuite(suite)
+	runner.AddTestSuite(TestSuite{
+		Name: "Never Run Suite",
+		Tests: []Test{
+			{
+				Name: "Never Run Test",
+				Run: func(ti TestInterface) error {
+					t.Error("Expected suite after cancellation not to run")
+					return nil
+				},
+			},
+		},
+	})
+
+	err := runner.RunTests(ctx)
+	if !errors.Is(err, ErrRunCancelled) {
+		t.Fatalf("Expected ErrRunCancelled, got %v", err)
+	}
+
+	if !teardownCalled {
+		t.Error("Expected suite teardown to run after cancellation")
+	}
+
+	results := runner.GetResults()
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 partial test results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("Expected completed test %s to succeed", result.Test.Name)
+		}
+	}
+
+	summary := runner.GetSummary()
+	if summary.TotalTests != 2 || summary.PassedTests != 2 {
+		t.Errorf("Expected 2 total and 2 passed tests, got %d total and %d passed", summary.TotalTests, summary.PassedTests)
+	}
+}
+
 // TestTestConfigValidation tests TestConfig validation
 func TestTestConfigValidation(t *testing.T) {
 	config := &TestConfig{
@@ -706,6 +2359,58 @@ func TestTestConfigValidation(t *testing.T) {
 	}
 }
 
+// TestTestConfigResourceName tests resolving resource names against the name prefix
+func TestTestConfigResourceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *TestConfig
+		input    string
+		expected string
+	}{
+		{name: "nil config", config: nil, input: "test-node", expected: "test-node"},
+		{name: "no prefix", config: &TestConfig{}, input: "test-node", expected: "test-node"},
+		{name: "prefix applied", config: &TestConfig{NamePrefix: "ci-"}, input: "test-node", expected: "ci-test-node"},
+		{name: "already prefixed", config: &TestConfig{NamePrefix: "ci-"}, input: "ci-test-node", expected: "ci-test-node"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.ResourceName(tt.input); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestTestConfigGenerateResourceName tests that generated names are unique,
+// carry the name prefix and base, and are valid DNS labels
+func TestTestConfigGenerateResourceName(t *testing.T) {
+	config := &TestConfig{NamePrefix: "ci-"}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		name := config.GenerateResourceName("test-node")
+		if seen[name] {
+			t.Fatalf("Expected unique names, got '%s' twice", name)
+		}
+		seen[name] = true
+
+		if !strings.HasPrefix(name, "ci-test-node-") {
+			t.Fatalf("Expected name to start with 'ci-test-node-', got '%s'", name)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			t.Fatalf("Expected a valid DNS label, got '%s': %v", name, errs)
+		}
+	}
+
+	if got := config.ResourceNameOrGenerate("explicit", "test-node"); got != "ci-explicit" {
+		t.Errorf("Expected explicit name 'ci-explicit', got '%s'", got)
+	}
+	if got := config.ResourceNameOrGenerate("", "test-node"); !strings.HasPrefix(got, "ci-test-node-") {
+		t.Errorf("Expected generated name starting with 'ci-test-node-', got '%s'", got)
+	}
+}
+
 // TestTestNodeConfigValidation tests TestNodeConfig validation
 func TestTestNodeConfigValidation(t *testing.T) {
 	nodeConfig := &TestNodeConfig{
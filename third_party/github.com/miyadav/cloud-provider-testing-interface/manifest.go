@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// RunManifest describes the environment a test run executed in, so that the
+// run can be reproduced from its report.
+type RunManifest struct {
+	// Provider is the name of the cloud provider under test.
+	Provider string `json:"provider"`
+
+	// Region is the region the test resources were created in.
+	Region string `json:"region,omitempty"`
+
+	// Zone is the zone the test resources were created in.
+	Zone string `json:"zone,omitempty"`
+
+	// ClusterName is the name of the test cluster.
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// HarnessVersion is the version of the main module of the binary running
+	// the tests, as recorded in its build info.
+	HarnessVersion string `json:"harnessVersion"`
+
+	// GoVersion is the Go version the harness was built with.
+	GoVersion string `json:"goVersion"`
+
+	// Hostname is the name of the host the tests ran on.
+	Hostname string `json:"hostname,omitempty"`
+
+	// Config is the resolved TestConfig, with credentials redacted. It is nil
+	// if the TestInterface does not expose its config.
+	Config *ManifestConfig `json:"config,omitempty"`
+}
+
+// ManifestConfig is the part of a TestConfig recorded in a RunManifest. Clients
+// and informers are left out, and TestData is redacted with RedactTestData.
+type ManifestConfig struct {
+	TestTimeout          string                 `json:"testTimeout"`
+	InformerSyncTimeout  string                 `json:"informerSyncTimeout,omitempty"`
+	CleanupResources     bool                   `json:"cleanupResources"`
+	MockExternalServices bool                   `json:"mockExternalServices"`
+	NamePrefix           string                 `json:"namePrefix,omitempty"`
+	UseExistingNodes     bool                   `json:"useExistingNodes"`
+	MaxLogs              int                    `json:"maxLogs,omitempty"`
+	StrictValidation     bool                   `json:"strictValidation"`
+	TestData             map[string]interface{} `json:"testData,omitempty"`
+}
+
+// GetRunManifest returns the manifest of the environment the runner's tests
+// run in, including the config of its TestInterface.
+func (tr *TestRunner) GetRunManifest() RunManifest {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	manifest := RunManifest{
+		Provider:       tr.providerName(),
+		HarnessVersion: harnessVersion(),
+		GoVersion:      runtime.Version(),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		manifest.Hostname = hostname
+	}
+
+	if tr.TestInterface == nil {
+		return manifest
+	}
+	config := tr.TestInterface.GetConfig()
+	if config == nil {
+		return manifest
+	}
+
+	if manifest.Provider == UnknownProviderName && config.ProviderName != "" {
+		manifest.Provider = config.ProviderName
+	}
+	manifest.Region = config.Region
+	manifest.Zone = config.Zone
+	manifest.ClusterName = config.ClusterName
+	manifest.Config = &ManifestConfig{
+		TestTimeout:          config.TestTimeout.String(),
+		CleanupResources:     config.CleanupResources,
+		MockExternalServices: config.MockExternalServices,
+		NamePrefix:           config.NamePrefix,
+		UseExistingNodes:     config.UseExistingNodes,
+		MaxLogs:              config.MaxLogs,
+		StrictValidation:     config.StrictValidation,
+		TestData:             RedactTestData(config.TestData),
+	}
+	if config.InformerSyncTimeout > 0 {
+		manifest.Config.InformerSyncTimeout = config.InformerSyncTimeout.String()
+	}
+	return manifest
+}
+
+// harnessVersion returns the version of the main module from the build info,
+// or "unknown" if the binary carries none.
+func harnessVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "unknown"
+	}
+	return info.Main.Version
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestGetRunManifest tests that the run manifest records the provider and the
+// resolved config with credentials redacted
+func TestGetRunManifest(t *testing.T) {
+	impl := NewFakeTestImplementation()
+	config := &TestConfig{
+		ProviderName: "fake",
+		ClusterName:  "test-cluster",
+		Region:       "us-test-1",
+		Zone:         "us-test-1a",
+		TestTimeout:  5 * time.Minute,
+		TestData: map[string]interface{}{
+			"secret":           "hunter2",
+			"api-key":          "abc123",
+			"reconcile-cycles": 10,
+			"auth": map[string]interface{}{
+				"user": "admin",
+			},
+			"cloud": map[string]interface{}{
+				"clientPassword": "hunter2",
+				"endpoint":       "https://cloud.example.com",
+			},
+		},
+	}
+	if err := impl.SetupTestEnvironment(config); err != nil {
+		t.Fatalf("Failed to set up test environment: %v", err)
+	}
+
+	manifest := NewTestRunner(impl).GetRunManifest()
+
+	if manifest.Provider != "fake" {
+		t.Errorf("Expected provider fake, got %s", manifest.Provider)
+	}
+	if manifest.ClusterName != "test-cluster" || manifest.Region != "us-test-1" || manifest.Zone != "us-test-1a" {
+		t.Errorf("Expected cluster test-cluster in us-test-1/us-test-1a, got %s in %s/%s", manifest.ClusterName, manifest.Region, manifest.Zone)
+	}
+	if manifest.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version %s, got %s", runtime.Version(), manifest.GoVersion)
+	}
+	if manifest.HarnessVersion == "" {
+		t.Errorf("Expected a harness version")
+	}
+	if manifest.Config == nil {
+		t.Fatalf("Expected the config to be recorded")
+	}
+	if manifest.Config.TestTimeout != "5m0s" {
+		t.Errorf("Expected test timeout 5m0s, got %s", manifest.Config.TestTimeout)
+	}
+
+	testData := manifest.Config.TestData
+	for _, key := range []string{"secret", "api-key", "auth"} {
+		if testData[key] != RedactedValue {
+			t.Errorf("Expected %s to be redacted, got %v", key, testData[key])
+		}
+	}
+	if testData["reconcile-cycles"] != 10 {
+		t.Errorf("Expected reconcile-cycles to be kept, got %v", testData["reconcile-cycles"])
+	}
+	cloud, ok := testData["cloud"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected cloud to be a map, got %T", testData["cloud"])
+	}
+	if cloud["clientPassword"] != RedactedValue {
+		t.Errorf("Expected nested clientPassword to be redacted, got %v", cloud["clientPassword"])
+	}
+	if cloud["endpoint"] != "https://cloud.example.com" {
+		t.Errorf("Expected nested endpoint to be kept, got %v", cloud["endpoint"])
+	}
+
+	if config.TestData["secret"] != "hunter2" {
+		t.Errorf("Expected the config itself to be left unredacted, got %v", config.TestData["secret"])
+	}
+}
+
+// TestGetRunManifestWithoutConfig tests that the manifest falls back to the
+// cloud provider when the TestInterface has no config
+func TestGetRunManifestWithoutConfig(t *testing.T) {
+	impl := NewFakeTestImplementation()
+	impl.TestConfig = nil
+	manifest := NewTestRunner(impl).GetRunManifest()
+
+	if manifest.Provider != "fake" {
+		t.Errorf("Expected provider fake, got %s", manifest.Provider)
+	}
+	if manifest.Config != nil {
+		t.Errorf("Expected no config, got %+v", manifest.Config)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AllProviders registers a suite plugin for every provider.
+const AllProviders = "*"
+
+// SuitePlugin contributes test suites from outside the harness, such as the
+// provider-specific suites of an out-of-tree cloud provider, which registers
+// it from an init function of a package linked into the test binary.
+type SuitePlugin interface {
+	// RegisterSuites adds the plugin's suites to the runner.
+	RegisterSuites(r *TestRunner)
+}
+
+// SuitePluginFunc adapts an ordinary function to a SuitePlugin.
+type SuitePluginFunc func(r *TestRunner)
+
+// RegisterSuites calls f(r).
+func (f SuitePluginFunc) RegisterSuites(r *TestRunner) {
+	f(r)
+}
+
+// registeredSuitePlugin is a suite plugin and the provider it applies to.
+type registeredSuitePlugin struct {
+	provider string
+	plugin   SuitePlugin
+}
+
+var (
+	suitePluginsMu sync.RWMutex
+	suitePlugins   []registeredSuitePlugin
+)
+
+// RegisterSuitePlugin registers a plugin contributing suites for the named
+// provider, or for every provider if it is AllProviders. It returns an error if
+// the provider is empty or the plugin is nil.
+func RegisterSuitePlugin(provider string, plugin SuitePlugin) error {
+	if provider == "" {
+		return fmt.Errorf("suite plugin provider is required")
+	}
+	if plugin == nil {
+		return fmt.Errorf("suite plugin for %q is nil", provider)
+	}
+
+	suitePluginsMu.Lock()
+	defer suitePluginsMu.Unlock()
+
+	suitePlugins = append(suitePlugins, registeredSuitePlugin{provider: provider, plugin: plugin})
+	return nil
+}
+
+// CollectSuites returns the suites the plugins registered for the named
+// provider and for AllProviders contribute, in registration order.
+func CollectSuites(provider string) []TestSuite {
+	suitePluginsMu.RLock()
+	defer suitePluginsMu.RUnlock()
+
+	collector := NewTestRunner(nil)
+	for _, registered := range suitePlugins {
+		if registered.provider == provider || registered.provider == AllProviders {
+			registered.plugin.RegisterSuites(collector)
+		}
+	}
+	return collector.TestSuites
+}
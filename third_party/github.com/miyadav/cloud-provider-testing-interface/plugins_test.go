@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// registerTestSuitePlugin registers a suite plugin for the duration of the test
+func registerTestSuitePlugin(t *testing.T, provider string, plugin SuitePlugin) {
+	t.Helper()
+
+	suitePluginsMu.RLock()
+	previous := suitePlugins
+	suitePluginsMu.RUnlock()
+
+	if err := RegisterSuitePlugin(provider, plugin); err != nil {
+		t.Fatalf("Failed to register suite plugin: %v", err)
+	}
+	t.Cleanup(func() {
+		suitePluginsMu.Lock()
+		suitePlugins = previous
+		suitePluginsMu.Unlock()
+	})
+}
+
+// exampleProviderPlugin is an out-of-tree provider plugin contributing a
+// suite for a feature only its cloud offers
+type exampleProviderPlugin struct{}
+
+func (exampleProviderPlugin) RegisterSuites(r *TestRunner) {
+	r.AddTestSuite(TestSuite{
+		Name:        "Example Provider Firewall",
+		Description: "Tests the firewall rules the example provider creates for load balancers",
+		Tests: []Test{
+			{
+				Name:    "FirewallRuleCreated",
+				Timeout: time.Minute,
+				Run: func(ti TestInterface) error {
+					if _, supported := ti.GetCloudProvider().LoadBalancer(); !supported {
+						return NewUnsupportedError("load balancers")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// TestCollectSuites tests that suites are collected from the plugins of the
+// requested provider and of every provider, in registration order
+func TestCollectSuites(t *testing.T) {
+	registerTestSuitePlugin(t, "example", exampleProviderPlugin{})
+	registerTestSuitePlugin(t, "other", SuitePluginFunc(func(r *TestRunner) {
+		r.AddTestSuite(TestSuite{Name: "Other Provider"})
+	}))
+	registerTestSuitePlugin(t, AllProviders, SuitePluginFunc(func(r *TestRunner) {
+		r.AddTestSuite(TestSuite{Name: "Shared"})
+	}))
+
+	tests := []struct {
+		provider string
+		expected []string
+	}{
+		{provider: "example", expected: []string{"Example Provider Firewall", "Shared"}},
+		{provider: "other", expected: []string{"Other Provider", "Shared"}},
+		{provider: "mock", expected: []string{"Shared"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var names []string
+			for _, suite := range CollectSuites(tt.provider) {
+				names = append(names, suite.Name)
+			}
+			if strings.Join(names, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("Expected suites %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+// TestCollectSuitesRun tests that a collected plugin suite runs alongside the
+// built-in suites
+func TestCollectSuitesRun(t *testing.T) {
+	registerTestSuitePlugin(t, "example", exampleProviderPlugin{})
+
+	runner := NewTestRunner(NewFakeTestImplementation())
+	runner.AddTestSuite(TestSuite{
+		Name:  "Built-in",
+		Tests: []Test{{Name: "Passes", Run: func(TestInterface) error { return nil }}},
+	})
+	for _, suite := range CollectSuites("example") {
+		if err := runner.AddTestSuiteChecked(suite); err != nil {
+			t.Fatalf("Expected the plugin suite to be valid, got %v", err)
+		}
+	}
+
+	if err := runner.RunTests(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	summaries := runner.GetSuiteSummaries()
+	if len(summaries) != 2 || summaries[1].Name != "Example Provider Firewall" || summaries[1].PassedTests != 1 {
+		t.Errorf("Expected the plugin suite to pass after the built-in one, got %+v", summaries)
+	}
+}
+
+// TestRegisterSuitePluginErrors tests that invalid registrations are rejected
+func TestRegisterSuitePluginErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		plugin   SuitePlugin
+	}{
+		{name: "empty provider", provider: "", plugin: exampleProviderPlugin{}},
+		{name: "nil plugin", provider: "example", plugin: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterSuitePlugin(tt.provider, tt.plugin); err == nil {
+				t.Error("Expected registration to fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces the values of credentials redacted by RedactSecrets
+// and RedactTestData.
+const RedactedValue = "***"
+
+// sensitiveKeyMarkers are the fragments of key names, lower-cased and stripped
+// of separators, that mark their values as credentials.
+var sensitiveKeyMarkers = []string{
+	"secret",
+	"key",
+	"token",
+	"password",
+	"passwd",
+	"credential",
+	"auth",
+}
+
+// sensitiveValuePatterns match values that are credentials whatever the key
+// they are stored under.
+var sensitiveValuePatterns = []*regexp.Regexp{
+	// PEM-encoded private keys
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	// HTTP authorization header values
+	regexp.MustCompile(`(?i)^(bearer|basic)\s+\S+`),
+	// AWS access key IDs
+	regexp.MustCompile(`^(AKIA|ASIA)[0-9A-Z]{16}$`),
+	// JSON web tokens
+	regexp.MustCompile(`^eyJ[0-9A-Za-z_-]+\.[0-9A-Za-z_-]+\.[0-9A-Za-z_-]*$`),
+}
+
+// RedactSecrets returns a copy of m with the values of keys that look like
+// credentials, and values that look like credentials under any key, replaced
+// with RedactedValue. It is meant for maps such as cloud credentials that are
+// logged or reported.
+func RedactSecrets(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(m))
+	for key, value := range m {
+		if isSensitiveKey(key) || isSensitiveValue(value) {
+			redacted[key] = RedactedValue
+			continue
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// RedactTestData returns a copy of data redacted like RedactSecrets, including
+// the maps nested in it. Values that are not strings are only redacted by key.
+func RedactTestData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if isSensitiveKey(key) {
+			redacted[key] = RedactedValue
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			redacted[key] = RedactTestData(v)
+		case map[string]string:
+			redacted[key] = RedactSecrets(v)
+		case string:
+			if isSensitiveValue(v) {
+				redacted[key] = RedactedValue
+			} else {
+				redacted[key] = v
+			}
+		default:
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+// isSensitiveKey reports whether a key name looks like it holds a credential,
+// such as "secret", "api-key", "access_token" or "clientPassword".
+func isSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("-", "", "_", "", ".", "").Replace(strings.ToLower(key))
+	for _, marker := range sensitiveKeyMarkers {
+		if strings.Contains(normalized, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSensitiveValue(value string) bool {
+	for _, pattern := range sensitiveValuePatterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
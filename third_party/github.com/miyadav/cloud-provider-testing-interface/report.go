@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReportFormatter writes the results of a test run in a particular format,
+// such as human-readable text, JSON or a chat message.
+type ReportFormatter interface {
+	// Format writes the report for results and summary to w.
+	Format(w io.Writer, results []TestResult, summary TestSummary) error
+}
+
+// ReportFormatterFunc adapts an ordinary function to a ReportFormatter.
+type ReportFormatterFunc func(w io.Writer, results []TestResult, summary TestSummary) error
+
+// Format calls f(w, results, summary).
+func (f ReportFormatterFunc) Format(w io.Writer, results []TestResult, summary TestSummary) error {
+	return f(w, results, summary)
+}
+
+// RunDetails holds the parts of a test run that are not captured by its
+// results, for formatters that report them.
+type RunDetails struct {
+	// Duration is the wall-clock duration of the run, including setup and
+	// teardown.
+	Duration time.Duration
+
+	// Logs are the logs the test interface collected during the run.
+	Logs []string
+
+	// Verbose asks for per-test results and logs to be included.
+	Verbose bool
+
+	// Manifest describes the environment of the run, or is nil if it was not
+	// collected.
+	Manifest *RunManifest
+}
+
+// RunDetailsFormatter is implemented by formatters that also report the
+// details of the run. WithRunDetails returns a formatter for those details,
+// leaving the registered formatter unchanged.
+type RunDetailsFormatter interface {
+	ReportFormatter
+	WithRunDetails(details RunDetails) ReportFormatter
+}
+
+// ErrUnknownReportFormat is returned for a format no formatter is registered for.
+var ErrUnknownReportFormat = errors.New("unknown report format")
+
+var (
+	reportFormattersMu sync.RWMutex
+	reportFormatters   = make(map[string]ReportFormatter)
+)
+
+// RegisterReportFormatter makes a formatter available under the given format
+// name. It returns an error if the name is empty, the formatter is nil or the
+// name is already taken.
+func RegisterReportFormatter(name string, formatter ReportFormatter) error {
+	if name == "" {
+		return fmt.Errorf("report format name is required")
+	}
+	if formatter == nil {
+		return fmt.Errorf("report formatter for %q is nil", name)
+	}
+
+	reportFormattersMu.Lock()
+	defer reportFormattersMu.Unlock()
+
+	if _, found := reportFormatters[name]; found {
+		return fmt.Errorf("report formatter for %q is already registered", name)
+	}
+	reportFormatters[name] = formatter
+	return nil
+}
+
+// GetReportFormatter returns the formatter registered under the given format
+// name.
+func GetReportFormatter(name string) (ReportFormatter, bool) {
+	reportFormattersMu.RLock()
+	defer reportFormattersMu.RUnlock()
+
+	formatter, found := reportFormatters[name]
+	return formatter, found
+}
+
+// ReportFormats returns the names of the registered formats, sorted.
+func ReportFormats() []string {
+	reportFormattersMu.RLock()
+	defer reportFormattersMu.RUnlock()
+
+	names := make([]string, 0, len(reportFormatters))
+	for name := range reportFormatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatReport writes the report for results and summary to w using the
+// formatter registered under the given format name, passing it the run
+// details if it reports them.
+func FormatReport(w io.Writer, format string, results []TestResult, summary TestSummary, details RunDetails) error {
+	formatter, found := GetReportFormatter(format)
+	if !found {
+		return fmt.Errorf("%w: %q", ErrUnknownReportFormat, format)
+	}
+
+	if detailed, ok := formatter.(RunDetailsFormatter); ok {
+		formatter = detailed.WithRunDetails(details)
+	}
+	return formatter.Format(w, results, summary)
+}
+
+// SummarizeSuites returns a summary of the results of each suite, in the
+// order the suites first appear in results.
+func SummarizeSuites(results []TestResult) []SuiteSummary {
+	var names []string
+	bySuite := make(map[string][]TestResult)
+	for _, result := range results {
+		if _, found := bySuite[result.Suite]; !found {
+			names = append(names, result.Suite)
+		}
+		bySuite[result.Suite] = append(bySuite[result.Suite], result)
+	}
+
+	summaries := make([]SuiteSummary, 0, len(names))
+	for _, name := range names {
+		summaries = append(summaries, SuiteSummary{
+			Name:        name,
+			TestSummary: summarizeResults(bySuite[name]),
+		})
+	}
+	return summaries
+}
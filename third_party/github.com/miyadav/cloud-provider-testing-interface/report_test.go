@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// registerTestReportFormatter registers a formatter for the duration of the test
+func registerTestReportFormatter(t *testing.T, name string, formatter ReportFormatter) {
+	t.Helper()
+
+	if err := RegisterReportFormatter(name, formatter); err != nil {
+		t.Fatalf("Failed to register formatter: %v", err)
+	}
+	t.Cleanup(func() {
+		reportFormattersMu.Lock()
+		delete(reportFormatters, name)
+		reportFormattersMu.Unlock()
+	})
+}
+
+// csvFormatter is a custom formatter writing one line per test result
+type csvFormatter struct {
+	details RunDetails
+}
+
+func (f *csvFormatter) WithRunDetails(details RunDetails) ReportFormatter {
+	return &csvFormatter{details: details}
+}
+
+func (f *csvFormatter) Format(w io.Writer, results []TestResult, summary TestSummary) error {
+	fmt.Fprintf(w, "suite,test,success\n")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s,%s,%t\n", result.Suite, result.Test.Name, result.Success)
+	}
+	fmt.Fprintf(w, "# %d total in %v\n", summary.TotalTests, f.details.Duration)
+	return nil
+}
+
+// TestFormatReportCustomFormatter tests that a registered custom formatter is
+// invoked by its format name and receives the run details
+func TestFormatReportCustomFormatter(t *testing.T) {
+	registerTestReportFormatter(t, "csv", &csvFormatter{})
+
+	results := []TestResult{
+		{Suite: "Nodes", Test: Test{Name: "NodeAddresses"}, Success: true},
+		{Suite: "Nodes", Test: Test{Name: "NodeZones"}, Success: false},
+	}
+	summary := summarizeResults(results)
+
+	var buf bytes.Buffer
+	if err := FormatReport(&buf, "csv", results, summary, RunDetails{Duration: 3 * time.Second}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "suite,test,success\nNodes,NodeAddresses,true\nNodes,NodeZones,false\n# 2 total in 3s\n"
+	if buf.String() != expected {
+		t.Errorf("Expected report %q, got %q", expected, buf.String())
+	}
+
+	found := false
+	for _, name := range ReportFormats() {
+		if name == "csv" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected csv in registered formats, got %v", ReportFormats())
+	}
+}
+
+// TestFormatReportFunc tests that a plain function can be registered as a formatter
+func TestFormatReportFunc(t *testing.T) {
+	registerTestReportFormatter(t, "count", ReportFormatterFunc(func(w io.Writer, results []TestResult, summary TestSummary) error {
+		_, err := fmt.Fprintf(w, "%d/%d", summary.PassedTests, summary.TotalTests)
+		return err
+	}))
+
+	results := []TestResult{{Suite: "Zones", Test: Test{Name: "GetZone"}, Success: true}}
+
+	var buf bytes.Buffer
+	if err := FormatReport(&buf, "count", results, summarizeResults(results), RunDetails{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if buf.String() != "1/1" {
+		t.Errorf("Expected report '1/1', got '%s'", buf.String())
+	}
+}
+
+// TestRegisterReportFormatterErrors tests that invalid and duplicate registrations are rejected
+func TestRegisterReportFormatterErrors(t *testing.T) {
+	registerTestReportFormatter(t, "taken", &csvFormatter{})
+
+	tests := []struct {
+		name      string
+		format    string
+		formatter ReportFormatter
+	}{
+		{name: "empty name", format: "", formatter: &csvFormatter{}},
+		{name: "nil formatter", format: "nil", formatter: nil},
+		{name: "duplicate name", format: "taken", formatter: &csvFormatter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterReportFormatter(tt.format, tt.formatter); err == nil {
+				t.Error("Expected registration to fail")
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	err := FormatReport(&buf, "unregistered", nil, TestSummary{}, RunDetails{})
+	if !errors.Is(err, ErrUnknownReportFormat) {
+		t.Errorf("Expected ErrUnknownReportFormat, got %v", err)
+	}
+}
+
+// TestSummarizeSuites tests that results are summarized per suite in the order the suites appear
+func TestSummarizeSuites(t *testing.T) {
+	results := []TestResult{
+		{Suite: "Routes", Test: Test{Name: "CreateRoute"}, Success: true, Duration: time.Second},
+		{Suite: "Nodes", Test: Test{Name: "NodeZones"}, Success: false, Duration: time.Second},
+		{Suite: "Routes", Test: Test{Name: "DeleteRoute", Skip: true}},
+	}
+
+	suites := SummarizeSuites(results)
+	if len(suites) != 2 {
+		t.Fatalf("Expected 2 suites, got %d", len(suites))
+	}
+
+	if suites[0].Name != "Routes" || suites[0].TotalTests != 2 || suites[0].PassedTests != 1 || suites[0].SkippedTests != 1 {
+		t.Errorf("Expected Routes with 1 passed and 1 skipped, got %+v", suites[0])
+	}
+	if suites[1].Name != "Nodes" || suites[1].FailedTests != 1 {
+		t.Errorf("Expected Nodes with 1 failed, got %+v", suites[1])
+	}
+}